@@ -24,9 +24,105 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/audit": {
+            "get": {
+                "description": "Lists the recorded changes to companies and portfolios, oldest first: who made each one, through which endpoint, and how the aggregate differs from before. Filters combine; without any, the whole log is listed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "company or portfolio",
+                        "name": "aggregateType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ticker or portfolio ID; needs aggregateType",
+                        "name": "aggregateId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who made the changes, as sent in X-Actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recorded at or after (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recorded at or before (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching entries of the audit log",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.AuditEntryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit/verify": {
+            "get": {
+                "description": "Checks the hash chain of the whole audit log, reporting the first entry that was changed, removed or moved since it was recorded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Verify the audit log",
+                "responses": {
+                    "200": {
+                        "description": "Result of the check",
+                        "schema": {
+                            "$ref": "#/definitions/http.AuditVerificationResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/company": {
             "get": {
-                "description": "Get company details by its stock ticker",
+                "description": "Get company details by its stock ticker. With knownAt, the company is returned as it was known at that time: with the financial metrics and score known then, free of later restatements. Past states carry no ETag, as they cannot be changed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -44,6 +140,12 @@ const docTemplate = `{
                         "name": "ticker",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC 3339, e.g. 2024-06-03T10:00:00Z)",
+                        "name": "knownAt",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -51,6 +153,12 @@ const docTemplate = `{
                         "description": "Successfully retrieved company",
                         "schema": {
                             "$ref": "#/definitions/company.Company"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the company"
+                            }
                         }
                     },
                     "400": {
@@ -59,12 +167,70 @@ const docTemplate = `{
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Company not found, or none of its metrics known at knownAt",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/company/corporate-actions": {
+            "post": {
+                "description": "Records a split, reverse split, spinoff or ticker change on a company and adjusts every portfolio holding its shares. Cost bases are preserved and each adjustment is recorded in the portfolio ledger.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "corporate-actions"
+                ],
+                "summary": "Apply a corporate action",
+                "parameters": [
+                    {
+                        "description": "Corporate action",
+                        "name": "action",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CorporateActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Applied action and affected portfolios",
+                        "schema": {
+                            "$ref": "#/definitions/application.CorporateActionResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Company not found",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "The company or a portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -103,6 +269,12 @@ const docTemplate = `{
                         "description": "Successfully created company",
                         "schema": {
                             "$ref": "#/definitions/company.Company"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the company"
+                            }
                         }
                     },
                     "400": {
@@ -111,6 +283,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Company already exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -120,9 +298,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/company/dividends": {
             "get": {
-                "description": "Get the status of server.",
+                "description": "Lists a company's declared dividends with its trailing twelve-month dividend per share and dividend yield.",
                 "consumes": [
                     "application/json"
                 ],
@@ -130,25 +308,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "dividends"
+                ],
+                "summary": "Get company dividends",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Company Ticker",
+                        "name": "ticker",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Show the status of server.",
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved health status",
+                        "description": "Dividend summary",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/application.DividendSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Company not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/portfolio": {
-            "get": {
-                "description": "Get details of a specific portfolio by its ID.",
+        "/company/dividends/declare": {
+            "post": {
+                "description": "Records a cash dividend declared by a company, with its ex-date, pay date and amount per share.",
                 "consumes": [
                     "application/json"
                 ],
@@ -156,33 +358,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "dividends"
                 ],
-                "summary": "Get portfolio details",
+                "summary": "Declare a dividend",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Portfolio ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Dividend declaration",
+                        "name": "dividend",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.DeclareDividendRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully retrieved portfolio",
+                    "201": {
+                        "description": "Recorded dividend",
                         "schema": {
-                            "$ref": "#/definitions/portfolio.Portfolio"
+                            "$ref": "#/definitions/company.Dividend"
                         }
                     },
                     "400": {
-                        "description": "Invalid request (e.g., missing ID)",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Portfolio not found",
+                        "description": "Company not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Company changed concurrently",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
@@ -196,9 +406,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/portfolio/create": {
+        "/company/metrics": {
             "post": {
-                "description": "Creates a new investment portfolio.",
+                "description": "Records a company's financial metrics for a reporting period as they became known. A restatement of a period is recorded beside the earlier figures rather than replacing them, so that the company can still be read as known before it. Revisions known earlier than others already recorded may be loaded late.",
                 "consumes": [
                     "application/json"
                 ],
@@ -206,29 +416,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "companies"
                 ],
-                "summary": "Create a new portfolio",
+                "summary": "Revise company metrics",
                 "parameters": [
                     {
-                        "description": "Portfolio data to create",
-                        "name": "portfolio",
+                        "description": "Metrics revision",
+                        "name": "revision",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.CreatePortfolioRequest"
+                            "$ref": "#/definitions/http.ReviseMetricsRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created portfolio",
+                    "200": {
+                        "description": "Company with the revision recorded",
                         "schema": {
-                            "$ref": "#/definitions/portfolio.Portfolio"
+                            "$ref": "#/definitions/company.Company"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the company"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid portfolio data provided",
+                        "description": "Invalid request, e.g. figures known before their period ended",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Company not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Company changed concurrently",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
@@ -241,137 +469,4434 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "company.Company": {
-            "type": "object",
-            "properties": {
-                "currentScore": {
-                    "type": "number"
-                },
-                "financialMetrics": {
-                    "description": "Defined in financial_metrics.go",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/company.FinancialMetrics"
+        },
+        "/dividends/process": {
+            "post": {
+                "description": "Credits every dividend paid up to the given date to the portfolios that held the shares before the ex-date, applying withholding tax and reinvestment. Dividends are never credited twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dividends"
+                ],
+                "summary": "Process paid dividends",
+                "parameters": [
+                    {
+                        "description": "Processing date",
+                        "name": "process",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProcessDividendsRequest"
                         }
-                    ]
-                },
-                "sector": {
-                    "description": "Enum defined in sector.go",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/company.Sector"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dividends credited by this run",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/portfolio.DividendPayment"
+                            }
                         }
-                    ]
-                },
-                "ticker": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
-                }
-            }
-        },
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A portfolio changed concurrently; run again",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Get the status of server.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Show the status of server.",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved health status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio": {
+            "get": {
+                "description": "Get details of a specific portfolio by its ID. With asOf, an event-sourced portfolio is rebuilt as it was at that time; past states carry no ETag, as they cannot be changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC 3339, e.g. 2024-06-03T10:00:00Z)",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the portfolio, for If-Match on changes"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request (e.g., missing ID)",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "asOf given but portfolios are not event-sourced",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/benchmark": {
+            "post": {
+                "description": "Designates what a portfolio's performance is compared against: an index proxy ticker, or a named basket of tickers whose weights sum to 1. An empty ticker and basket reverts to the configured default benchmark.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Set portfolio benchmark",
+                "parameters": [
+                    {
+                        "description": "Benchmark designation",
+                        "name": "benchmark",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.BenchmarkRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/benchmark/comparison": {
+            "get": {
+                "description": "Reports a portfolio's time-weighted return against its benchmark's, the active return, the annualized tracking error and information ratio, and a Brinson-Fachler attribution of the active return to sector allocation, selection and interaction, from its end-of-day valuation snapshots.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Compare portfolio with its benchmark",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD), defaults to the first snapshot",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD), inclusive; defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Relative performance and attribution",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.BenchmarkComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or no benchmark designated",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Not enough valuation history or no FX rate available",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cash/deposit": {
+            "post": {
+                "description": "Adds external cash to a portfolio. Recorded as an external cash flow for performance calculations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cash"
+                ],
+                "summary": "Deposit cash",
+                "parameters": [
+                    {
+                        "description": "Deposit details",
+                        "name": "deposit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CashFlowRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cash/exchange": {
+            "post": {
+                "description": "Converts cash held in one currency into another at the current FX rate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cash"
+                ],
+                "summary": "Exchange portfolio cash",
+                "parameters": [
+                    {
+                        "description": "Amount to convert and target currency",
+                        "name": "exchange",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ExchangeCashRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Insufficient cash or no FX rate for the currency pair",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cash/flows": {
+            "get": {
+                "description": "Lists the deposits and withdrawals of a portfolio, optionally within a date range.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cash"
+                ],
+                "summary": "List external cash flows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD), inclusive",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "External cash flows",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/portfolio.CashFlow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cash/interest": {
+            "post": {
+                "description": "Sets the annual interest rate earned on idle cash in a currency. A rate of 0 disables accrual.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cash"
+                ],
+                "summary": "Set cash interest rate",
+                "parameters": [
+                    {
+                        "description": "Currency and annual rate",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.InterestRateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cash/interest/accrue": {
+            "post": {
+                "description": "Credits the interest earned on idle cash since the last accrual.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cash"
+                ],
+                "summary": "Accrue cash interest",
+                "parameters": [
+                    {
+                        "description": "Portfolio and accrual date",
+                        "name": "accrual",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AccrueInterestRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cash/withdraw": {
+            "post": {
+                "description": "Takes external cash out of a portfolio. The cash balance can never go negative.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cash"
+                ],
+                "summary": "Withdraw cash",
+                "parameters": [
+                    {
+                        "description": "Withdrawal details",
+                        "name": "withdrawal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CashFlowRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Insufficient cash",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/create": {
+            "post": {
+                "description": "Creates a new investment portfolio.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Create a new portfolio",
+                "parameters": [
+                    {
+                        "description": "Portfolio data to create",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreatePortfolioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid portfolio data provided",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/dividends/policy": {
+            "post": {
+                "description": "Configures the withholding tax applied to a portfolio's dividends and whether they are reinvested (DRIP).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dividends"
+                ],
+                "summary": "Set dividend policy",
+                "parameters": [
+                    {
+                        "description": "Dividend policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.DividendPolicyRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/events": {
+            "get": {
+                "description": "Lists the changes saved to an event-sourced portfolio, oldest first, its deletion included. Each event carries the state of what it changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Events of the portfolio",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.EventResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Portfolios are not event-sourced",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/exposure": {
+            "get": {
+                "description": "Breaks a portfolio down by position and sector weight at the latest prices and lists the limits of its risk policy it breaches, with their severity and the holdings causing them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio exposure",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Position and sector exposure",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Exposure"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No FX rate available for a currency held",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/fees": {
+            "post": {
+                "description": "Configures the commissions charged on a portfolio's trades (flat, per share, percentage, with min/max), the FX spread on currency exchanges and the slippage assumed for market orders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Set fee schedule",
+                "parameters": [
+                    {
+                        "description": "Fee schedule",
+                        "name": "fees",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.FeeScheduleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/performance": {
+            "get": {
+                "description": "Reports a portfolio's time-weighted return (chain-linked across deposits and withdrawals) and money-weighted return (XIRR) month-to-date, quarter-to-date, year-to-date, over one year and since inception, computed from its ledger and end-of-day valuation snapshots. Periods of a year or more are also annualized.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio performance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of the periods (YYYY-MM-DD); defaults to now, valued at the latest prices",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date of an additional custom period (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns per period",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Performance"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No valuation history for the custom period or no FX rate available",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/pnl": {
+            "get": {
+                "description": "Breaks down a portfolio's result into realized and unrealized gains, dividends, withholding tax, interest and fees, in the base currency.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Get profit and loss",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD), inclusive",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profit and loss",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.ProfitAndLoss"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No FX rate available for a currency held",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/rebalance": {
+            "get": {
+                "description": "Proposes the whole-share orders that move a portfolio towards the target weights derived from company scores and its risk profile, within the available cash, minimum trade size and tolerance band.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Recommend a rebalance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rebalancing orders",
+                        "schema": {
+                            "$ref": "#/definitions/application.RebalanceRecommendation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No holding drifted beyond the tolerance band, or an FX rate is missing",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/rebalance/execute": {
+            "post": {
+                "description": "Executes an approved, unexpired recommendation whose prices have not drifted beyond the configured tolerance. All trades are applied or none is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Execute a rebalance",
+                "parameters": [
+                    {
+                        "description": "Recommendation to execute",
+                        "name": "execution",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ExecuteRebalanceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fills, fees and residual cash",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.ExecutionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio or recommendation not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Recommendation is not approved or has expired, or the portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Prices drifted beyond the tolerance, or not enough cash",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/recommendations": {
+            "get": {
+                "description": "Lists the rebalancing recommendations made for a portfolio, oldest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "List portfolio recommendations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recommendations",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/application.RebalanceRecommendation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/risk": {
+            "get": {
+                "description": "Computes a portfolio's annualized volatility, beta against the configured benchmark, maximum drawdown and 1-day/10-day historical and parametric value at risk at 95% and 99%, from the daily prices of its current holdings.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "risk"
+                ],
+                "summary": "Get portfolio risk metrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date to measure risk at (YYYY-MM-DD), defaults to today",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Risk metrics",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.RiskMetrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Not enough price history or no FX rate available",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/risk-policy": {
+            "post": {
+                "description": "Assigns a risk policy to a portfolio: the default policy of a built-in risk profile or a custom policy from the configuration. The policy limits position and sector weights, the cash buffer, the entry score and the debt-to-equity of companies bought.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Set risk policy",
+                "parameters": [
+                    {
+                        "description": "Risk policy assignment",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RiskPolicyRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only apply to this version of the portfolio, as in its ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Portfolio"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated portfolio"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio or risk policy not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Portfolio changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Portfolio is not at the If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/risk/history": {
+            "get": {
+                "description": "Lists the risk metrics recorded daily for a portfolio, oldest first, so that trends can be charted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "risk"
+                ],
+                "summary": "Get portfolio risk metrics history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD), inclusive",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daily risk metrics",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/portfolio.RiskMetrics"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/target-weights": {
+            "get": {
+                "description": "Scores the companies under the portfolio's risk policy into the target weights a rebalance moves towards. With knownAt, they are scored on the financial metrics and scores known then, without look-ahead from later reports or restatements; companies with nothing known then get no weight.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get target weights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC 3339, e.g. 2024-06-03T10:00:00Z)",
+                        "name": "knownAt",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Target weights",
+                        "schema": {
+                            "$ref": "#/definitions/http.TargetWeightsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/valuation": {
+            "get": {
+                "description": "Values a portfolio's holdings and cash in its base currency using the latest prices and FX rates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio valuation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully valued portfolio",
+                        "schema": {
+                            "$ref": "#/definitions/portfolio.Valuation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request (e.g., missing ID)",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Portfolio not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Missing FX rate for a held currency",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/valuation/history": {
+            "get": {
+                "description": "Lists the end-of-day valuation snapshots of a portfolio (holdings, prices, market value, cash and base-currency total), oldest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio valuation history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD), inclusive",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daily valuation snapshots",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/portfolio.Valuation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recommendation": {
+            "get": {
+                "description": "Retrieves a rebalancing recommendation with its trades and approval status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Get a recommendation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recommendation ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recommendation",
+                        "schema": {
+                            "$ref": "#/definitions/application.RebalanceRecommendation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Recommendation not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recommendation/approve": {
+            "post": {
+                "description": "Approves a proposed recommendation so that it can be executed before it expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Approve a recommendation",
+                "parameters": [
+                    {
+                        "description": "Approval",
+                        "name": "approval",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ApproveRecommendationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Approved recommendation",
+                        "schema": {
+                            "$ref": "#/definitions/application.RebalanceRecommendation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Recommendation not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Recommendation is not proposed or has expired",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recommendation/reject": {
+            "post": {
+                "description": "Rejects a proposed recommendation, recording who rejected it and why.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Reject a recommendation",
+                "parameters": [
+                    {
+                        "description": "Rejection",
+                        "name": "rejection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RejectRecommendationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rejected recommendation",
+                        "schema": {
+                            "$ref": "#/definitions/application.RebalanceRecommendation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Recommendation not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Recommendation is not proposed or has expired",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/risk-policies": {
+            "get": {
+                "description": "Lists the risk policies portfolios can be assigned: the defaults of the built-in risk profiles and the custom policies from the configuration.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "List risk policies",
+                "responses": {
+                    "200": {
+                        "description": "Risk policies, sorted by name",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/portfolio.RiskPolicy"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist": {
+            "get": {
+                "description": "Retrieves a watchlist with the target of each watched company.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Get a watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watchlist ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Watchlist",
+                        "schema": {
+                            "$ref": "#/definitions/watchlist.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist/create": {
+            "post": {
+                "description": "Creates an empty watchlist belonging to a user or kept for a portfolio. Entry signals of a portfolio's watchlist become position entry recommendations for it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Create a watchlist",
+                "parameters": [
+                    {
+                        "description": "Watchlist",
+                        "name": "watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateWatchlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created watchlist",
+                        "schema": {
+                            "$ref": "#/definitions/watchlist.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist/delete": {
+            "post": {
+                "description": "Deletes a watchlist and everything on it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Delete a watchlist",
+                "parameters": [
+                    {
+                        "description": "Watchlist to delete",
+                        "name": "watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.DeleteWatchlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Watchlist deleted"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist/unwatch": {
+            "post": {
+                "description": "Removes a company from a watchlist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Unwatch a company",
+                "parameters": [
+                    {
+                        "description": "Company to remove",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UnwatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated watchlist",
+                        "schema": {
+                            "$ref": "#/definitions/watchlist.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist not found or company not watched",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlist/watch": {
+            "post": {
+                "description": "Adds a company to a watchlist with a target buy price, a target score or both, or replaces the target of a company already watched. An entry signal fires when either target is met.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Watch a company",
+                "parameters": [
+                    {
+                        "description": "Company and target",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.WatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated watchlist",
+                        "schema": {
+                            "$ref": "#/definitions/watchlist.Watchlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Watchlist or company not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists": {
+            "get": {
+                "description": "Lists the watchlists of a user or of a portfolio, oldest first; every watchlist when neither is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "List watchlists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Portfolio ID",
+                        "name": "portfolioId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Watchlists",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/watchlist.Watchlist"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/check": {
+            "post": {
+                "description": "Evaluates every watchlist against the latest prices and scores. Each company whose target buy price or target score was crossed raises an alert; for watchlists kept for a portfolio it proposes a position entry recommendation. Signals fire once per crossing. This also runs periodically.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watchlists"
+                ],
+                "summary": "Check entry signals",
+                "responses": {
+                    "200": {
+                        "description": "Alerts raised by this check",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/application.EntryAlert"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "application.CorporateActionResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/company.CorporateAction"
+                },
+                "affectedPortfolios": {
+                    "description": "IDs of the portfolios whose holdings were adjusted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "application.DividendSummary": {
+            "type": "object",
+            "properties": {
+                "asOf": {
+                    "type": "string"
+                },
+                "dividends": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/company.Dividend"
+                    }
+                },
+                "price": {
+                    "description": "Latest known share price, nil if unknown",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "trailingAnnualDividend": {
+                    "description": "Dividends per share with an ex-date in the last twelve months",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "yield": {
+                    "description": "TrailingAnnualDividend / Price, zero when the price is unknown",
+                    "type": "number"
+                }
+            }
+        },
+        "application.EntryAlert": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Why no recommendation was proposed, if so",
+                    "type": "string"
+                },
+                "recommendationID": {
+                    "description": "Empty when no recommendation was proposed",
+                    "type": "string"
+                },
+                "signal": {
+                    "$ref": "#/definitions/watchlist.EntrySignalTriggeredEvent"
+                }
+            }
+        },
+        "application.RebalanceRecommendation": {
+            "type": "object",
+            "properties": {
+                "decidedBy": {
+                    "description": "Who approved or rejected it",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "The recommendation must be approved and executed before then",
+                    "type": "string"
+                },
+                "generatedAt": {
+                    "description": "Timestamp when the recommendation was generated",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Recommendation to approve and execute; empty when not persisted",
+                    "type": "string"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Why it was rejected, if it was",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/recommendation.Status"
+                },
+                "suggestions": {
+                    "description": "Sells first, then buys by size",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.TradeRecommendation"
+                    }
+                }
+            }
+        },
+        "company.Company": {
+            "type": "object",
+            "properties": {
+                "corporateActions": {
+                    "description": "Splits, spinoffs and ticker changes, defined in corporate_action.go",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/company.CorporateAction"
+                    }
+                },
+                "currentScore": {
+                    "type": "number"
+                },
+                "dividends": {
+                    "description": "Declared dividends, defined in dividend.go",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/company.Dividend"
+                    }
+                },
+                "financialMetrics": {
+                    "description": "Defined in financial_metrics.go",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/company.FinancialMetrics"
+                        }
+                    ]
+                },
+                "metricsRevisions": {
+                    "description": "Every revision of the metrics in the order they became known, defined in metrics_revision.go",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/company.MetricsRevision"
+                    }
+                },
+                "sector": {
+                    "description": "Enum defined in sector.go",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/company.Sector"
+                        }
+                    ]
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Number of times the company has been saved; repositories use it to reject stale writes",
+                    "type": "integer"
+                }
+            }
+        },
+        "company.CorporateAction": {
+            "type": "object",
+            "properties": {
+                "basisAllocation": {
+                    "description": "Spinoff: fraction of the parent's cost basis allocated to the new company (0 \u003c f \u003c 1)",
+                    "type": "number"
+                },
+                "effectiveDate": {
+                    "description": "Day the action takes effect",
+                    "type": "string"
+                },
+                "newTicker": {
+                    "description": "Spinoff: the spun-off company; TickerChange: the new ticker",
+                    "type": "string"
+                },
+                "ratioFrom": {
+                    "type": "integer"
+                },
+                "ratioTo": {
+                    "type": "integer"
+                },
+                "recordedAt": {
+                    "type": "string"
+                },
+                "ticker": {
+                    "description": "Ticker of the company before the action",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/company.CorporateActionType"
+                }
+            }
+        },
+        "company.CorporateActionType": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-comments": {
+                "ReverseSplit": "Fewer shares for every share held (e.g., 1-for-10)",
+                "Spinoff": "Shares of a new company distributed to holders of the parent",
+                "Split": "More shares for every share held (e.g., 4-for-1)",
+                "TickerChange": "The company starts trading under a new ticker",
+                "UndefinedCorporateAction": "Default or unknown action"
+            },
+            "x-enum-varnames": [
+                "UndefinedCorporateAction",
+                "Split",
+                "ReverseSplit",
+                "Spinoff",
+                "TickerChange"
+            ]
+        },
+        "company.Dividend": {
+            "type": "object",
+            "properties": {
+                "amountPerShare": {
+                    "description": "Gross amount per share in the smallest currency unit",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Currency the dividend is paid in (e.g., \"USD\")",
+                    "type": "string"
+                },
+                "declaredAt": {
+                    "description": "When the declaration was recorded",
+                    "type": "string"
+                },
+                "exDate": {
+                    "description": "First trading day on which buyers are no longer entitled to the dividend",
+                    "type": "string"
+                },
+                "payDate": {
+                    "description": "Day the dividend is paid to eligible shareholders",
+                    "type": "string"
+                },
+                "ticker": {
+                    "description": "Ticker the dividend was declared under (kept if the company is later renamed)",
+                    "type": "string"
+                }
+            }
+        },
         "company.FinancialMetrics": {
             "type": "object",
             "properties": {
                 "debtToEquity": {
-                    "description": "Debt-to-Equity Ratio",
+                    "description": "Debt-to-Equity Ratio",
+                    "type": "number"
+                },
+                "metricsUpdatedAt": {
+                    "description": "Timestamp of when these metrics were last updated",
+                    "type": "string"
+                },
+                "pbratio": {
+                    "description": "Price-to-Book Ratio",
+                    "type": "number"
+                },
+                "peratio": {
+                    "description": "Price-to-Earnings Ratio",
+                    "type": "number"
+                }
+            }
+        },
+        "company.MetricsRevision": {
+            "type": "object",
+            "properties": {
+                "knownAt": {
+                    "description": "When the metrics became known",
+                    "type": "string"
+                },
+                "metrics": {
+                    "$ref": "#/definitions/company.FinancialMetrics"
+                },
+                "periodEnd": {
+                    "description": "End of the reporting period the metrics describe",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Value score of the company as known at KnownAt",
+                    "type": "number"
+                }
+            }
+        },
+        "company.Sector": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6,
+                7,
+                8,
+                9,
+                10,
+                11
+            ],
+            "x-enum-comments": {
+                "UndefinedSector": "Default or unknown sector"
+            },
+            "x-enum-varnames": [
+                "UndefinedSector",
+                "Technology",
+                "Healthcare",
+                "Financials",
+                "ConsumerDiscretionary",
+                "ConsumerStaples",
+                "Industrials",
+                "Energy",
+                "Utilities",
+                "RealEstate",
+                "Materials",
+                "TelecommunicationServices"
+            ]
+        },
+        "http.AccrueInterestRequest": {
+            "type": "object",
+            "properties": {
+                "asOf": {
+                    "description": "Defaults to today",
+                    "type": "string",
+                    "example": "2024-01-31"
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                }
+            }
+        },
+        "http.ApproveRecommendationRequest": {
+            "type": "object",
+            "properties": {
+                "approver": {
+                    "type": "string",
+                    "example": "jane.doe"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9b1d6c2e-3f4a-4e5b-8c7d-1a2b3c4d5e6f"
+                }
+            }
+        },
+        "http.AuditChangeResponse": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "Absent when the value was removed"
+                },
+                "before": {
+                    "description": "Absent when the value was set"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "CashBalance.Amount"
+                }
+            }
+        },
+        "http.AuditEntryResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "jane.doe"
+                },
+                "aggregateId": {
+                    "type": "string",
+                    "example": "p1"
+                },
+                "aggregateType": {
+                    "type": "string",
+                    "example": "portfolio"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.AuditChangeResponse"
+                    }
+                },
+                "endpoint": {
+                    "type": "string",
+                    "example": "POST /portfolio/cash/deposit"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "previousHash": {
+                    "type": "string"
+                },
+                "recordedAt": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "version": {
+                    "description": "0 when the change deleted the aggregate",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "http.AuditVerificationResponse": {
+            "type": "object",
+            "properties": {
+                "brokenAt": {
+                    "description": "First entry changed or out of place",
+                    "type": "integer",
+                    "example": 0
+                },
+                "entries": {
+                    "description": "Entries checked",
+                    "type": "integer",
+                    "example": 42
+                },
+                "intact": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "reason": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "http.BenchmarkConstituentRequest": {
+            "type": "object",
+            "properties": {
+                "ticker": {
+                    "type": "string",
+                    "example": "MSFT"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 0.5
+                }
+            }
+        },
+        "http.BenchmarkRequest": {
+            "type": "object",
+            "properties": {
+                "constituents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.BenchmarkConstituentRequest"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Value basket"
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "SPY"
+                }
+            }
+        },
+        "http.CashFlowRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "e.g. {\"amount\": 100000, \"currency\": \"USD\"}",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "date": {
+                    "description": "Effective date, defaults to today",
+                    "type": "string",
+                    "example": "2024-01-31"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Monthly savings"
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                }
+            }
+        },
+        "http.CorporateActionRequest": {
+            "type": "object",
+            "properties": {
+                "basisAllocation": {
+                    "description": "Spinoff only: fraction of cost basis moved to the new company",
+                    "type": "number",
+                    "example": 0
+                },
+                "effectiveDate": {
+                    "type": "string",
+                    "example": "2020-08-31"
+                },
+                "newTicker": {
+                    "description": "Spinoff or TickerChange only",
+                    "type": "string",
+                    "example": ""
+                },
+                "ratioFrom": {
+                    "description": "...for every this many shares held",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ratioTo": {
+                    "description": "New shares received...",
+                    "type": "integer",
+                    "example": 4
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "AAPL"
+                },
+                "type": {
+                    "description": "Split, ReverseSplit, Spinoff or TickerChange",
+                    "type": "string",
+                    "example": "Split"
+                }
+            }
+        },
+        "http.CreateCompanyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Apple Inc."
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "AAPL"
+                }
+            }
+        },
+        "http.CreatePortfolioRequest": {
+            "type": "object"
+        },
+        "http.CreateWatchlistRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Deep value candidates"
+                },
+                "owner": {
+                    "description": "User the watchlist belongs to",
+                    "type": "string",
+                    "example": "jane.doe"
+                },
+                "portfolioId": {
+                    "description": "Portfolio entry recommendations are made for",
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                }
+            }
+        },
+        "http.DeclareDividendRequest": {
+            "type": "object",
+            "properties": {
+                "amountPerShare": {
+                    "description": "e.g. {\"amount\": 24, \"currency\": \"USD\"}",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "exDate": {
+                    "type": "string",
+                    "example": "2024-02-09"
+                },
+                "payDate": {
+                    "type": "string",
+                    "example": "2024-02-15"
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "AAPL"
+                }
+            }
+        },
+        "http.DeleteWatchlistRequest": {
+            "type": "object",
+            "properties": {
+                "watchlistId": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                }
+            }
+        },
+        "http.DividendPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                },
+                "reinvestment": {
+                    "description": "None, WholeShares or FractionalShares",
+                    "type": "string",
+                    "example": "FractionalShares"
+                },
+                "withholdingTaxRate": {
+                    "type": "number",
+                    "example": 0.15
+                },
+                "withholdingTaxRates": {
+                    "description": "Per-currency overrides, e.g. {\"EUR\": 0.19}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Detailed error message"
+                }
+            }
+        },
+        "http.EventResponse": {
+            "type": "object",
+            "properties": {
+                "cash": {
+                    "description": "Cash balances that changed, by currency",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/portfolio.Money"
+                    }
+                },
+                "closed": {
+                    "description": "Tickers no longer held",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "entries": {
+                    "description": "Ledger entries appended",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.LedgerEntry"
+                    }
+                },
+                "positions": {
+                    "description": "Holdings opened or changed, by ticker",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/portfolio.Position"
+                    }
+                },
+                "recordedAt": {
+                    "type": "string"
+                },
+                "settings": {
+                    "description": "All the settings, when any of them changed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Settings"
+                        }
+                    ]
+                },
+                "type": {
+                    "type": "string",
+                    "example": "PositionOpened"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "http.ExchangeCashRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "e.g. {\"amount\": 50000, \"currency\": \"USD\"}",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                },
+                "toCurrency": {
+                    "type": "string",
+                    "example": "EUR"
+                }
+            }
+        },
+        "http.ExecuteRebalanceRequest": {
+            "type": "object",
+            "properties": {
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                },
+                "recommendationId": {
+                    "type": "string",
+                    "example": "9b1d6c2e-3f4a-4e5b-8c7d-1a2b3c4d5e6f"
+                }
+            }
+        },
+        "http.FeeScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "flatFee": {
+                    "description": "Per trade",
+                    "type": "integer",
+                    "example": 100
+                },
+                "fxSpread": {
+                    "type": "number",
+                    "example": 0.0025
+                },
+                "maxFee": {
+                    "description": "0 = no maximum",
+                    "type": "integer",
+                    "example": 2500
+                },
+                "minFee": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "perShareFee": {
+                    "description": "Per share traded",
+                    "type": "integer",
+                    "example": 0
+                },
+                "percentageFee": {
+                    "description": "Fraction of trade value",
+                    "type": "number",
+                    "example": 0.0005
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                },
+                "slippage": {
+                    "type": "number",
+                    "example": 0.001
+                }
+            }
+        },
+        "http.InterestRateRequest": {
+            "type": "object",
+            "properties": {
+                "annualRate": {
+                    "description": "0.035 = 3.5% per year",
+                    "type": "number",
+                    "example": 0.035
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                }
+            }
+        },
+        "http.ProcessDividendsRequest": {
+            "type": "object",
+            "properties": {
+                "asOf": {
+                    "description": "Defaults to today",
+                    "type": "string",
+                    "example": "2024-02-15"
+                }
+            }
+        },
+        "http.RejectRecommendationRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "9b1d6c2e-3f4a-4e5b-8c7d-1a2b3c4d5e6f"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Waiting for earnings"
+                },
+                "rejectedBy": {
+                    "type": "string",
+                    "example": "jane.doe"
+                }
+            }
+        },
+        "http.ReviseMetricsRequest": {
+            "type": "object",
+            "properties": {
+                "debtToEquity": {
+                    "type": "number",
+                    "example": 1.8
+                },
+                "knownAt": {
+                    "description": "When the figures became known (RFC 3339); defaults to now",
+                    "type": "string",
+                    "example": "2024-05-02T20:30:00Z"
+                },
+                "pbRatio": {
+                    "type": "number",
+                    "example": 45.1
+                },
+                "peRatio": {
+                    "type": "number",
+                    "example": 28.5
+                },
+                "periodEnd": {
+                    "description": "End of the reporting period described",
+                    "type": "string",
+                    "example": "2024-03-31"
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "AAPL"
+                }
+            }
+        },
+        "http.RiskPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "policy": {
+                    "description": "A built-in risk profile or a configured policy",
+                    "type": "string",
+                    "example": "Conservative"
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                }
+            }
+        },
+        "http.TargetWeightsResponse": {
+            "type": "object",
+            "properties": {
+                "knownAt": {
+                    "description": "Absent when the companies are scored as they are now",
+                    "type": "string"
+                },
+                "portfolioId": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"
+                },
+                "weights": {
+                    "description": "Target weight by ticker, e.g. {\"AAPL\": 0.15}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "http.UnwatchRequest": {
+            "type": "object",
+            "properties": {
+                "ticker": {
+                    "type": "string",
+                    "example": "AAPL"
+                },
+                "watchlistId": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                }
+            }
+        },
+        "http.WatchRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "example": "Wait for a pullback after earnings"
+                },
+                "targetPrice": {
+                    "description": "Buy at or below, e.g. {\"amount\": 15000, \"currency\": \"USD\"}",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "targetScore": {
+                    "description": "Buy once the score reaches this",
+                    "type": "number",
+                    "example": 75
+                },
+                "ticker": {
+                    "type": "string",
+                    "example": "AAPL"
+                },
+                "watchlistId": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                }
+            }
+        },
+        "portfolio.Benchmark": {
+            "type": "object",
+            "properties": {
+                "constituents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.BenchmarkConstituent"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.BenchmarkComparison": {
+            "type": "object",
+            "properties": {
+                "activeReturn": {
+                    "description": "PortfolioReturn − BenchmarkReturn",
+                    "type": "number"
+                },
+                "allocation": {
+                    "description": "Totals of the attribution effects",
+                    "type": "number"
+                },
+                "attribution": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.SectorAttribution"
+                    }
+                },
+                "benchmark": {
+                    "type": "string"
+                },
+                "benchmarkReturn": {
+                    "type": "number"
+                },
+                "from": {
+                    "description": "Time of the first snapshot of the period",
+                    "type": "string"
+                },
+                "informationRatio": {
+                    "description": "Annualized mean active return divided by the tracking error",
+                    "type": "number"
+                },
+                "interaction": {
+                    "type": "number"
+                },
+                "observations": {
+                    "description": "Number of sub-periods compared",
+                    "type": "integer"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "portfolioReturn": {
+                    "description": "Time-weighted return",
+                    "type": "number"
+                },
+                "selection": {
+                    "type": "number"
+                },
+                "to": {
+                    "description": "Time of the last snapshot of the period",
+                    "type": "string"
+                },
+                "trackingError": {
+                    "description": "Annualized standard deviation of the sub-period active returns",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.BenchmarkConstituent": {
+            "type": "object",
+            "properties": {
+                "ticker": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Fraction of the benchmark, in (0, 1]",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.CashFlow": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/portfolio.Money"
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.DividendPayment": {
+            "type": "object",
+            "properties": {
+                "companyTicker": {
+                    "type": "string"
+                },
+                "dividendID": {
+                    "type": "string"
+                },
+                "eligibleShares": {
+                    "description": "Shares held before the ex-date",
+                    "type": "number"
+                },
+                "gross": {
+                    "$ref": "#/definitions/portfolio.Money"
+                },
+                "net": {
+                    "$ref": "#/definitions/portfolio.Money"
+                },
+                "payDate": {
+                    "type": "string"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "reinvestedAmount": {
+                    "description": "Cash spent on reinvestment",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "reinvestedShares": {
+                    "description": "Shares bought with the dividend (zero without reinvestment)",
+                    "type": "number"
+                },
+                "withholdingTax": {
+                    "$ref": "#/definitions/portfolio.Money"
+                }
+            }
+        },
+        "portfolio.DividendPolicy": {
+            "type": "object",
+            "properties": {
+                "reinvestment": {
+                    "description": "Dividend reinvestment plan (DRIP) setting",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.ReinvestmentMode"
+                        }
+                    ]
+                },
+                "withholdingTaxRate": {
+                    "description": "Default tax withheld at source (e.g., 0.15 for 15%)",
+                    "type": "number"
+                },
+                "withholdingTaxRates": {
+                    "description": "Per-currency overrides of the default rate, keyed by currency code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "portfolio.EntryType": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6,
+                7,
+                8,
+                9,
+                10
+            ],
+            "x-enum-comments": {
+                "Buy": "Purchase of shares",
+                "CorporateAction": "Share movement caused by a split, spinoff or ticker change",
+                "Deposit": "External cash paid into the portfolio",
+                "Dividend": "Gross dividend received",
+                "FXExchange": "One leg of a currency conversion",
+                "Fee": "Trading commission or FX spread",
+                "Interest": "Interest earned on idle cash",
+                "Sell": "Sale of shares",
+                "UndefinedEntry": "Default or unknown entry type",
+                "Withdrawal": "External cash taken out of the portfolio",
+                "WithholdingTax": "Tax withheld at source from a dividend"
+            },
+            "x-enum-varnames": [
+                "UndefinedEntry",
+                "Deposit",
+                "Withdrawal",
+                "Interest",
+                "Buy",
+                "Sell",
+                "FXExchange",
+                "Dividend",
+                "WithholdingTax",
+                "CorporateAction",
+                "Fee"
+            ]
+        },
+        "portfolio.ExecutionReport": {
+            "type": "object",
+            "properties": {
+                "adjusted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.PositionAdjustedEvent"
+                    }
+                },
+                "executedAt": {
+                    "type": "string"
+                },
+                "fees": {
+                    "description": "Total fees paid, per currency",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.Money"
+                    }
+                },
+                "fills": {
+                    "description": "In execution order: sells, then buys",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.Fill"
+                    }
+                },
+                "opened": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.PositionOpenedEvent"
+                    }
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Identifier of what was executed (e.g., a recommendation ID)",
+                    "type": "string"
+                },
+                "residualCash": {
+                    "description": "Cash left per currency, base currency first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.Money"
+                    }
+                }
+            }
+        },
+        "portfolio.Exposure": {
+            "type": "object",
+            "properties": {
+                "asOf": {
+                    "type": "string"
+                },
+                "breaches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.RiskThresholdBreachedEvent"
+                    }
+                },
+                "cashWeight": {
+                    "type": "number"
+                },
+                "policy": {
+                    "$ref": "#/definitions/portfolio.RiskPolicy"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "positions": {
+                    "description": "Largest weight first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.PositionExposure"
+                    }
+                },
+                "sectors": {
+                    "description": "Largest weight first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.SectorExposure"
+                    }
+                },
+                "totalValue": {
+                    "$ref": "#/definitions/portfolio.Money"
+                }
+            }
+        },
+        "portfolio.FeeSchedule": {
+            "type": "object",
+            "properties": {
+                "flatFee": {
+                    "description": "Charged once per trade",
+                    "type": "integer"
+                },
+                "fxspread": {
+                    "description": "Fraction of the converted amount kept by the broker on currency exchanges",
+                    "type": "number"
+                },
+                "maxFee": {
+                    "description": "Upper bound for the fee of a trade (0 = none)",
+                    "type": "integer"
+                },
+                "minFee": {
+                    "description": "Lower bound for the fee of a trade (0 = none)",
+                    "type": "integer"
+                },
+                "perShareFee": {
+                    "description": "Charged per share traded",
+                    "type": "integer"
+                },
+                "percentageFee": {
+                    "description": "Fraction of the trade value (e.g., 0.001 for 0.1%)",
+                    "type": "number"
+                },
+                "slippage": {
+                    "description": "Expected adverse price move when trading at market, as a fraction of the price",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.Fill": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/portfolio.RecommendationAction"
+                },
+                "fee": {
+                    "$ref": "#/definitions/portfolio.Money"
+                },
+                "price": {
+                    "description": "Price per share, including slippage",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "shares": {
+                    "type": "integer"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Shares × Price",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                }
+            }
+        },
+        "portfolio.HoldingValuation": {
+            "type": "object",
+            "properties": {
+                "baseMarketValue": {
+                    "description": "MarketValue converted into the base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "companyTicker": {
+                    "type": "string"
+                },
+                "fxrate": {
+                    "description": "Trading currency → base currency rate applied (1 for base currency holdings)",
+                    "type": "number"
+                },
+                "marketValue": {
+                    "description": "Shares × Price in the trading currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "price": {
+                    "description": "Price per share in the trading currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "priceIsCost": {
+                    "description": "True when no market price was available and the purchase price was used",
+                    "type": "boolean"
+                },
+                "shares": {
+                    "description": "Including any fractional share",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.LedgerEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Signed cash effect: positive when cash comes in, negative when it goes out",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "capitalized": {
+                    "description": "Fee entries: the fee was added to the cost basis of the shares bought",
+                    "type": "boolean"
+                },
+                "costBasis": {
+                    "description": "Sell entries: cost basis of the shares sold, including capitalized fees",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "description": {
+                    "description": "Free-text note (e.g., the source of a deposit)",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Identifier of the business event behind the entry (e.g., a dividend ID)",
+                    "type": "string"
+                },
+                "sequence": {
+                    "description": "Position of the entry in the ledger, starting at 1",
+                    "type": "integer"
+                },
+                "shares": {
+                    "description": "Signed share quantity for share movements (fractional for reinvested dividends)",
+                    "type": "number"
+                },
+                "ticker": {
+                    "description": "Company ticker for share movements, empty otherwise",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "Effective time of the movement",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Kind of movement",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.EntryType"
+                        }
+                    ]
+                }
+            }
+        },
+        "portfolio.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount in the smallest currency unit (e.g., cents for USD)",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "Currency code (e.g., \"USD\", \"EUR\")",
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.Performance": {
+            "type": "object",
+            "properties": {
+                "asOf": {
+                    "type": "string"
+                },
+                "baseCurrency": {
+                    "type": "string"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "returns": {
+                    "description": "In the order of StandardPeriods, followed by any custom period",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.PeriodReturn"
+                    }
+                }
+            }
+        },
+        "portfolio.Period": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5,
+                6
+            ],
+            "x-enum-comments": {
+                "CustomPeriod": "An arbitrary start date",
+                "MonthToDate": "Since the start of the current month",
+                "OneYear": "The trailing twelve months",
+                "QuarterToDate": "Since the start of the current quarter",
+                "SinceInception": "Since the portfolio's first ledger entry",
+                "UndefinedPeriod": "Default or unknown period",
+                "YearToDate": "Since the start of the current year"
+            },
+            "x-enum-varnames": [
+                "UndefinedPeriod",
+                "MonthToDate",
+                "QuarterToDate",
+                "YearToDate",
+                "OneYear",
+                "SinceInception",
+                "CustomPeriod"
+            ]
+        },
+        "portfolio.PeriodReturn": {
+            "type": "object",
+            "properties": {
+                "annualized": {
+                    "description": "True when the period spans at least a year",
+                    "type": "boolean"
+                },
+                "annualizedMWR": {
+                    "description": "The XIRR for periods of a year or more",
+                    "type": "number"
+                },
+                "annualizedTWR": {
+                    "type": "number"
+                },
+                "endValue": {
+                    "description": "Portfolio value at To",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "from": {
+                    "description": "Start actually measured from: a snapshot, or inception",
+                    "type": "string"
+                },
+                "mwr": {
+                    "description": "Cumulative money-weighted return, implied by XIRR",
+                    "type": "number"
+                },
+                "netFlows": {
+                    "description": "Deposits minus withdrawals in (From, To]",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "period": {
+                    "$ref": "#/definitions/portfolio.Period"
+                },
+                "startValue": {
+                    "description": "Portfolio value at From (zero at inception)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "to": {
+                    "description": "Time of the valuation the period ends with",
+                    "type": "string"
+                },
+                "twr": {
+                    "description": "Cumulative time-weighted return, chain-linked across external flows",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.Portfolio": {
+            "type": "object",
+            "properties": {
+                "baseCurrency": {
+                    "description": "Reporting currency for valuations (e.g., \"USD\")",
+                    "type": "string"
+                },
+                "benchmark": {
+                    "description": "What performance is compared against; the default benchmark when unset",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Benchmark"
+                        }
+                    ]
+                },
+                "cashBalance": {
+                    "description": "Current cash balance in the base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "dividendPolicy": {
+                    "description": "Withholding tax and reinvestment settings for dividends",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.DividendPolicy"
+                        }
+                    ]
+                },
+                "feeSchedule": {
+                    "description": "Broker fees applied to trades and currency exchanges",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.FeeSchedule"
+                        }
+                    ]
+                },
+                "foreignCash": {
+                    "description": "Cash held in other currencies, keyed by currency code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/portfolio.Money"
+                    }
+                },
+                "holdings": {
+                    "description": "Keyed by company ticker",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/portfolio.Position"
+                    }
+                },
+                "id": {
+                    "description": "Unique identifier for the portfolio",
+                    "type": "string"
+                },
+                "interestRates": {
+                    "description": "Annual interest rate paid on idle cash, keyed by currency code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "lastInterestAccrual": {
+                    "description": "Time up to which interest has been credited",
+                    "type": "string"
+                },
+                "lastRebalanceTime": {
+                    "description": "Timestamp of the last rebalance",
+                    "type": "string"
+                },
+                "ledger": {
+                    "description": "Append-only record of cash and share movements",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.LedgerEntry"
+                    }
+                },
+                "riskPolicy": {
+                    "description": "Limits in force; the profile's default policy when unset",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.RiskPolicy"
+                        }
+                    ]
+                },
+                "riskProfile": {
+                    "description": "Investor's risk tolerance",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.RiskProfile"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "description": "Timestamp of the last update to the portfolio",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Number of times the portfolio has been saved; repositories use it to reject stale writes",
+                    "type": "integer"
+                }
+            }
+        },
+        "portfolio.Position": {
+            "type": "object",
+            "properties": {
+                "companyTicker": {
+                    "description": "Stock ticker of the company",
+                    "type": "string"
+                },
+                "fractionalShares": {
+                    "description": "Fraction of a share (0 ≤ f \u003c 1) acquired through dividend reinvestment",
+                    "type": "number"
+                },
+                "purchasePrice": {
+                    "description": "Average purchase price per share for this position",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "shares": {
+                    "description": "Number of whole shares held",
+                    "type": "integer"
+                }
+            }
+        },
+        "portfolio.PositionAdjustedEvent": {
+            "type": "object",
+            "properties": {
+                "companyTicker": {
+                    "type": "string"
+                },
+                "newShares": {
+                    "type": "integer"
+                },
+                "oldShares": {
+                    "type": "integer"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.PositionExposure": {
+            "type": "object",
+            "properties": {
+                "sector": {
+                    "type": "string"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Market value in the base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "weight": {
+                    "description": "Share of the portfolio's total value",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.PositionOpenedEvent": {
+            "type": "object",
+            "properties": {
+                "companyTicker": {
+                    "type": "string"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "purchasePrice": {
+                    "$ref": "#/definitions/portfolio.Money"
+                },
+                "shares": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.ProfitAndLoss": {
+            "type": "object",
+            "properties": {
+                "baseCurrency": {
+                    "type": "string"
+                },
+                "capitalizedFees": {
+                    "description": "Part of Fees included in cost basis",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "dividends": {
+                    "description": "Gross dividends received",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "fees": {
+                    "description": "All trading fees and FX spreads paid (positive = paid)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interest": {
+                    "description": "Interest earned on cash",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "netProfit": {
+                    "description": "Realized + Unrealized + Dividends − WithholdingTax + Interest − expensed fees",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "realizedGains": {
+                    "description": "Sale proceeds minus the cost basis of the shares sold",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unrealizedGains": {
+                    "description": "Market value minus cost basis of the current holdings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "withholdingTax": {
+                    "description": "Tax withheld from dividends (positive = paid)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                }
+            }
+        },
+        "portfolio.RecommendationAction": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-comments": {
+                "Enter": "Open a new position",
+                "Increase": "Add to an existing position",
+                "Liquidate": "Sell the whole position",
+                "Reduce": "Sell part of a position",
+                "UndefinedAction": "Default or unknown action"
+            },
+            "x-enum-varnames": [
+                "UndefinedAction",
+                "Enter",
+                "Increase",
+                "Reduce",
+                "Liquidate"
+            ]
+        },
+        "portfolio.ReinvestmentMode": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-comments": {
+                "NoReinvestment": "Dividends are kept as cash",
+                "ReinvestFractionalShares": "Reinvest the whole dividend, holding fractional shares",
+                "ReinvestWholeShares": "Buy as many whole shares as the dividend pays for; the rest stays as cash"
+            },
+            "x-enum-varnames": [
+                "NoReinvestment",
+                "ReinvestWholeShares",
+                "ReinvestFractionalShares"
+            ]
+        },
+        "portfolio.RiskMetrics": {
+            "type": "object",
+            "properties": {
+                "asOf": {
+                    "type": "string"
+                },
+                "benchmark": {
+                    "description": "Ticker beta is measured against; empty when beta is unavailable",
+                    "type": "string"
+                },
+                "beta": {
+                    "description": "Sensitivity of daily returns to the benchmark's",
+                    "type": "number"
+                },
+                "maxDrawdown": {
+                    "description": "Largest peak-to-trough fall, as a fraction of the peak",
+                    "type": "number"
+                },
+                "observations": {
+                    "description": "Number of daily values used",
+                    "type": "integer"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "vaR": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.ValueAtRisk"
+                    }
+                },
+                "value": {
+                    "description": "Portfolio value on AsOf, in the base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "volatility": {
+                    "description": "Annualized standard deviation of daily returns",
+                    "type": "number"
+                }
+            }
+        },
+        "portfolio.RiskPolicy": {
+            "type": "object",
+            "properties": {
+                "maxDebtToEquity": {
+                    "description": "Highest debt-to-equity ratio of a company bought; 0 = no limit",
+                    "type": "number"
+                },
+                "maxPositionWeight": {
+                    "description": "Largest share of the portfolio's value in a single company",
+                    "type": "number"
+                },
+                "maxSectorWeight": {
+                    "description": "Largest share of the portfolio's value in a single sector",
+                    "type": "number"
+                },
+                "minCashBuffer": {
+                    "description": "Smallest share of the portfolio's value kept in cash",
+                    "type": "number"
+                },
+                "minEntryScore": {
+                    "description": "Lowest value score (0-100) for opening a position",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.RiskProfile": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3
+            ],
+            "x-enum-comments": {
+                "UndefinedProfile": "Default or unknown profile"
+            },
+            "x-enum-varnames": [
+                "UndefinedProfile",
+                "Conservative",
+                "Moderate",
+                "Aggressive"
+            ]
+        },
+        "portfolio.RiskThresholdBreachedEvent": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "holdings": {
+                    "description": "Tickers causing the breach, sorted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "portfolioID": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Limit breached, e.g., \"max sector weight\"",
+                    "type": "string"
+                },
+                "severity": {
+                    "description": "How far past the limit the portfolio is",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Severity"
+                        }
+                    ]
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.ScoreInputs": {
+            "type": "object",
+            "properties": {
+                "debtToEquity": {
+                    "type": "number"
+                },
+                "pbratio": {
+                    "type": "number"
+                },
+                "peratio": {
+                    "type": "number"
+                },
+                "score": {
+                    "description": "Value score, 0-100; zero when the company is unknown",
+                    "type": "number"
+                },
+                "sector": {
+                    "description": "Sector name; empty when unknown",
+                    "type": "string"
+                }
+            }
+        },
+        "portfolio.SectorAttribution": {
+            "type": "object",
+            "properties": {
+                "allocation": {
+                    "description": "(PortfolioWeight − BenchmarkWeight) × (BenchmarkReturn − benchmark total return)",
+                    "type": "number"
+                },
+                "benchmarkReturn": {
+                    "type": "number"
+                },
+                "benchmarkWeight": {
                     "type": "number"
                 },
-                "metricsUpdatedAt": {
-                    "description": "Timestamp of when these metrics were last updated",
+                "interaction": {
+                    "description": "(PortfolioWeight − BenchmarkWeight) × (PortfolioReturn − BenchmarkReturn)",
+                    "type": "number"
+                },
+                "portfolioReturn": {
+                    "type": "number"
+                },
+                "portfolioWeight": {
+                    "type": "number"
+                },
+                "sector": {
                     "type": "string"
                 },
-                "pbratio": {
-                    "description": "Price-to-Book Ratio",
+                "selection": {
+                    "description": "BenchmarkWeight × (PortfolioReturn − BenchmarkReturn)",
                     "type": "number"
+                }
+            }
+        },
+        "portfolio.SectorExposure": {
+            "type": "object",
+            "properties": {
+                "sector": {
+                    "type": "string"
                 },
-                "peratio": {
-                    "description": "Price-to-Earnings Ratio",
+                "tickers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "value": {
+                    "description": "Market value in the base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "weight": {
+                    "description": "Share of the portfolio's total value",
                     "type": "number"
                 }
             }
         },
-        "company.Sector": {
+        "portfolio.Settings": {
+            "type": "object",
+            "properties": {
+                "baseCurrency": {
+                    "type": "string"
+                },
+                "benchmark": {
+                    "$ref": "#/definitions/portfolio.Benchmark"
+                },
+                "dividendPolicy": {
+                    "$ref": "#/definitions/portfolio.DividendPolicy"
+                },
+                "feeSchedule": {
+                    "$ref": "#/definitions/portfolio.FeeSchedule"
+                },
+                "interestRates": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "lastInterestAccrual": {
+                    "type": "string"
+                },
+                "lastRebalanceTime": {
+                    "type": "string"
+                },
+                "riskPolicy": {
+                    "$ref": "#/definitions/portfolio.RiskPolicy"
+                },
+                "riskProfile": {
+                    "$ref": "#/definitions/portfolio.RiskProfile"
+                }
+            }
+        },
+        "portfolio.Severity": {
             "type": "integer",
             "enum": [
                 0,
                 1,
-                2,
-                3,
-                4,
-                5,
-                6,
-                7,
-                8,
-                9,
-                10,
-                11
+                2
             ],
             "x-enum-comments": {
-                "UndefinedSector": "Default or unknown sector"
+                "Critical": "Past the limit by a quarter of it or more",
+                "UndefinedSeverity": "Default or unknown severity",
+                "Warning": "Past the limit by less than a quarter of it"
             },
             "x-enum-varnames": [
-                "UndefinedSector",
-                "Technology",
-                "Healthcare",
-                "Financials",
-                "ConsumerDiscretionary",
-                "ConsumerStaples",
-                "Industrials",
-                "Energy",
-                "Utilities",
-                "RealEstate",
-                "Materials",
-                "TelecommunicationServices"
+                "UndefinedSeverity",
+                "Warning",
+                "Critical"
             ]
         },
-        "http.CreateCompanyRequest": {
+        "portfolio.TradeRecommendation": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string",
-                    "example": "Apple Inc."
+                "action": {
+                    "$ref": "#/definitions/portfolio.RecommendationAction"
+                },
+                "confidence": {
+                    "description": "0-1; grows with the drift from target and with the score's support for the trade",
+                    "type": "number"
+                },
+                "currentWeight": {
+                    "description": "Weight of the holding before the trade",
+                    "type": "number"
+                },
+                "estimatedFee": {
+                    "description": "Fee from the portfolio's fee schedule",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "limitPrice": {
+                    "description": "Highest price to pay when buying, lowest to accept when selling",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "marketPrice": {
+                    "description": "Quote the recommendation was based on",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "quantity": {
+                    "description": "Whole shares to buy or sell",
+                    "type": "integer"
+                },
+                "rationale": {
+                    "type": "string"
+                },
+                "scoreInputs": {
+                    "$ref": "#/definitions/portfolio.ScoreInputs"
+                },
+                "targetWeight": {
+                    "type": "number"
                 },
                 "ticker": {
-                    "type": "string",
-                    "example": "AAPL"
+                    "type": "string"
                 }
             }
         },
-        "http.CreatePortfolioRequest": {
-            "type": "object"
-        },
-        "http.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Detailed error message"
-                }
-            }
+        "portfolio.VaRMethod": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2
+            ],
+            "x-enum-comments": {
+                "Historical": "Empirical quantile of past daily returns",
+                "Parametric": "Normal distribution fitted to past daily returns",
+                "UndefinedVaRMethod": "Default or unknown method"
+            },
+            "x-enum-varnames": [
+                "UndefinedVaRMethod",
+                "Historical",
+                "Parametric"
+            ]
         },
-        "portfolio.Money": {
+        "portfolio.Valuation": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "Amount in the smallest currency unit (e.g., cents for USD)",
-                    "type": "integer"
+                "asOf": {
+                    "type": "string"
                 },
-                "currency": {
-                    "description": "Currency code (e.g., \"USD\", \"EUR\")",
+                "baseCurrency": {
                     "type": "string"
-                }
-            }
-        },
-        "portfolio.Portfolio": {
-            "type": "object",
-            "properties": {
-                "cashBalance": {
-                    "description": "Current cash balance",
+                },
+                "cash": {
+                    "description": "Cash per currency, base currency first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.Money"
+                    }
+                },
+                "cashValue": {
+                    "description": "Sum of cash in the base currency",
                     "allOf": [
                         {
                             "$ref": "#/definitions/portfolio.Money"
@@ -379,72 +4904,184 @@ const docTemplate = `{
                     ]
                 },
                 "holdings": {
-                    "description": "Keyed by company ticker",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/portfolio.Position"
+                    "description": "Sorted by ticker",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/portfolio.HoldingValuation"
                     }
                 },
-                "id": {
-                    "description": "Unique identifier for the portfolio",
-                    "type": "string"
+                "marketValue": {
+                    "description": "Sum of holdings in the base currency",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
                 },
-                "lastRebalanceTime": {
-                    "description": "Timestamp of the last rebalance",
+                "portfolioID": {
                     "type": "string"
                 },
-                "riskProfile": {
-                    "description": "Investor's risk tolerance",
+                "totalValue": {
+                    "description": "MarketValue + CashValue",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/portfolio.RiskProfile"
+                            "$ref": "#/definitions/portfolio.Money"
                         }
                     ]
-                },
-                "updatedAt": {
-                    "description": "Timestamp of the last update to the portfolio",
-                    "type": "string"
                 }
             }
         },
-        "portfolio.Position": {
+        "portfolio.ValueAtRisk": {
             "type": "object",
             "properties": {
-                "companyTicker": {
-                    "description": "Stock ticker of the company",
-                    "type": "string"
+                "confidence": {
+                    "description": "e.g., 0.99",
+                    "type": "number"
                 },
-                "purchasePrice": {
-                    "description": "Average purchase price per share for this position",
+                "horizonDays": {
+                    "type": "integer"
+                },
+                "loss": {
+                    "description": "Loss in the base currency",
                     "allOf": [
                         {
                             "$ref": "#/definitions/portfolio.Money"
                         }
                     ]
                 },
-                "shares": {
-                    "description": "Number of shares held",
-                    "type": "integer"
+                "method": {
+                    "$ref": "#/definitions/portfolio.VaRMethod"
+                },
+                "ratio": {
+                    "description": "Loss as a fraction of the portfolio's value",
+                    "type": "number"
                 }
             }
         },
-        "portfolio.RiskProfile": {
+        "recommendation.Status": {
             "type": "integer",
             "enum": [
                 0,
                 1,
                 2,
-                3
+                3,
+                4,
+                5
             ],
             "x-enum-comments": {
-                "UndefinedProfile": "Default or unknown profile"
+                "Approved": "Accepted for execution",
+                "Executed": "Traded; final",
+                "Expired": "Not executed before its expiry; final",
+                "Proposed": "Waiting for a decision",
+                "Rejected": "Turned down; final",
+                "UndefinedStatus": "Default or unknown status"
             },
             "x-enum-varnames": [
-                "UndefinedProfile",
-                "Conservative",
-                "Moderate",
-                "Aggressive"
+                "UndefinedStatus",
+                "Proposed",
+                "Approved",
+                "Rejected",
+                "Executed",
+                "Expired"
             ]
+        },
+        "watchlist.EntrySignalTriggeredEvent": {
+            "type": "object",
+            "properties": {
+                "marginOfSafety": {
+                    "description": "Below the target price, as a fraction of it",
+                    "type": "number"
+                },
+                "portfolioID": {
+                    "description": "Empty for a personal watchlist",
+                    "type": "string"
+                },
+                "price": {
+                    "$ref": "#/definitions/portfolio.Money"
+                },
+                "rationale": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "watchlistID": {
+                    "type": "string"
+                }
+            }
+        },
+        "watchlist.WatchItem": {
+            "type": "object",
+            "properties": {
+                "addedAt": {
+                    "type": "string"
+                },
+                "note": {
+                    "description": "Free-text investment thesis",
+                    "type": "string"
+                },
+                "targetPrice": {
+                    "description": "Buy at or below this price per share; zero when unused",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/portfolio.Money"
+                        }
+                    ]
+                },
+                "targetScore": {
+                    "description": "Buy once the value score reaches this; zero when unused",
+                    "type": "number"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "triggered": {
+                    "description": "The target is met and its entry signal has fired",
+                    "type": "boolean"
+                },
+                "triggeredAt": {
+                    "description": "When the entry signal last fired",
+                    "type": "string"
+                }
+            }
+        },
+        "watchlist.Watchlist": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "items": {
+                    "description": "Keyed by ticker",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/watchlist.WatchItem"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "User the watchlist belongs to; empty for a portfolio's watchlist",
+                    "type": "string"
+                },
+                "portfolioID": {
+                    "description": "Portfolio entry recommendations are made for; empty for a personal watchlist",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
         }
     },
     "externalDocs": {
@@ -18,13 +18,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"time"
 
 	// Project packages
 	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/config"
 	infHttp "github.com/jizumer/expedition-value/pkg/infrastructure/http"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/marketdata"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/audited"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/bolt"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/eventsourced"
 	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/postgres"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/scheduler"
 
 	// Swagger imports
 	_ "github.com/jizumer/expedition-value/cmd/server/docs" // Generated Swagger docs
@@ -35,20 +50,129 @@ func main() {
 	log.Println("Starting Value Investment Analysis MVP server...")
 
 	// 1. Initialization
+	cfg := config.Load()
 	log.Println("Initializing repositories and services...")
 
 	// Instantiate Repositories
-	companyRepo := memory.NewInMemoryCompanyRepository()
-	// Portfolio repo needs company repo for some operations (e.g., SearchBySector, if implemented fully)
-	portfolioRepo := memory.NewInMemoryPortfolioRepository(companyRepo)
+	recommendationRepo := memory.NewInMemoryRecommendationRepository()
+	st, err := openStore(cfg, recommendationRepo)
+	if err != nil {
+		log.Fatalf("Error opening %s storage: %v\n", cfg.Storage, err)
+	}
+	defer st.close()
+	companyRepo, portfolioRepo := st.companies, st.portfolios
+	riskMetricsRepo, snapshotRepo := st.riskMetrics, st.snapshots
+	watchlistRepo := memory.NewInMemoryWatchlistRepository()
 
-	// Instantiate Application Services
-	companyService := application.NewCompanyService(companyRepo)
-	portfolioService := application.NewPortfolioService(portfolioRepo, companyRepo)
+	// Instantiate Market Data Providers (optional, file-backed stand-ins for real feeds)
+	portfolioOpts := []application.PortfolioServiceOption{
+		application.WithRebalanceConstraints(portfolio.RebalanceConstraints{
+			ToleranceBand:       cfg.RebalanceTolerance,
+			MinTradeValue:       cfg.MinTradeValue,
+			PriceDriftTolerance: cfg.PriceDriftTolerance,
+		}),
+		application.WithRecommendationRepository(recommendationRepo, cfg.RecommendationTTL),
+		application.WithValuationSnapshotRepository(snapshotRepo),
+		application.WithDefaultBenchmark(cfg.BenchmarkTicker),
+	}
+	if st.history != nil {
+		portfolioOpts = append(portfolioOpts, application.WithPortfolioHistory(st.history))
+	}
+	if cfg.RiskPoliciesFile != "" {
+		policies, err := config.LoadRiskPolicies(cfg.RiskPoliciesFile)
+		if err != nil {
+			log.Fatalf("Error loading risk policies: %v\n", err)
+		}
+		portfolioOpts = append(portfolioOpts, application.WithRiskPolicies(policies))
+		log.Printf("Loaded %d risk policies from %s\n", len(policies), cfg.RiskPoliciesFile)
+	}
+	var priceProvider portfolio.PriceProvider // Stays nil without a prices file
+	var fxRateProvider portfolio.FXRateProvider
+	if cfg.FXRatesFile != "" {
+		fxRates, err := marketdata.NewFileFXRateProvider(cfg.FXRatesFile)
+		if err != nil {
+			log.Fatalf("Error loading FX rates: %v\n", err)
+		}
+		portfolioOpts = append(portfolioOpts, application.WithFXRateProvider(fxRates))
+		fxRateProvider = fxRates
+		log.Printf("Loaded FX rates from %s\n", cfg.FXRatesFile)
+	}
+	if cfg.PricesFile != "" {
+		prices, err := marketdata.NewFilePriceProvider(cfg.PricesFile)
+		if err != nil {
+			log.Fatalf("Error loading prices: %v\n", err)
+		}
+		portfolioOpts = append(portfolioOpts, application.WithPriceProvider(prices))
+		priceProvider = prices
+		log.Printf("Loaded prices from %s\n", cfg.PricesFile)
+	}
+
+	// Instantiate Application Services. Those changing companies and portfolios are made for
+	// each request or job, writing through repositories that record every change in the audit
+	// log as made by whoever asked for it. With a unit of work, every change is saved in one, so
+	// that it commits with its audit entry where the storage allows.
+	newWriters := func(source audit.Source) writers {
+		portfolioOpts := slices.Clone(portfolioOpts)
+		var corporateActionOpts []application.CorporateActionServiceOption
+		var repoOpts []audited.RepositoryOption
+		if st.unitOfWork != nil {
+			uow := audited.NewAuditedUnitOfWork(st.unitOfWork, st.auditLog, source)
+			portfolioOpts = append(portfolioOpts, application.WithUnitOfWork(uow))
+			corporateActionOpts = append(corporateActionOpts, application.WithCorporateActionUnitOfWork(uow))
+			repoOpts = append(repoOpts, audited.ThroughUnitOfWork(uow))
+		}
+		companies := audited.NewAuditedCompanyRepository(companyRepo, st.auditLog, source, repoOpts...)
+		portfolios := audited.NewAuditedPortfolioRepository(portfolioRepo, st.auditLog, source, repoOpts...)
+		return writers{
+			companies:        application.NewCompanyService(companies),
+			portfolios:       application.NewPortfolioService(portfolios, companies, portfolioOpts...),
+			dividends:        application.NewDividendService(companies, portfolios, priceProvider),
+			corporateActions: application.NewCorporateActionService(companies, portfolios, corporateActionOpts...),
+		}
+	}
+	scheduled := func(job string) writers { return newWriters(audit.Source{Actor: schedulerActor, Endpoint: job}) }
+	recommendationService := application.NewRecommendationService(recommendationRepo)
+	riskAnalyticsService := application.NewRiskAnalyticsService(portfolioRepo, riskMetricsRepo, priceProvider, fxRateProvider, cfg.BenchmarkTicker, cfg.RiskLookbackDays)
+	watchlistService := application.NewWatchlistService(watchlistRepo, companyRepo, priceProvider, scheduled("watchlist alerts").portfolios)
+	auditService := application.NewAuditService(st.auditLog)
+
+	// Instantiate HTTP Handlers; those over the services above are made for each request
+	companyRoute := perRequest(newWriters, func(s writers) *infHttp.CompanyHandler { return infHttp.NewCompanyHandler(s.companies) })
+	portfolioRoute := perRequest(newWriters, func(s writers) *infHttp.PortfolioHandler { return infHttp.NewPortfolioHandler(s.portfolios) })
+	dividendRoute := perRequest(newWriters, func(s writers) *infHttp.DividendHandler { return infHttp.NewDividendHandler(s.dividends) })
+	corporateActionRoute := perRequest(newWriters, func(s writers) *infHttp.CorporateActionHandler {
+		return infHttp.NewCorporateActionHandler(s.corporateActions)
+	})
+	recommendationHandler := infHttp.NewRecommendationHandler(recommendationService)
+	riskAnalyticsHandler := infHttp.NewRiskAnalyticsHandler(riskAnalyticsService)
+	watchlistHandler := infHttp.NewWatchlistHandler(watchlistService)
+	auditHandler := infHttp.NewAuditHandler(auditService)
 
-	// Instantiate HTTP Handlers
-	companyHandler := infHttp.NewCompanyHandler(companyService)
-	portfolioHandler := infHttp.NewPortfolioHandler(portfolioService)
+	// Scheduled jobs
+	endOfDayWriters, exposureWriters := scheduled("end of day"), scheduled("exposure monitoring")
+	jobs := []scheduler.Job{
+		{Name: "end of day", Schedule: scheduler.DailyAt(cfg.EndOfDay), Run: func(now time.Time) error {
+			return endOfDay(now, endOfDayWriters.portfolios, endOfDayWriters.dividends, riskAnalyticsService, priceProvider != nil)
+		}},
+		{Name: "recommendation expiry", Schedule: scheduler.Every(time.Hour), Run: func(now time.Time) error {
+			expired, err := recommendationService.ExpireRecommendations(now)
+			if len(expired) > 0 {
+				log.Printf("Expired %d recommendations\n", len(expired))
+			}
+			return err
+		}},
+	}
+	if cfg.ExposureCheckInterval > 0 {
+		jobs = append(jobs, scheduler.Job{Name: "exposure monitoring", Schedule: scheduler.Every(cfg.ExposureCheckInterval), Run: func(now time.Time) error {
+			return monitorExposures(now, exposureWriters.portfolios)
+		}})
+	}
+	if cfg.WatchlistCheckInterval > 0 {
+		jobs = append(jobs, scheduler.Job{Name: "watchlist alerts", Schedule: scheduler.Every(cfg.WatchlistCheckInterval), Run: func(now time.Time) error {
+			return checkWatchlists(now, watchlistService)
+		}})
+	}
+	go scheduler.New(jobs...).Run(context.Background())
 
 	log.Println("Initialization complete.")
 
@@ -73,23 +197,97 @@ func main() {
 	// GetCompanyByTicker expects GET with ?ticker=XYZ
 	// The handler infHttp.CompanyHandler.GetCompanyByTicker needs to be implemented
 	// to parse r.URL.Query().Get("ticker")
-	mux.HandleFunc("/company", companyHandler.GetCompanyByTicker)
+	mux.HandleFunc("/company", companyRoute((*infHttp.CompanyHandler).GetCompanyByTicker))
 
 	// CreateCompany expects POST
 	// The handler infHttp.CompanyHandler.CreateCompany needs to be implemented
 	// to check r.Method == http.MethodPost and parse the request body.
-	mux.HandleFunc("/company/create", companyHandler.CreateCompany)
+	mux.HandleFunc("/company/create", companyRoute((*infHttp.CompanyHandler).CreateCompany))
+	// ReviseCompanyMetrics expects POST with the metrics of a period and when they became known;
+	// GET /company also takes &knownAt=RFC3339 to read the company as known then
+	mux.HandleFunc("/company/metrics", companyRoute((*infHttp.CompanyHandler).ReviseCompanyMetrics))
 
 	// Portfolio routes
 	// GetPortfolioDetails expects GET with ?id=XYZ
 	// The handler infHttp.PortfolioHandler.GetPortfolioDetails needs to be implemented
 	// to parse r.URL.Query().Get("id")
-	mux.HandleFunc("/portfolio", portfolioHandler.GetPortfolioDetails)
+	mux.HandleFunc("/portfolio", portfolioRoute((*infHttp.PortfolioHandler).GetPortfolioDetails))
+	// GetPortfolioEvents expects GET with ?id=XYZ; GET /portfolio also takes &asOf=RFC3339 (event-sourced portfolios only)
+	mux.HandleFunc("/portfolio/events", portfolioRoute((*infHttp.PortfolioHandler).GetPortfolioEvents))
 
 	// CreatePortfolio expects POST
 	// The handler infHttp.PortfolioHandler.CreatePortfolio needs to be implemented
 	// to check r.Method == http.MethodPost and parse the request body.
-	mux.HandleFunc("/portfolio/create", portfolioHandler.CreatePortfolio)
+	mux.HandleFunc("/portfolio/create", portfolioRoute((*infHttp.PortfolioHandler).CreatePortfolio))
+
+	// GetPortfolioValuation expects GET with ?id=XYZ
+	mux.HandleFunc("/portfolio/valuation", portfolioRoute((*infHttp.PortfolioHandler).GetPortfolioValuation))
+	// GetValuationHistory expects GET with ?id=XYZ and optional &from=YYYY-MM-DD&to=YYYY-MM-DD
+	mux.HandleFunc("/portfolio/valuation/history", portfolioRoute((*infHttp.PortfolioHandler).GetValuationHistory))
+	// GetPerformance expects GET with ?id=XYZ and optional &asOf=YYYY-MM-DD&from=YYYY-MM-DD
+	mux.HandleFunc("/portfolio/performance", portfolioRoute((*infHttp.PortfolioHandler).GetPerformance))
+	// SetBenchmark expects POST with {"portfolioId": "...", "ticker": "SPY"} or a named basket of constituents
+	mux.HandleFunc("/portfolio/benchmark", portfolioRoute((*infHttp.PortfolioHandler).SetBenchmark))
+	// CompareWithBenchmark expects GET with ?id=XYZ and optional &from=YYYY-MM-DD&to=YYYY-MM-DD
+	mux.HandleFunc("/portfolio/benchmark/comparison", portfolioRoute((*infHttp.PortfolioHandler).CompareWithBenchmark))
+
+	// Cash management routes (all POST with a JSON body, except flows)
+	mux.HandleFunc("/portfolio/cash/exchange", portfolioRoute((*infHttp.PortfolioHandler).ExchangeCash))
+	mux.HandleFunc("/portfolio/cash/deposit", portfolioRoute((*infHttp.PortfolioHandler).Deposit))
+	mux.HandleFunc("/portfolio/cash/withdraw", portfolioRoute((*infHttp.PortfolioHandler).Withdraw))
+	mux.HandleFunc("/portfolio/cash/interest", portfolioRoute((*infHttp.PortfolioHandler).SetInterestRate))
+	mux.HandleFunc("/portfolio/cash/interest/accrue", portfolioRoute((*infHttp.PortfolioHandler).AccrueInterest))
+	// GetCashFlows expects GET with ?id=XYZ and optional &from=YYYY-MM-DD&to=YYYY-MM-DD
+	mux.HandleFunc("/portfolio/cash/flows", portfolioRoute((*infHttp.PortfolioHandler).GetCashFlows))
+
+	// RecommendRebalance expects GET with ?id=XYZ
+	mux.HandleFunc("/portfolio/rebalance", portfolioRoute((*infHttp.PortfolioHandler).RecommendRebalance))
+	mux.HandleFunc("/portfolio/rebalance/execute", portfolioRoute((*infHttp.PortfolioHandler).ExecuteRebalance))
+	// GetTargetWeights expects GET with ?id=XYZ and optional &knownAt=RFC3339
+	mux.HandleFunc("/portfolio/target-weights", portfolioRoute((*infHttp.PortfolioHandler).GetTargetWeights))
+
+	// Recommendation workflow routes: GET with ?id=XYZ, approve and reject are POST
+	mux.HandleFunc("/recommendation", recommendationHandler.GetRecommendation)
+	mux.HandleFunc("/recommendation/approve", recommendationHandler.ApproveRecommendation)
+	mux.HandleFunc("/recommendation/reject", recommendationHandler.RejectRecommendation)
+	mux.HandleFunc("/portfolio/recommendations", recommendationHandler.ListRecommendations)
+
+	// Fee schedule (POST) and profit and loss (GET with ?id=XYZ and optional &from=&to=)
+	mux.HandleFunc("/portfolio/fees", portfolioRoute((*infHttp.PortfolioHandler).SetFeeSchedule))
+	mux.HandleFunc("/portfolio/pnl", portfolioRoute((*infHttp.PortfolioHandler).GetProfitAndLoss))
+
+	// Risk policies: assignment (POST) and the available policies (GET)
+	mux.HandleFunc("/portfolio/risk-policy", portfolioRoute((*infHttp.PortfolioHandler).SetRiskPolicy))
+	mux.HandleFunc("/risk-policies", portfolioRoute((*infHttp.PortfolioHandler).ListRiskPolicies))
+	// GetExposure expects GET with ?id=XYZ
+	mux.HandleFunc("/portfolio/exposure", portfolioRoute((*infHttp.PortfolioHandler).GetExposure))
+
+	// Risk analytics: GET with ?id=XYZ (and optional &asOf=, or &from=&to= for the history)
+	mux.HandleFunc("/portfolio/risk", riskAnalyticsHandler.GetRiskMetrics)
+	mux.HandleFunc("/portfolio/risk/history", riskAnalyticsHandler.GetRiskMetricsHistory)
+
+	// Dividend routes
+	mux.HandleFunc("/company/dividends/declare", dividendRoute((*infHttp.DividendHandler).DeclareDividend))
+	// GetDividendSummary expects GET with ?ticker=XYZ
+	mux.HandleFunc("/company/dividends", dividendRoute((*infHttp.DividendHandler).GetDividendSummary))
+	mux.HandleFunc("/dividends/process", dividendRoute((*infHttp.DividendHandler).ProcessDividends))
+	mux.HandleFunc("/portfolio/dividends/policy", portfolioRoute((*infHttp.PortfolioHandler).SetDividendPolicy))
+
+	// Corporate action routes (POST with a JSON body)
+	mux.HandleFunc("/company/corporate-actions", corporateActionRoute((*infHttp.CorporateActionHandler).ApplyCorporateAction))
+
+	// Watchlist routes (GET with query params, POST with a JSON body)
+	mux.HandleFunc("/watchlist", watchlistHandler.GetWatchlist) // GET ?id=
+	mux.HandleFunc("/watchlist/create", watchlistHandler.CreateWatchlist)
+	mux.HandleFunc("/watchlist/watch", watchlistHandler.Watch)
+	mux.HandleFunc("/watchlist/unwatch", watchlistHandler.Unwatch)
+	mux.HandleFunc("/watchlist/delete", watchlistHandler.DeleteWatchlist)
+	mux.HandleFunc("/watchlists", watchlistHandler.ListWatchlists) // GET ?owner= or ?portfolioId=
+	mux.HandleFunc("/watchlists/check", watchlistHandler.CheckEntrySignals)
+
+	// Audit log, for administrators: GET with optional ?aggregateType=&aggregateId=&actor=&from=&to=
+	mux.HandleFunc("/admin/audit", auditHandler.GetAuditLog)
+	mux.HandleFunc("/admin/audit/verify", auditHandler.VerifyAuditLog)
 
 	// Swagger UI handler
 	mux.HandleFunc("/swagger/", httpSwagger.WrapHandler)
@@ -101,8 +299,211 @@ func main() {
 	port := ":8080"
 	log.Printf("Server listening on port %s\n", port)
 
-	err := http.ListenAndServe(port, mux)
+	err = http.ListenAndServe(port, mux)
 	if err != nil {
 		log.Fatalf("Error starting server: %v\n", err)
 	}
 }
+
+// schedulerActor is who the audit log records as making the changes of scheduled jobs.
+const schedulerActor = "scheduler"
+
+// writers are the services changing companies and portfolios, made for one source of changes
+// so that the audit log records who made them.
+type writers struct {
+	companies        *application.CompanyService
+	portfolios       *application.PortfolioService
+	dividends        *application.DividendService
+	corporateActions *application.CorporateActionService
+}
+
+// perRequest returns a function turning a method of the handlers made by newHandler into an
+// http.HandlerFunc that serves each request with a handler over writers made for it.
+func perRequest[H any](newWriters func(audit.Source) writers, newHandler func(writers) H) func(method func(H, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(method func(H, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			method(newHandler(newWriters(infHttp.AuditSource(r))), w, r)
+		}
+	}
+}
+
+// store is the configured storage of companies, portfolios, their valuation snapshots and risk
+// metrics.
+type store struct {
+	companies   company.CompanyRepository
+	portfolios  portfolio.PortfolioRepository
+	history     portfolio.PortfolioHistory // Nil unless portfolios are event-sourced
+	unitOfWork  application.UnitOfWork     // Nil when the storage cannot group writes
+	auditLog    audit.Log
+	snapshots   portfolio.ValuationSnapshotRepository
+	riskMetrics portfolio.RiskMetricsRepository
+	close       func()
+}
+
+// backend is what a storage backend offers, of which openStore picks what the configured
+// portfolio persistence needs.
+type backend struct {
+	companies  company.CompanyRepository
+	portfolios portfolio.PortfolioRepository
+	events     portfolio.EventStore
+	// unitOfWork returns the backend's unit of work over state or event-sourced portfolios, nil
+	// when it has none for them.
+	unitOfWork  func(eventSourced bool) application.UnitOfWork
+	auditLog    audit.Log
+	snapshots   portfolio.ValuationSnapshotRepository
+	riskMetrics portfolio.RiskMetricsRepository
+	close       func()
+}
+
+// openStore opens the company, portfolio, valuation snapshot and risk metrics repositories of
+// the configured storage, with the unit of work grouping the writes of companies, portfolios and
+// recommendations when the storage has one. The other repositories are kept in memory.
+func openStore(cfg config.Config, recommendations *memory.InMemoryRecommendationRepository) (store, error) {
+	b, err := openBackend(cfg, recommendations)
+	if err != nil {
+		return store{}, err
+	}
+	s := store{companies: b.companies, auditLog: b.auditLog, snapshots: b.snapshots, riskMetrics: b.riskMetrics, close: b.close}
+	switch cfg.PortfolioPersistence {
+	case "state":
+		s.portfolios, s.unitOfWork = b.portfolios, b.unitOfWork(false)
+	case "events":
+		log.Printf("Portfolios are event-sourced, with a snapshot every %d events\n", cfg.SnapshotInterval)
+		repo := eventsourced.NewEventSourcedPortfolioRepository(b.events, b.companies, cfg.SnapshotInterval)
+		s.portfolios, s.history, s.unitOfWork = repo, repo, b.unitOfWork(true)
+	default:
+		b.close()
+		return store{}, fmt.Errorf("unknown portfolio persistence %q, want state or events", cfg.PortfolioPersistence)
+	}
+	if s.unitOfWork == nil {
+		log.Printf("The %s storage cannot group writes with %s portfolio persistence; operations changing several aggregates save them one by one\n", cfg.Storage, cfg.PortfolioPersistence)
+	}
+	return s, nil
+}
+
+// openBackend opens the configured storage.
+func openBackend(cfg config.Config, recommendations *memory.InMemoryRecommendationRepository) (backend, error) {
+	switch cfg.Storage {
+	case "memory":
+		companyRepo := memory.NewInMemoryCompanyRepository()
+		// Portfolio repo needs company repo for some operations (e.g., SearchBySector, if implemented fully)
+		portfolioRepo := memory.NewInMemoryPortfolioRepository(companyRepo)
+		return backend{
+			companies:  companyRepo,
+			portfolios: portfolioRepo,
+			events:     memory.NewInMemoryPortfolioEventStore(),
+			unitOfWork: func(eventSourced bool) application.UnitOfWork {
+				if eventSourced {
+					return nil
+				}
+				return memory.NewInMemoryUnitOfWork(companyRepo, portfolioRepo, recommendations)
+			},
+			auditLog:    memory.NewInMemoryAuditLog(),
+			snapshots:   memory.NewInMemoryValuationSnapshotRepository(),
+			riskMetrics: memory.NewInMemoryRiskMetricsRepository(),
+			close:       func() {},
+		}, nil
+	case "bolt":
+		db, err := bolt.Open(cfg.BoltPath)
+		if err != nil {
+			return backend{}, err
+		}
+		log.Printf("Opened database file %s\n", cfg.BoltPath)
+		return backend{
+			companies:  bolt.NewBoltCompanyRepository(db),
+			portfolios: bolt.NewBoltPortfolioRepository(db),
+			events:     bolt.NewBoltPortfolioEventStore(db),
+			unitOfWork: func(eventSourced bool) application.UnitOfWork {
+				var opts []bolt.UnitOfWorkOption
+				if eventSourced {
+					opts = append(opts, bolt.WithEventSourcedPortfolios(cfg.SnapshotInterval))
+				}
+				return bolt.NewBoltUnitOfWork(db, recommendations, opts...)
+			},
+			auditLog:    bolt.NewBoltAuditLog(db),
+			snapshots:   bolt.NewBoltValuationSnapshotRepository(db),
+			riskMetrics: bolt.NewBoltRiskMetricsRepository(db),
+			close:       func() { db.Close() },
+		}, nil
+	case "postgres":
+		if cfg.DatabaseURL == "" {
+			return backend{}, errors.New("EXPEDITION_DATABASE_URL is required")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		pool, err := postgres.Open(ctx, cfg.DatabaseURL, cfg.DatabaseMaxConns)
+		if err != nil {
+			return backend{}, err
+		}
+		if err := postgres.Migrate(ctx, pool); err != nil {
+			pool.Close()
+			return backend{}, err
+		}
+		log.Println("Connected to PostgreSQL; schema is up to date")
+		return backend{
+			companies:  postgres.NewPostgresCompanyRepository(pool, cfg.DatabaseQueryTimeout),
+			portfolios: postgres.NewPostgresPortfolioRepository(pool, cfg.DatabaseQueryTimeout),
+			events:     postgres.NewPostgresPortfolioEventStore(pool, cfg.DatabaseQueryTimeout),
+			unitOfWork: func(eventSourced bool) application.UnitOfWork {
+				var opts []postgres.UnitOfWorkOption
+				if eventSourced {
+					opts = append(opts, postgres.WithEventSourcedPortfolios(cfg.SnapshotInterval))
+				}
+				return postgres.NewPostgresUnitOfWork(pool, cfg.DatabaseQueryTimeout, recommendations, opts...)
+			},
+			auditLog:    postgres.NewPostgresAuditLog(pool, cfg.DatabaseQueryTimeout),
+			snapshots:   postgres.NewPostgresValuationSnapshotRepository(pool, cfg.DatabaseQueryTimeout),
+			riskMetrics: postgres.NewPostgresRiskMetricsRepository(pool, cfg.DatabaseQueryTimeout),
+			close:       pool.Close,
+		}, nil
+	default:
+		return backend{}, fmt.Errorf("unknown storage %q, want memory, bolt or postgres", cfg.Storage)
+	}
+}
+
+// endOfDay runs the daily jobs in order: interest and dividends are credited before the
+// valuation snapshots and risk metrics are taken. Every step runs even if an earlier one fails.
+func endOfDay(now time.Time, portfolios *application.PortfolioService, dividends *application.DividendService, risk *application.RiskAnalyticsService, withPrices bool) error {
+	var errs []error
+	if err := portfolios.AccrueInterestForAll(now); err != nil {
+		errs = append(errs, fmt.Errorf("interest accrual: %w", err))
+	}
+	if payments, err := dividends.ProcessDividends(now); err != nil {
+		errs = append(errs, fmt.Errorf("dividend processing: %w", err))
+	} else if len(payments) > 0 {
+		log.Printf("Credited %d dividend payments\n", len(payments))
+	}
+	if err := portfolios.SnapshotValuations(now); err != nil {
+		errs = append(errs, fmt.Errorf("valuation snapshots: %w", err))
+	}
+	if withPrices { // Risk metrics need price history
+		if err := risk.RecordRiskMetrics(now); err != nil {
+			errs = append(errs, fmt.Errorf("risk metrics: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// monitorExposures checks every portfolio against its risk policy and logs the breaches found
+// until an event bus exists.
+func monitorExposures(now time.Time, service *application.PortfolioService) error {
+	breaches, err := service.MonitorExposures(now)
+	for _, b := range breaches {
+		log.Printf("RiskThresholdBreached [%s] portfolio %s: %s (holdings %v)\n", b.Severity, b.PortfolioID, b.Description, b.Holdings)
+	}
+	return err
+}
+
+// checkWatchlists checks watched companies against their entry targets and logs the alerts
+// raised until an event bus exists.
+func checkWatchlists(now time.Time, service *application.WatchlistService) error {
+	alerts, err := service.CheckEntrySignals(now)
+	for _, a := range alerts {
+		if a.RecommendationID != "" {
+			log.Printf("EntrySignalTriggered %s: %s (recommendation %s)\n", a.Signal.Ticker, a.Signal.Rationale, a.RecommendationID)
+		} else {
+			log.Printf("EntrySignalTriggered %s: %s (%s)\n", a.Signal.Ticker, a.Signal.Rationale, a.Reason)
+		}
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+// AuditService answers questions about the audit log of company and portfolio changes, which
+// the audited repositories write.
+type AuditService struct {
+	log audit.Log
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(log audit.Log) *AuditService {
+	return &AuditService{log: log}
+}
+
+// FindAuditEntries returns the entries of the audit log the filter selects, in log order.
+func (s *AuditService) FindAuditEntries(filter audit.Filter) ([]*audit.Entry, error) {
+	if filter.AggregateID != "" && filter.AggregateType == "" {
+		return nil, errors.New("aggregate ID needs an aggregate type")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, errors.New("to cannot be before from")
+	}
+	entries, err := s.log.Find(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// VerifyAuditLog checks that no entry of the audit log was changed or removed since it was
+// recorded, returning the number of entries checked. A broken chain is reported as a
+// *audit.TamperedError.
+func (s *AuditService) VerifyAuditLog() (int, error) {
+	entries, err := s.log.Find(audit.Filter{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	return len(entries), audit.Verify(entries)
+}
@@ -0,0 +1,72 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+// MockAuditLog is a slice-backed audit Log for tests.
+type MockAuditLog struct {
+	entries []*audit.Entry
+}
+
+func (m *MockAuditLog) Append(e *audit.Entry) error {
+	previous := ""
+	if len(m.entries) > 0 {
+		previous = m.entries[len(m.entries)-1].Hash
+	}
+	if err := e.Seal(len(m.entries)+1, previous); err != nil {
+		return err
+	}
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *MockAuditLog) Find(filter audit.Filter) ([]*audit.Entry, error) {
+	found := []*audit.Entry{}
+	for _, e := range m.entries {
+		if filter.Matches(e) {
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
+
+func TestAuditService(t *testing.T) {
+	log := &MockAuditLog{}
+	at := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	for i, actor := range []string{"alice", "bob", "alice"} {
+		e, _ := audit.NewEntry(audit.Source{Actor: actor, Endpoint: "POST /portfolio/cash/deposit"}, audit.Portfolio, "p1", i+1,
+			map[string]int{"Cash": i}, map[string]int{"Cash": i + 1}, at.Add(time.Duration(i)*time.Hour))
+		log.Append(e)
+	}
+	service := application.NewAuditService(log)
+
+	t.Run("FindAuditEntries", func(t *testing.T) {
+		entries, err := service.FindAuditEntries(audit.Filter{AggregateType: audit.Portfolio, AggregateID: "p1", Actor: "alice", From: at.Add(time.Hour)})
+		if err != nil || len(entries) != 1 || entries[0].Sequence != 3 {
+			t.Errorf("FindAuditEntries() = %+v, %v, want entry 3", entries, err)
+		}
+		if _, err := service.FindAuditEntries(audit.Filter{AggregateID: "p1"}); err == nil {
+			t.Error("FindAuditEntries(ID without type) error = nil, want an error")
+		}
+		if _, err := service.FindAuditEntries(audit.Filter{From: at, To: at.Add(-time.Hour)}); err == nil {
+			t.Error("FindAuditEntries(to before from) error = nil, want an error")
+		}
+	})
+
+	t.Run("VerifyAuditLog", func(t *testing.T) {
+		if n, err := service.VerifyAuditLog(); err != nil || n != 3 {
+			t.Errorf("VerifyAuditLog() = %d, %v, want 3 entries intact", n, err)
+		}
+		log.entries[1].Actor = "mallory"
+		var tampered *audit.TamperedError
+		if n, err := service.VerifyAuditLog(); n != 3 || !errors.As(err, &tampered) || tampered.Sequence != 2 {
+			t.Errorf("VerifyAuditLog() = %d, %v, want tampering at entry 2", n, err)
+		}
+	})
+}
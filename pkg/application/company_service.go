@@ -2,6 +2,8 @@ package application
 
 import (
 	"errors" // Using standard errors for now
+	"fmt"
+	"time"
 
 	"github.com/jizumer/expedition-value/pkg/domain/company"
 )
@@ -27,6 +29,16 @@ func (s *CompanyService) GetCompanyByTicker(ticker string) (*company.Company, er
 	return s.companyRepo.FindByTicker(ticker)
 }
 
+// GetCompanyAsKnownAt retrieves a company as it was known at the given time: with the metrics
+// and score known then, free of later restatements. A company none of whose metrics were known
+// then is not found.
+func (s *CompanyService) GetCompanyAsKnownAt(ticker string, at time.Time) (*company.Company, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+	return company.KnownAt(s.companyRepo, at).FindByTicker(ticker)
+}
+
 // SearchCompaniesByScore retrieves companies whose current value score falls within the given range.
 func (s *CompanyService) SearchCompaniesByScore(minScore, maxScore float64) ([]*company.Company, error) {
 	if minScore > maxScore {
@@ -70,7 +82,7 @@ func (s *CompanyService) UpdateCompanyMetrics(ticker string, newMetrics company.
 		return err // Company not found or other repository error
 	}
 	if existingCompany == nil {
-		return errors.New("company not found") // Should be covered by repo error, but good practice
+		return company.ErrCompanyNotFound // Should be covered by repo error, but good practice
 	}
 
 	// Call domain method to update metrics and recalculate score
@@ -84,6 +96,25 @@ func (s *CompanyService) UpdateCompanyMetrics(ticker string, newMetrics company.
 	return s.companyRepo.Save(existingCompany)
 }
 
+// ReviseCompanyMetrics records the financial metrics of a company's reporting period ending at
+// periodEnd as they became known at knownAt, keeping the earlier revisions of that period.
+func (s *CompanyService) ReviseCompanyMetrics(ticker string, metrics company.FinancialMetrics, periodEnd, knownAt time.Time) (*company.Company, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+	c, err := s.companyRepo.FindByTicker(ticker)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ReviseFinancialMetrics(metrics, periodEnd, knownAt); err != nil {
+		return nil, fmt.Errorf("domain error revising metrics of %s: %w", ticker, err)
+	}
+	if err := s.companyRepo.Save(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 // RefreshCompany triggers a refresh of a company's data, potentially involving external sources.
 // For the MVP, this is a placeholder that calls domain logic for refreshing stale metrics.
 func (s *CompanyService) RefreshCompany(ticker string) error {
@@ -97,7 +128,7 @@ func (s *CompanyService) RefreshCompany(ticker string) error {
 		return err
 	}
 	if c == nil {
-		return errors.New("company not found")
+		return company.ErrCompanyNotFound
 	}
 
 	// Call domain method to refresh stale metrics
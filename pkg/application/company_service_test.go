@@ -17,8 +17,8 @@ type MockCompanyRepository struct {
 	SearchByScoreRangeFunc   func(minScore, maxScore float64) ([]*company.Company, error)
 	SaveFunc                 func(c *company.Company) error
 	DeleteFunc               func(ticker string) error
+	FindAllFunc              func() ([]*company.Company, error)
 	// Optional methods if needed for other tests
-	// FindAllFunc           func() ([]*company.Company, error)
 	// FindBySectorFunc      func(sector company.Sector) ([]*company.Company, error)
 
 	// Spy fields (optional, to check if methods were called)
@@ -56,6 +56,13 @@ func (m *MockCompanyRepository) Delete(ticker string) error {
 	return errors.New("DeleteFunc not implemented in mock")
 }
 
+func (m *MockCompanyRepository) FindAll() ([]*company.Company, error) {
+	if m.FindAllFunc != nil {
+		return m.FindAllFunc()
+	}
+	return nil, errors.New("FindAllFunc not implemented in mock")
+}
+
 // --- CompanyService Tests ---
 
 func TestCompanyService_GetCompanyByTicker(t *testing.T) {
@@ -484,3 +491,44 @@ func TestCompanyService_RefreshCompany(t *testing.T) {
 		}
 	})
 }
+
+func TestCompanyService_Revisions(t *testing.T) {
+	stored, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	mockRepo := &MockCompanyRepository{
+		FindByTickerFunc: func(ticker string) (*company.Company, error) {
+			if ticker != "AAPL" {
+				return nil, company.ErrCompanyNotFound
+			}
+			return stored.Clone(), nil
+		},
+		SaveFunc: func(c *company.Company) error {
+			stored = c.Clone()
+			return nil
+		},
+	}
+	service := application.NewCompanyService(mockRepo)
+	q1End := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	reported, restated := q1End.AddDate(0, 0, 25), q1End.AddDate(0, 2, 10)
+
+	if _, err := service.ReviseCompanyMetrics("AAPL", company.FinancialMetrics{PERatio: 12}, q1End, reported); err != nil {
+		t.Fatalf("ReviseCompanyMetrics(reported) error = %v", err)
+	}
+	c, err := service.ReviseCompanyMetrics("AAPL", company.FinancialMetrics{PERatio: 18}, q1End, restated)
+	if err != nil || c.FinancialMetrics.PERatio != 18 || len(stored.MetricsRevisions) != 2 {
+		t.Fatalf("ReviseCompanyMetrics(restated) = %+v, %v, want the restatement saved beside the first report", c, err)
+	}
+	if _, err := service.ReviseCompanyMetrics("AAPL", company.FinancialMetrics{PERatio: 20}, restated, q1End); err == nil {
+		t.Error("ReviseCompanyMetrics() known before its period ended expected error, got nil")
+	}
+
+	past, err := service.GetCompanyAsKnownAt("AAPL", reported.AddDate(0, 1, 0))
+	if err != nil || past.FinancialMetrics.PERatio != 12 {
+		t.Errorf("GetCompanyAsKnownAt(before the restatement) = %+v, %v, want the P/E first reported", past, err)
+	}
+	if _, err := service.GetCompanyAsKnownAt("AAPL", q1End); !errors.Is(err, company.ErrCompanyNotFound) {
+		t.Errorf("GetCompanyAsKnownAt(before any report) error = %v, want ErrCompanyNotFound", err)
+	}
+	if _, err := service.GetCompanyAsKnownAt("", reported); err == nil {
+		t.Error("GetCompanyAsKnownAt() with empty ticker expected error, got nil")
+	}
+}
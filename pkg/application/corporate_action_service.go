@@ -0,0 +1,148 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// CorporateActionResult is a DTO describing an applied corporate action.
+type CorporateActionResult struct {
+	Action             company.CorporateAction
+	AffectedPortfolios []string // IDs of the portfolios whose holdings were adjusted
+}
+
+// CorporateActionService applies splits, reverse splits, spinoffs and ticker changes to the
+// company that takes them and to every portfolio holding its shares.
+type CorporateActionService struct {
+	companyRepo   company.CompanyRepository
+	portfolioRepo portfolio.PortfolioRepository
+	uow           UnitOfWork // Writes to the company and its holders commit together
+}
+
+// CorporateActionServiceOption configures optional collaborators of a CorporateActionService.
+type CorporateActionServiceOption func(*CorporateActionService)
+
+// WithCorporateActionUnitOfWork makes the writes of a corporate action to its company and to
+// the portfolios holding it commit together or not at all. They are made one by one otherwise.
+func WithCorporateActionUnitOfWork(uow UnitOfWork) CorporateActionServiceOption {
+	return func(s *CorporateActionService) {
+		s.uow = uow
+	}
+}
+
+// NewCorporateActionService creates a new instance of CorporateActionService.
+func NewCorporateActionService(cRepo company.CompanyRepository, pRepo portfolio.PortfolioRepository, opts ...CorporateActionServiceOption) *CorporateActionService {
+	s := &CorporateActionService{
+		companyRepo:   cRepo,
+		portfolioRepo: pRepo,
+		uow:           directUnitOfWork{repos: Repositories{Companies: cRepo, Portfolios: pRepo}},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ApplyCorporateAction records a corporate action on its company and adjusts the holdings of
+// every portfolio that holds the company's shares, recording the adjustment in each ledger.
+// A spun-off company must already exist; a ticker change must not collide with another company.
+func (s *CorporateActionService) ApplyCorporateAction(action company.CorporateAction) (*CorporateActionResult, error) {
+	if err := action.Validate(); err != nil {
+		return nil, fmt.Errorf("domain error validating corporate action: %w", err)
+	}
+	c, err := s.companyRepo.FindByTicker(action.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNewTicker(action); err != nil {
+		return nil, err
+	}
+
+	recorded, err := c.RecordCorporateAction(action)
+	if err != nil {
+		return nil, fmt.Errorf("domain error recording corporate action for %s: %w", action.Ticker, err)
+	}
+
+	portfolios, err := s.portfolioRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portfolios for corporate action %s: %w", recorded.ID(), err)
+	}
+	result := &CorporateActionResult{Action: recorded, AffectedPortfolios: []string{}}
+	var affected []*portfolio.Portfolio
+	for _, p := range portfolios {
+		applied, err := applyToPortfolio(p, recorded)
+		if err != nil {
+			return nil, fmt.Errorf("domain error applying corporate action %s to portfolio %s: %w", recorded.ID(), p.ID, err)
+		}
+		if applied {
+			affected = append(affected, p)
+			result.AffectedPortfolios = append(result.AffectedPortfolios, p.ID)
+		}
+	}
+
+	// Every adjustment has been validated in memory before anything is saved.
+	if recorded.Type == company.TickerChange {
+		c.Version = 0 // Stored anew under its new ticker
+	}
+	err = s.uow.Do(func(repos Repositories) error {
+		for _, p := range affected {
+			if err := repos.Portfolios.Save(p); err != nil {
+				return fmt.Errorf("failed to save portfolio %s after corporate action %s: %w", p.ID, recorded.ID(), err)
+			}
+		}
+		if err := repos.Companies.Save(c); err != nil {
+			return fmt.Errorf("failed to save company %s after corporate action: %w", c.Ticker, err)
+		}
+		if recorded.Type == company.TickerChange {
+			if err := repos.Companies.Delete(recorded.Ticker); err != nil {
+				return fmt.Errorf("failed to remove old ticker %s: %w", recorded.Ticker, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Publish CorporateActionRecordedEvent
+	return result, nil
+}
+
+// checkNewTicker verifies that a spun-off company exists and that a renamed company's new
+// ticker is not already used by another company.
+func (s *CorporateActionService) checkNewTicker(action company.CorporateAction) error {
+	switch action.Type {
+	case company.Spinoff:
+		if _, err := s.companyRepo.FindByTicker(action.NewTicker); err != nil {
+			return fmt.Errorf("spun-off company %s must be created first: %w", action.NewTicker, err)
+		}
+	case company.TickerChange:
+		if existing, err := s.companyRepo.FindByTicker(action.NewTicker); err == nil && existing != nil {
+			return fmt.Errorf("domain error changing ticker: ticker %s is already in use", action.NewTicker)
+		}
+	}
+	return nil
+}
+
+// applyToPortfolio adjusts a portfolio's holdings for a corporate action, reporting whether
+// the portfolio was affected. Actions already applied to the portfolio are skipped.
+func applyToPortfolio(p *portfolio.Portfolio, a company.CorporateAction) (bool, error) {
+	var applied bool
+	var err error
+	switch a.Type {
+	case company.Split, company.ReverseSplit:
+		applied, err = p.ApplySplit(a.Ticker, a.RatioTo, a.RatioFrom, a.ID(), a.EffectiveDate)
+	case company.Spinoff:
+		applied, err = p.ApplySpinoff(a.Ticker, a.NewTicker, a.RatioTo, a.RatioFrom, a.BasisAllocation, a.ID(), a.EffectiveDate)
+	case company.TickerChange:
+		applied, err = p.RenameTicker(a.Ticker, a.NewTicker, a.ID(), a.EffectiveDate)
+	default:
+		return false, errors.New("unsupported corporate action type " + a.Type.String())
+	}
+	if errors.Is(err, portfolio.ErrCorporateActionAlreadyApplied) {
+		return false, nil
+	}
+	return applied, err
+}
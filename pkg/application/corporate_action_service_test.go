@@ -0,0 +1,149 @@
+package application_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestCorporateActionService_ApplyCorporateAction(t *testing.T) {
+	newFixture := func(t *testing.T) (*application.CorporateActionService, map[string]*company.Company, *portfolio.Portfolio, *[]string) {
+		t.Helper()
+		fb, _ := company.NewCompany("FB", company.FinancialMetrics{}, company.Technology)
+		fb.Version = 3
+		companies := map[string]*company.Company{"FB": fb}
+		var deleted []string
+		companyRepo := &MockCompanyRepository{
+			FindByTickerFunc: func(ticker string) (*company.Company, error) {
+				if c, ok := companies[ticker]; ok {
+					return c, nil
+				}
+				return nil, errors.New("company not found")
+			},
+			SaveFunc: func(c *company.Company) error {
+				// Like a real repository, a company is only created from version 0.
+				if _, exists := companies[c.Ticker]; !exists && c.Version != 0 {
+					return &company.VersionConflictError{Ticker: c.Ticker, Version: c.Version}
+				}
+				companies[c.Ticker] = c
+				return nil
+			},
+			DeleteFunc: func(ticker string) error { delete(companies, ticker); deleted = append(deleted, ticker); return nil },
+		}
+
+		holder, _ := portfolio.NewPortfolio("holder", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+		pos, _ := portfolio.NewPosition("FB", 10, portfolio.Money{Amount: 1000, Currency: "USD"})
+		_ = holder.AddPosition(*pos, portfolio.Money{Amount: 10000, Currency: "USD"})
+		other, _ := portfolio.NewPortfolio("other", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+		portfolioRepo := &MockPortfolioRepository{
+			FindAllFunc: func() ([]*portfolio.Portfolio, error) { return []*portfolio.Portfolio{holder, other}, nil },
+			SaveFunc:    func(p *portfolio.Portfolio) error { return nil },
+		}
+		return application.NewCorporateActionService(companyRepo, portfolioRepo), companies, holder, &deleted
+	}
+	on := time.Now()
+
+	t.Run("TickerChangeRenamesCompanyAndHoldings", func(t *testing.T) {
+		service, companies, holder, deleted := newFixture(t)
+		result, err := service.ApplyCorporateAction(company.CorporateAction{Type: company.TickerChange, Ticker: "FB", EffectiveDate: on, NewTicker: "META"})
+		if err != nil {
+			t.Fatalf("ApplyCorporateAction() error = %v, wantErr nil", err)
+		}
+		if len(result.AffectedPortfolios) != 1 || result.AffectedPortfolios[0] != "holder" {
+			t.Errorf("AffectedPortfolios = %v, want [holder]", result.AffectedPortfolios)
+		}
+		if _, ok := companies["META"]; !ok || len(*deleted) != 1 || (*deleted)[0] != "FB" {
+			t.Errorf("Companies = %v, deleted = %v; want META saved and FB removed", companies, *deleted)
+		}
+		if _, ok := holder.Holdings["META"]; !ok {
+			t.Errorf("Holdings = %v, want META", holder.Holdings)
+		}
+	})
+
+	t.Run("SplitAdjustsHolders", func(t *testing.T) {
+		service, _, holder, _ := newFixture(t)
+		if _, err := service.ApplyCorporateAction(company.CorporateAction{Type: company.Split, Ticker: "FB", EffectiveDate: on, RatioTo: 2, RatioFrom: 1}); err != nil {
+			t.Fatalf("ApplyCorporateAction() error = %v, wantErr nil", err)
+		}
+		if pos := holder.Holdings["FB"]; pos.Shares != 20 || pos.PurchasePrice.Amount != 500 {
+			t.Errorf("Holding = %+v, want 20 shares at 500", pos)
+		}
+	})
+
+	t.Run("SpinoffRequiresNewCompany", func(t *testing.T) {
+		service, _, holder, _ := newFixture(t)
+		_, err := service.ApplyCorporateAction(company.CorporateAction{Type: company.Spinoff, Ticker: "FB", EffectiveDate: on, RatioTo: 1, RatioFrom: 1, NewTicker: "IG", BasisAllocation: 0.1})
+		if err == nil {
+			t.Fatal("ApplyCorporateAction() for an unknown spun-off company expected error, got nil")
+		}
+		if _, ok := holder.Holdings["IG"]; ok {
+			t.Error("Holding was created although the action failed")
+		}
+	})
+
+	t.Run("InvalidAction", func(t *testing.T) {
+		service, _, _, _ := newFixture(t)
+		if _, err := service.ApplyCorporateAction(company.CorporateAction{Type: company.Split, Ticker: "FB", EffectiveDate: on, RatioTo: 1, RatioFrom: 2}); err == nil {
+			t.Error("ApplyCorporateAction() with a share-reducing split expected error, got nil")
+		}
+	})
+}
+
+func TestCorporateActionService_UnitOfWork(t *testing.T) {
+	fb, _ := company.NewCompany("FB", company.FinancialMetrics{}, company.Technology)
+	holder, _ := portfolio.NewPortfolio("holder", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	pos, _ := portfolio.NewPosition("FB", 10, portfolio.Money{Amount: 1000, Currency: "USD"})
+	_ = holder.AddPosition(*pos, portfolio.Money{Amount: 10000, Currency: "USD"})
+	notHere := func() error { t.Error("write made outside the unit of work"); return nil }
+	companyRepo := &MockCompanyRepository{
+		FindByTickerFunc: func(ticker string) (*company.Company, error) {
+			if ticker == "FB" {
+				return fb, nil
+			}
+			return nil, company.ErrCompanyNotFound
+		},
+		SaveFunc:   func(*company.Company) error { return notHere() },
+		DeleteFunc: func(string) error { return notHere() },
+	}
+	portfolioRepo := &MockPortfolioRepository{
+		FindAllFunc: func() ([]*portfolio.Portfolio, error) { return []*portfolio.Portfolio{holder}, nil },
+		SaveFunc:    func(*portfolio.Portfolio) error { return notHere() },
+	}
+
+	var written []string
+	uowCompanies := &MockCompanyRepository{
+		SaveFunc:   func(c *company.Company) error { written = append(written, "save "+c.Ticker); return nil },
+		DeleteFunc: func(ticker string) error { written = append(written, "delete "+ticker); return nil },
+	}
+	uowPortfolios := &MockPortfolioRepository{SaveFunc: func(p *portfolio.Portfolio) error { written = append(written, "save "+p.ID); return nil }}
+	uow := &fakeUnitOfWork{repos: application.Repositories{Companies: uowCompanies, Portfolios: uowPortfolios}, err: errors.New("commit failed")}
+	service := application.NewCorporateActionService(companyRepo, portfolioRepo, application.WithCorporateActionUnitOfWork(uow))
+
+	_, err := service.ApplyCorporateAction(company.CorporateAction{Type: company.TickerChange, Ticker: "FB", EffectiveDate: time.Now(), NewTicker: "META"})
+	if err == nil || err.Error() != "commit failed" {
+		t.Errorf("ApplyCorporateAction() error = %v, want the commit error", err)
+	}
+	if uow.calls != 1 || fmt.Sprint(written) != "[save holder save META delete FB]" {
+		t.Errorf("unit of work ran %d times writing %v, want one unit saving holder and META and deleting FB", uow.calls, written)
+	}
+}
+
+// fakeUnitOfWork runs work against its own repositories, then fails the commit with err.
+type fakeUnitOfWork struct {
+	repos application.Repositories
+	err   error
+	calls int
+}
+
+func (u *fakeUnitOfWork) Do(work func(repos application.Repositories) error) error {
+	u.calls++
+	if err := work(u.repos); err != nil {
+		return err
+	}
+	return u.err
+}
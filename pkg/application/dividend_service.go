@@ -0,0 +1,173 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// DividendSummary is a DTO describing a company's dividends and current dividend yield.
+type DividendSummary struct {
+	Ticker                 string
+	Dividends              []company.Dividend
+	TrailingAnnualDividend portfolio.Money  // Dividends per share with an ex-date in the last twelve months
+	Price                  *portfolio.Money // Latest known share price, nil if unknown
+	Yield                  float64          // TrailingAnnualDividend / Price, zero when the price is unknown
+	AsOf                   time.Time
+}
+
+// DividendService provides application-level functionalities for dividends: recording
+// declarations on companies and crediting them to the portfolios that hold the shares.
+type DividendService struct {
+	companyRepo   company.CompanyRepository
+	portfolioRepo portfolio.PortfolioRepository
+	prices        portfolio.PriceProvider // Optional; needed for dividend yield and reinvestment
+}
+
+// NewDividendService creates a new instance of DividendService.
+func NewDividendService(cRepo company.CompanyRepository, pRepo portfolio.PortfolioRepository, prices portfolio.PriceProvider) *DividendService {
+	return &DividendService{
+		companyRepo:   cRepo,
+		portfolioRepo: pRepo,
+		prices:        prices,
+	}
+}
+
+// DeclareDividend records a dividend declared by a company.
+func (s *DividendService) DeclareDividend(ticker string, exDate, payDate time.Time, amountPerShare portfolio.Money) (company.Dividend, error) {
+	if ticker == "" {
+		return company.Dividend{}, errors.New("ticker cannot be empty")
+	}
+	c, err := s.companyRepo.FindByTicker(ticker)
+	if err != nil {
+		return company.Dividend{}, err
+	}
+	d, err := c.DeclareDividend(exDate, payDate, amountPerShare.Amount, amountPerShare.Currency)
+	if err != nil {
+		return company.Dividend{}, fmt.Errorf("domain error declaring dividend for %s: %w", ticker, err)
+	}
+	if err := s.companyRepo.Save(c); err != nil {
+		return company.Dividend{}, fmt.Errorf("failed to save company %s after declaring dividend: %w", ticker, err)
+	}
+	// Publish DividendDeclaredEvent
+	return d, nil
+}
+
+// GetDividendSummary returns a company's declared dividends with its trailing twelve-month
+// dividend per share and, when a share price is known, its dividend yield.
+func (s *DividendService) GetDividendSummary(ticker string) (*DividendSummary, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+	c, err := s.companyRepo.FindByTicker(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	amount, currency := c.TrailingAnnualDividend(now)
+	summary := &DividendSummary{
+		Ticker:                 c.Ticker,
+		Dividends:              c.Dividends,
+		TrailingAnnualDividend: portfolio.Money{Amount: amount, Currency: currency},
+		AsOf:                   now,
+	}
+	if s.prices == nil {
+		return summary, nil
+	}
+	price, err := s.prices.Price(ticker, now)
+	if errors.Is(err, portfolio.ErrPriceNotFound) {
+		return summary, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price for %s: %w", ticker, err)
+	}
+	summary.Price = &price
+	if price.IsPositive() && price.Currency == currency {
+		summary.Yield = float64(amount) / float64(price.Amount)
+	}
+	return summary, nil
+}
+
+// ProcessDividends credits every dividend paid on or before asOf to the portfolios that held
+// the company's shares before its ex-date, applying each portfolio's dividend policy.
+// Dividends already credited to a portfolio are skipped, so it is safe to run repeatedly;
+// it is intended to be run once a day by a scheduler.
+func (s *DividendService) ProcessDividends(asOf time.Time) ([]portfolio.DividendPayment, error) {
+	companies, err := s.companyRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies for dividend processing: %w", err)
+	}
+	sort.Slice(companies, func(i, j int) bool { return companies[i].Ticker < companies[j].Ticker })
+	portfolios, err := s.portfolioRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portfolios for dividend processing: %w", err)
+	}
+
+	var payments []portfolio.DividendPayment
+	for _, p := range portfolios {
+		credited := false
+		for _, c := range companies {
+			for _, d := range c.DividendsPayableBy(effectiveDate(asOf)) {
+				payment, err := s.creditDividend(p, d)
+				if err != nil {
+					return nil, err
+				}
+				if payment != nil {
+					payments = append(payments, *payment)
+					credited = true
+					// Publish DividendReceivedEvent
+				}
+			}
+		}
+		if !credited {
+			continue
+		}
+		if err := s.portfolioRepo.Save(p); err != nil {
+			return nil, fmt.Errorf("failed to save portfolio %s after crediting dividends: %w", p.ID, err)
+		}
+	}
+	return payments, nil
+}
+
+// creditDividend credits one dividend to a portfolio, returning nil if it is not entitled to
+// it or has already received it. Entitlement is checked against the ticker the dividend was
+// declared under, so that dividends declared before a ticker change are still paid.
+func (s *DividendService) creditDividend(p *portfolio.Portfolio, d company.Dividend) (*portfolio.DividendPayment, error) {
+	reinvestPrice, err := s.reinvestmentPrice(p, d.Ticker, d.PayDate)
+	if err != nil {
+		return nil, err
+	}
+	amount := portfolio.Money{Amount: d.AmountPerShare, Currency: d.Currency}
+	payment, err := p.CreditDividend(d.Ticker, d.ID(), d.ExDate, d.PayDate, amount, reinvestPrice)
+	if errors.Is(err, portfolio.ErrDividendAlreadyCredited) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("domain error crediting dividend %s to portfolio %s: %w", d.ID(), p.ID, err)
+	}
+	return payment, nil
+}
+
+// reinvestmentPrice returns the share price used to reinvest a dividend, or nil when the
+// portfolio does not reinvest dividends or no price is known for the pay date.
+func (s *DividendService) reinvestmentPrice(p *portfolio.Portfolio, ticker string, payDate time.Time) (*portfolio.Money, error) {
+	if p.DividendPolicy.Reinvestment == portfolio.NoReinvestment || s.prices == nil {
+		return nil, nil
+	}
+	if _, held := p.Holdings[ticker]; !held {
+		return nil, nil
+	}
+	price, err := s.prices.Price(ticker, payDate)
+	if errors.Is(err, portfolio.ErrPriceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reinvestment price for %s: %w", ticker, err)
+	}
+	return &price, nil
+}
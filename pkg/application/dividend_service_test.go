@@ -0,0 +1,84 @@
+package application_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestDividendService_ProcessDividends(t *testing.T) {
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	exDate := time.Now().Add(24 * time.Hour)
+	payDate := exDate.Add(7 * 24 * time.Hour)
+	if _, err := aapl.DeclareDividend(exDate, payDate, 50, "USD"); err != nil {
+		t.Fatalf("DeclareDividend() error = %v", err)
+	}
+	companyRepo := &MockCompanyRepository{
+		FindAllFunc: func() ([]*company.Company, error) { return []*company.Company{aapl}, nil },
+	}
+
+	holder, _ := portfolio.NewPortfolio("holder", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	pos, _ := portfolio.NewPosition("AAPL", 10, portfolio.Money{Amount: 1000, Currency: "USD"})
+	_ = holder.AddPosition(*pos, portfolio.Money{Amount: 10000, Currency: "USD"})
+	_ = holder.SetDividendPolicy(portfolio.DividendPolicy{Reinvestment: portfolio.ReinvestFractionalShares, WithholdingTaxRate: 0.15})
+	other, _ := portfolio.NewPortfolio("other", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+
+	saved := map[string]int{}
+	portfolioRepo := &MockPortfolioRepository{
+		FindAllFunc: func() ([]*portfolio.Portfolio, error) { return []*portfolio.Portfolio{holder, other}, nil },
+		SaveFunc:    func(p *portfolio.Portfolio) error { saved[p.ID]++; return nil },
+	}
+	prices := stubPrices{"AAPL": {Amount: 2000, Currency: "USD"}}
+	service := application.NewDividendService(companyRepo, portfolioRepo, prices)
+
+	t.Run("NothingPaidBeforePayDate", func(t *testing.T) {
+		payments, err := service.ProcessDividends(exDate)
+		if err != nil || len(payments) != 0 {
+			t.Errorf("ProcessDividends(ex-date) = %v, %v; want no payments", payments, err)
+		}
+	})
+
+	t.Run("CreditsHoldersOnce", func(t *testing.T) {
+		payments, err := service.ProcessDividends(payDate)
+		if err != nil {
+			t.Fatalf("ProcessDividends() error = %v, wantErr nil", err)
+		}
+		if len(payments) != 1 || payments[0].PortfolioID != "holder" || payments[0].Net.Amount != 425 {
+			t.Fatalf("ProcessDividends() = %+v, want one net 425 payment to holder", payments)
+		}
+		if math.Abs(holder.Holdings["AAPL"].Quantity()-10.2125) > 1e-9 {
+			t.Errorf("Holding quantity = %v, want 10.2125 after reinvestment", holder.Holdings["AAPL"].Quantity())
+		}
+		if saved["holder"] != 1 || saved["other"] != 0 {
+			t.Errorf("Saves = %v, want only the holder portfolio saved", saved)
+		}
+
+		again, err := service.ProcessDividends(payDate)
+		if err != nil || len(again) != 0 {
+			t.Errorf("second ProcessDividends() = %v, %v; want no payments", again, err)
+		}
+	})
+}
+
+func TestDividendService_GetDividendSummary(t *testing.T) {
+	ko, _ := company.NewCompany("KO", company.FinancialMetrics{}, company.ConsumerStaples)
+	exDate := time.Now().AddDate(0, -1, 0)
+	_, _ = ko.DeclareDividend(exDate, exDate.AddDate(0, 0, 14), 46, "USD")
+	_, _ = ko.DeclareDividend(exDate.AddDate(0, -3, 0), exDate.AddDate(0, -3, 14), 46, "USD")
+	companyRepo := &MockCompanyRepository{
+		FindByTickerFunc: func(ticker string) (*company.Company, error) { return ko, nil },
+	}
+	service := application.NewDividendService(companyRepo, nil, stubPrices{"KO": {Amount: 6000, Currency: "USD"}})
+
+	summary, err := service.GetDividendSummary("KO")
+	if err != nil {
+		t.Fatalf("GetDividendSummary() error = %v, wantErr nil", err)
+	}
+	if summary.TrailingAnnualDividend.Amount != 92 || math.Abs(summary.Yield-92.0/6000) > 1e-9 {
+		t.Errorf("GetDividendSummary() = TTM %d, yield %v; want 92 and %v", summary.TrailingAnnualDividend.Amount, summary.Yield, 92.0/6000)
+	}
+}
@@ -3,36 +3,136 @@ package application
 import (
 	"errors" // Using standard errors for now
 	"fmt"    // For error formatting
-	"time"   // For setting UpdatedAt if decided here
+	"sort"
+	"time" // For setting UpdatedAt if decided here
 
 	// Project packages
 	"github.com/jizumer/expedition-value/pkg/domain/company"
 	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
 
 	"github.com/google/uuid" // For generating portfolio IDs
 )
 
 // RebalanceRecommendation is a DTO for rebalancing suggestions.
-// For MVP, it's a simple structure.
 type RebalanceRecommendation struct {
+	ID          string // Recommendation to approve and execute; empty when not persisted
 	PortfolioID string
-	Suggestions []string  // Example: ["Sell 10 shares of AAPL", "Buy 5 shares of MSFT"]
+	Suggestions []portfolio.TradeRecommendation // Sells first, then buys by size
+	Status      recommendation.Status
 	GeneratedAt time.Time // Timestamp when the recommendation was generated
+	ExpiresAt   time.Time // The recommendation must be approved and executed before then
+	DecidedBy   string    // Who approved or rejected it
+	Reason      string    // Why it was rejected, if it was
 }
 
 // PortfolioService provides application-level functionalities for managing portfolios.
 // It orchestrates domain logic and interacts with portfolio and company repositories.
+// Changes that take expectedVersions, such as those made on behalf of a client that read the
+// portfolio earlier, apply only if the portfolio loaded for the change is at one of them, and
+// fail with a *portfolio.PreconditionFailedError otherwise.
 type PortfolioService struct {
 	portfolioRepo portfolio.PortfolioRepository
 	companyRepo   company.CompanyRepository // To validate company tickers
+	fxRates       portfolio.FXRateProvider  // Optional; needed for multi-currency portfolios
+	prices        portfolio.PriceProvider   // Optional; holdings are valued at cost without it
+	constraints   portfolio.RebalanceConstraints
+	recRepo       recommendation.RecommendationRepository // Optional; recommendations are not kept without it
+	recTTL        time.Duration
+	policies      map[string]portfolio.RiskPolicy       // Custom risk policies, keyed by name
+	snapshots     portfolio.ValuationSnapshotRepository // Optional; no valuation history is kept without it
+	benchmark     portfolio.Benchmark                   // Compared against when a portfolio designates none
+	history       portfolio.PortfolioHistory            // Optional; only event-sourced portfolios have one
+	uow           UnitOfWork                            // Optional; multi-aggregate writes are not atomic without it
+}
+
+// PortfolioServiceOption configures optional collaborators of a PortfolioService.
+type PortfolioServiceOption func(*PortfolioService)
+
+// WithFXRateProvider sets the exchange rate source used for currency conversions and valuations.
+func WithFXRateProvider(rates portfolio.FXRateProvider) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.fxRates = rates
+	}
+}
+
+// WithPriceProvider sets the market price source used for valuations.
+func WithPriceProvider(prices portfolio.PriceProvider) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.prices = prices
+	}
+}
+
+// WithRebalanceConstraints sets the tolerance band and minimum trade size used when
+// recommending rebalances. portfolio.DefaultRebalanceConstraints applies otherwise.
+func WithRebalanceConstraints(constraints portfolio.RebalanceConstraints) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.constraints = constraints
+	}
+}
+
+// WithRecommendationRepository keeps generated rebalancing recommendations so that they can go
+// through the approval workflow; they expire ttl after being generated.
+func WithRecommendationRepository(repo recommendation.RecommendationRepository, ttl time.Duration) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.recRepo = repo
+		s.recTTL = ttl
+	}
+}
+
+// WithRiskPolicies makes custom risk policies, keyed by name, available to portfolios.
+// A policy named after a risk profile replaces that profile's default policy.
+func WithRiskPolicies(policies map[string]portfolio.RiskPolicy) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.policies = policies
+	}
+}
+
+// WithValuationSnapshotRepository keeps end-of-day valuation snapshots of every portfolio.
+func WithValuationSnapshotRepository(repo portfolio.ValuationSnapshotRepository) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.snapshots = repo
+	}
+}
+
+// WithDefaultBenchmark sets the index proxy ticker portfolios without a benchmark of their own
+// are compared against.
+func WithDefaultBenchmark(ticker string) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		if ticker != "" {
+			s.benchmark = portfolio.TickerBenchmark(ticker)
+		}
+	}
+}
+
+// WithPortfolioHistory makes the past states and the events of event-sourced portfolios
+// available.
+func WithPortfolioHistory(history portfolio.PortfolioHistory) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.history = history
+	}
+}
+
+// WithUnitOfWork makes the writes of operations that change several aggregates, such as
+// executing a rebalance, commit together or not at all.
+func WithUnitOfWork(uow UnitOfWork) PortfolioServiceOption {
+	return func(s *PortfolioService) {
+		s.uow = uow
+	}
 }
 
 // NewPortfolioService creates a new instance of PortfolioService.
-func NewPortfolioService(pRepo portfolio.PortfolioRepository, cRepo company.CompanyRepository) *PortfolioService {
-	return &PortfolioService{
+func NewPortfolioService(pRepo portfolio.PortfolioRepository, cRepo company.CompanyRepository, opts ...PortfolioServiceOption) *PortfolioService {
+	s := &PortfolioService{
 		portfolioRepo: pRepo,
 		companyRepo:   cRepo,
+		constraints:   portfolio.DefaultRebalanceConstraints(),
+		recTTL:        recommendation.DefaultTTL,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // CreatePortfolio creates a new Portfolio instance, generates an ID, and saves it.
@@ -43,6 +143,11 @@ func (s *PortfolioService) CreatePortfolio(cashBalance portfolio.Money, riskProf
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new portfolio in domain: %w", err)
 	}
+	if policy, ok := s.policies[riskProfile.String()]; ok {
+		if err := newPortfolio.SetRiskPolicy(policy); err != nil {
+			return nil, fmt.Errorf("failed to apply the %s risk policy: %w", policy.Name, err)
+		}
+	}
 
 	// Save the new portfolio to the repository
 	err = s.portfolioRepo.Save(newPortfolio)
@@ -67,7 +172,39 @@ func (s *PortfolioService) GetPortfolioDetails(portfolioID string) (*portfolio.P
 	return p, nil
 }
 
+// GetPortfolioAsOf rebuilds a portfolio as it was at the given time from its events.
+func (s *PortfolioService) GetPortfolioAsOf(portfolioID string, at time.Time) (*portfolio.Portfolio, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	if s.history == nil {
+		return nil, errors.New("portfolio history needs event-sourced persistence")
+	}
+	p, err := s.history.FindByIDAsOf(portfolioID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find portfolio %s as of %s: %w", portfolioID, at.Format(time.RFC3339), err)
+	}
+	return p, nil
+}
+
+// GetPortfolioEvents returns the events recorded for a portfolio, oldest first.
+func (s *PortfolioService) GetPortfolioEvents(portfolioID string) ([]portfolio.Event, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	if s.history == nil {
+		return nil, errors.New("portfolio history needs event-sourced persistence")
+	}
+	events, err := s.history.Events(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find events of portfolio %s: %w", portfolioID, err)
+	}
+	return events, nil
+}
+
 // AddPosition adds a new position to an existing portfolio.
+// When companies can be looked up, the purchase must respect the portfolio's risk policy;
+// otherwise it fails with a *portfolio.RiskPolicyViolationError.
 func (s *PortfolioService) AddPosition(portfolioID string, companyTicker string, shares int, purchasePrice portfolio.Money) error {
 	if portfolioID == "" {
 		return errors.New("portfolioID cannot be empty")
@@ -80,8 +217,10 @@ func (s *PortfolioService) AddPosition(portfolioID string, companyTicker string,
 	}
 
 	// Optional: Validate company ticker
+	var comp *company.Company
 	if s.companyRepo != nil {
-		comp, err := s.companyRepo.FindByTicker(companyTicker)
+		var err error
+		comp, err = s.companyRepo.FindByTicker(companyTicker)
 		if err != nil {
 			return fmt.Errorf("failed to verify company ticker %s: %w", companyTicker, err)
 		}
@@ -102,9 +241,15 @@ func (s *PortfolioService) AddPosition(portfolioID string, companyTicker string,
 		return fmt.Errorf("failed to create new position: %w", err)
 	}
 
-	// Calculate cost (simplified: purchasePrice is per share)
-	// Proper money multiplication would be in Money VO
-	cost := portfolio.Money{Amount: purchasePrice.Amount * int64(shares), Currency: purchasePrice.Currency}
+	// Calculate cost (purchasePrice is per share, paid in its own currency).
+	// The portfolio adds its trading fee on top and includes it in the cost basis.
+	cost := purchasePrice.Multiply(int64(shares))
+
+	if comp != nil {
+		if err := s.checkPurchase(p, comp, cost); err != nil {
+			return fmt.Errorf("domain error adding position to portfolio %s: %w", portfolioID, err)
+		}
+	}
 
 	// Call domain method to add position
 	err = p.AddPosition(*newPosition, cost) // Assuming AddPosition is a method on *Portfolio
@@ -120,54 +265,94 @@ func (s *PortfolioService) AddPosition(portfolioID string, companyTicker string,
 	return nil
 }
 
-// AdjustPosition modifies an existing position in a portfolio.
-// For simplicity, this example assumes adjusting means changing the number of shares.
-// A more robust implementation might handle price changes, splits, etc.
-func (s *PortfolioService) AdjustPosition(portfolioID string, companyTicker string, newShares int /*, newAveragePrice *portfolio.Money */) error {
+// checkPurchase verifies that buying comp for cost keeps p within its risk policy, valuing the
+// portfolio at the latest known prices.
+func (s *PortfolioService) checkPurchase(p *portfolio.Portfolio, comp *company.Company, cost portfolio.Money) error {
+	now := time.Now()
+	prices, err := s.currentPrices(p, now)
+	if err != nil {
+		return err
+	}
+	valuation, err := p.Valuate(prices, s.fxRates, now)
+	if err != nil {
+		return err
+	}
+	baseCost, err := portfolio.ConvertMoney(cost, p.BaseCurrency, s.fxRates, now)
+	if err != nil {
+		return err
+	}
+
+	companies := map[string]portfolio.ScoreInputs{comp.Ticker: scoreInputsOf(comp)}
+	for ticker := range p.Holdings {
+		if held, err := s.companyRepo.FindByTicker(ticker); err == nil && held != nil {
+			companies[ticker] = scoreInputsOf(held)
+		}
+	}
+	return p.CheckPurchase(comp.Ticker, baseCost, companies, valuation)
+}
+
+// AdjustPosition buys or sells shares of a company held by a portfolio at tradePrice per share,
+// so that it holds newShares whole shares of it; zero sells the whole position, fractional
+// shares included. The trade moves cash, pays the fee of the portfolio's fee schedule and is
+// recorded in the ledger as AddPosition does. When companies can be looked up, a purchase must
+// respect the portfolio's risk policy; otherwise it fails with a *portfolio.RiskPolicyViolationError.
+func (s *PortfolioService) AdjustPosition(portfolioID string, companyTicker string, newShares int, tradePrice portfolio.Money) error {
 	if portfolioID == "" {
 		return errors.New("portfolioID cannot be empty")
 	}
 	if companyTicker == "" {
 		return errors.New("companyTicker cannot be empty")
 	}
-	if newShares <= 0 { // Assuming adjusting to 0 means closing the position
-		return errors.New("new shares count must be positive; use RemovePosition to close")
+	if newShares < 0 {
+		return errors.New("new shares count cannot be negative")
+	}
+	if !tradePrice.IsPositive() {
+		return errors.New("trade price must be positive")
 	}
 
 	p, err := s.GetPortfolioDetails(portfolioID)
 	if err != nil {
 		return err
 	}
-
-	// --- Domain logic to adjust position ---
-	// This is a simplified placeholder. The actual logic would be more complex:
-	// - Find the existing position.
-	// - Calculate difference in shares.
-	// - If shares increase: calculate cost, check cash balance, update cash balance.
-	// - If shares decrease: calculate proceeds, update cash balance.
-	// - Update the position's share count and potentially average price.
-	// - The Portfolio aggregate should enforce these rules.
-
-	existingPosition, ok := p.Holdings[companyTicker]
+	existing, ok := p.Holdings[companyTicker]
 	if !ok {
 		return fmt.Errorf("position for ticker %s not found in portfolio %s", companyTicker, portfolioID)
 	}
 
-	// Simplified: just update shares. Real logic needs cost/proceeds & cash adjustment.
-	// This should ideally call a method on `p` like `p.AdjustHolding(companyTicker, newShares)`
-	// For now, directly modifying for brevity, but this bypasses domain logic.
-	// This is a placeholder for where a proper domain method call would go.
-	p.Holdings[companyTicker] = portfolio.Position{
-		CompanyTicker: existingPosition.CompanyTicker,
-		Shares:        newShares,
-		PurchasePrice: existingPosition.PurchasePrice, // Average price would change in reality
+	switch change := newShares - existing.Shares; {
+	case change > 0:
+		bought, err := portfolio.NewPosition(companyTicker, change, tradePrice)
+		if err != nil {
+			return fmt.Errorf("failed to create new position: %w", err)
+		}
+		cost := tradePrice.Multiply(int64(change))
+		if s.companyRepo != nil {
+			comp, err := s.companyRepo.FindByTicker(companyTicker)
+			if err != nil {
+				return fmt.Errorf("failed to verify company ticker %s: %w", companyTicker, err)
+			}
+			if comp != nil {
+				fee := p.FeeSchedule.TradeFee(float64(change), tradePrice)
+				if err := s.checkPurchase(p, comp, portfolio.Money{Amount: cost.Amount + fee.Amount, Currency: cost.Currency}); err != nil {
+					return fmt.Errorf("domain error adjusting position in portfolio %s: %w", portfolioID, err)
+				}
+			}
+		}
+		err = p.AddPosition(*bought, cost)
+	case change < 0 || newShares == 0: // A position of only a fractional share is closed too
+		proceeds := tradePrice.Multiply(int64(-change))
+		if newShares == 0 {
+			proceeds = tradePrice.Scale(existing.Quantity()) // The fractional shares are sold too
+		}
+		err = p.RemovePosition(companyTicker, -change, proceeds)
+	default:
+		return nil
 	}
-	p.UpdatedAt = time.Now()
-	// --- End of simplified domain logic placeholder ---
-
-	// Save the updated portfolio
-	err = s.portfolioRepo.Save(p)
 	if err != nil {
+		return fmt.Errorf("domain error adjusting position in portfolio %s: %w", portfolioID, err)
+	}
+
+	if err := s.portfolioRepo.Save(p); err != nil {
 		return fmt.Errorf("failed to save updated portfolio %s after adjusting position: %w", portfolioID, err)
 	}
 	return nil
@@ -184,56 +369,690 @@ func (s *PortfolioService) RecommendRebalance(portfolioID string) (*RebalanceRec
 		return nil, err
 	}
 
-	// Call domain logic on the portfolio to generate recommendations.
-	// The domain method `GenerateRebalanceRecommendations` is a placeholder.
-	// It would contain the actual logic for determining what to buy/sell.
-	domainRecs, err := p.GenerateRebalanceRecommendations()
+	now := time.Now()
+	inputs, err := s.companyScoreInputs()
+	if err != nil {
+		return nil, err
+	}
+	targets := portfolio.TargetWeights(inputs, p.Policy())
+
+	tickers := make([]string, 0, len(p.Holdings)+len(targets))
+	for ticker := range p.Holdings {
+		tickers = append(tickers, ticker)
+	}
+	for ticker := range targets {
+		tickers = append(tickers, ticker)
+	}
+	prices, err := s.pricesOf(tickers, now) // Tickers without a price are not traded
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := p.GenerateRebalanceRecommendations(targets, prices, s.fxRates, s.constraints, now)
 	if err != nil {
 		// This could be an error like "rebalance not needed" or a real calculation error.
 		return nil, fmt.Errorf("domain error generating rebalance recommendations for portfolio %s: %w", portfolioID, err)
 	}
 
-	recommendation := &RebalanceRecommendation{
-		PortfolioID: portfolioID,
-		Suggestions: domainRecs, // Assuming domainRecs is []string as per domain placeholder
-		GeneratedAt: time.Now(),
+	return s.propose(portfolioID, p.Recommend(orders, inputs), now)
+}
+
+// RecommendEntry proposes opening (or adding to) a position in ticker at its latest price,
+// sized to what the portfolio's risk policy allows. It is how entry signals of a portfolio's
+// watchlist become recommendations, going through the same approval workflow as rebalancing.
+func (s *PortfolioService) RecommendEntry(portfolioID string, ticker string, rationale string) (*RebalanceRecommendation, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+	if s.companyRepo == nil {
+		return nil, errors.New("company repository is not configured")
+	}
+	comp, err := s.companyRepo.FindByTicker(ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find company %s: %w", ticker, err)
+	}
+	if comp == nil {
+		return nil, fmt.Errorf("company with ticker %s not found", ticker)
+	}
+
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	prices, err := s.currentPrices(p, now)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.pricesOf([]string{ticker}, now)
+	if err != nil {
+		return nil, err
+	}
+	price, ok := quote[ticker]
+	if !ok {
+		return nil, fmt.Errorf("price for %s not found", ticker)
+	}
+	valuation, err := p.Valuate(prices, s.fxRates, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to value portfolio %s: %w", portfolioID, err)
+	}
+
+	trade, err := p.RecommendEntry(ticker, price, scoreInputsOf(comp), valuation, s.fxRates, rationale)
+	if err != nil {
+		return nil, fmt.Errorf("domain error recommending entry into %s for portfolio %s: %w", ticker, portfolioID, err)
+	}
+	if err := s.checkPurchase(p, comp, trade.LimitPrice.Multiply(int64(trade.Quantity))); err != nil {
+		return nil, fmt.Errorf("domain error recommending entry into %s for portfolio %s: %w", ticker, portfolioID, err)
+	}
+	return s.propose(portfolioID, []portfolio.TradeRecommendation{trade}, now)
+}
+
+// propose records trades as a recommendation awaiting approval. Without a recommendation
+// repository the recommendation is returned unsaved.
+func (s *PortfolioService) propose(portfolioID string, trades []portfolio.TradeRecommendation, now time.Time) (*RebalanceRecommendation, error) {
+	if s.recRepo == nil {
+		return &RebalanceRecommendation{
+			PortfolioID: portfolioID,
+			Suggestions: trades,
+			Status:      recommendation.Proposed,
+			GeneratedAt: now,
+			ExpiresAt:   now.Add(s.recTTL),
+		}, nil
+	}
+
+	rec, err := recommendation.NewRecommendation(uuid.NewString(), portfolioID, trades, now, s.recTTL)
+	if err != nil {
+		return nil, fmt.Errorf("domain error creating recommendation for portfolio %s: %w", portfolioID, err)
+	}
+	if err := s.recRepo.Save(rec); err != nil {
+		return nil, fmt.Errorf("failed to save recommendation for portfolio %s: %w", portfolioID, err)
 	}
+	return toRebalanceRecommendation(rec), nil
+}
 
-	return recommendation, nil
+// toRebalanceRecommendation maps a Recommendation aggregate to its DTO.
+func toRebalanceRecommendation(rec *recommendation.Recommendation) *RebalanceRecommendation {
+	return &RebalanceRecommendation{
+		ID:          rec.ID,
+		PortfolioID: rec.PortfolioID,
+		Suggestions: rec.Trades,
+		Status:      rec.Status,
+		GeneratedAt: rec.CreatedAt,
+		ExpiresAt:   rec.ExpiresAt,
+		DecidedBy:   rec.DecidedBy,
+		Reason:      rec.Reason,
+	}
 }
 
-// ExecuteRebalance applies a given rebalancing recommendation to the portfolio.
-func (s *PortfolioService) ExecuteRebalance(portfolioID string, recommendation RebalanceRecommendation) error {
+// GetTargetWeights returns the weights the portfolio's current risk policy gives the companies,
+// keyed by ticker, scoring them as they were known at knownAt, or as they are now when it is
+// zero. Past weights are free of look-ahead: companies are scored on the metrics and scores
+// known then, and those with nothing known then get no weight.
+func (s *PortfolioService) GetTargetWeights(portfolioID string, knownAt time.Time) (map[string]float64, error) {
 	if portfolioID == "" {
-		return errors.New("portfolioID cannot be empty")
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
 	}
-	if recommendation.PortfolioID != portfolioID {
-		return errors.New("recommendation portfolioID does not match provided portfolioID")
+	companies := s.companyRepo
+	if companies != nil && !knownAt.IsZero() {
+		companies = company.KnownAt(companies, knownAt)
+	}
+	inputs, err := scoreInputsFrom(companies)
+	if err != nil {
+		return nil, err
+	}
+	return portfolio.TargetWeights(inputs, p.Policy()), nil
+}
+
+// companyScoreInputs returns the current score and metrics of every known company, keyed by
+// ticker. Held tickers the company repository no longer knows get no score, so they are sold.
+func (s *PortfolioService) companyScoreInputs() (map[string]portfolio.ScoreInputs, error) {
+	return scoreInputsFrom(s.companyRepo)
+}
+
+// scoreInputsFrom returns the score and metrics of every company in companies, keyed by ticker;
+// none when there is no repository.
+func scoreInputsFrom(companies company.CompanyRepository) (map[string]portfolio.ScoreInputs, error) {
+	inputs := make(map[string]portfolio.ScoreInputs)
+	if companies == nil {
+		return inputs, nil
+	}
+	all, err := companies.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load company scores: %w", err)
+	}
+	for _, c := range all {
+		inputs[c.Ticker] = scoreInputsOf(c)
+	}
+	return inputs, nil
+}
+
+// scoreInputsOf returns the score, metrics and sector of a company as the portfolio sees them.
+func scoreInputsOf(c *company.Company) portfolio.ScoreInputs {
+	return portfolio.ScoreInputs{
+		Score:        c.CurrentScore,
+		PERatio:      c.FinancialMetrics.PERatio,
+		PBRatio:      c.FinancialMetrics.PBRatio,
+		DebtToEquity: c.FinancialMetrics.DebtToEquity,
+		Sector:       sectorName(c),
+	}
+}
+
+// sectorName returns the name of a company's sector, or "" when it is undefined.
+func sectorName(c *company.Company) string {
+	if c.Sector == company.UndefinedSector {
+		return ""
+	}
+	return c.Sector.String()
+}
+
+// ExecuteRebalance applies an approved rebalancing recommendation to the portfolio.
+// The recommendation must still be valid and every price it was based on must be within the
+// configured drift tolerance of the latest price. The trades are executed all-or-nothing at the
+// latest prices; the report lists the fills, fees and the cash left.
+func (s *PortfolioService) ExecuteRebalance(portfolioID string, recommendationID string, expectedVersions ...int) (*portfolio.ExecutionReport, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	if recommendationID == "" {
+		return nil, errors.New("recommendationID cannot be empty")
+	}
+	if s.recRepo == nil {
+		return nil, errors.New("recommendation repository is not configured")
+	}
+
+	rec, err := s.recRepo.FindByID(recommendationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recommendation %s: %w", recommendationID, err)
+	}
+	if rec.PortfolioID != portfolioID {
+		return nil, errors.New("recommendation portfolioID does not match provided portfolioID")
 	}
 
 	p, err := s.GetPortfolioDetails(portfolioID)
 	if err != nil {
+		return nil, err
+	}
+	if err := portfolio.CheckVersion(p, expectedVersions...); err != nil {
+		return nil, fmt.Errorf("refused executing recommendation %s: %w", recommendationID, err)
+	}
+
+	now := time.Now()
+	if rec.Expire(now) {
+		if err := s.recRepo.Save(rec); err != nil {
+			return nil, fmt.Errorf("failed to save expired recommendation %s: %w", recommendationID, err)
+		}
+		return nil, fmt.Errorf("domain error executing recommendation %s: %w", recommendationID, recommendation.ErrExpired)
+	}
+	tickers := make([]string, 0, len(rec.Trades))
+	for _, trade := range rec.Trades {
+		tickers = append(tickers, trade.Ticker)
+	}
+	prices, err := s.pricesOf(tickers, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := rec.CheckExecutable(prices, s.constraints.PriceDriftTolerance, now); err != nil {
+		return nil, fmt.Errorf("domain error executing recommendation %s: %w", recommendationID, err)
+	}
+
+	report, err := p.ExecuteTrades(rec.Trades, prices, rec.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("domain error executing recommendation %s: %w", recommendationID, err)
+	}
+	if err := rec.MarkExecuted(now); err != nil {
+		return nil, fmt.Errorf("domain error executing recommendation %s: %w", recommendationID, err)
+	}
+	err = s.unitOfWork().Do(func(repos Repositories) error {
+		if err := repos.Portfolios.Save(p); err != nil {
+			return fmt.Errorf("failed to save portfolio %s after executing rebalance: %w", portfolioID, err)
+		}
+		if err := repos.Recommendations.Save(rec); err != nil {
+			return fmt.Errorf("failed to save recommendation %s after executing it: %w", recommendationID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// unitOfWork returns the unit of work to group writes in, which writes directly to the
+// service's repositories when none was configured.
+func (s *PortfolioService) unitOfWork() UnitOfWork {
+	if s.uow != nil {
+		return s.uow
+	}
+	return directUnitOfWork{repos: Repositories{Companies: s.companyRepo, Portfolios: s.portfolioRepo, Recommendations: s.recRepo}}
+}
+
+// ExchangeCash converts cash held by a portfolio from one currency into another at the
+// current rate, e.g. to fund purchases of securities that trade in a foreign currency.
+func (s *PortfolioService) ExchangeCash(portfolioID string, amount portfolio.Money, toCurrency string, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	if toCurrency == "" {
+		return nil, errors.New("target currency cannot be empty")
+	}
+	return s.modifyPortfolio(portfolioID, "exchanging cash", expectedVersions, func(p *portfolio.Portfolio) error {
+		_, err := p.ExchangeCash(amount, toCurrency, s.fxRates, time.Now())
 		return err
+	})
+}
+
+// GetValuation values a portfolio in its base currency using the latest known prices and rates.
+// Holdings without a known price are valued at their purchase price.
+func (s *PortfolioService) GetValuation(portfolioID string) (*portfolio.Valuation, error) {
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
 	}
 
-	// --- Domain logic to apply rebalance ---
-	// This would involve:
-	// - Iterating through recommendation.Suggestions.
-	// - For each suggestion (e.g., "Sell 10 AAPL", "Buy 5 MSFT"):
-	//   - Parse the action, ticker, shares.
-	//   - Call domain methods like `p.RemovePosition` or `p.AddPosition`.
-	//   - These domain methods must handle cash adjustments.
-	// - This entire process should be transactional within the Portfolio aggregate.
-	// For now, this is a placeholder as the domain `ApplyRebalance` is not fully defined.
-	// p.ApplyRebalance(recommendation) // This would be the ideal call
-	fmt.Printf("Executing rebalance for portfolio %s with %d suggestions (placeholder)\n", portfolioID, len(recommendation.Suggestions))
-	p.LastRebalanceTime = time.Now() // Mark as rebalanced
-	p.UpdatedAt = time.Now()
-	// --- End of placeholder ---
+	now := time.Now()
+	prices, err := s.currentPrices(p, now)
+	if err != nil {
+		return nil, err
+	}
 
-	err = s.portfolioRepo.Save(p)
+	valuation, err := p.Valuate(prices, s.fxRates, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to value portfolio %s: %w", portfolioID, err)
+	}
+	return valuation, nil
+}
+
+// SnapshotValuations values every portfolio at the prices and rates of asOf and stores the
+// valuations as that day's snapshots. It is intended to be run at the end of each day by a
+// scheduler; running it again on the same day replaces the day's snapshots.
+func (s *PortfolioService) SnapshotValuations(asOf time.Time) error {
+	if s.snapshots == nil {
+		return errors.New("valuation snapshots need a snapshot repository")
+	}
+	portfolios, err := s.portfolioRepo.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list portfolios for valuation snapshots: %w", err)
+	}
+	for _, p := range portfolios {
+		prices, err := s.currentPrices(p, asOf)
+		if err != nil {
+			return err
+		}
+		valuation, err := p.Valuate(prices, s.fxRates, asOf)
+		if err != nil {
+			return fmt.Errorf("failed to value portfolio %s: %w", p.ID, err)
+		}
+		if err := s.snapshots.Save(valuation); err != nil {
+			return fmt.Errorf("failed to save valuation snapshot of portfolio %s: %w", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetValuationHistory returns the end-of-day valuation snapshots of a portfolio in [from, to],
+// oldest first. Zero dates leave that side of the range open.
+func (s *PortfolioService) GetValuationHistory(portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	if s.snapshots == nil {
+		return nil, errors.New("valuation snapshots need a snapshot repository")
+	}
+	snapshots, err := s.snapshots.FindByPortfolio(portfolioID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load valuation snapshots of portfolio %s: %w", portfolioID, err)
+	}
+	return snapshots, nil
+}
+
+// GetPerformance returns a portfolio's time- and money-weighted returns over the standard
+// periods ending at asOf, computed from its ledger and valuation snapshots. A zero asOf means
+// "now", valued at the latest known prices. A non-zero from adds a custom period starting then.
+func (s *PortfolioService) GetPerformance(portfolioID string, asOf, from time.Time) (*portfolio.Performance, error) {
+	if s.snapshots == nil {
+		return nil, errors.New("performance needs a valuation snapshot repository")
+	}
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	live := asOf.IsZero()
+	asOf = effectiveDate(asOf)
+	history, err := s.snapshots.FindByPortfolio(portfolioID, time.Time{}, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load valuation snapshots of portfolio %s: %w", portfolioID, err)
+	}
+	if live {
+		// Today's snapshot is not taken yet, so end the periods with the current valuation.
+		prices, err := s.currentPrices(p, asOf)
+		if err != nil {
+			return nil, err
+		}
+		current, err := p.Valuate(prices, s.fxRates, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value portfolio %s: %w", portfolioID, err)
+		}
+		history = append(history, current)
+	}
+
+	perf, err := p.Performance(history, s.fxRates, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the performance of portfolio %s: %w", portfolioID, err)
+	}
+	if !from.IsZero() {
+		custom, err := p.ReturnOver(history, s.fxRates, from, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute the return of portfolio %s since %s: %w", portfolioID, from.Format("2006-01-02"), err)
+		}
+		perf.Returns = append(perf.Returns, *custom)
+	}
+	return perf, nil
+}
+
+// SetBenchmark designates the index proxy ticker or weighted basket a portfolio is compared
+// against. A zero benchmark reverts to the default one.
+func (s *PortfolioService) SetBenchmark(portfolioID string, benchmark portfolio.Benchmark, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "setting benchmark", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.SetBenchmark(benchmark)
+	})
+}
+
+// CompareWithBenchmark reports a portfolio's relative performance, tracking error, information
+// ratio and sector attribution against its benchmark over [from, to], from its valuation
+// snapshots. A zero from starts at the first snapshot; a zero to means "now".
+func (s *PortfolioService) CompareWithBenchmark(portfolioID string, from, to time.Time) (*portfolio.BenchmarkComparison, error) {
+	if s.snapshots == nil || s.prices == nil {
+		return nil, errors.New("benchmark comparison needs valuation snapshots and a price provider")
+	}
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	benchmark := p.Benchmark
+	if benchmark.IsZero() {
+		benchmark = s.benchmark
+	}
+	if benchmark.IsZero() {
+		return nil, fmt.Errorf("domain error comparing portfolio %s: no benchmark is designated", portfolioID)
+	}
+	to = effectiveDate(to)
+	history, err := s.snapshots.FindByPortfolio(portfolioID, time.Time{}, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load valuation snapshots of portfolio %s: %w", portfolioID, err)
+	}
+
+	// Attribution groups the holdings and the benchmark's constituents by company sector.
+	sectors := make(map[string]string)
+	if s.companyRepo != nil {
+		tickers := make([]string, 0, len(benchmark.Constituents))
+		for _, c := range benchmark.Constituents {
+			tickers = append(tickers, c.Ticker)
+		}
+		for _, v := range history {
+			for _, h := range v.Holdings {
+				tickers = append(tickers, h.CompanyTicker)
+			}
+		}
+		for _, ticker := range tickers {
+			if _, ok := sectors[ticker]; ok {
+				continue
+			}
+			sectors[ticker] = ""
+			if c, err := s.companyRepo.FindByTicker(ticker); err == nil && c != nil {
+				sectors[ticker] = sectorName(c)
+			}
+		}
+	}
+
+	comparison, err := p.CompareWithBenchmark(history, benchmark, s.prices, s.fxRates, sectors, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare portfolio %s with %s: %w", portfolioID, benchmark.Name, err)
+	}
+	return comparison, nil
+}
+
+// GetExposure breaks a portfolio down by position and sector at the latest known prices and
+// lists the limits of its risk policy it breaches.
+func (s *PortfolioService) GetExposure(portfolioID string) (*portfolio.Exposure, error) {
+	p, err := s.GetPortfolioDetails(portfolioID)
 	if err != nil {
-		return fmt.Errorf("failed to save portfolio %s after executing rebalance: %w", portfolioID, err)
+		return nil, err
+	}
+	return s.exposureOf(p, time.Now())
+}
+
+// MonitorExposures checks every portfolio against its risk policy as of asOf and returns the
+// breaches found. It is intended to be run periodically by a scheduler. The service publishes
+// nothing: the events returned are the breaches raised, for the caller to deliver. A portfolio
+// that cannot be checked does not stop the others from being; the breaches found are returned
+// along with the failures, joined.
+func (s *PortfolioService) MonitorExposures(asOf time.Time) ([]portfolio.RiskThresholdBreachedEvent, error) {
+	portfolios, err := s.portfolioRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portfolios for exposure monitoring: %w", err)
+	}
+	var breaches []portfolio.RiskThresholdBreachedEvent
+	var errs []error
+	for _, p := range portfolios {
+		exposure, err := s.exposureOf(p, asOf)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to monitor exposure of portfolio %s: %w", p.ID, err))
+			continue
+		}
+		breaches = append(breaches, exposure.Breaches...)
+	}
+	return breaches, errors.Join(errs...)
+}
+
+// exposureOf values p as of the given date and computes its exposure, looking up the sector of
+// each holding in the company repository.
+func (s *PortfolioService) exposureOf(p *portfolio.Portfolio, asOf time.Time) (*portfolio.Exposure, error) {
+	prices, err := s.currentPrices(p, asOf)
+	if err != nil {
+		return nil, err
+	}
+	valuation, err := p.Valuate(prices, s.fxRates, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to value portfolio %s: %w", p.ID, err)
+	}
+	sectors := make(map[string]string, len(p.Holdings))
+	if s.companyRepo != nil {
+		for ticker := range p.Holdings {
+			if c, err := s.companyRepo.FindByTicker(ticker); err == nil && c != nil {
+				sectors[ticker] = sectorName(c)
+			}
+		}
+	}
+	return p.Exposure(valuation, sectors), nil
+}
+
+// currentPrices looks up the price of every holding in p as of the given date.
+// Tickers the price provider does not know are left out so that they are valued at cost.
+func (s *PortfolioService) currentPrices(p *portfolio.Portfolio, on time.Time) (map[string]portfolio.Money, error) {
+	tickers := make([]string, 0, len(p.Holdings))
+	for ticker := range p.Holdings {
+		tickers = append(tickers, ticker)
+	}
+	return s.pricesOf(tickers, on)
+}
+
+// pricesOf looks up the price of each ticker as of the given date, leaving out the ones the
+// price provider does not know.
+func (s *PortfolioService) pricesOf(tickers []string, on time.Time) (map[string]portfolio.Money, error) {
+	prices := make(map[string]portfolio.Money, len(tickers))
+	if s.prices == nil {
+		return prices, nil
+	}
+	for _, ticker := range tickers {
+		price, err := s.prices.Price(ticker, on)
+		if errors.Is(err, portfolio.ErrPriceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price for %s: %w", ticker, err)
+		}
+		prices[ticker] = price
+	}
+	return prices, nil
+}
+
+// Deposit adds external cash to a portfolio. A zero date means "now".
+func (s *PortfolioService) Deposit(portfolioID string, amount portfolio.Money, on time.Time, description string, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "depositing cash", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.Deposit(amount, effectiveDate(on), description)
+	})
+}
+
+// Withdraw takes external cash out of a portfolio. A zero date means "now".
+// Withdrawals larger than the cash held fail with a *portfolio.InsufficientCashError.
+func (s *PortfolioService) Withdraw(portfolioID string, amount portfolio.Money, on time.Time, description string, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "withdrawing cash", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.Withdraw(amount, effectiveDate(on), description)
+	})
+}
+
+// SetCashInterestRate sets the annual interest rate a portfolio earns on idle cash in a currency.
+func (s *PortfolioService) SetCashInterestRate(portfolioID string, currency string, annualRate float64, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "setting interest rate", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.SetInterestRate(currency, annualRate, time.Now())
+	})
+}
+
+// AccrueInterest credits the interest a portfolio has earned on idle cash up to asOf.
+func (s *PortfolioService) AccrueInterest(portfolioID string, asOf time.Time, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "accruing interest", expectedVersions, func(p *portfolio.Portfolio) error {
+		p.AccrueInterest(effectiveDate(asOf))
+		return nil
+	})
+}
+
+// AccrueInterestForAll credits interest on every portfolio that earns it.
+// It is intended to be run once a day by a scheduler.
+func (s *PortfolioService) AccrueInterestForAll(asOf time.Time) error {
+	portfolios, err := s.portfolioRepo.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list portfolios for interest accrual: %w", err)
+	}
+	for _, p := range portfolios {
+		if len(p.InterestRates) == 0 {
+			continue
+		}
+		if _, err := s.AccrueInterest(p.ID, asOf); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// SetDividendPolicy sets how a portfolio receives dividends: the withholding tax applied and
+// whether dividends are reinvested.
+func (s *PortfolioService) SetDividendPolicy(portfolioID string, policy portfolio.DividendPolicy, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "setting dividend policy", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.SetDividendPolicy(policy)
+	})
+}
+
+// SetRiskPolicy assigns a risk policy to a portfolio by name: a custom policy from the
+// configuration or the default policy of a built-in risk profile.
+func (s *PortfolioService) SetRiskPolicy(portfolioID string, name string, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	policy, ok := s.policies[name]
+	if !ok {
+		profile := portfolio.ParseRiskProfile(name)
+		if profile == portfolio.UndefinedProfile {
+			return nil, fmt.Errorf("risk policy %q not found", name)
+		}
+		policy = profile.DefaultPolicy()
+	}
+	return s.modifyPortfolio(portfolioID, "setting risk policy", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.SetRiskPolicy(policy)
+	})
+}
+
+// RiskPolicies returns the risk policies portfolios can be assigned, sorted by name: the
+// defaults of the built-in risk profiles and the custom policies from the configuration.
+func (s *PortfolioService) RiskPolicies() []portfolio.RiskPolicy {
+	byName := make(map[string]portfolio.RiskPolicy)
+	for _, profile := range []portfolio.RiskProfile{portfolio.Conservative, portfolio.Moderate, portfolio.Aggressive} {
+		byName[profile.String()] = profile.DefaultPolicy()
+	}
+	for name, policy := range s.policies {
+		byName[name] = policy
+	}
+	policies := make([]portfolio.RiskPolicy, 0, len(byName))
+	for _, policy := range byName {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	return policies
+}
+
+// SetFeeSchedule sets the broker fees a portfolio pays on trades and currency exchanges.
+func (s *PortfolioService) SetFeeSchedule(portfolioID string, fees portfolio.FeeSchedule, expectedVersions ...int) (*portfolio.Portfolio, error) {
+	return s.modifyPortfolio(portfolioID, "setting fee schedule", expectedVersions, func(p *portfolio.Portfolio) error {
+		return p.SetFeeSchedule(fees)
+	})
+}
+
+// GetProfitAndLoss returns a portfolio's P&L over [from, to], with unrealized gains valued at
+// the latest known prices. Zero dates leave that side of the range open.
+func (s *PortfolioService) GetProfitAndLoss(portfolioID string, from, to time.Time) (*portfolio.ProfitAndLoss, error) {
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	asOf := effectiveDate(to)
+	prices, err := s.currentPrices(p, asOf)
+	if err != nil {
+		return nil, err
+	}
+	pnl, err := p.ProfitAndLoss(from, to, prices, s.fxRates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute P&L for portfolio %s: %w", portfolioID, err)
+	}
+	return pnl, nil
+}
+
+// GetExternalCashFlows returns the deposits and withdrawals of a portfolio in [from, to].
+// Zero dates leave that side of the range open.
+func (s *PortfolioService) GetExternalCashFlows(portfolioID string, from, to time.Time) ([]portfolio.CashFlow, error) {
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ExternalCashFlows(from, to), nil
+}
+
+// modifyPortfolio loads a portfolio, applies a change to it and saves it. With
+// expected versions, the change applies only if the portfolio loaded is at one of them;
+// otherwise it fails with a *portfolio.PreconditionFailedError. The save then fails with a
+// conflict if the portfolio changed since it was loaded, so the change never applies to a
+// version the caller did not expect.
+func (s *PortfolioService) modifyPortfolio(portfolioID string, action string, expectedVersions []int, change func(p *portfolio.Portfolio) error) (*portfolio.Portfolio, error) {
+	p, err := s.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if err := portfolio.CheckVersion(p, expectedVersions...); err != nil {
+		return nil, fmt.Errorf("refused %s in portfolio %s: %w", action, portfolioID, err)
+	}
+	if err := change(p); err != nil {
+		return nil, fmt.Errorf("domain error %s in portfolio %s: %w", action, portfolioID, err)
+	}
+	if err := s.portfolioRepo.Save(p); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio %s after %s: %w", portfolioID, action, err)
+	}
+	return p, nil
+}
+
+// effectiveDate defaults a zero date to the current time.
+func effectiveDate(on time.Time) time.Time {
+	if on.IsZero() {
+		return time.Now()
+	}
+	return on
+}
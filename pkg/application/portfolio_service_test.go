@@ -2,6 +2,7 @@ package application_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/jizumer/expedition-value/pkg/application"
 	"github.com/jizumer/expedition-value/pkg/domain/company"
 	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
 	// "github.com/stretchr/testify/assert"
 )
 
@@ -87,6 +89,7 @@ func (m *MinimalMockCompanyRepository) FindByTicker(ticker string) (*company.Com
 func (m *MinimalMockCompanyRepository) SearchByScoreRange(minScore, maxScore float64) ([]*company.Company, error) { return nil, nil }
 func (m *MinimalMockCompanyRepository) Save(c *company.Company) error { return nil }
 func (m *MinimalMockCompanyRepository) Delete(ticker string) error    { return nil }
+func (m *MinimalMockCompanyRepository) FindAll() ([]*company.Company, error) { return nil, nil }
 
 
 // --- PortfolioService Tests ---
@@ -217,6 +220,68 @@ func TestPortfolioService_GetPortfolioDetails(t *testing.T) {
 	})
 }
 
+// MockPortfolioHistory serves the states of a portfolio recorded at given times.
+type MockPortfolioHistory struct {
+	states []*portfolio.Portfolio // Oldest first, with UpdatedAt as the time each was recorded
+}
+
+func (m *MockPortfolioHistory) FindByIDAsOf(id string, at time.Time) (*portfolio.Portfolio, error) {
+	var found *portfolio.Portfolio
+	for _, p := range m.states {
+		if p.ID == id && !p.UpdatedAt.After(at) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, portfolio.ErrPortfolioNotFound
+	}
+	return found, nil
+}
+
+func (m *MockPortfolioHistory) Events(id string) ([]portfolio.Event, error) {
+	events := []portfolio.Event{}
+	for i, p := range m.states {
+		if p.ID == id {
+			events = append(events, portfolio.Event{PortfolioID: id, Version: i + 1, Type: portfolio.PortfolioUpdated, RecordedAt: p.UpdatedAt})
+		}
+	}
+	if len(events) == 0 {
+		return nil, portfolio.ErrPortfolioNotFound
+	}
+	return events, nil
+}
+
+func TestPortfolioService_History(t *testing.T) {
+	first, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+	first.UpdatedAt = time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	second := first.Clone()
+	second.RiskProfile = portfolio.Aggressive
+	second.UpdatedAt = first.UpdatedAt.Add(time.Hour)
+	history := &MockPortfolioHistory{states: []*portfolio.Portfolio{first, second}}
+	service := application.NewPortfolioService(&MockPortfolioRepository{}, &MinimalMockCompanyRepository{}, application.WithPortfolioHistory(history))
+
+	if p, err := service.GetPortfolioAsOf("p1", first.UpdatedAt.Add(time.Minute)); err != nil || p.RiskProfile != portfolio.Moderate {
+		t.Errorf("GetPortfolioAsOf(10:01) = %+v, %v, want the moderate portfolio", p, err)
+	}
+	if _, err := service.GetPortfolioAsOf("p1", first.UpdatedAt.Add(-time.Minute)); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("GetPortfolioAsOf(before creation) error = %v, want ErrPortfolioNotFound", err)
+	}
+	if events, err := service.GetPortfolioEvents("p1"); err != nil || len(events) != 2 {
+		t.Errorf("GetPortfolioEvents() = %+v, %v, want 2 events", events, err)
+	}
+	if _, err := service.GetPortfolioEvents(""); err == nil {
+		t.Error("GetPortfolioEvents(\"\") error = nil, want an error")
+	}
+
+	withoutHistory := application.NewPortfolioService(&MockPortfolioRepository{}, &MinimalMockCompanyRepository{})
+	if _, err := withoutHistory.GetPortfolioAsOf("p1", time.Now()); err == nil {
+		t.Error("GetPortfolioAsOf() without history error = nil, want an error")
+	}
+	if _, err := withoutHistory.GetPortfolioEvents("p1"); err == nil {
+		t.Error("GetPortfolioEvents() without history error = nil, want an error")
+	}
+}
+
 func TestPortfolioService_AddPosition(t *testing.T) {
 	mockPortfolioRepo := &MockPortfolioRepository{}
 	mockCompanyRepo := &MinimalMockCompanyRepository{}
@@ -232,10 +297,13 @@ func TestPortfolioService_AddPosition(t *testing.T) {
 	purchasePrice, _ := portfolio.NewMoney(15000, "USD") // 150.00 per share
 
 	sampleCompany, _ := company.NewCompany(companyTicker, company.FinancialMetrics{}, company.Technology)
+	// The sample company has no score, so these tests lift the policy limits unless they test them.
+	unrestricted := portfolio.RiskPolicy{Name: "Unrestricted", MaxPositionWeight: 1, MaxSectorWeight: 1}
 
 	t.Run("Success", func(t *testing.T) {
 		// Reset state for this sub-test
 		freshPortfolio, _ := portfolio.NewPortfolio(portfolioID, portfolio.Aggressive, *initialCash)
+		_ = freshPortfolio.SetRiskPolicy(unrestricted)
 		mockPortfolioRepo.SaveCalledWith = nil
 
 		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
@@ -281,6 +349,43 @@ func TestPortfolioService_AddPosition(t *testing.T) {
 		}
 	})
 
+	t.Run("WithFees", func(t *testing.T) {
+		feePortfolio, _ := portfolio.NewPortfolio(portfolioID, portfolio.Aggressive, *initialCash)
+		_ = feePortfolio.SetFeeSchedule(portfolio.FeeSchedule{PercentageFee: 0.001, MinFee: 500})
+		_ = feePortfolio.SetRiskPolicy(unrestricted)
+		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { return feePortfolio, nil }
+		mockCompanyRepo.FindByTickerFunc = func(ticker string) (*company.Company, error) { return sampleCompany, nil }
+		mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error { return nil }
+
+		if err := service.AddPosition(portfolioID, companyTicker, shares, *purchasePrice); err != nil {
+			t.Fatalf("AddPosition() error = %v, wantErr nil", err)
+		}
+		// 0.1% of 1500.00 is below the 5.00 minimum fee.
+		expectedCash := initialCash.Amount - purchasePrice.Amount*int64(shares) - 500
+		if feePortfolio.CashBalance.Amount != expectedCash {
+			t.Errorf("CashBalance = %d, want %d", feePortfolio.CashBalance.Amount, expectedCash)
+		}
+		if pos := feePortfolio.Holdings[companyTicker]; pos.PurchasePrice.Amount != 15050 {
+			t.Errorf("PurchasePrice = %d, want 15050 (fee included in cost basis)", pos.PurchasePrice.Amount)
+		}
+	})
+
+	t.Run("RiskPolicyViolation", func(t *testing.T) {
+		strictPortfolio, _ := portfolio.NewPortfolio(portfolioID, portfolio.Aggressive, *initialCash)
+		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { return strictPortfolio, nil }
+		mockCompanyRepo.FindByTickerFunc = func(ticker string) (*company.Company, error) { return sampleCompany, nil }
+		mockPortfolioRepo.SaveCalledWith = nil
+
+		err := service.AddPosition(portfolioID, companyTicker, shares, *purchasePrice)
+		var violation *portfolio.RiskPolicyViolationError
+		if !errors.As(err, &violation) || violation.Rule != "min entry score" {
+			t.Fatalf("AddPosition() error = %v, want a min entry score violation", err)
+		}
+		if mockPortfolioRepo.SaveCalledWith != nil || len(strictPortfolio.Holdings) != 0 {
+			t.Errorf("AddPosition() changed the portfolio despite the violation")
+		}
+	})
+
 	t.Run("PortfolioNotFound", func(t *testing.T) {
 		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
 			return nil, errors.New("portfolio not found error")
@@ -337,83 +442,141 @@ func TestPortfolioService_AddPosition(t *testing.T) {
 
 }
 
-// TestPortfolioService_AdjustPosition - Placeholder, as domain logic is very basic
 func TestPortfolioService_AdjustPosition(t *testing.T) {
 	mockPortfolioRepo := &MockPortfolioRepository{}
-	mockCompanyRepo := &MinimalMockCompanyRepository{} // Not directly used by AdjustPosition's current simplified logic
+	msft, _ := company.NewCompany("MSFT", company.FinancialMetrics{}, company.Technology)
+	mockCompanyRepo := &MinimalMockCompanyRepository{FindByTickerFunc: func(ticker string) (*company.Company, error) { return msft, nil }}
 	service := application.NewPortfolioService(mockPortfolioRepo, mockCompanyRepo)
 
 	portfolioID := uuid.NewString()
-	initialCash, _ := portfolio.NewMoney(100000, "USD")
-	existingPortfolio, _ := portfolio.NewPortfolio(portfolioID, portfolio.Aggressive, *initialCash)
-	
-	// Pre-add a position
-	ticker := "MSFT"
-	oldShares := 10
-	price, _ := portfolio.NewMoney(5000, "USD")
-	pos, _ := portfolio.NewPosition(ticker, oldShares, *price)
-	cost, _ := portfolio.NewMoney(price.Amount * int64(oldShares), price.Currency)
-	_ = existingPortfolio.AddPosition(*pos, *cost) // Add directly for test setup convenience
-
-
-	t.Run("Success_AdjustShares", func(t *testing.T) {
-		// Important: GetPortfolioDetails returns a *copy* or the *actual object* based on FindByIDFunc.
-		// For this test, we want the service to operate on the 'existingPortfolio' we've set up.
-		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
-			if id == portfolioID {
-				// Return the portfolio instance that has the position already
-				return existingPortfolio, nil
+	usd := func(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+	existingPortfolio, _ := portfolio.NewPortfolio(portfolioID, portfolio.Aggressive, usd(100000))
+	_ = existingPortfolio.SetRiskPolicy(portfolio.RiskPolicy{Name: "Unrestricted", MaxPositionWeight: 1, MaxSectorWeight: 1})
+	_ = existingPortfolio.SetFeeSchedule(portfolio.FeeSchedule{FlatFee: 100})
+
+	// 10 MSFT bought at 50.00 for 501.00 with the fee, and half a share from a reinvested dividend.
+	pos, _ := portfolio.NewPosition("MSFT", 10, usd(5000))
+	_ = existingPortfolio.AddPosition(*pos, usd(50000))
+	held := existingPortfolio.Holdings["MSFT"]
+	held.FractionalShares = 0.5
+	existingPortfolio.Holdings["MSFT"] = held
+
+	mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
+		if id == portfolioID {
+			return existingPortfolio, nil
+		}
+		return nil, errors.New("not found")
+	}
+	mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error {
+		mockPortfolioRepo.SaveCalledWith = p
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		newShares int
+		wantHeld  float64 // Whole and fractional shares held after the adjustment
+		wantCash  int64
+	}{
+		{"Buy", 15, 15.5, 49900 - 5*6000 - 100},
+		{"Sell", 12, 12.5, 19800 + 3*6000 - 100},
+		{"SellAll", 0, 0, 37700 + 12.5*6000 - 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPortfolioRepo.SaveCalledWith = nil
+			if err := service.AdjustPosition(portfolioID, "MSFT", tt.newShares, usd(6000)); err != nil {
+				t.Fatalf("AdjustPosition() error = %v, wantErr nil", err)
+			}
+			saved := mockPortfolioRepo.SaveCalledWith
+			if saved == nil {
+				t.Fatal("Save was not called")
+			}
+			if got := saved.Holdings["MSFT"].Quantity(); got != tt.wantHeld {
+				t.Errorf("MSFT held = %v, want %v", got, tt.wantHeld)
+			}
+			if saved.CashBalance.Amount != tt.wantCash {
+				t.Errorf("CashBalance = %d, want %d", saved.CashBalance.Amount, tt.wantCash)
 			}
-			return nil, errors.New("not found")
+			if fee := saved.Ledger[len(saved.Ledger)-1]; fee.Type != portfolio.Fee || fee.Amount.Amount != -100 {
+				t.Errorf("last ledger entry = %+v, want the trade's fee", fee)
+			}
+		})
+	}
+	t.Run("SellFraction", func(t *testing.T) {
+		// What a reinvested dividend can leave once the whole shares are sold.
+		fraction, _ := portfolio.NewPosition("MSFT", 1, usd(6000))
+		fraction.Shares, fraction.FractionalShares = 0, 0.25
+		existingPortfolio.Holdings["MSFT"] = *fraction
+		cash := existingPortfolio.CashBalance.Amount
+
+		if err := service.AdjustPosition(portfolioID, "MSFT", 0, usd(6000)); err != nil {
+			t.Fatalf("AdjustPosition() error = %v, wantErr nil", err)
 		}
-		mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error {
-			mockPortfolioRepo.SaveCalledWith = p
-			return nil
+		if _, held := existingPortfolio.Holdings["MSFT"]; held {
+			t.Error("MSFT still held, want the fractional share sold")
 		}
-		
-		newShares := 15
-		err := service.AdjustPosition(portfolioID, ticker, newShares)
-		if err != nil {
-			t.Fatalf("AdjustPosition() error = %v, wantErr nil", err)
+		if got := existingPortfolio.CashBalance.Amount; got != cash+1500-100 {
+			t.Errorf("CashBalance = %d, want %d", got, cash+1500-100)
 		}
-		if mockPortfolioRepo.SaveCalledWith == nil {
-			t.Fatal("Save was not called")
+	})
+
+	t.Run("RiskPolicyCountsFee", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio(uuid.NewString(), portfolio.Aggressive, usd(100000))
+		_ = p.SetFeeSchedule(portfolio.FeeSchedule{FlatFee: 1000})
+		_ = p.SetRiskPolicy(portfolio.RiskPolicy{Name: "Buffered", MaxPositionWeight: 1, MaxSectorWeight: 1, MinCashBuffer: 0.538})
+		one, _ := portfolio.NewPosition("MSFT", 1, usd(5000))
+		_ = p.AddPosition(*one, usd(5000))
+		repo := &MockPortfolioRepository{
+			FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return p, nil },
+			SaveFunc:     func(p *portfolio.Portfolio) error { return nil },
 		}
-		if savedPos, ok := mockPortfolioRepo.SaveCalledWith.Holdings[ticker]; !ok {
-			t.Errorf("Position for %s not found after adjustment", ticker)
-		} else if savedPos.Shares != newShares {
-			t.Errorf("Shares for %s = %d, want %d", ticker, savedPos.Shares, newShares)
+		service := application.NewPortfolioService(repo, mockCompanyRepo)
+
+		// 8 shares at 50.00 leave 54% of the portfolio in cash, but only 53% once the fee is paid.
+		err := service.AdjustPosition(p.ID, "MSFT", 9, usd(5000))
+		var violation *portfolio.RiskPolicyViolationError
+		if !errors.As(err, &violation) || violation.Rule != "min cash buffer" {
+			t.Errorf("AdjustPosition() error = %v, want a min cash buffer violation", err)
 		}
-		// Note: Cash balance adjustment is NOT part of this simplified AdjustPosition service method.
 	})
-	
+
+	t.Run("NoPrice", func(t *testing.T) {
+		if err := service.AdjustPosition(portfolioID, "MSFT", 5, portfolio.Money{}); err == nil {
+			t.Error("Expected error for a missing trade price")
+		}
+	})
+
 	t.Run("PortfolioNotFound", func(t *testing.T) {
-		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { return nil, errors.New("not found"); }
-		err := service.AdjustPosition(uuid.NewString(), "ANY", 5)
-		if err == nil { t.Error("Expected error for non-existent portfolio") }
+		if err := service.AdjustPosition(uuid.NewString(), "ANY", 5, usd(6000)); err == nil {
+			t.Error("Expected error for non-existent portfolio")
+		}
 	})
 
 	t.Run("PositionNotFoundInPortfolio", func(t *testing.T) {
-		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { 
-			freshP, _ := portfolio.NewPortfolio(portfolioID, portfolio.Aggressive, *initialCash) // Portfolio without the position
-			return freshP, nil
+		if err := service.AdjustPosition(portfolioID, "NONEXISTENT", 5, usd(6000)); err == nil {
+			t.Error("Expected error for non-existent position in portfolio")
 		}
-		err := service.AdjustPosition(portfolioID, "NONEXISTENT", 5)
-		if err == nil { t.Error("Expected error for non-existent position in portfolio") }
-		// Expected: "position for ticker NONEXISTENT not found in portfolio..."
 	})
 }
 
 
 func TestPortfolioService_RecommendRebalance(t *testing.T) {
 	mockPortfolioRepo := &MockPortfolioRepository{}
-	service := application.NewPortfolioService(mockPortfolioRepo, nil) // CompanyRepo not needed for this method
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	aapl.CurrentScore = 90
+	msft, _ := company.NewCompany("MSFT", company.FinancialMetrics{PERatio: 20}, company.Technology)
+	msft.CurrentScore = 80
+	mockCompanyRepo := &MockCompanyRepository{
+		FindAllFunc: func() ([]*company.Company, error) { return []*company.Company{aapl, msft}, nil },
+	}
+	prices := stubPrices{"AAPL": {Amount: 1000, Currency: "USD"}, "MSFT": {Amount: 2000, Currency: "USD"}}
+	service := application.NewPortfolioService(mockPortfolioRepo, mockCompanyRepo, application.WithPriceProvider(prices))
 
 	portfolioID := uuid.NewString()
-	pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount:1000, Currency:"USD"})
 
 	t.Run("Success_Triggered", func(t *testing.T) {
-		pInstance.LastRebalanceTime = time.Time{} // Ensure rebalance is triggered in domain logic
+		pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
 		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
 			return pInstance, nil
 		}
@@ -428,70 +591,486 @@ func TestPortfolioService_RecommendRebalance(t *testing.T) {
 		if rec.PortfolioID != portfolioID {
 			t.Errorf("Recommendation PortfolioID = %s, want %s", rec.PortfolioID, portfolioID)
 		}
-		if len(rec.Suggestions) == 0 { // Based on placeholder domain logic
-			t.Error("Expected suggestions, got empty")
+		// Moderate caps each position at 15% of 1000.00: 15 AAPL at 10.00 and 7 MSFT at 20.00.
+		if len(rec.Suggestions) != 2 || rec.Suggestions[0].Quantity != 15 || rec.Suggestions[1].Quantity != 7 {
+			t.Fatalf("Recommendation suggestions = %+v, want 15 AAPL and 7 MSFT", rec.Suggestions)
+		}
+		first := rec.Suggestions[0]
+		if first.Action != portfolio.Enter || first.Ticker != "AAPL" || first.ScoreInputs.Score != 90 || first.ScoreInputs.PERatio != 15 {
+			t.Errorf("First suggestion = %+v, want Enter AAPL with its score inputs", first)
 		}
 	})
 
-	t.Run("Success_NotTriggeredErrorFromDomain", func(t *testing.T) {
-		pInstance.LastRebalanceTime = time.Now().Add(-10 * 24 * time.Hour) // Recently rebalanced
+	t.Run("PersistsProposedRecommendation", func(t *testing.T) {
+		pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
 		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
 			return pInstance, nil
 		}
+		recRepo := NewMockRecommendationRepository()
+		withRepo := application.NewPortfolioService(mockPortfolioRepo, mockCompanyRepo,
+			application.WithPriceProvider(prices), application.WithRecommendationRepository(recRepo, time.Hour))
+
+		rec, err := withRepo.RecommendRebalance(portfolioID)
+		if err != nil {
+			t.Fatalf("RecommendRebalance() error = %v, wantErr nil", err)
+		}
+		stored, ok := recRepo.recs[rec.ID]
+		if rec.ID == "" || !ok || stored.Status != recommendation.Proposed {
+			t.Errorf("RecommendRebalance() = %+v, want a saved Proposed recommendation", rec)
+		}
+		if !rec.ExpiresAt.Equal(rec.GeneratedAt.Add(time.Hour)) {
+			t.Errorf("ExpiresAt = %v, want an hour after %v", rec.ExpiresAt, rec.GeneratedAt)
+		}
+	})
+
+	t.Run("Success_NotTriggeredErrorFromDomain", func(t *testing.T) {
+		small, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
+			return small, nil
+		}
 		_, err := service.RecommendRebalance(portfolioID)
-		if err == nil {
-			t.Errorf("RecommendRebalance() expected error when not triggered by domain, got nil")
+		if !errors.Is(err, portfolio.ErrRebalanceNotTriggered) {
+			t.Errorf("RecommendRebalance() error = %v, want ErrRebalanceNotTriggered (every trade is below the minimum size)", err)
 		}
-		// Expected: "domain error generating rebalance recommendations ...: rebalance not currently triggered"
 	})
 }
 
+func TestPortfolioService_GetTargetWeights(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	// AAPL scored 40 on its Q4 figures and 90 once Q1's were known; MSFT is known since today.
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	aapl.CurrentScore = 40
+	if err := aapl.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 30}, day(1, 1), day(2, 1)); err != nil {
+		t.Fatalf("ReviseFinancialMetrics() error = %v", err)
+	}
+	aapl.CurrentScore = 90
+	if err := aapl.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 15}, day(3, 31), day(5, 1)); err != nil {
+		t.Fatalf("ReviseFinancialMetrics() error = %v", err)
+	}
+	msft, _ := company.NewCompany("MSFT", company.FinancialMetrics{PERatio: 20}, company.Technology)
+	msft.CurrentScore = 80
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	service := application.NewPortfolioService(
+		&MockPortfolioRepository{FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return p, nil }},
+		&MockCompanyRepository{FindAllFunc: func() ([]*company.Company, error) { return []*company.Company{aapl, msft}, nil }})
+
+	tests := []struct {
+		name    string
+		knownAt time.Time
+		want    string
+	}{
+		{"now", time.Time{}, "map[AAPL:0.15 MSFT:0.15]"},
+		{"before Q1 was known", day(3, 1), "map[]"}, // AAPL's score of 40 is below the entry threshold
+		{"after Q1 was known", day(6, 1), "map[AAPL:0.15]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := service.GetTargetWeights("p1", tt.knownAt)
+			if err != nil || fmt.Sprint(weights) != tt.want {
+				t.Errorf("GetTargetWeights(%v) = %v, %v, want %s", tt.knownAt, weights, err, tt.want)
+			}
+		})
+	}
+}
+
 func TestPortfolioService_ExecuteRebalance(t *testing.T) {
 	mockPortfolioRepo := &MockPortfolioRepository{}
-	service := application.NewPortfolioService(mockPortfolioRepo, nil)
-
-	portfolioID := uuid.NewString()
-	pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount:1000, Currency:"USD"})
-	originalLastRebalanceTime := pInstance.LastRebalanceTime
+	portfolioID := "p1"
+	mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
+		return portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 1000000, Currency: "USD"})
+	}
+	mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error {
+		mockPortfolioRepo.SaveCalledWith = p
+		return nil
+	}
 
-	recommendation := application.RebalanceRecommendation{
-		PortfolioID: portfolioID,
-		Suggestions: []string{"Sell AAPL", "Buy MSFT"},
-		GeneratedAt: time.Now(),
+	approved := func(t *testing.T, id string) *recommendation.Recommendation {
+		rec := newProposedRecommendation(t, id, time.Now())
+		if err := rec.Approve("jane", time.Now()); err != nil {
+			t.Fatalf("Approve() error = %v", err)
+		}
+		return rec
+	}
+	newService := func(recRepo *MockRecommendationRepository, aaplPrice int64) *application.PortfolioService {
+		prices := stubPrices{"AAPL": {Amount: aaplPrice, Currency: "USD"}}
+		return application.NewPortfolioService(mockPortfolioRepo, nil,
+			application.WithPriceProvider(prices), application.WithRecommendationRepository(recRepo, time.Hour))
 	}
 
 	t.Run("Success", func(t *testing.T) {
 		mockPortfolioRepo.SaveCalledWith = nil
-		mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) {
-			// Return a fresh instance to ensure LastRebalanceTime is as expected pre-call
-			freshP, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount:1000, Currency:"USD"})
-			freshP.LastRebalanceTime = originalLastRebalanceTime
-			return freshP, nil
-		}
-		mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error {
-			mockPortfolioRepo.SaveCalledWith = p
-			return nil
-		}
-
-		err := service.ExecuteRebalance(portfolioID, recommendation)
+		recRepo := NewMockRecommendationRepository(approved(t, "r1"))
+		report, err := newService(recRepo, 10100).ExecuteRebalance(portfolioID, "r1")
 		if err != nil {
 			t.Fatalf("ExecuteRebalance() error = %v, wantErr nil", err)
 		}
-		if mockPortfolioRepo.SaveCalledWith == nil {
+		if len(report.Fills) != 1 || report.Fills[0].Shares != 10 || report.Fills[0].Price.Amount != 10100 {
+			t.Errorf("report fills = %+v, want 10 AAPL at 101.00", report.Fills)
+		}
+		if len(report.ResidualCash) != 1 || report.ResidualCash[0].Amount != 1000000-101000 {
+			t.Errorf("report residual cash = %+v, want 8990.00 USD", report.ResidualCash)
+		}
+		if len(report.Opened) != 1 || report.Opened[0].CompanyTicker != "AAPL" {
+			t.Errorf("report opened = %+v, want a PositionOpenedEvent for AAPL", report.Opened)
+		}
+		saved := mockPortfolioRepo.SaveCalledWith
+		if saved == nil {
 			t.Fatal("Save was not called")
 		}
-		// Check if LastRebalanceTime was updated (placeholder logic in service does this)
-		if mockPortfolioRepo.SaveCalledWith.LastRebalanceTime.Equal(originalLastRebalanceTime) {
-			t.Errorf("LastRebalanceTime was not updated. Original: %v, Current: %v",
-				originalLastRebalanceTime, mockPortfolioRepo.SaveCalledWith.LastRebalanceTime)
+		if saved.LastRebalanceTime.IsZero() || saved.Holdings["AAPL"].Shares != 10 {
+			t.Errorf("saved portfolio holdings = %+v, last rebalance %v; want 10 AAPL and a rebalance time", saved.Holdings, saved.LastRebalanceTime)
+		}
+		if recRepo.recs["r1"].Status != recommendation.Executed {
+			t.Errorf("recommendation status = %v, want Executed", recRepo.recs["r1"].Status)
+		}
+	})
+
+	t.Run("NotApproved", func(t *testing.T) {
+		recRepo := NewMockRecommendationRepository(newProposedRecommendation(t, "r1", time.Now()))
+		_, err := newService(recRepo, 10000).ExecuteRebalance(portfolioID, "r1")
+		if !errors.Is(err, recommendation.ErrNotApproved) {
+			t.Errorf("ExecuteRebalance() error = %v, want ErrNotApproved", err)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		rec := newProposedRecommendation(t, "r1", time.Now().Add(-50*time.Minute))
+		if err := rec.Approve("jane", time.Now()); err != nil {
+			t.Fatalf("Approve() error = %v", err)
+		}
+		rec.ExpiresAt = time.Now().Add(-time.Minute)
+		recRepo := NewMockRecommendationRepository(rec)
+		_, err := newService(recRepo, 10000).ExecuteRebalance(portfolioID, "r1")
+		if !errors.Is(err, recommendation.ErrExpired) || recRepo.recs["r1"].Status != recommendation.Expired {
+			t.Errorf("ExecuteRebalance() error = %v, status %v; want ErrExpired and Expired", err, recRepo.recs["r1"].Status)
+		}
+	})
+
+	t.Run("PriceDrifted", func(t *testing.T) {
+		recRepo := NewMockRecommendationRepository(approved(t, "r1"))
+		_, err := newService(recRepo, 11000).ExecuteRebalance(portfolioID, "r1")
+		if !errors.Is(err, recommendation.ErrPriceDrifted) {
+			t.Errorf("ExecuteRebalance() error = %v, want ErrPriceDrifted", err)
+		}
+		if recRepo.recs["r1"].Status != recommendation.Approved {
+			t.Errorf("recommendation status = %v, want it to stay Approved", recRepo.recs["r1"].Status)
 		}
 	})
 
 	t.Run("MismatchedPortfolioID", func(t *testing.T) {
-		wrongRec := application.RebalanceRecommendation{PortfolioID: "wrong-id"}
-		err := service.ExecuteRebalance(portfolioID, wrongRec)
-		if err == nil {
+		recRepo := NewMockRecommendationRepository(approved(t, "r1"))
+		if _, err := newService(recRepo, 10000).ExecuteRebalance("wrong-id", "r1"); err == nil {
 			t.Error("Expected error for mismatched portfolio ID in recommendation")
 		}
 	})
+
+	t.Run("UnitOfWork", func(t *testing.T) {
+		mockPortfolioRepo.SaveCalledWith = nil
+		uowPortfolios := &MockPortfolioRepository{SaveFunc: func(p *portfolio.Portfolio) error { return nil }}
+		uow := &fakeUnitOfWork{
+			repos: application.Repositories{Portfolios: uowPortfolios, Recommendations: NewMockRecommendationRepository()},
+			err:   errors.New("commit failed"),
+		}
+		prices := stubPrices{"AAPL": {Amount: 10000, Currency: "USD"}}
+		service := application.NewPortfolioService(mockPortfolioRepo, nil, application.WithPriceProvider(prices),
+			application.WithRecommendationRepository(NewMockRecommendationRepository(approved(t, "r1")), time.Hour), application.WithUnitOfWork(uow))
+
+		if _, err := service.ExecuteRebalance(portfolioID, "r1"); err == nil || err.Error() != "commit failed" {
+			t.Errorf("ExecuteRebalance() error = %v, want the commit error", err)
+		}
+		if uow.calls != 1 || uowPortfolios.SaveCalledWith == nil || uow.repos.Recommendations.(*MockRecommendationRepository).recs["r1"] == nil {
+			t.Errorf("unit of work ran %d times, saving portfolio %v; want both writes made in one unit", uow.calls, uowPortfolios.SaveCalledWith)
+		}
+		if mockPortfolioRepo.SaveCalledWith != nil {
+			t.Error("portfolio was saved outside the unit of work")
+		}
+	})
+}
+
+// stubPrices is a fixed PriceProvider for tests.
+type stubPrices map[string]portfolio.Money
+
+func (s stubPrices) Price(ticker string, on time.Time) (portfolio.Money, error) {
+	if price, ok := s[ticker]; ok {
+		return price, nil
+	}
+	return portfolio.Money{}, portfolio.ErrPriceNotFound
+}
+
+// stubFXRates is a fixed-rate FXRateProvider for tests, keyed by "FROM/TO".
+type stubFXRates map[string]float64
+
+func (s stubFXRates) Rate(from, to string, on time.Time) (float64, error) {
+	if rate, ok := s[from+"/"+to]; ok {
+		return rate, nil
+	}
+	return 0, portfolio.ErrFXRateNotFound
+}
+
+func TestPortfolioService_GetValuation(t *testing.T) {
+	mockPortfolioRepo := &MockPortfolioRepository{}
+	prices := stubPrices{"SAP": {Amount: 3000, Currency: "EUR"}}
+	rates := stubFXRates{"EUR/USD": 1.1}
+	service := application.NewPortfolioService(mockPortfolioRepo, nil,
+		application.WithPriceProvider(prices), application.WithFXRateProvider(rates))
+
+	portfolioID := uuid.NewString()
+	pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+	pInstance.Holdings["SAP"] = portfolio.Position{CompanyTicker: "SAP", Shares: 2, PurchasePrice: portfolio.Money{Amount: 2500, Currency: "EUR"}}
+	pInstance.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 1, PurchasePrice: portfolio.Money{Amount: 500, Currency: "USD"}}
+	mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { return pInstance, nil }
+
+	v, err := service.GetValuation(portfolioID)
+	if err != nil {
+		t.Fatalf("GetValuation() error = %v, wantErr nil", err)
+	}
+	// SAP 2 × 3000 EUR × 1.1 + AAPL at cost 500 USD + 1000 USD cash
+	if want := int64(6600 + 500 + 1000); v.TotalValue.Amount != want {
+		t.Errorf("TotalValue = %d, want %d", v.TotalValue.Amount, want)
+	}
+}
+
+func TestPortfolioService_ExchangeCash(t *testing.T) {
+	mockPortfolioRepo := &MockPortfolioRepository{}
+	service := application.NewPortfolioService(mockPortfolioRepo, nil, application.WithFXRateProvider(stubFXRates{"USD/EUR": 0.5}))
+
+	portfolioID := uuid.NewString()
+	pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+	mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { return pInstance, nil }
+	mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error { return nil }
+
+	p, err := service.ExchangeCash(portfolioID, portfolio.Money{Amount: 400, Currency: "USD"}, "EUR")
+	if err != nil {
+		t.Fatalf("ExchangeCash() error = %v, wantErr nil", err)
+	}
+	if p.CashBalance.Amount != 600 || p.CashIn("EUR").Amount != 200 {
+		t.Errorf("Cash after exchange = %d USD / %d EUR, want 600 USD / 200 EUR", p.CashBalance.Amount, p.CashIn("EUR").Amount)
+	}
+	if mockPortfolioRepo.SaveCalledWith != p {
+		t.Error("Save was not called with the updated portfolio")
+	}
+}
+
+func TestPortfolioService_DepositAndWithdraw(t *testing.T) {
+	mockPortfolioRepo := &MockPortfolioRepository{}
+	service := application.NewPortfolioService(mockPortfolioRepo, nil)
+
+	portfolioID := uuid.NewString()
+	pInstance, _ := portfolio.NewPortfolio(portfolioID, portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+	mockPortfolioRepo.FindByIDFunc = func(id string) (*portfolio.Portfolio, error) { return pInstance, nil }
+	mockPortfolioRepo.SaveFunc = func(p *portfolio.Portfolio) error { return nil }
+
+	t.Run("Deposit", func(t *testing.T) {
+		p, err := service.Deposit(portfolioID, portfolio.Money{Amount: 500, Currency: "USD"}, time.Time{}, "top-up")
+		if err != nil {
+			t.Fatalf("Deposit() error = %v, wantErr nil", err)
+		}
+		if p.CashBalance.Amount != 1500 {
+			t.Errorf("CashBalance = %d, want 1500", p.CashBalance.Amount)
+		}
+		if last := p.Ledger[len(p.Ledger)-1]; last.Timestamp.IsZero() {
+			t.Error("Deposit with zero date should default to now")
+		}
+	})
+
+	t.Run("WithdrawInsufficient", func(t *testing.T) {
+		mockPortfolioRepo.SaveCalledWith = nil
+		_, err := service.Withdraw(portfolioID, portfolio.Money{Amount: 99999, Currency: "USD"}, time.Time{}, "")
+		if !errors.Is(err, portfolio.ErrInsufficientCash) {
+			t.Errorf("Withdraw() error = %v, want ErrInsufficientCash", err)
+		}
+		if mockPortfolioRepo.SaveCalledWith != nil {
+			t.Error("Save should not be called after a failed withdrawal")
+		}
+	})
+
+	t.Run("ExpectedVersions", func(t *testing.T) {
+		pInstance.Version = 3
+		mockPortfolioRepo.SaveCalledWith = nil
+		_, err := service.Deposit(portfolioID, portfolio.Money{Amount: 500, Currency: "USD"}, time.Time{}, "", 1, 2)
+		var precondition *portfolio.PreconditionFailedError
+		if !errors.As(err, &precondition) || precondition.CurrentVersion != 3 || mockPortfolioRepo.SaveCalledWith != nil {
+			t.Errorf("Deposit() at another version error = %v, want a precondition failure at version 3 and no save", err)
+		}
+		if _, err := service.Deposit(portfolioID, portfolio.Money{Amount: 500, Currency: "USD"}, time.Time{}, "", 2, 3); err != nil {
+			t.Errorf("Deposit() at an expected version error = %v, want nil", err)
+		}
+	})
+}
+
+func TestPortfolioService_MonitorExposures(t *testing.T) {
+	concentrated, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 50000, Currency: "USD"})
+	concentrated.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 5, PurchasePrice: portfolio.Money{Amount: 10000, Currency: "USD"}}
+	allCash, _ := portfolio.NewPortfolio("p2", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	// Listed first, p0 holds shares priced in euros, which cannot be valued without FX rates.
+	unpriced, _ := portfolio.NewPortfolio("p0", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	unpriced.Holdings["SAP"] = portfolio.Position{CompanyTicker: "SAP", Shares: 5, PurchasePrice: portfolio.Money{Amount: 10000, Currency: "EUR"}}
+
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	companyRepo := &MockCompanyRepository{FindByTickerFunc: func(ticker string) (*company.Company, error) { return aapl, nil }}
+	portfolioRepo := &MockPortfolioRepository{
+		FindAllFunc:  func() ([]*portfolio.Portfolio, error) { return []*portfolio.Portfolio{unpriced, concentrated, allCash}, nil },
+		FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return concentrated, nil },
+	}
+	service := application.NewPortfolioService(portfolioRepo, companyRepo, application.WithPriceProvider(stubPrices{"AAPL": {Amount: 12000, Currency: "USD"}}))
+
+	breaches, err := service.MonitorExposures(time.Now())
+	if !errors.Is(err, portfolio.ErrFXRateNotFound) {
+		t.Errorf("MonitorExposures() error = %v, want p0's missing FX rate", err)
+	}
+	// AAPL is worth 600.00 of 1100.00, well past both the 15% position and 35% sector limits.
+	if len(breaches) != 2 {
+		t.Fatalf("MonitorExposures() = %+v, want a position and a sector breach", breaches)
+	}
+	for _, b := range breaches {
+		if b.PortfolioID != "p1" || b.Severity != portfolio.Critical || len(b.Holdings) != 1 || b.Holdings[0] != "AAPL" {
+			t.Errorf("unexpected breach %+v", b)
+		}
+	}
+
+	exposure, err := service.GetExposure("p1")
+	if err != nil {
+		t.Fatalf("GetExposure() error = %v", err)
+	}
+	if len(exposure.Sectors) != 1 || exposure.Sectors[0].Sector != "Technology" {
+		t.Errorf("Sectors = %+v, want only Technology", exposure.Sectors)
+	}
+}
+
+// MockValuationSnapshotRepository is a slice-backed ValuationSnapshotRepository for tests.
+type MockValuationSnapshotRepository struct {
+	saved []*portfolio.Valuation
+}
+
+func (m *MockValuationSnapshotRepository) Save(snapshot *portfolio.Valuation) error {
+	m.saved = append(m.saved, snapshot)
+	return nil
+}
+
+func (m *MockValuationSnapshotRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error) {
+	var found []*portfolio.Valuation
+	for _, snapshot := range m.saved {
+		if snapshot.PortfolioID == portfolioID && !snapshot.AsOf.Before(from) && (to.IsZero() || !snapshot.AsOf.After(to)) {
+			found = append(found, snapshot)
+		}
+	}
+	return found, nil
+}
+
+func TestPortfolioService_SnapshotValuations(t *testing.T) {
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 50000, Currency: "USD"})
+	p.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 5, PurchasePrice: portfolio.Money{Amount: 10000, Currency: "USD"}}
+	portfolioRepo := &MockPortfolioRepository{FindAllFunc: func() ([]*portfolio.Portfolio, error) { return []*portfolio.Portfolio{p}, nil }}
+	snapshots := &MockValuationSnapshotRepository{}
+	service := application.NewPortfolioService(portfolioRepo, &MinimalMockCompanyRepository{},
+		application.WithPriceProvider(stubPrices{"AAPL": {Amount: 12000, Currency: "USD"}}), application.WithValuationSnapshotRepository(snapshots))
+
+	days := []time.Time{time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC), time.Date(2024, 6, 4, 22, 0, 0, 0, time.UTC)}
+	for _, day := range days {
+		if err := service.SnapshotValuations(day); err != nil {
+			t.Fatalf("SnapshotValuations(%v) error = %v", day, err)
+		}
+	}
+
+	history, err := service.GetValuationHistory("p1", days[1], time.Time{})
+	if err != nil {
+		t.Fatalf("GetValuationHistory() error = %v", err)
+	}
+	// 5 AAPL at 120.00 plus 500.00 in cash.
+	if len(history) != 1 || !history[0].AsOf.Equal(days[1]) || history[0].TotalValue.Amount != 110000 {
+		t.Errorf("GetValuationHistory() = %+v, want the 2024-06-04 snapshot worth 1100.00", history)
+	}
+
+	if err := application.NewPortfolioService(portfolioRepo, &MinimalMockCompanyRepository{}).SnapshotValuations(days[0]); err == nil {
+		t.Error("SnapshotValuations() without a snapshot repository error = nil, want an error")
+	}
+}
+
+func TestPortfolioService_GetPerformance(t *testing.T) {
+	inception := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	p.Ledger[0].Timestamp = inception
+	portfolioRepo := &MockPortfolioRepository{FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return p, nil }}
+	snapshots := &MockValuationSnapshotRepository{saved: []*portfolio.Valuation{
+		{PortfolioID: "p1", BaseCurrency: "USD", AsOf: time.Date(2024, 3, 28, 22, 0, 0, 0, time.UTC), TotalValue: portfolio.Money{Amount: 110000, Currency: "USD"}},
+		{PortfolioID: "p1", BaseCurrency: "USD", AsOf: time.Date(2024, 6, 28, 22, 0, 0, 0, time.UTC), TotalValue: portfolio.Money{Amount: 121000, Currency: "USD"}},
+	}}
+	service := application.NewPortfolioService(portfolioRepo, &MinimalMockCompanyRepository{}, application.WithValuationSnapshotRepository(snapshots))
+
+	perf, err := service.GetPerformance("p1", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetPerformance() error = %v", err)
+	}
+	// MTD has no June snapshot to start from; QTD, YTD, 1Y and inception do, plus the custom period.
+	var ytd, custom *portfolio.PeriodReturn
+	for i, r := range perf.Returns {
+		switch r.Period {
+		case portfolio.YearToDate:
+			ytd = &perf.Returns[i]
+		case portfolio.CustomPeriod:
+			custom = &perf.Returns[i]
+		}
+	}
+	if ytd == nil || ytd.TWR < 0.2099 || ytd.TWR > 0.2101 {
+		t.Errorf("YTD = %+v, want a 21%% TWR", ytd)
+	}
+	if custom == nil || custom.TWR < 0.0999 || custom.TWR > 0.1001 {
+		t.Errorf("Custom = %+v, want a 10%% TWR from the March snapshot", custom)
+	}
+
+	// Live performance ends with the current valuation: 1000.00 in cash.
+	live, err := service.GetPerformance("p1", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetPerformance(live) error = %v", err)
+	}
+	last := live.Returns[len(live.Returns)-1]
+	if last.Period != portfolio.SinceInception || last.EndValue.Amount != 100000 {
+		t.Errorf("live since-inception return = %+v, want it to end at the current value", last)
+	}
+
+	if _, err := service.GetPerformance("p1", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, portfolio.ErrInsufficientHistory) {
+		t.Errorf("GetPerformance(from before the snapshots) error = %v, want ErrInsufficientHistory", err)
+	}
+}
+
+func TestPortfolioService_CompareWithBenchmark(t *testing.T) {
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 50000, Currency: "USD"})
+	portfolioRepo := &MockPortfolioRepository{FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return p, nil }}
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	companyRepo := &MockCompanyRepository{FindByTickerFunc: func(ticker string) (*company.Company, error) {
+		if ticker == "AAPL" {
+			return aapl, nil
+		}
+		return nil, errors.New("company not found")
+	}}
+	holding := func(value int64) []portfolio.HoldingValuation {
+		return []portfolio.HoldingValuation{{CompanyTicker: "AAPL", Shares: 5, Price: portfolio.Money{Amount: value / 5, Currency: "USD"}, BaseMarketValue: portfolio.Money{Amount: value, Currency: "USD"}}}
+	}
+	snapshots := &MockValuationSnapshotRepository{saved: []*portfolio.Valuation{
+		{PortfolioID: "p1", BaseCurrency: "USD", AsOf: time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC), Holdings: holding(50000), CashValue: portfolio.Money{Amount: 50000, Currency: "USD"}, TotalValue: portfolio.Money{Amount: 100000, Currency: "USD"}},
+		{PortfolioID: "p1", BaseCurrency: "USD", AsOf: time.Date(2024, 6, 4, 22, 0, 0, 0, time.UTC), Holdings: holding(55000), CashValue: portfolio.Money{Amount: 50000, Currency: "USD"}, TotalValue: portfolio.Money{Amount: 105000, Currency: "USD"}},
+	}}
+	prices := stubPrices{"AAPL": {Amount: 11000, Currency: "USD"}, "SPY": {Amount: 50000, Currency: "USD"}}
+
+	service := application.NewPortfolioService(portfolioRepo, companyRepo, application.WithPriceProvider(prices),
+		application.WithValuationSnapshotRepository(snapshots), application.WithDefaultBenchmark("SPY"))
+	c, err := service.CompareWithBenchmark("p1", time.Time{}, time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("CompareWithBenchmark() error = %v", err)
+	}
+	if c.Benchmark != "SPY" || c.PortfolioReturn < 0.0499 || c.PortfolioReturn > 0.0501 || c.BenchmarkReturn != 0 {
+		t.Errorf("CompareWithBenchmark() = %+v, want 5%% against a flat SPY", c)
+	}
+	sectors := make(map[string]bool)
+	for _, a := range c.Attribution {
+		sectors[a.Sector] = true
+	}
+	if !sectors["Technology"] || !sectors[portfolio.CashSegment] || !sectors[portfolio.UnclassifiedSector] {
+		t.Errorf("Attribution = %+v, want Technology, Cash and the unclassified SPY", c.Attribution)
+	}
+
+	noDefault := application.NewPortfolioService(portfolioRepo, companyRepo, application.WithPriceProvider(prices), application.WithValuationSnapshotRepository(snapshots))
+	if _, err := noDefault.CompareWithBenchmark("p1", time.Time{}, time.Time{}); err == nil {
+		t.Error("CompareWithBenchmark() without a benchmark error = nil, want an error")
+	}
 }
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+// RecommendationService runs the approval workflow of rebalancing recommendations:
+// a proposed recommendation is approved or rejected before it expires.
+type RecommendationService struct {
+	repo recommendation.RecommendationRepository
+}
+
+// NewRecommendationService creates a new instance of RecommendationService.
+func NewRecommendationService(repo recommendation.RecommendationRepository) *RecommendationService {
+	return &RecommendationService{repo: repo}
+}
+
+// GetRecommendation retrieves a recommendation by its ID, expiring it first if its time is up.
+func (s *RecommendationService) GetRecommendation(id string) (*RebalanceRecommendation, error) {
+	rec, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Expire(time.Now()) {
+		if err := s.repo.Save(rec); err != nil {
+			return nil, fmt.Errorf("failed to save expired recommendation %s: %w", id, err)
+		}
+	}
+	return toRebalanceRecommendation(rec), nil
+}
+
+// ListRecommendations returns the recommendations made for a portfolio, oldest first.
+func (s *RecommendationService) ListRecommendations(portfolioID string) ([]*RebalanceRecommendation, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	recs, err := s.repo.FindByPortfolio(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recommendations for portfolio %s: %w", portfolioID, err)
+	}
+	results := make([]*RebalanceRecommendation, 0, len(recs))
+	for _, rec := range recs {
+		results = append(results, toRebalanceRecommendation(rec))
+	}
+	return results, nil
+}
+
+// ApproveRecommendation approves a proposed recommendation on behalf of approver.
+func (s *RecommendationService) ApproveRecommendation(id, approver string) (*RebalanceRecommendation, error) {
+	return s.decide(id, "approving", func(rec *recommendation.Recommendation, at time.Time) error {
+		return rec.Approve(approver, at)
+	})
+}
+
+// RejectRecommendation rejects a proposed recommendation, recording who rejected it and why.
+func (s *RecommendationService) RejectRecommendation(id, by, reason string) (*RebalanceRecommendation, error) {
+	return s.decide(id, "rejecting", func(rec *recommendation.Recommendation, at time.Time) error {
+		return rec.Reject(by, reason, at)
+	})
+}
+
+// ExpireRecommendations moves every proposed or approved recommendation whose expiry time has
+// passed at asOf to Expired, returning their IDs.
+func (s *RecommendationService) ExpireRecommendations(asOf time.Time) ([]string, error) {
+	expired := []string{}
+	for _, status := range []recommendation.Status{recommendation.Proposed, recommendation.Approved} {
+		recs, err := s.repo.FindByStatus(status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s recommendations: %w", status, err)
+		}
+		for _, rec := range recs {
+			if !rec.Expire(asOf) {
+				continue
+			}
+			if err := s.repo.Save(rec); err != nil {
+				return nil, fmt.Errorf("failed to save expired recommendation %s: %w", rec.ID, err)
+			}
+			expired = append(expired, rec.ID)
+		}
+	}
+	return expired, nil
+}
+
+// decide loads a recommendation, applies a decision to it and saves it. A recommendation that
+// turns out to be expired is saved as such and the decision fails.
+func (s *RecommendationService) decide(id, action string, decision func(*recommendation.Recommendation, time.Time) error) (*RebalanceRecommendation, error) {
+	rec, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	decisionErr := decision(rec, time.Now())
+	if decisionErr != nil && !errors.Is(decisionErr, recommendation.ErrExpired) {
+		return nil, fmt.Errorf("domain error %s recommendation %s: %w", action, id, decisionErr)
+	}
+	if err := s.repo.Save(rec); err != nil {
+		return nil, fmt.Errorf("failed to save recommendation %s: %w", id, err)
+	}
+	if decisionErr != nil {
+		return nil, fmt.Errorf("domain error %s recommendation %s: %w", action, id, decisionErr)
+	}
+	return toRebalanceRecommendation(rec), nil
+}
+
+// find retrieves a recommendation by its ID.
+func (s *RecommendationService) find(id string) (*recommendation.Recommendation, error) {
+	if id == "" {
+		return nil, errors.New("recommendation ID cannot be empty")
+	}
+	rec, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recommendation %s: %w", id, err)
+	}
+	return rec, nil
+}
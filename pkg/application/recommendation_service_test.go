@@ -0,0 +1,139 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+// MockRecommendationRepository is a map-backed RecommendationRepository for tests.
+type MockRecommendationRepository struct {
+	recs map[string]*recommendation.Recommendation
+}
+
+func NewMockRecommendationRepository(recs ...*recommendation.Recommendation) *MockRecommendationRepository {
+	m := &MockRecommendationRepository{recs: make(map[string]*recommendation.Recommendation)}
+	for _, r := range recs {
+		m.recs[r.ID] = r
+	}
+	return m
+}
+
+func (m *MockRecommendationRepository) FindByID(id string) (*recommendation.Recommendation, error) {
+	if r, ok := m.recs[id]; ok {
+		return r, nil
+	}
+	return nil, errors.New("recommendation not found")
+}
+
+func (m *MockRecommendationRepository) FindByPortfolio(portfolioID string) ([]*recommendation.Recommendation, error) {
+	var results []*recommendation.Recommendation
+	for _, r := range m.recs {
+		if r.PortfolioID == portfolioID {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockRecommendationRepository) FindByStatus(status recommendation.Status) ([]*recommendation.Recommendation, error) {
+	var results []*recommendation.Recommendation
+	for _, r := range m.recs {
+		if r.Status == status {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockRecommendationRepository) Save(r *recommendation.Recommendation) error {
+	m.recs[r.ID] = r
+	return nil
+}
+
+// newProposedRecommendation returns a proposed recommendation to buy 10 AAPL at 100.00 for
+// portfolio p1, created at the given time and valid for an hour.
+func newProposedRecommendation(t *testing.T, id string, createdAt time.Time) *recommendation.Recommendation {
+	t.Helper()
+	trades := []portfolio.TradeRecommendation{
+		{Action: portfolio.Enter, Ticker: "AAPL", Quantity: 10, MarketPrice: portfolio.Money{Amount: 10000, Currency: "USD"}, LimitPrice: portfolio.Money{Amount: 10000, Currency: "USD"}},
+	}
+	rec, err := recommendation.NewRecommendation(id, "p1", trades, createdAt, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRecommendation() error = %v", err)
+	}
+	return rec
+}
+
+func TestRecommendationService_ApproveAndReject(t *testing.T) {
+	repo := NewMockRecommendationRepository(
+		newProposedRecommendation(t, "fresh", time.Now()),
+		newProposedRecommendation(t, "other", time.Now()),
+		newProposedRecommendation(t, "stale", time.Now().Add(-2*time.Hour)),
+	)
+	service := application.NewRecommendationService(repo)
+
+	t.Run("Approve", func(t *testing.T) {
+		rec, err := service.ApproveRecommendation("fresh", "jane")
+		if err != nil {
+			t.Fatalf("ApproveRecommendation() error = %v", err)
+		}
+		if rec.Status != recommendation.Approved || rec.DecidedBy != "jane" {
+			t.Errorf("ApproveRecommendation() = %+v, want Approved by jane", rec)
+		}
+	})
+
+	t.Run("RejectApproved", func(t *testing.T) {
+		_, err := service.RejectRecommendation("fresh", "john", "changed my mind")
+		if !errors.Is(err, recommendation.ErrInvalidTransition) {
+			t.Errorf("RejectRecommendation() error = %v, want ErrInvalidTransition", err)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		rec, err := service.RejectRecommendation("other", "john", "waiting for earnings")
+		if err != nil || rec.Status != recommendation.Rejected || rec.Reason != "waiting for earnings" {
+			t.Errorf("RejectRecommendation() = %+v, %v; want Rejected with its reason", rec, err)
+		}
+	})
+
+	t.Run("ApproveExpired", func(t *testing.T) {
+		_, err := service.ApproveRecommendation("stale", "jane")
+		if !errors.Is(err, recommendation.ErrExpired) {
+			t.Errorf("ApproveRecommendation() error = %v, want ErrExpired", err)
+		}
+		if repo.recs["stale"].Status != recommendation.Expired {
+			t.Errorf("stale recommendation status = %v, want Expired to be saved", repo.recs["stale"].Status)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		if _, err := service.ApproveRecommendation("missing", "jane"); err == nil {
+			t.Error("ApproveRecommendation() expected an error for an unknown ID")
+		}
+	})
+}
+
+func TestRecommendationService_ExpireRecommendations(t *testing.T) {
+	now := time.Now()
+	repo := NewMockRecommendationRepository(
+		newProposedRecommendation(t, "fresh", now),
+		newProposedRecommendation(t, "stale", now.Add(-2*time.Hour)),
+	)
+	service := application.NewRecommendationService(repo)
+
+	expired, err := service.ExpireRecommendations(now)
+	if err != nil {
+		t.Fatalf("ExpireRecommendations() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0] != "stale" {
+		t.Errorf("ExpireRecommendations() = %v, want [stale]", expired)
+	}
+	if repo.recs["fresh"].Status != recommendation.Proposed {
+		t.Errorf("fresh recommendation status = %v, want Proposed", repo.recs["fresh"].Status)
+	}
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// RiskAnalyticsService measures the market risk of portfolios from daily price history and
+// keeps a daily record of the metrics.
+type RiskAnalyticsService struct {
+	portfolioRepo portfolio.PortfolioRepository
+	metricsRepo   portfolio.RiskMetricsRepository
+	prices        portfolio.PriceProvider
+	fxRates       portfolio.FXRateProvider // Optional; needed for multi-currency portfolios
+	benchmark     string                   // Ticker beta is measured against
+	lookbackDays  int                      // Number of daily returns the metrics are computed from
+}
+
+// NewRiskAnalyticsService creates a new instance of RiskAnalyticsService. Metrics are computed
+// over the last lookbackDays trading days, with beta measured against the benchmark ticker.
+func NewRiskAnalyticsService(pRepo portfolio.PortfolioRepository, metricsRepo portfolio.RiskMetricsRepository, prices portfolio.PriceProvider, rates portfolio.FXRateProvider, benchmark string, lookbackDays int) *RiskAnalyticsService {
+	return &RiskAnalyticsService{
+		portfolioRepo: pRepo,
+		metricsRepo:   metricsRepo,
+		prices:        prices,
+		fxRates:       rates,
+		benchmark:     benchmark,
+		lookbackDays:  lookbackDays,
+	}
+}
+
+// GetRiskMetrics computes a portfolio's volatility, beta, maximum drawdown and value at risk as
+// of asOf (a zero date means "now").
+func (s *RiskAnalyticsService) GetRiskMetrics(portfolioID string, asOf time.Time) (*portfolio.RiskMetrics, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	p, err := s.portfolioRepo.FindByID(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find portfolio %s: %w", portfolioID, err)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("portfolio %s not found", portfolioID)
+	}
+	return s.riskMetricsOf(p, effectiveDate(asOf))
+}
+
+// RecordRiskMetrics computes and stores the risk metrics of every portfolio as of asOf.
+// Portfolios without enough price history are skipped. It is intended to be run once a day by a
+// scheduler.
+func (s *RiskAnalyticsService) RecordRiskMetrics(asOf time.Time) error {
+	portfolios, err := s.portfolioRepo.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list portfolios for risk metrics: %w", err)
+	}
+	for _, p := range portfolios {
+		metrics, err := s.riskMetricsOf(p, asOf)
+		if errors.Is(err, portfolio.ErrInsufficientHistory) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.metricsRepo.Save(metrics); err != nil {
+			return fmt.Errorf("failed to save risk metrics of portfolio %s: %w", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetRiskMetricsHistory returns the stored daily risk metrics of a portfolio in [from, to],
+// oldest first. Zero dates leave that side of the range open.
+func (s *RiskAnalyticsService) GetRiskMetricsHistory(portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error) {
+	if portfolioID == "" {
+		return nil, errors.New("portfolioID cannot be empty")
+	}
+	metrics, err := s.metricsRepo.FindByPortfolio(portfolioID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load risk metrics of portfolio %s: %w", portfolioID, err)
+	}
+	return metrics, nil
+}
+
+// riskMetricsOf values p's current holdings over the lookback window and measures their risk.
+func (s *RiskAnalyticsService) riskMetricsOf(p *portfolio.Portfolio, asOf time.Time) (*portfolio.RiskMetrics, error) {
+	if s.prices == nil {
+		return nil, errors.New("risk analytics need a price provider")
+	}
+	days := tradingDays(asOf, s.lookbackDays)
+	values, err := p.ValueHistory(s.prices, s.fxRates, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to value the history of portfolio %s: %w", p.ID, err)
+	}
+	metrics, err := portfolio.ComputeRiskMetrics(p.ID, asOf, p.BaseCurrency, values, s.benchmark, s.benchmarkPrices(days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute risk metrics of portfolio %s: %w", p.ID, err)
+	}
+	return metrics, nil
+}
+
+// benchmarkPrices returns the benchmark's closing price on each day, or nil if one is missing.
+func (s *RiskAnalyticsService) benchmarkPrices(days []time.Time) []float64 {
+	if s.benchmark == "" {
+		return nil
+	}
+	prices := make([]float64, 0, len(days))
+	for _, day := range days {
+		price, err := s.prices.Price(s.benchmark, day)
+		if err != nil {
+			return nil
+		}
+		prices = append(prices, float64(price.Amount))
+	}
+	return prices
+}
+
+// tradingDays returns the weekdays giving n daily returns up to and including asOf's weekday,
+// oldest first.
+func tradingDays(asOf time.Time, n int) []time.Time {
+	days := make([]time.Time, 0, n+1)
+	for day := asOf; len(days) < n+1; day = day.AddDate(0, 0, -1) {
+		if wd := day.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		days = append(days, day)
+	}
+	for i, j := 0, len(days)-1; i < j; i, j = i+1, j-1 {
+		days[i], days[j] = days[j], days[i]
+	}
+	return days
+}
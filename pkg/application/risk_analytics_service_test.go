@@ -0,0 +1,81 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// MockRiskMetricsRepository is a slice-backed RiskMetricsRepository for tests.
+type MockRiskMetricsRepository struct {
+	saved []*portfolio.RiskMetrics
+}
+
+func (m *MockRiskMetricsRepository) Save(metrics *portfolio.RiskMetrics) error {
+	m.saved = append(m.saved, metrics)
+	return nil
+}
+
+func (m *MockRiskMetricsRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error) {
+	var found []*portfolio.RiskMetrics
+	for _, metrics := range m.saved {
+		if metrics.PortfolioID == portfolioID && !metrics.AsOf.Before(from) && (to.IsZero() || !metrics.AsOf.After(to)) {
+			found = append(found, metrics)
+		}
+	}
+	return found, nil
+}
+
+func TestRiskAnalyticsService(t *testing.T) {
+	asOf := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC) // A Monday
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	p.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 10, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	portfolioRepo := &MockPortfolioRepository{
+		FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return p, nil },
+		FindAllFunc:  func() ([]*portfolio.Portfolio, error) { return []*portfolio.Portfolio{p}, nil },
+	}
+	prices := stubPrices{"AAPL": {Amount: 1200, Currency: "USD"}, "SPY": {Amount: 50000, Currency: "USD"}}
+
+	t.Run("RecordAndHistory", func(t *testing.T) {
+		metricsRepo := &MockRiskMetricsRepository{}
+		service := application.NewRiskAnalyticsService(portfolioRepo, metricsRepo, prices, nil, "SPY", 5)
+
+		if err := service.RecordRiskMetrics(asOf); err != nil {
+			t.Fatalf("RecordRiskMetrics() error = %v", err)
+		}
+		history, err := service.GetRiskMetricsHistory("p1", time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("GetRiskMetricsHistory() error = %v", err)
+		}
+		if len(history) != 1 {
+			t.Fatalf("GetRiskMetricsHistory() = %d entries, want 1", len(history))
+		}
+		// Flat prices: no volatility, no drawdown, no loss at risk, and no beta against a flat benchmark.
+		m := history[0]
+		if m.Observations != 6 || m.Value.Amount != 112000 || m.Volatility != 0 || m.MaxDrawdown != 0 || m.Benchmark != "" {
+			t.Errorf("recorded metrics = %+v", m)
+		}
+	})
+
+	t.Run("InsufficientHistory", func(t *testing.T) {
+		metricsRepo := &MockRiskMetricsRepository{}
+		service := application.NewRiskAnalyticsService(portfolioRepo, metricsRepo, prices, nil, "SPY", 1)
+
+		if _, err := service.GetRiskMetrics("p1", asOf); !errors.Is(err, portfolio.ErrInsufficientHistory) {
+			t.Errorf("GetRiskMetrics() error = %v, want ErrInsufficientHistory", err)
+		}
+		if err := service.RecordRiskMetrics(asOf); err != nil || len(metricsRepo.saved) != 0 {
+			t.Errorf("RecordRiskMetrics() = %v with %d saved, want the portfolio skipped", err, len(metricsRepo.saved))
+		}
+	})
+
+	t.Run("NoPriceProvider", func(t *testing.T) {
+		service := application.NewRiskAnalyticsService(portfolioRepo, &MockRiskMetricsRepository{}, nil, nil, "SPY", 5)
+		if _, err := service.GetRiskMetrics("p1", asOf); err == nil {
+			t.Error("GetRiskMetrics() error = nil, want an error without a price provider")
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package application
+
+import (
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+// Repositories are the repositories a unit of work writes through.
+type Repositories struct {
+	Companies       company.CompanyRepository
+	Portfolios      portfolio.PortfolioRepository
+	Recommendations recommendation.RecommendationRepository
+	// AuditLog appends to the audit log as part of the unit, so that entries commit with the
+	// changes they record; nil when the unit cannot hold audit entries.
+	AuditLog audit.Log
+}
+
+// UnitOfWork runs operations that change several aggregates so that either all of their
+// repository writes commit or none do. Do passes work repositories whose writes only take effect
+// if work returns nil; reads through them see the unit's own writes. When work fails, or the
+// commit does because another writer changed an aggregate the unit saved, Do returns that error
+// and nothing is written. Aggregates saved by a failed unit keep the versions the unit gave
+// them and must be loaded again.
+type UnitOfWork interface {
+	Do(work func(repos Repositories) error) error
+}
+
+// directUnitOfWork writes straight through to its repositories, for storages that cannot group
+// writes. A failure leaves the writes made before it in place.
+type directUnitOfWork struct {
+	repos Repositories
+}
+
+// Do runs work against the repositories themselves.
+func (u directUnitOfWork) Do(work func(repos Repositories) error) error {
+	return work(u.repos)
+}
@@ -0,0 +1,229 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/watchlist"
+)
+
+// EntryRecommender proposes new position entries for a portfolio. It is implemented by
+// PortfolioService.
+type EntryRecommender interface {
+	RecommendEntry(portfolioID string, ticker string, rationale string) (*RebalanceRecommendation, error)
+}
+
+// EntryAlert is an entry signal raised by a watchlist and what came of it: the recommendation
+// proposed for the watchlist's portfolio, or why none was.
+type EntryAlert struct {
+	Signal           watchlist.EntrySignalTriggeredEvent
+	RecommendationID string // Empty when no recommendation was proposed
+	Reason           string // Why no recommendation was proposed, if so
+}
+
+// WatchlistService manages watchlists and turns the entry signals of watched companies into
+// position entry recommendations.
+type WatchlistService struct {
+	repo        watchlist.WatchlistRepository
+	companyRepo company.CompanyRepository
+	prices      portfolio.PriceProvider // Optional; without it only score targets can be met
+	entries     EntryRecommender        // Optional; without it signals are only reported
+}
+
+// NewWatchlistService creates a new instance of WatchlistService.
+func NewWatchlistService(repo watchlist.WatchlistRepository, cRepo company.CompanyRepository, prices portfolio.PriceProvider, entries EntryRecommender) *WatchlistService {
+	return &WatchlistService{
+		repo:        repo,
+		companyRepo: cRepo,
+		prices:      prices,
+		entries:     entries,
+	}
+}
+
+// CreateWatchlist creates an empty watchlist belonging to a user, a portfolio, or both.
+func (s *WatchlistService) CreateWatchlist(name, owner, portfolioID string) (*watchlist.Watchlist, error) {
+	w, err := watchlist.NewWatchlist(uuid.NewString(), name, owner, portfolioID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("domain error creating watchlist: %w", err)
+	}
+	if err := s.repo.Save(w); err != nil {
+		return nil, fmt.Errorf("failed to save new watchlist: %w", err)
+	}
+	return w, nil
+}
+
+// GetWatchlist retrieves a watchlist by its ID.
+func (s *WatchlistService) GetWatchlist(id string) (*watchlist.Watchlist, error) {
+	if id == "" {
+		return nil, errors.New("watchlist ID cannot be empty")
+	}
+	w, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find watchlist %s: %w", id, err)
+	}
+	if w == nil {
+		return nil, fmt.Errorf("watchlist %s not found", id)
+	}
+	return w, nil
+}
+
+// ListWatchlists returns the watchlists of a user or of a portfolio, oldest first. With neither
+// given it returns every watchlist.
+func (s *WatchlistService) ListWatchlists(owner, portfolioID string) ([]*watchlist.Watchlist, error) {
+	var (
+		lists []*watchlist.Watchlist
+		err   error
+	)
+	switch {
+	case owner != "" && portfolioID != "":
+		return nil, errors.New("watchlists can be listed by owner or by portfolio, not both")
+	case owner != "":
+		lists, err = s.repo.FindByOwner(owner)
+	case portfolioID != "":
+		lists, err = s.repo.FindByPortfolio(portfolioID)
+	default:
+		lists, err = s.repo.FindAll()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlists: %w", err)
+	}
+	return lists, nil
+}
+
+// Watch adds a known company to a watchlist with its target buy price and/or target score, or
+// replaces the target of a company already watched.
+func (s *WatchlistService) Watch(id, ticker string, targetPrice portfolio.Money, targetScore float64, note string) (*watchlist.Watchlist, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+	comp, err := s.companyRepo.FindByTicker(ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find company %s: %w", ticker, err)
+	}
+	if comp == nil {
+		return nil, fmt.Errorf("company with ticker %s not found", ticker)
+	}
+	return s.update(id, "watching "+ticker, func(w *watchlist.Watchlist, at time.Time) error {
+		return w.Watch(ticker, targetPrice, targetScore, note, at)
+	})
+}
+
+// Unwatch removes a company from a watchlist.
+func (s *WatchlistService) Unwatch(id, ticker string) (*watchlist.Watchlist, error) {
+	return s.update(id, "unwatching "+ticker, func(w *watchlist.Watchlist, at time.Time) error {
+		return w.Unwatch(ticker, at)
+	})
+}
+
+// DeleteWatchlist removes a watchlist.
+func (s *WatchlistService) DeleteWatchlist(id string) error {
+	if _, err := s.GetWatchlist(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete watchlist %s: %w", id, err)
+	}
+	return nil
+}
+
+// CheckEntrySignals evaluates every watchlist against the prices and scores as of at (a zero
+// time means "now"). Each company whose target was crossed raises an alert; for watchlists kept
+// for a portfolio the alert proposes a new position entry recommendation. A signal that cannot
+// be turned into a recommendation (no room in the risk policy, say) is still reported, with the
+// reason. It is intended to be run periodically by a scheduler.
+func (s *WatchlistService) CheckEntrySignals(at time.Time) ([]EntryAlert, error) {
+	at = effectiveDate(at)
+	lists, err := s.repo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlists for entry signals: %w", err)
+	}
+
+	observations := make(map[string]watchlist.Observation)
+	alerts := []EntryAlert{}
+	for _, w := range lists {
+		for _, ticker := range w.Tickers() {
+			if _, ok := observations[ticker]; ok {
+				continue
+			}
+			obs, err := s.observe(ticker, at)
+			if err != nil {
+				return nil, err
+			}
+			observations[ticker] = obs
+		}
+
+		// Re-armed items are saved too, or they would stay triggered and never fire again.
+		signals, changed := w.Evaluate(observations, at)
+		if !changed {
+			continue
+		}
+		if err := s.repo.Save(w); err != nil {
+			return nil, fmt.Errorf("failed to save watchlist %s: %w", w.ID, err)
+		}
+		for _, signal := range signals {
+			alerts = append(alerts, s.alert(signal))
+		}
+	}
+	return alerts, nil
+}
+
+// observe returns the latest price and score of a watched company. Unknown companies and
+// prices are left zero, so they cannot meet their targets.
+func (s *WatchlistService) observe(ticker string, at time.Time) (watchlist.Observation, error) {
+	var obs watchlist.Observation
+	comp, err := s.companyRepo.FindByTicker(ticker)
+	if err == nil && comp != nil {
+		obs.Score = comp.CurrentScore
+	}
+	if s.prices == nil {
+		return obs, nil
+	}
+	price, err := s.prices.Price(ticker, at)
+	if errors.Is(err, portfolio.ErrPriceNotFound) {
+		return obs, nil
+	}
+	if err != nil {
+		return obs, fmt.Errorf("failed to get price for %s: %w", ticker, err)
+	}
+	obs.Price = price
+	return obs, nil
+}
+
+// alert turns an entry signal into an alert, proposing a recommendation when the signal was
+// raised for a portfolio.
+func (s *WatchlistService) alert(signal watchlist.EntrySignalTriggeredEvent) EntryAlert {
+	alert := EntryAlert{Signal: signal}
+	switch {
+	case signal.PortfolioID == "":
+		alert.Reason = "watchlist is not kept for a portfolio"
+	case s.entries == nil:
+		alert.Reason = "entry recommendations are not configured"
+	default:
+		rec, err := s.entries.RecommendEntry(signal.PortfolioID, signal.Ticker, signal.Rationale)
+		if err != nil {
+			alert.Reason = err.Error()
+		} else {
+			alert.RecommendationID = rec.ID
+		}
+	}
+	return alert
+}
+
+// update loads a watchlist, applies op to it and saves it.
+func (s *WatchlistService) update(id string, action string, op func(w *watchlist.Watchlist, at time.Time) error) (*watchlist.Watchlist, error) {
+	w, err := s.GetWatchlist(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := op(w, time.Now()); err != nil {
+		return nil, fmt.Errorf("domain error %s on watchlist %s: %w", action, id, err)
+	}
+	if err := s.repo.Save(w); err != nil {
+		return nil, fmt.Errorf("failed to save watchlist %s: %w", id, err)
+	}
+	return w, nil
+}
@@ -0,0 +1,228 @@
+package application_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/watchlist"
+)
+
+// MockWatchlistRepository keeps copies of the watchlists, as real repositories do.
+type MockWatchlistRepository struct {
+	lists map[string]*watchlist.Watchlist
+}
+
+func NewMockWatchlistRepository() *MockWatchlistRepository {
+	return &MockWatchlistRepository{lists: make(map[string]*watchlist.Watchlist)}
+}
+
+func (m *MockWatchlistRepository) FindByID(id string) (*watchlist.Watchlist, error) {
+	if w, ok := m.lists[id]; ok {
+		return w.Clone(), nil
+	}
+	return nil, errors.New("watchlist not found")
+}
+
+func (m *MockWatchlistRepository) FindAll() ([]*watchlist.Watchlist, error) {
+	return m.filter(func(*watchlist.Watchlist) bool { return true }), nil
+}
+
+func (m *MockWatchlistRepository) FindByOwner(owner string) ([]*watchlist.Watchlist, error) {
+	return m.filter(func(w *watchlist.Watchlist) bool { return w.Owner == owner }), nil
+}
+
+func (m *MockWatchlistRepository) FindByPortfolio(portfolioID string) ([]*watchlist.Watchlist, error) {
+	return m.filter(func(w *watchlist.Watchlist) bool { return w.PortfolioID == portfolioID }), nil
+}
+
+func (m *MockWatchlistRepository) Save(w *watchlist.Watchlist) error {
+	m.lists[w.ID] = w.Clone()
+	return nil
+}
+
+func (m *MockWatchlistRepository) Delete(id string) error {
+	if _, ok := m.lists[id]; !ok {
+		return errors.New("watchlist not found")
+	}
+	delete(m.lists, id)
+	return nil
+}
+
+func (m *MockWatchlistRepository) filter(keep func(*watchlist.Watchlist) bool) []*watchlist.Watchlist {
+	var results []*watchlist.Watchlist
+	for _, w := range m.lists {
+		if keep(w) {
+			results = append(results, w.Clone())
+		}
+	}
+	return results
+}
+
+// stubEntryRecommender records the entries it is asked to recommend, failing for tickers in fail.
+type stubEntryRecommender struct {
+	calls []string
+	fail  map[string]bool
+}
+
+func (s *stubEntryRecommender) RecommendEntry(portfolioID string, ticker string, rationale string) (*application.RebalanceRecommendation, error) {
+	s.calls = append(s.calls, portfolioID+":"+ticker)
+	if s.fail[ticker] {
+		return nil, errors.New("domain error recommending entry: no room")
+	}
+	return &application.RebalanceRecommendation{ID: "rec-" + ticker, PortfolioID: portfolioID}, nil
+}
+
+func TestWatchlistService_Watch(t *testing.T) {
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	companyRepo := &MockCompanyRepository{
+		FindByTickerFunc: func(ticker string) (*company.Company, error) {
+			if ticker == "AAPL" {
+				return aapl, nil
+			}
+			return nil, nil
+		},
+	}
+	repo := NewMockWatchlistRepository()
+	service := application.NewWatchlistService(repo, companyRepo, nil, nil)
+
+	w, err := service.CreateWatchlist("Tech", "jane.doe", "")
+	if err != nil {
+		t.Fatalf("CreateWatchlist() error = %v", err)
+	}
+	if _, err := service.Watch(w.ID, "AAPL", portfolio.Money{Amount: 15000, Currency: "USD"}, 0, "Wait for a pullback"); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if _, err := service.Watch(w.ID, "ZZZZ", portfolio.Money{Amount: 1000, Currency: "USD"}, 0, ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Watch(unknown company) error = %v, want not found", err)
+	}
+	if _, err := service.Watch(w.ID, "AAPL", portfolio.Money{}, 0, ""); err == nil || !strings.Contains(err.Error(), "domain error") {
+		t.Errorf("Watch(no target) error = %v, want a domain error", err)
+	}
+
+	lists, err := service.ListWatchlists("jane.doe", "")
+	if err != nil || len(lists) != 1 || lists[0].Items["AAPL"].TargetPrice.Amount != 15000 {
+		t.Errorf("ListWatchlists() = %v, %v, want the watchlist with AAPL", lists, err)
+	}
+	if _, err := service.Unwatch(w.ID, "MSFT"); !errors.Is(err, watchlist.ErrNotWatched) {
+		t.Errorf("Unwatch(MSFT) error = %v, want ErrNotWatched", err)
+	}
+	if err := service.DeleteWatchlist(w.ID); err != nil {
+		t.Fatalf("DeleteWatchlist() error = %v", err)
+	}
+	if _, err := service.GetWatchlist(w.ID); err == nil {
+		t.Errorf("GetWatchlist() after delete error = nil, want not found")
+	}
+}
+
+func TestWatchlistService_CheckEntrySignals(t *testing.T) {
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	aapl.CurrentScore = 60
+	msft, _ := company.NewCompany("MSFT", company.FinancialMetrics{PERatio: 20}, company.Technology)
+	msft.CurrentScore = 85
+	companies := map[string]*company.Company{"AAPL": aapl, "MSFT": msft}
+	companyRepo := &MockCompanyRepository{
+		FindByTickerFunc: func(ticker string) (*company.Company, error) { return companies[ticker], nil },
+	}
+	prices := stubPrices{"AAPL": {Amount: 14000, Currency: "USD"}, "MSFT": {Amount: 40000, Currency: "USD"}}
+	entries := &stubEntryRecommender{fail: map[string]bool{"MSFT": true}}
+	repo := NewMockWatchlistRepository()
+	service := application.NewWatchlistService(repo, companyRepo, prices, entries)
+
+	personal, _ := service.CreateWatchlist("Personal", "jane.doe", "")
+	managed, _ := service.CreateWatchlist("Fund", "", "p1")
+	usd := func(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+	for _, w := range []*watchlist.Watchlist{personal, managed} {
+		if _, err := service.Watch(w.ID, "AAPL", usd(15000), 0, ""); err != nil {
+			t.Fatalf("Watch(AAPL) error = %v", err)
+		}
+	}
+	if _, err := service.Watch(managed.ID, "MSFT", portfolio.Money{}, 80, ""); err != nil {
+		t.Fatalf("Watch(MSFT) error = %v", err)
+	}
+
+	alerts, err := service.CheckEntrySignals(time.Now())
+	if err != nil {
+		t.Fatalf("CheckEntrySignals() error = %v", err)
+	}
+	if len(alerts) != 3 {
+		t.Fatalf("CheckEntrySignals() = %+v, want 3 alerts", alerts)
+	}
+	byKey := make(map[string]application.EntryAlert)
+	for _, a := range alerts {
+		byKey[a.Signal.WatchlistID+":"+a.Signal.Ticker] = a
+	}
+
+	// AAPL trades at 140.00 against a 150.00 target: a 6.7% margin of safety.
+	if a := byKey[managed.ID+":AAPL"]; a.RecommendationID != "rec-AAPL" || a.Signal.MarginOfSafety < 0.066 || a.Signal.MarginOfSafety > 0.067 {
+		t.Errorf("managed AAPL alert = %+v, want a recommendation with a 6.7%% margin of safety", a)
+	}
+	if a := byKey[personal.ID+":AAPL"]; a.RecommendationID != "" || a.Reason == "" {
+		t.Errorf("personal AAPL alert = %+v, want no recommendation for a personal watchlist", a)
+	}
+	if a := byKey[managed.ID+":MSFT"]; a.RecommendationID != "" || !strings.Contains(a.Reason, "no room") {
+		t.Errorf("managed MSFT alert = %+v, want the reason the entry was not recommended", a)
+	}
+	if len(entries.calls) != 2 {
+		t.Errorf("RecommendEntry calls = %v, want one per signal of the portfolio's watchlist", entries.calls)
+	}
+
+	// Signals fire once per crossing.
+	if alerts, err := service.CheckEntrySignals(time.Now()); err != nil || len(alerts) != 0 {
+		t.Errorf("CheckEntrySignals() again = %+v, %v, want no alerts", alerts, err)
+	}
+
+	// AAPL rises above its target, which re-arms it, and falls below it again.
+	prices["AAPL"] = usd(15500)
+	if alerts, err := service.CheckEntrySignals(time.Now()); err != nil || len(alerts) != 0 {
+		t.Fatalf("CheckEntrySignals() above target = %+v, %v, want no alerts", alerts, err)
+	}
+	if stored, _ := repo.FindByID(personal.ID); stored.Items["AAPL"].Triggered {
+		t.Errorf("stored AAPL item = %+v, want it re-armed", stored.Items["AAPL"])
+	}
+	prices["AAPL"] = usd(14500)
+	if alerts, err := service.CheckEntrySignals(time.Now()); err != nil || len(alerts) != 2 {
+		t.Errorf("CheckEntrySignals() below target again = %+v, %v, want an AAPL alert per watchlist", alerts, err)
+	}
+}
+
+func TestPortfolioService_RecommendEntry(t *testing.T) {
+	msft, _ := company.NewCompany("MSFT", company.FinancialMetrics{PERatio: 20}, company.Technology)
+	msft.CurrentScore = 80
+	mockCompanyRepo := &MockCompanyRepository{
+		FindByTickerFunc: func(ticker string) (*company.Company, error) {
+			if ticker == "MSFT" {
+				return msft, nil
+			}
+			return nil, nil
+		},
+	}
+	pInstance, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	mockPortfolioRepo := &MockPortfolioRepository{
+		FindByIDFunc: func(id string) (*portfolio.Portfolio, error) { return pInstance, nil },
+	}
+	prices := stubPrices{"MSFT": {Amount: 2000, Currency: "USD"}}
+	recRepo := NewMockRecommendationRepository()
+	service := application.NewPortfolioService(mockPortfolioRepo, mockCompanyRepo,
+		application.WithPriceProvider(prices), application.WithRecommendationRepository(recRepo, time.Hour))
+
+	rec, err := service.RecommendEntry("p1", "MSFT", "MSFT crossed its target")
+	if err != nil {
+		t.Fatalf("RecommendEntry() error = %v", err)
+	}
+	// Moderate caps the position at 15% of 1000.00: 7 MSFT at 20.00.
+	if len(rec.Suggestions) != 1 || rec.Suggestions[0].Action != portfolio.Enter || rec.Suggestions[0].Quantity != 7 {
+		t.Fatalf("RecommendEntry() suggestions = %+v, want Enter 7 MSFT", rec.Suggestions)
+	}
+	if _, ok := recRepo.recs[rec.ID]; !ok || rec.Suggestions[0].Rationale != "MSFT crossed its target" {
+		t.Errorf("RecommendEntry() = %+v, want a saved recommendation with the signal's rationale", rec)
+	}
+
+	if _, err := service.RecommendEntry("p1", "AAPL", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("RecommendEntry(unknown company) error = %v, want not found", err)
+	}
+}
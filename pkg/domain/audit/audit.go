@@ -0,0 +1,303 @@
+// Package audit records who changed which company or portfolio, when and how. Entries are only
+// ever appended to the log, and each is chained to the one before it by a hash of both, so that
+// changing or removing a recorded entry breaks the chain from there on.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AggregateType names the kind of aggregate an entry records a change to.
+type AggregateType string
+
+// Defines the aggregates whose changes are audited.
+const (
+	Company   AggregateType = "company"
+	Portfolio AggregateType = "portfolio"
+)
+
+// ParseAggregateType converts a string to an AggregateType.
+func ParseAggregateType(s string) (AggregateType, error) {
+	switch t := AggregateType(s); t {
+	case Company, Portfolio:
+		return t, nil
+	default:
+		return "", fmt.Errorf("invalid aggregate type: %q, want company or portfolio", s)
+	}
+}
+
+// Source is who made a change and through which entry point: an HTTP endpoint or a scheduled job.
+// This is a value object.
+type Source struct {
+	Actor    string
+	Endpoint string
+}
+
+// Change is one value of an aggregate that a change set, replaced or removed. Path names the
+// value in the aggregate's JSON form, e.g. "Holdings.AAPL.Quantity"; Before is nil for a value
+// set and After for one removed.
+type Change struct {
+	Path   string
+	Before any
+	After  any
+}
+
+// Entry records one saved change to a company or portfolio: who made it, through which
+// endpoint, and how the aggregate differs from before. An entry for a deleted aggregate has
+// version 0.
+type Entry struct {
+	Sequence      int // Position in the log, from 1
+	RecordedAt    time.Time
+	Actor         string
+	Endpoint      string
+	AggregateType AggregateType
+	AggregateID   string
+	Version       int
+	Changes       []Change
+	PreviousHash  string // Hash of the entry before, empty for the first
+	Hash          string
+}
+
+// NewEntry creates the entry recording that an aggregate changed from before to after, either
+// of which is nil when the change created or deleted it. It is not in a log yet.
+func NewEntry(source Source, aggregateType AggregateType, aggregateID string, version int, before, after any, at time.Time) (*Entry, error) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s %s with its previous state: %w", aggregateType, aggregateID, err)
+	}
+	return &Entry{
+		RecordedAt:    at,
+		Actor:         source.Actor,
+		Endpoint:      source.Endpoint,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Version:       version,
+		Changes:       changes,
+	}, nil
+}
+
+// Seal puts the entry in a log at sequence, after the entry whose hash is previous: it sets
+// the sequence, the previous hash and the entry's own hash.
+func (e *Entry) Seal(sequence int, previous string) error {
+	e.Sequence = sequence
+	e.PreviousHash = previous
+	hash, err := e.computeHash()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	return nil
+}
+
+// computeHash returns the SHA-256 of the entry's JSON form without its hash, which covers the
+// hash of the entry before.
+func (e *Entry) computeHash() (string, error) {
+	unsealed := *e
+	unsealed.Hash = ""
+	data, err := json.Marshal(unsealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry %d: %w", e.Sequence, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Filter selects entries of a log. Zero fields match every entry; From and To bound the time
+// entries were recorded, both inclusive.
+type Filter struct {
+	AggregateType AggregateType
+	AggregateID   string
+	Actor         string
+	From          time.Time
+	To            time.Time
+}
+
+// Matches reports whether the filter selects e.
+func (f Filter) Matches(e *Entry) bool {
+	return (f.AggregateType == "" || e.AggregateType == f.AggregateType) &&
+		(f.AggregateID == "" || e.AggregateID == f.AggregateID) &&
+		(f.Actor == "" || e.Actor == f.Actor) &&
+		(f.From.IsZero() || !e.RecordedAt.Before(f.From)) &&
+		(f.To.IsZero() || !e.RecordedAt.After(f.To))
+}
+
+// Log is the append-only store of audit entries. Entries passed in and returned are copies.
+type Log interface {
+	// Append seals an entry after the last one of the log and stores it, setting its sequence
+	// and hashes.
+	Append(e *Entry) error
+
+	// Find retrieves the entries the filter selects, in log order. It returns an empty, non-nil
+	// slice when there are none.
+	Find(filter Filter) ([]*Entry, error)
+}
+
+// ErrTampered is matched (via errors.Is) by every TamperedError.
+var ErrTampered = errors.New("audit log has been tampered with")
+
+// TamperedError is returned by Verify for the first entry of a log that is not the one
+// originally appended there.
+type TamperedError struct {
+	Sequence int
+	Reason   string
+}
+
+// Error returns the error message string.
+func (e *TamperedError) Error() string {
+	return fmt.Sprintf("audit log has been tampered with at entry %d: %s", e.Sequence, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrTampered) match any TamperedError.
+func (e *TamperedError) Is(target error) bool {
+	return target == ErrTampered
+}
+
+// Verify checks that entries, a whole log in order, form an unbroken chain: every entry is at
+// its place, follows the hash of the one before and still has the hash it was sealed with.
+func Verify(entries []*Entry) error {
+	previous := ""
+	for i, e := range entries {
+		switch hash, err := e.computeHash(); {
+		case err != nil:
+			return err
+		case e.Sequence != i+1:
+			return &TamperedError{Sequence: i + 1, Reason: fmt.Sprintf("found entry %d instead", e.Sequence)}
+		case e.PreviousHash != previous:
+			return &TamperedError{Sequence: e.Sequence, Reason: "it does not follow the entry before"}
+		case e.Hash != hash:
+			return &TamperedError{Sequence: e.Sequence, Reason: "its content does not match its hash"}
+		}
+		previous = e.Hash
+	}
+	return nil
+}
+
+// Diff returns the values that differ between the JSON forms of before and after, ordered by
+// path. Objects are compared key by key and arrays index by index. A nil before or after stands
+// for an aggregate that does not exist, all of whose fields are set or removed.
+func Diff(before, after any) ([]Change, error) {
+	b, err := jsonValue(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := jsonValue(after)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		b = map[string]any{}
+	}
+	if a == nil {
+		a = map[string]any{}
+	}
+	changes := []Change{}
+	diff("", b, a, &changes)
+	return changes, nil
+}
+
+// DecodeEntry decodes an entry from its JSON form as stored, keeping the numbers of its changes
+// as written so that its hash still matches.
+func DecodeEntry(data []byte) (*Entry, error) {
+	var e Entry
+	if err := decodeJSON(data, &e); err != nil {
+		return nil, fmt.Errorf("invalid audit entry: %w", err)
+	}
+	return &e, nil
+}
+
+// jsonValue returns v as decoded from its JSON form, keeping numbers as written.
+func jsonValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := decodeJSON(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// decodeJSON decodes data into v, keeping numbers as json.Number.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// diff appends the differences between the JSON values b and a at path to changes.
+func diff(path string, b, a any, changes *[]Change) {
+	bObject, bIsObject := b.(map[string]any)
+	aObject, aIsObject := a.(map[string]any)
+	if bIsObject && aIsObject {
+		for _, key := range sortedKeys(bObject, aObject) {
+			bValue, inB := bObject[key]
+			aValue, inA := aObject[key]
+			switch {
+			case !inB:
+				*changes = append(*changes, Change{Path: join(path, key), After: aValue})
+			case !inA:
+				*changes = append(*changes, Change{Path: join(path, key), Before: bValue})
+			default:
+				diff(join(path, key), bValue, aValue, changes)
+			}
+		}
+		return
+	}
+	bArray, bIsArray := b.([]any)
+	aArray, aIsArray := a.([]any)
+	if bIsArray && aIsArray {
+		for i := 0; i < max(len(bArray), len(aArray)); i++ {
+			key := fmt.Sprint(i)
+			switch {
+			case i >= len(bArray):
+				*changes = append(*changes, Change{Path: join(path, key), After: aArray[i]})
+			case i >= len(aArray):
+				*changes = append(*changes, Change{Path: join(path, key), Before: bArray[i]})
+			default:
+				diff(join(path, key), bArray[i], aArray[i], changes)
+			}
+		}
+		return
+	}
+	if !jsonEqual(b, a) {
+		*changes = append(*changes, Change{Path: path, Before: b, After: a})
+	}
+}
+
+// sortedKeys returns the keys of both objects once each, sorted.
+func sortedKeys(b, a map[string]any) []string {
+	keys := make([]string, 0, len(b)+len(a))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	for key := range a {
+		if _, inB := b[key]; !inB {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// jsonEqual reports whether two JSON values have the same JSON form.
+func jsonEqual(b, a any) bool {
+	bData, _ := json.Marshal(b) // Decoded JSON always encodes
+	aData, _ := json.Marshal(a)
+	return bytes.Equal(bData, aData)
+}
+
+// join appends key to a JSON value path.
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,141 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+type account struct {
+	Owner    string
+	Balances map[string]int64
+	Tags     []string
+}
+
+func TestDiff(t *testing.T) {
+	before := &account{Owner: "alice", Balances: map[string]int64{"USD": 100, "EUR": 5}, Tags: []string{"a", "b"}}
+	after := &account{Owner: "alice", Balances: map[string]int64{"USD": 250, "GBP": 7}, Tags: []string{"a"}}
+
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := "[{Balances.EUR 5 <nil>} {Balances.GBP <nil> 7} {Balances.USD 100 250} {Tags.1 b <nil>}]"
+	if got := fmt.Sprint(changes); got != want {
+		t.Errorf("Diff() = %s, want %s", got, want)
+	}
+
+	if changes, err := audit.Diff(before, before); err != nil || len(changes) != 0 {
+		t.Errorf("Diff(same) = %v, %v, want no changes", changes, err)
+	}
+	// A created aggregate has all of its fields set.
+	if changes, err := audit.Diff(nil, after); err != nil || fmt.Sprint(changes) != "[{Balances <nil> map[GBP:7 USD:250]} {Owner <nil> alice} {Tags <nil> [a]}]" {
+		t.Errorf("Diff(nil, after) = %v, %v, want every field set", changes, err)
+	}
+	if changes, err := audit.Diff(before, nil); err != nil || len(changes) != 3 || changes[1].After != nil {
+		t.Errorf("Diff(before, nil) = %v, %v, want every field removed", changes, err)
+	}
+}
+
+// sealedLog returns n entries sealed one after the other, as a log appends them.
+func sealedLog(t *testing.T, n int) []*audit.Entry {
+	t.Helper()
+	var entries []*audit.Entry
+	previous := ""
+	for i := 1; i <= n; i++ {
+		e, err := audit.NewEntry(audit.Source{Actor: "alice", Endpoint: "POST /portfolio/cash/deposit"}, audit.Portfolio, "p1", i,
+			&account{Balances: map[string]int64{"USD": int64(i - 1)}}, &account{Balances: map[string]int64{"USD": int64(i)}},
+			time.Date(2024, 6, 3, 9, i, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("NewEntry() error = %v", err)
+		}
+		if err := e.Seal(i, previous); err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		previous = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestVerify(t *testing.T) {
+	if err := audit.Verify(sealedLog(t, 3)); err != nil {
+		t.Errorf("Verify(untouched) error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(entries []*audit.Entry) []*audit.Entry
+		at     int
+	}{
+		{"changed actor", func(entries []*audit.Entry) []*audit.Entry {
+			entries[1].Actor = "mallory"
+			return entries
+		}, 2},
+		{"changed diff", func(entries []*audit.Entry) []*audit.Entry {
+			entries[0].Changes[0].After = json.Number("1000")
+			return entries
+		}, 1},
+		{"resealed entry", func(entries []*audit.Entry) []*audit.Entry {
+			entries[1].Actor = "mallory"
+			entries[1].Seal(2, entries[0].Hash)
+			return entries
+		}, 3},
+		{"removed entry", func(entries []*audit.Entry) []*audit.Entry {
+			return append(entries[:1], entries[2:]...)
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := audit.Verify(tt.tamper(sealedLog(t, 3)))
+			var tampered *audit.TamperedError
+			if !errors.As(err, &tampered) || tampered.Sequence != tt.at || !errors.Is(err, audit.ErrTampered) {
+				t.Errorf("Verify() error = %v, want tampering detected at entry %d", err, tt.at)
+			}
+		})
+	}
+}
+
+func TestDecodeEntry(t *testing.T) {
+	entries := sealedLog(t, 2)
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		decoded, err := audit.DecodeEntry(data)
+		if err != nil {
+			t.Fatalf("DecodeEntry() error = %v", err)
+		}
+		*e = *decoded
+	}
+	if err := audit.Verify(entries); err != nil {
+		t.Errorf("Verify(decoded) error = %v, want the hashes to survive storage", err)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	e := sealedLog(t, 1)[0] // Recorded at 09:01
+	tests := []struct {
+		filter audit.Filter
+		want   bool
+	}{
+		{audit.Filter{}, true},
+		{audit.Filter{AggregateType: audit.Portfolio, AggregateID: "p1", Actor: "alice"}, true},
+		{audit.Filter{AggregateType: audit.Company}, false},
+		{audit.Filter{AggregateID: "p2"}, false},
+		{audit.Filter{Actor: "bob"}, false},
+		{audit.Filter{From: e.RecordedAt, To: e.RecordedAt}, true},
+		{audit.Filter{From: e.RecordedAt.Add(time.Second)}, false},
+		{audit.Filter{To: e.RecordedAt.Add(-time.Second)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(e); got != tt.want {
+			t.Errorf("%+v.Matches() = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
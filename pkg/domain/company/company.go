@@ -10,8 +10,12 @@ type Company struct {
 	Ticker           string
 	FinancialMetrics FinancialMetrics // Defined in financial_metrics.go
 	CurrentScore     float64
-	Sector           Sector // Enum defined in sector.go
+	Sector           Sector            // Enum defined in sector.go
+	Dividends        []Dividend        // Declared dividends, defined in dividend.go
+	CorporateActions []CorporateAction // Splits, spinoffs and ticker changes, defined in corporate_action.go
+	MetricsRevisions []MetricsRevision // Every revision of the metrics in the order they became known, defined in metrics_revision.go
 	UpdatedAt        time.Time
+	Version          int // Number of times the company has been saved; repositories use it to reject stale writes
 }
 
 // NewCompany creates a new Company instance.
@@ -21,13 +25,30 @@ func NewCompany(ticker string, metrics FinancialMetrics, sector Sector) (*Compan
 	if ticker == "" {
 		return nil, Errors.New("ticker cannot be empty")
 	}
-	return &Company{
+	now := time.Now()
+	c := &Company{
 		Ticker:           ticker,
 		FinancialMetrics: metrics,
 		Sector:           sector,
 		CurrentScore:     0, // Initial score, will be calculated
-		UpdatedAt:        time.Now(),
-	}, nil
+		UpdatedAt:        now,
+	}
+	// Initial metrics, if any, are the first revision: those of the company as known now.
+	given := metrics
+	given.MetricsUpdatedAt = time.Time{}
+	if given != (FinancialMetrics{}) {
+		c.MetricsRevisions = []MetricsRevision{{PeriodEnd: now, KnownAt: now, Metrics: metrics}}
+	}
+	return c, nil
+}
+
+// Clone returns a deep copy of the company that shares no slices with the original.
+func (c *Company) Clone() *Company {
+	clone := *c
+	clone.Dividends = append([]Dividend(nil), c.Dividends...)
+	clone.CorporateActions = append([]CorporateAction(nil), c.CorporateActions...)
+	clone.MetricsRevisions = append([]MetricsRevision(nil), c.MetricsRevisions...)
+	return &clone
 }
 
 // --- Invariant Enforcement Methods (Placeholders) ---
@@ -72,9 +93,8 @@ func (c *Company) RefreshStaleMetrics() error {
 // RecalculateScoreOnMetricUpdate recalculates the CurrentScore when financial metrics change.
 // This is another corrective policy, often triggered after metrics are updated.
 func (c *Company) RecalculateScoreOnMetricUpdate() error {
-	// Placeholder: Implement logic to recalculate CurrentScore based on FinancialMetrics.
 	// oldScore := c.CurrentScore
-	// c.CurrentScore = calculateNewScore(c.FinancialMetrics)
+	c.CurrentScore = calculateScore(c.FinancialMetrics, c.CurrentScore)
 	c.UpdatedAt = time.Now()
 	// if oldScore != c.CurrentScore {
 	// Publish ScoreRecalculatedEvent
@@ -82,12 +102,18 @@ func (c *Company) RecalculateScoreOnMetricUpdate() error {
 	return nil
 }
 
+// calculateScore returns the value score of a company with the given metrics whose score was
+// previous. Placeholder: scoring is not implemented yet, so the score is kept. It is a variable
+// so that tests can stand in a score that changes.
+var calculateScore = func(metrics FinancialMetrics, previous float64) float64 {
+	return previous
+}
+
 // UpdateFinancialMetrics updates the company's financial metrics and triggers a score recalculation.
+// The metrics are recorded as a revision describing, and known, now.
 func (c *Company) UpdateFinancialMetrics(newMetrics FinancialMetrics) error {
-	c.FinancialMetrics = newMetrics
-	c.FinancialMetrics.MetricsUpdatedAt = time.Now() // Ensure this is set
-	c.UpdatedAt = time.Now()
-	return c.RecalculateScoreOnMetricUpdate()
+	now := time.Now()
+	return c.ReviseFinancialMetrics(newMetrics, now, now)
 }
 
 // --- Domain Event Types (Placeholders) ---
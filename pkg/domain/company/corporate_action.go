@@ -0,0 +1,152 @@
+package company
+
+import (
+	"fmt"
+	"time"
+)
+
+// CorporateActionType classifies a corporate action.
+type CorporateActionType int
+
+// Defines the supported corporate actions.
+const (
+	UndefinedCorporateAction CorporateActionType = iota // Default or unknown action
+	Split                                               // More shares for every share held (e.g., 4-for-1)
+	ReverseSplit                                        // Fewer shares for every share held (e.g., 1-for-10)
+	Spinoff                                             // Shares of a new company distributed to holders of the parent
+	TickerChange                                        // The company starts trading under a new ticker
+)
+
+// String returns the string representation of a CorporateActionType.
+func (t CorporateActionType) String() string {
+	switch t {
+	case Split:
+		return "Split"
+	case ReverseSplit:
+		return "ReverseSplit"
+	case Spinoff:
+		return "Spinoff"
+	case TickerChange:
+		return "TickerChange"
+	default:
+		return "UndefinedCorporateAction"
+	}
+}
+
+// ParseCorporateActionType converts a string (as returned by String) to a CorporateActionType.
+func ParseCorporateActionType(s string) (CorporateActionType, error) {
+	switch s {
+	case "Split":
+		return Split, nil
+	case "ReverseSplit":
+		return ReverseSplit, nil
+	case "Spinoff":
+		return Spinoff, nil
+	case "TickerChange":
+		return TickerChange, nil
+	default:
+		return UndefinedCorporateAction, Errors.New("invalid corporate action type: " + s)
+	}
+}
+
+// CorporateAction is an event initiated by a company that changes the shares its holders own.
+// Ratios read "RatioTo new shares for every RatioFrom shares held": a 4-for-1 split is
+// RatioTo=4, RatioFrom=1 and a 1-for-10 reverse split is RatioTo=1, RatioFrom=10. For a
+// spinoff the ratio gives the shares of NewTicker received per parent share.
+// This is a value object.
+type CorporateAction struct {
+	Type            CorporateActionType
+	Ticker          string    // Ticker of the company before the action
+	EffectiveDate   time.Time // Day the action takes effect
+	RatioTo         int
+	RatioFrom       int
+	NewTicker       string  // Spinoff: the spun-off company; TickerChange: the new ticker
+	BasisAllocation float64 // Spinoff: fraction of the parent's cost basis allocated to the new company (0 < f < 1)
+	RecordedAt      time.Time
+}
+
+// ID identifies a corporate action by company, effective date and type.
+func (a CorporateAction) ID() string {
+	return fmt.Sprintf("%s@%s#%s", a.Ticker, a.EffectiveDate.Format("2006-01-02"), a.Type)
+}
+
+// Ratio returns the number of new shares per share held.
+func (a CorporateAction) Ratio() float64 {
+	return float64(a.RatioTo) / float64(a.RatioFrom)
+}
+
+// Validate checks that the action is complete and consistent for its type.
+func (a CorporateAction) Validate() error {
+	if a.Ticker == "" {
+		return Errors.New("corporate action ticker cannot be empty")
+	}
+	if a.EffectiveDate.IsZero() {
+		return Errors.New("corporate action effective date is required")
+	}
+	switch a.Type {
+	case Split, ReverseSplit, Spinoff:
+		if a.RatioTo <= 0 || a.RatioFrom <= 0 {
+			return Errors.New("corporate action ratio must be positive")
+		}
+	}
+	switch a.Type {
+	case Split:
+		if a.RatioTo <= a.RatioFrom {
+			return Errors.New("a split must increase the number of shares")
+		}
+	case ReverseSplit:
+		if a.RatioTo >= a.RatioFrom {
+			return Errors.New("a reverse split must decrease the number of shares")
+		}
+	case Spinoff:
+		if a.NewTicker == "" || a.NewTicker == a.Ticker {
+			return Errors.New("a spinoff needs a new ticker different from the parent's")
+		}
+		if a.BasisAllocation <= 0 || a.BasisAllocation >= 1 {
+			return Errors.New("spinoff basis allocation must be between 0 and 1 (exclusive)")
+		}
+	case TickerChange:
+		if a.NewTicker == "" || a.NewTicker == a.Ticker {
+			return Errors.New("a ticker change needs a new ticker different from the current one")
+		}
+	default:
+		return Errors.New("corporate action type must be Split, ReverseSplit, Spinoff or TickerChange")
+	}
+	return nil
+}
+
+// RecordCorporateAction records a corporate action taken by the company. A ticker change
+// renames the company; dividends keep the ticker they were declared under.
+// An action can be recorded only once.
+func (c *Company) RecordCorporateAction(a CorporateAction) (CorporateAction, error) {
+	if err := a.Validate(); err != nil {
+		return CorporateAction{}, err
+	}
+	if a.Ticker != c.Ticker {
+		return CorporateAction{}, Errors.New("corporate action ticker does not match company " + c.Ticker)
+	}
+	for _, existing := range c.CorporateActions {
+		if existing.ID() == a.ID() {
+			return CorporateAction{}, Errors.New("corporate action " + a.ID() + " was already recorded")
+		}
+	}
+
+	a.RecordedAt = time.Now()
+	c.CorporateActions = append(c.CorporateActions, a)
+	if a.Type == TickerChange {
+		c.Ticker = a.NewTicker
+	}
+	c.UpdatedAt = time.Now()
+	return a, nil
+}
+
+// CorporateActionRecordedEvent indicates that a company has taken a corporate action.
+type CorporateActionRecordedEvent struct {
+	Action    CorporateAction
+	Timestamp time.Time
+}
+
+// NewCorporateActionRecordedEvent creates a new CorporateActionRecordedEvent.
+func NewCorporateActionRecordedEvent(a CorporateAction) CorporateActionRecordedEvent {
+	return CorporateActionRecordedEvent{Action: a, Timestamp: time.Now()}
+}
@@ -0,0 +1,54 @@
+package company_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+)
+
+func TestCorporateAction_Validate(t *testing.T) {
+	on := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		action  company.CorporateAction
+		wantErr bool
+	}{
+		{"ValidSplit", company.CorporateAction{Type: company.Split, Ticker: "NVDA", EffectiveDate: on, RatioTo: 10, RatioFrom: 1}, false},
+		{"SplitThatReducesShares", company.CorporateAction{Type: company.Split, Ticker: "NVDA", EffectiveDate: on, RatioTo: 1, RatioFrom: 10}, true},
+		{"ValidReverseSplit", company.CorporateAction{Type: company.ReverseSplit, Ticker: "GE", EffectiveDate: on, RatioTo: 1, RatioFrom: 8}, false},
+		{"ZeroRatio", company.CorporateAction{Type: company.ReverseSplit, Ticker: "GE", EffectiveDate: on, RatioTo: 0, RatioFrom: 8}, true},
+		{"ValidSpinoff", company.CorporateAction{Type: company.Spinoff, Ticker: "GE", EffectiveDate: on, RatioTo: 1, RatioFrom: 4, NewTicker: "GEV", BasisAllocation: 0.3}, false},
+		{"SpinoffWithoutBasisAllocation", company.CorporateAction{Type: company.Spinoff, Ticker: "GE", EffectiveDate: on, RatioTo: 1, RatioFrom: 4, NewTicker: "GEV"}, true},
+		{"TickerChangeToSameTicker", company.CorporateAction{Type: company.TickerChange, Ticker: "FB", EffectiveDate: on, NewTicker: "FB"}, true},
+		{"MissingEffectiveDate", company.CorporateAction{Type: company.TickerChange, Ticker: "FB", NewTicker: "META"}, true},
+		{"UndefinedType", company.CorporateAction{Ticker: "FB", EffectiveDate: on}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.action.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompany_RecordCorporateAction(t *testing.T) {
+	c, _ := company.NewCompany("FB", company.FinancialMetrics{}, company.Technology)
+	exDate := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	d, _ := c.DeclareDividend(exDate, exDate.AddDate(0, 0, 7), 50, "USD")
+
+	rename := company.CorporateAction{Type: company.TickerChange, Ticker: "FB", EffectiveDate: time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC), NewTicker: "META"}
+	if _, err := c.RecordCorporateAction(rename); err != nil {
+		t.Fatalf("RecordCorporateAction() error = %v, wantErr nil", err)
+	}
+	if c.Ticker != "META" || len(c.CorporateActions) != 1 {
+		t.Errorf("Company = %s with %d actions, want META with 1", c.Ticker, len(c.CorporateActions))
+	}
+	if c.Dividends[0].ID() != d.ID() || d.ID() != "FB@2022-05-01" {
+		t.Errorf("Dividend ID = %s, want it to keep the declared ticker FB", c.Dividends[0].ID())
+	}
+	if _, err := c.RecordCorporateAction(rename); err == nil {
+		t.Error("RecordCorporateAction() for the old ticker after the rename expected error, got nil")
+	}
+}
@@ -0,0 +1,114 @@
+package company
+
+import (
+	"time"
+)
+
+// Dividend is a cash dividend declared by a company.
+// Shareholders of record before the ex-date are entitled to it, and it is paid on the pay date.
+// This is a value object.
+type Dividend struct {
+	Ticker         string    // Ticker the dividend was declared under (kept if the company is later renamed)
+	ExDate         time.Time // First trading day on which buyers are no longer entitled to the dividend
+	PayDate        time.Time // Day the dividend is paid to eligible shareholders
+	AmountPerShare int64     // Gross amount per share in the smallest currency unit
+	Currency       string    // Currency the dividend is paid in (e.g., "USD")
+	DeclaredAt     time.Time // When the declaration was recorded
+}
+
+// ID identifies a dividend by its company and ex-date, which is unique per company.
+func (d Dividend) ID() string {
+	return d.Ticker + "@" + d.ExDate.Format("2006-01-02")
+}
+
+// DeclareDividend records a new dividend declaration for the company.
+// A company can declare at most one dividend per ex-date.
+func (c *Company) DeclareDividend(exDate, payDate time.Time, amountPerShare int64, currency string) (Dividend, error) {
+	if exDate.IsZero() || payDate.IsZero() {
+		return Dividend{}, Errors.New("dividend ex-date and pay date are required")
+	}
+	if payDate.Before(exDate) {
+		return Dividend{}, Errors.New("dividend pay date cannot be before the ex-date")
+	}
+	if amountPerShare <= 0 {
+		return Dividend{}, Errors.New("dividend amount per share must be positive")
+	}
+	if currency == "" {
+		return Dividend{}, Errors.New("dividend currency cannot be empty")
+	}
+	for _, d := range c.Dividends {
+		if sameDay(d.ExDate, exDate) {
+			return Dividend{}, Errors.New("a dividend with this ex-date was already declared")
+		}
+	}
+
+	d := Dividend{
+		Ticker:         c.Ticker,
+		ExDate:         exDate,
+		PayDate:        payDate,
+		AmountPerShare: amountPerShare,
+		Currency:       currency,
+		DeclaredAt:     time.Now(),
+	}
+	c.Dividends = append(c.Dividends, d)
+	c.UpdatedAt = time.Now()
+	return d, nil
+}
+
+// DividendsPayableBy returns the declared dividends whose pay date is on or before asOf.
+func (c *Company) DividendsPayableBy(asOf time.Time) []Dividend {
+	var payable []Dividend
+	for _, d := range c.Dividends {
+		if !d.PayDate.After(asOf) {
+			payable = append(payable, d)
+		}
+	}
+	return payable
+}
+
+// TrailingAnnualDividend sums the dividends per share that went ex in the twelve months up
+// to asOf, in the currency of the most recent one. Dividends in other currencies are ignored.
+func (c *Company) TrailingAnnualDividend(asOf time.Time) (int64, string) {
+	from := asOf.AddDate(-1, 0, 0)
+	var total int64
+	var currency string
+	var latest time.Time
+	for _, d := range c.Dividends {
+		if d.ExDate.After(asOf) || !d.ExDate.After(from) {
+			continue
+		}
+		if d.ExDate.After(latest) {
+			latest, currency = d.ExDate, d.Currency
+		}
+	}
+	for _, d := range c.Dividends {
+		if d.ExDate.After(asOf) || !d.ExDate.After(from) || d.Currency != currency {
+			continue
+		}
+		total += d.AmountPerShare
+	}
+	return total, currency
+}
+
+// sameDay reports whether two times fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// DividendDeclaredEvent indicates that a company has declared a dividend.
+type DividendDeclaredEvent struct {
+	Ticker    string
+	Dividend  Dividend
+	Timestamp time.Time
+}
+
+// NewDividendDeclaredEvent creates a new DividendDeclaredEvent.
+func NewDividendDeclaredEvent(ticker string, d Dividend) DividendDeclaredEvent {
+	return DividendDeclaredEvent{
+		Ticker:    ticker,
+		Dividend:  d,
+		Timestamp: time.Now(),
+	}
+}
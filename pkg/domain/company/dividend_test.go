@@ -0,0 +1,56 @@
+package company_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+)
+
+func TestCompany_DeclareDividend(t *testing.T) {
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	exDate := time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC)
+	payDate := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	d, err := c.DeclareDividend(exDate, payDate, 24, "USD")
+	if err != nil {
+		t.Fatalf("DeclareDividend() error = %v, wantErr nil", err)
+	}
+	if d.ID() != "AAPL@2024-02-09" || len(c.Dividends) != 1 {
+		t.Errorf("DeclareDividend() = %+v, dividends = %d; want AAPL@2024-02-09 recorded once", d, len(c.Dividends))
+	}
+
+	if _, err := c.DeclareDividend(exDate, payDate, 30, "USD"); err == nil {
+		t.Error("DeclareDividend() with a duplicate ex-date expected error, got nil")
+	}
+	if _, err := c.DeclareDividend(payDate, exDate, 24, "USD"); err == nil {
+		t.Error("DeclareDividend() with pay date before ex-date expected error, got nil")
+	}
+	if _, err := c.DeclareDividend(exDate.AddDate(0, 3, 0), payDate.AddDate(0, 3, 0), 0, "USD"); err == nil {
+		t.Error("DeclareDividend() with zero amount expected error, got nil")
+	}
+
+	if got := c.DividendsPayableBy(payDate.Add(-time.Hour)); len(got) != 0 {
+		t.Errorf("DividendsPayableBy(before pay date) = %v, want none", got)
+	}
+	if got := c.DividendsPayableBy(payDate); len(got) != 1 {
+		t.Errorf("DividendsPayableBy(pay date) = %v, want one", got)
+	}
+}
+
+func TestCompany_TrailingAnnualDividend(t *testing.T) {
+	c, _ := company.NewCompany("KO", company.FinancialMetrics{}, company.ConsumerStaples)
+	base := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for q := 0; q < 5; q++ {
+		exDate := base.AddDate(0, 3*q, 0)
+		if _, err := c.DeclareDividend(exDate, exDate.AddDate(0, 0, 14), 46, "USD"); err != nil {
+			t.Fatalf("DeclareDividend() error = %v", err)
+		}
+	}
+
+	// As of 2025-01-20 the last four quarterly dividends went ex within the past twelve months.
+	amount, currency := c.TrailingAnnualDividend(time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC))
+	if amount != 184 || currency != "USD" {
+		t.Errorf("TrailingAnnualDividend() = %d %s, want 184 USD", amount, currency)
+	}
+}
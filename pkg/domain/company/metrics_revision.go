@@ -0,0 +1,166 @@
+package company
+
+import (
+	"fmt"
+	"time"
+)
+
+// MetricsRevision is one version of a company's financial metrics: the figures of a reporting
+// period as they became known at a point in time. A restatement adds a revision of the same
+// period instead of replacing the earlier one, so that the metrics can be read as they were
+// known on any past date, free of the look-ahead of later corrections.
+// This is a value object.
+type MetricsRevision struct {
+	PeriodEnd time.Time // End of the reporting period the metrics describe
+	KnownAt   time.Time // When the metrics became known
+	Metrics   FinancialMetrics
+	Score     float64 // Value score of the company as known at KnownAt
+}
+
+// ErrMetricsNotKnown is matched (via errors.Is) by the error of AsKnownAt for a time before
+// any of the company's metrics became known.
+var ErrMetricsNotKnown = Errors.New("no financial metrics known")
+
+// ReviseFinancialMetrics records the metrics of the reporting period ending at periodEnd as
+// they became known at knownAt. Revisions may be recorded late, to load the history of a
+// company: they take their place in the order the metrics became known. The company's
+// FinancialMetrics become those of its latest period as last revised. Each revision is scored
+// from the metrics of the latest period as known then and the score of the revision before it,
+// so a revision recorded late has it and every later revision scored again; the company's score
+// is that of its last known revision.
+func (c *Company) ReviseFinancialMetrics(metrics FinancialMetrics, periodEnd, knownAt time.Time) error {
+	if periodEnd.IsZero() || knownAt.IsZero() {
+		return Errors.New("a metrics revision needs the end of its period and when it became known")
+	}
+	if periodEnd.After(knownAt) {
+		return Errors.New("metrics cannot be known before the end of the period they describe")
+	}
+	metrics.MetricsUpdatedAt = knownAt
+	revision := MetricsRevision{PeriodEnd: periodEnd, KnownAt: knownAt, Metrics: metrics}
+
+	// Revisions known at the same time keep the order they were recorded in.
+	i := len(c.MetricsRevisions)
+	for i > 0 && c.MetricsRevisions[i-1].KnownAt.After(knownAt) {
+		i--
+	}
+	score := c.CurrentScore // Known just before a revision recorded last
+	if i < len(c.MetricsRevisions) {
+		score = 0
+		if i > 0 {
+			score = c.MetricsRevisions[i-1].Score
+		}
+	}
+	c.MetricsRevisions = append(c.MetricsRevisions[:i], append([]MetricsRevision{revision}, c.MetricsRevisions[i:]...)...)
+	for j := i; j < len(c.MetricsRevisions); j++ {
+		score = calculateScore(currentMetrics(c.MetricsRevisions[:j+1]), score)
+		c.MetricsRevisions[j].Score = score
+	}
+	c.FinancialMetrics = currentMetrics(c.MetricsRevisions)
+	c.CurrentScore = score
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// AsKnownAt returns a copy of the company as it was known at the given time: with the metrics
+// of its latest period as revised by then, the score known then and only the revisions known
+// then. Its other data, dividends and corporate actions included, are the current ones. Before
+// any of its metrics were known it returns a nil company and an error matching
+// ErrMetricsNotKnown.
+func (c *Company) AsKnownAt(at time.Time) (*Company, error) {
+	known := 0
+	for known < len(c.MetricsRevisions) && !c.MetricsRevisions[known].KnownAt.After(at) {
+		known++
+	}
+	if known == 0 {
+		return nil, fmt.Errorf("%w for %s at %s", ErrMetricsNotKnown, c.Ticker, at.Format(time.RFC3339))
+	}
+	past := c.Clone()
+	past.MetricsRevisions = past.MetricsRevisions[:known:known]
+	past.FinancialMetrics = currentMetrics(past.MetricsRevisions)
+	past.CurrentScore = past.MetricsRevisions[known-1].Score
+	return past, nil
+}
+
+// currentMetrics returns the metrics of the latest period among revisions, as last revised.
+func currentMetrics(revisions []MetricsRevision) FinancialMetrics {
+	latest := revisions[0]
+	for _, r := range revisions[1:] {
+		if !r.PeriodEnd.Before(latest.PeriodEnd) {
+			latest = r
+		}
+	}
+	return latest.Metrics
+}
+
+// KnownAt returns a read-only view of repo in which companies are as known at the given time,
+// as AsKnownAt returns them. Companies none of whose metrics were known then are not found, and
+// searches by score go by the scores known then. Saving or deleting through the view fails.
+func KnownAt(repo CompanyRepository, at time.Time) CompanyRepository {
+	return &knownAtRepository{repo: repo, at: at}
+}
+
+// knownAtRepository is the view of a CompanyRepository returned by KnownAt.
+type knownAtRepository struct {
+	repo CompanyRepository
+	at   time.Time
+}
+
+// FindByTicker retrieves a company as known at the time of the view.
+func (r *knownAtRepository) FindByTicker(ticker string) (*Company, error) {
+	c, err := r.repo.FindByTicker(ticker)
+	if err != nil {
+		return nil, err
+	}
+	past, err := c.AsKnownAt(r.at)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCompanyNotFound, err)
+	}
+	return past, nil
+}
+
+// SearchByScoreRange retrieves the companies whose score known at the time of the view falls
+// within the given range, bounds included, ordered by ticker.
+func (r *knownAtRepository) SearchByScoreRange(minScore, maxScore float64) ([]*Company, error) {
+	if minScore > maxScore {
+		return nil, Errors.New("minScore cannot be greater than maxScore")
+	}
+	all, err := r.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	found := make([]*Company, 0, len(all))
+	for _, c := range all {
+		if c.CurrentScore >= minScore && c.CurrentScore <= maxScore {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+// FindAll retrieves the companies with metrics known at the time of the view, as known then,
+// ordered by ticker.
+func (r *knownAtRepository) FindAll() ([]*Company, error) {
+	all, err := r.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	known := make([]*Company, 0, len(all))
+	for _, c := range all {
+		if past, err := c.AsKnownAt(r.at); err == nil { // It fails only when nothing was known
+			known = append(known, past)
+		}
+	}
+	return known, nil
+}
+
+// Save fails: companies as known in the past cannot be changed.
+func (r *knownAtRepository) Save(c *Company) error {
+	return errPastCompany
+}
+
+// Delete fails: companies as known in the past cannot be changed.
+func (r *knownAtRepository) Delete(ticker string) error {
+	return errPastCompany
+}
+
+var errPastCompany = Errors.New("companies as known in the past cannot be changed")
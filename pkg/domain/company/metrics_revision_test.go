@@ -0,0 +1,129 @@
+package company_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+)
+
+func date(month time.Month, d int) time.Time {
+	return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// revisedCompany returns a company whose Q1 P/E of 12 was restated to 18 in June, recorded
+// late, after Q2's P/E of 20 reported in July, and before Q3's became known in October.
+func revisedCompany(t *testing.T) *company.Company {
+	t.Helper()
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	c.CurrentScore = 60
+	revise := func(pe float64, periodEnd, knownAt time.Time) {
+		t.Helper()
+		if err := c.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: pe}, periodEnd, knownAt); err != nil {
+			t.Fatalf("ReviseFinancialMetrics(%v) error = %v", pe, err)
+		}
+	}
+	revise(12, date(3, 31), date(4, 25))
+	revise(20, date(6, 30), date(7, 25))
+	revise(18, date(3, 31), date(6, 10)) // The restatement, recorded late
+	c.CurrentScore = 70
+	revise(25, date(9, 30), date(10, 24))
+	return c
+}
+
+func TestCompany_ReviseFinancialMetrics(t *testing.T) {
+	c := revisedCompany(t)
+
+	if len(c.MetricsRevisions) != 4 || c.MetricsRevisions[1].PeriodEnd != date(3, 31) || c.MetricsRevisions[1].Metrics.PERatio != 18 {
+		t.Fatalf("MetricsRevisions = %+v, want the restatement second, in the order they became known", c.MetricsRevisions)
+	}
+	// The restatement, and Q2 after it, are scored from the score known before it; Q3 from the
+	// score when it was recorded.
+	if scores := []float64{c.MetricsRevisions[0].Score, c.MetricsRevisions[1].Score, c.MetricsRevisions[3].Score}; scores[0] != 60 || scores[1] != 60 || scores[2] != 70 {
+		t.Errorf("revision scores = %v, want [60 60 70]", scores)
+	}
+	if c.FinancialMetrics.PERatio != 25 || !c.FinancialMetrics.MetricsUpdatedAt.Equal(date(10, 24)) {
+		t.Errorf("FinancialMetrics = %+v, want Q3's as known in October", c.FinancialMetrics)
+	}
+
+	if err := c.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 30}, date(12, 31), date(12, 1)); err == nil {
+		t.Error("ReviseFinancialMetrics() known before its period ended expected error, got nil")
+	}
+	if err := c.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 30}, time.Time{}, date(12, 1)); err == nil {
+		t.Error("ReviseFinancialMetrics() without a period expected error, got nil")
+	}
+}
+
+func TestCompany_ReviseFinancialMetrics_LateLatestPeriod(t *testing.T) {
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+	c.CurrentScore = 50
+	if err := c.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 12}, date(1, 31), date(2, 15)); err != nil {
+		t.Fatalf("ReviseFinancialMetrics() error = %v", err)
+	}
+	if err := c.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 14}, date(1, 31), date(5, 10)); err != nil {
+		t.Fatalf("ReviseFinancialMetrics() error = %v", err)
+	}
+	c.CurrentScore = 80 // Scored from the January restatement
+
+	// March's metrics, known in April and recorded late, are the latest period's from then on:
+	// the May restatement of January no longer changes them.
+	if err := c.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: 20}, date(3, 31), date(4, 20)); err != nil {
+		t.Fatalf("ReviseFinancialMetrics() error = %v", err)
+	}
+	if c.FinancialMetrics.PERatio != 20 {
+		t.Errorf("FinancialMetrics = %+v, want March's", c.FinancialMetrics)
+	}
+	if scores := []float64{c.MetricsRevisions[0].Score, c.MetricsRevisions[1].Score, c.MetricsRevisions[2].Score}; scores[0] != 50 || scores[1] != 50 || scores[2] != 50 {
+		t.Errorf("revision scores = %v, want [50 50 50], scored again from March's metrics", scores)
+	}
+	if c.CurrentScore != 50 {
+		t.Errorf("CurrentScore = %v, want 50, the score of the last known revision", c.CurrentScore)
+	}
+}
+
+func TestCompany_AsKnownAt(t *testing.T) {
+	c := revisedCompany(t)
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		pe    float64
+		score float64
+		known int
+	}{
+		{"first report", date(5, 1), 12, 60, 1},
+		{"after the restatement", date(6, 10), 18, 60, 2},
+		{"after Q2", date(7, 25), 20, 60, 3},
+		{"now", date(12, 31), 25, 70, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			past, err := c.AsKnownAt(tt.at)
+			if err != nil {
+				t.Fatalf("AsKnownAt() error = %v", err)
+			}
+			if past.FinancialMetrics.PERatio != tt.pe || past.CurrentScore != tt.score || len(past.MetricsRevisions) != tt.known {
+				t.Errorf("AsKnownAt() = P/E %v, score %v, %d revisions; want %v, %v, %d",
+					past.FinancialMetrics.PERatio, past.CurrentScore, len(past.MetricsRevisions), tt.pe, tt.score, tt.known)
+			}
+		})
+	}
+
+	if _, err := c.AsKnownAt(date(4, 24)); !errors.Is(err, company.ErrMetricsNotKnown) {
+		t.Errorf("AsKnownAt(before the first report) error = %v, want ErrMetricsNotKnown", err)
+	}
+	if past, _ := c.AsKnownAt(date(5, 1)); c.FinancialMetrics.PERatio != 25 || len(c.MetricsRevisions) != 4 || past.Ticker != "AAPL" {
+		t.Errorf("AsKnownAt() changed the company: %+v", c)
+	}
+}
+
+func TestNewCompany_RecordsInitialMetrics(t *testing.T) {
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	if len(c.MetricsRevisions) != 1 || c.MetricsRevisions[0].Metrics.PERatio != 15 || !c.MetricsRevisions[0].KnownAt.Equal(c.UpdatedAt) {
+		t.Errorf("MetricsRevisions = %+v, want the initial metrics as known on creation", c.MetricsRevisions)
+	}
+	if empty, _ := company.NewCompany("MSFT", company.FinancialMetrics{}, company.Technology); len(empty.MetricsRevisions) != 0 {
+		t.Errorf("MetricsRevisions without metrics = %+v, want none", empty.MetricsRevisions)
+	}
+}
@@ -1,25 +1,61 @@
 package company
 
+import "fmt"
+
+// ErrCompanyNotFound is returned, possibly wrapped, by repositories asked for a company they
+// do not have.
+var ErrCompanyNotFound = Errors.New("company not found")
+
+// ErrVersionConflict is matched (via errors.Is) by every VersionConflictError.
+var ErrVersionConflict = Errors.New("company version conflict")
+
+// VersionConflictError is returned by Save when the stored company is not the version the one
+// being saved was loaded from: someone else saved it, or deleted it, in the meantime.
+type VersionConflictError struct {
+	Ticker         string
+	Version        int // Version of the company being saved
+	CurrentVersion int // Version stored, 0 when there is none
+}
+
+// Error returns the error message string.
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("company %s was changed concurrently: saving version %d, stored version is %d",
+		e.Ticker, e.Version, e.CurrentVersion)
+}
+
+// Is makes errors.Is(err, ErrVersionConflict) match any VersionConflictError.
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
 // CompanyRepository defines the interface for accessing and persisting Company aggregates.
 // Implementations will handle the underlying data storage (e.g., in-memory, database).
+// Companies passed in and returned are copies: changing one has no effect on the repository
+// until it is saved. Searches return an empty, non-nil slice when nothing matches.
 type CompanyRepository interface {
-	// FindByTicker retrieves a company by its stock ticker.
+	// FindByTicker retrieves a company by its stock ticker. When there is none, it returns a
+	// nil company and an error matching ErrCompanyNotFound.
 	FindByTicker(ticker string) (*Company, error)
 
-	// SearchByScoreRange retrieves companies whose current value score falls within the given range.
+	// SearchByScoreRange retrieves companies whose current value score falls within the given
+	// range, bounds included, ordered by ticker. It fails when minScore is above maxScore.
 	SearchByScoreRange(minScore, maxScore float64) ([]*Company, error)
 
 	// Save creates or updates a company in the repository.
 	// If the company with the given ticker already exists, it should be updated.
 	// Otherwise, a new company entry should be created.
+	// The company's Version must be the stored one (0 to create it); otherwise Save fails with
+	// a *VersionConflictError and stores nothing. On success the Version is incremented.
 	Save(company *Company) error
 
 	// Delete removes a company from the repository by its ticker.
 	// This method is optional for the initial MVP but good to define.
+	// Deleting a company that is not there fails with an error matching ErrCompanyNotFound.
 	Delete(ticker string) error
 
-	// FindAll (Optional) retrieves all companies. Useful for some scenarios.
-	// FindAll() ([]*Company, error)
+	// FindAll retrieves all companies (e.g., to process dividends for every company), ordered
+	// by ticker.
+	FindAll() ([]*Company, error)
 
 	// FindBySector (Optional) retrieves companies belonging to a specific sector.
 	// FindBySector(sector Sector) ([]*Company, error)
@@ -0,0 +1,46 @@
+package company
+
+import (
+	"testing"
+	"time"
+)
+
+// scoreByPERatio stands in for calculateScore until the test ends with a score that adds the
+// P/E ratio to the previous one, so that every revision's score depends on those before it.
+func scoreByPERatio(t *testing.T) {
+	t.Helper()
+	calculate := calculateScore
+	calculateScore = func(metrics FinancialMetrics, previous float64) float64 { return previous + metrics.PERatio }
+	t.Cleanup(func() { calculateScore = calculate })
+}
+
+func TestCompany_ReviseFinancialMetrics_Scores(t *testing.T) {
+	scoreByPERatio(t)
+	date := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	c, _ := NewCompany("AAPL", FinancialMetrics{}, Technology)
+	revise := func(pe float64, periodEnd, knownAt time.Time) {
+		t.Helper()
+		if err := c.ReviseFinancialMetrics(FinancialMetrics{PERatio: pe}, periodEnd, knownAt); err != nil {
+			t.Fatalf("ReviseFinancialMetrics(%v) error = %v", pe, err)
+		}
+	}
+	revise(10, date(3, 31), date(4, 25))
+	revise(20, date(6, 30), date(7, 25))
+	revise(22, date(6, 30), date(8, 10)) // Q2 restated
+	if c.CurrentScore != 52 {
+		t.Fatalf("CurrentScore = %v, want 52 (10, then 20, then 22)", c.CurrentScore)
+	}
+
+	// Q1 restated in June, recorded late: Q2 is still the latest period from July on, but its
+	// revisions follow a changed score.
+	revise(12, date(3, 31), date(6, 10))
+	want := []float64{10, 22, 42, 64}
+	for i, r := range c.MetricsRevisions {
+		if r.Score != want[i] {
+			t.Errorf("revision %d score = %v, want %v", i, r.Score, want[i])
+		}
+	}
+	if c.CurrentScore != 64 || c.FinancialMetrics.PERatio != 22 {
+		t.Errorf("CurrentScore = %v, P/E %v; want 64 from Q2's restatement, P/E 22", c.CurrentScore, c.FinancialMetrics.PERatio)
+	}
+}
@@ -0,0 +1,321 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CashSegment is the attribution segment holding the portfolio's cash.
+const CashSegment = "Cash"
+
+// weightTolerance is how far the weights of a basket may sum from 1.
+const weightTolerance = 1e-6
+
+// BenchmarkConstituent is one ticker of a benchmark basket and its fixed weight.
+// This is a value object.
+type BenchmarkConstituent struct {
+	Ticker string
+	Weight float64 // Fraction of the benchmark, in (0, 1]
+}
+
+// Benchmark is what a portfolio's performance is measured against: an index proxy ticker, or a
+// custom basket of tickers with fixed weights rebalanced daily.
+// This is a value object.
+type Benchmark struct {
+	Name         string
+	Constituents []BenchmarkConstituent
+}
+
+// TickerBenchmark returns a benchmark tracking a single index proxy ticker (e.g., "SPY").
+func TickerBenchmark(ticker string) Benchmark {
+	return Benchmark{Name: ticker, Constituents: []BenchmarkConstituent{{Ticker: ticker, Weight: 1}}}
+}
+
+// IsZero reports whether no benchmark is set.
+func (b Benchmark) IsZero() bool {
+	return b.Name == "" && len(b.Constituents) == 0
+}
+
+// Validate checks that the benchmark is named and that its constituents are distinct tickers
+// with positive weights summing to 1.
+func (b Benchmark) Validate() error {
+	if strings.TrimSpace(b.Name) == "" {
+		return Errors.New("benchmark name cannot be empty")
+	}
+	if len(b.Constituents) == 0 {
+		return Errors.New("benchmark must have at least one constituent")
+	}
+	seen := make(map[string]bool, len(b.Constituents))
+	total := 0.0
+	for _, c := range b.Constituents {
+		if c.Ticker == "" {
+			return Errors.New("benchmark constituent ticker cannot be empty")
+		}
+		if seen[c.Ticker] {
+			return fmt.Errorf("benchmark constituent %s is listed more than once", c.Ticker)
+		}
+		seen[c.Ticker] = true
+		if c.Weight <= 0 || c.Weight > 1 {
+			return fmt.Errorf("benchmark weight of %s must be in (0, 1], got %v", c.Ticker, c.Weight)
+		}
+		total += c.Weight
+	}
+	if math.Abs(total-1) > weightTolerance {
+		return fmt.Errorf("benchmark weights must sum to 1, got %v", total)
+	}
+	return nil
+}
+
+// SetBenchmark designates the benchmark the portfolio is compared against. A zero Benchmark
+// clears it, falling back to the default benchmark.
+func (p *Portfolio) SetBenchmark(b Benchmark) error {
+	if !b.IsZero() {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		b.Constituents = append([]BenchmarkConstituent(nil), b.Constituents...)
+	}
+	p.Benchmark = b
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// SectorAttribution is the Brinson-Fachler attribution of one segment: a sector, the cash
+// (earning nothing, so its allocation is the cash drag), or the Unclassified holdings.
+// Allocation rewards over-weighting segments that beat the benchmark, selection rewards picking
+// segment members that beat the benchmark's.
+// This is a value object.
+type SectorAttribution struct {
+	Sector          string
+	PortfolioWeight float64
+	BenchmarkWeight float64
+	PortfolioReturn float64
+	BenchmarkReturn float64
+	Allocation      float64 // (PortfolioWeight − BenchmarkWeight) × (BenchmarkReturn − benchmark total return)
+	Selection       float64 // BenchmarkWeight × (PortfolioReturn − BenchmarkReturn)
+	Interaction     float64 // (PortfolioWeight − BenchmarkWeight) × (PortfolioReturn − BenchmarkReturn)
+}
+
+// BenchmarkComparison reports a portfolio's performance relative to its benchmark over a period.
+// Returns are chain-linked across the valuation snapshots in the period. The attribution holds
+// the holdings of the first snapshot for the whole period, so its effects add up to the active
+// return of that buy-and-hold portfolio rather than exactly to ActiveReturn.
+// This is a value object.
+type BenchmarkComparison struct {
+	PortfolioID      string
+	Benchmark        string
+	From             time.Time // Time of the first snapshot of the period
+	To               time.Time // Time of the last snapshot of the period
+	Observations     int       // Number of sub-periods compared
+	PortfolioReturn  float64   // Time-weighted return
+	BenchmarkReturn  float64
+	ActiveReturn     float64 // PortfolioReturn − BenchmarkReturn
+	TrackingError    float64 // Annualized standard deviation of the sub-period active returns
+	InformationRatio float64 // Annualized mean active return divided by the tracking error
+	Attribution      []SectorAttribution
+	Allocation       float64 // Totals of the attribution effects
+	Selection        float64
+	Interaction      float64
+}
+
+// CompareWithBenchmark compares the portfolio with a benchmark over [from, to] using its
+// valuation snapshots (oldest first): the period runs from the latest snapshot at or before from
+// (the first snapshot when from is zero) to the latest at or before to. Benchmark returns are in
+// the constituents' trading currencies. sectors maps the tickers held and the benchmark's
+// constituents to their sector; tickers without one are grouped as Unclassified.
+func (p *Portfolio) CompareWithBenchmark(history []*Valuation, benchmark Benchmark, prices PriceProvider, rates FXRateProvider, sectors map[string]string, from, to time.Time) (*BenchmarkComparison, error) {
+	if err := benchmark.Validate(); err != nil {
+		return nil, err
+	}
+	var snapshots []*Valuation
+	for _, v := range history {
+		if v.PortfolioID == p.ID && !v.AsOf.After(to) {
+			snapshots = append(snapshots, v)
+		}
+	}
+	if !from.IsZero() {
+		first := -1
+		for i, v := range snapshots {
+			if !v.AsOf.After(from) {
+				first = i
+			}
+		}
+		if first < 0 {
+			return nil, ErrInsufficientHistory
+		}
+		snapshots = snapshots[first:]
+	}
+	if len(snapshots) < 2 {
+		return nil, ErrInsufficientHistory
+	}
+	begin, end := snapshots[0], snapshots[len(snapshots)-1]
+
+	points := make([]valuePoint, len(snapshots))
+	for i, v := range snapshots {
+		points[i] = valuePoint{at: v.AsOf, value: float64(v.TotalValue.Amount)}
+	}
+	flows, err := p.baseFlows(rates, begin.AsOf, end.AsOf, false)
+	if err != nil {
+		return nil, err
+	}
+	portfolioReturns, _ := subPeriodReturns(points[0], points[1:], flows)
+
+	c := &BenchmarkComparison{PortfolioID: p.ID, Benchmark: benchmark.Name, From: begin.AsOf, To: end.AsOf, Observations: len(portfolioReturns)}
+	active := make([]float64, len(portfolioReturns))
+	pGrowth, bGrowth := 1.0, 1.0
+	for i, rp := range portfolioReturns {
+		rb, err := benchmark.periodReturn(prices, snapshots[i].AsOf, snapshots[i+1].AsOf)
+		if err != nil {
+			return nil, err
+		}
+		pGrowth *= 1 + rp
+		bGrowth *= 1 + rb
+		active[i] = rp - rb
+	}
+	c.PortfolioReturn, c.BenchmarkReturn = pGrowth-1, bGrowth-1
+	c.ActiveReturn = c.PortfolioReturn - c.BenchmarkReturn
+
+	if years := end.AsOf.Sub(begin.AsOf).Hours() / 24 / daysPerYear; len(active) > 1 && years > 0 {
+		perYear := float64(len(active)) / years
+		mean, stdev := meanStdev(active)
+		c.TrackingError = stdev * math.Sqrt(perYear)
+		if c.TrackingError > 0 {
+			c.InformationRatio = mean * perYear / c.TrackingError
+		}
+	}
+
+	if err := c.attribute(begin, benchmark, prices, rates, sectors, end.AsOf); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// periodReturn returns the benchmark's return between two times, its constituents weighted as
+// at the start.
+func (b Benchmark) periodReturn(prices PriceProvider, from, to time.Time) (float64, error) {
+	total := 0.0
+	for _, c := range b.Constituents {
+		r, err := priceReturn(prices, c.Ticker, from, to)
+		if err != nil {
+			return 0, err
+		}
+		total += c.Weight * r
+	}
+	return total, nil
+}
+
+// priceReturn returns a ticker's price return between two times.
+func priceReturn(prices PriceProvider, ticker string, from, to time.Time) (float64, error) {
+	start, err := prices.Price(ticker, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get price for %s: %w", ticker, err)
+	}
+	finish, err := prices.Price(ticker, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get price for %s: %w", ticker, err)
+	}
+	if start.Amount <= 0 {
+		return 0, fmt.Errorf("cannot compute the return of %s from a non-positive price", ticker)
+	}
+	return float64(finish.Amount)/float64(start.Amount) - 1, nil
+}
+
+// attributionSegment accumulates the weight and weighted return of one segment.
+type attributionSegment struct {
+	portfolioWeight, portfolioWeighted float64
+	benchmarkWeight, benchmarkWeighted float64
+}
+
+// benchmarkReturn returns the segment's return in the benchmark. Cash earns nothing; other
+// segments the benchmark does not hold are measured against its total return.
+func (s *attributionSegment) benchmarkReturn(name string, total float64) float64 {
+	switch {
+	case s.benchmarkWeight > 0:
+		return s.benchmarkWeighted / s.benchmarkWeight
+	case name == CashSegment:
+		return 0
+	default:
+		return total
+	}
+}
+
+// attribute fills in the Brinson-Fachler attribution of the holdings in the begin snapshot,
+// held until to, against the benchmark's constituents over the same period.
+func (c *BenchmarkComparison) attribute(begin *Valuation, benchmark Benchmark, prices PriceProvider, rates FXRateProvider, sectors map[string]string, to time.Time) error {
+	if begin.TotalValue.Amount <= 0 {
+		return nil
+	}
+	segments := make(map[string]*attributionSegment)
+	segment := func(ticker string) *attributionSegment {
+		name := sectors[ticker]
+		if name == "" {
+			name = UnclassifiedSector
+		}
+		if segments[name] == nil {
+			segments[name] = &attributionSegment{}
+		}
+		return segments[name]
+	}
+
+	total := float64(begin.TotalValue.Amount)
+	for _, h := range begin.Holdings {
+		if h.BaseMarketValue.Amount <= 0 {
+			continue
+		}
+		price, err := prices.Price(h.CompanyTicker, to)
+		if errors.Is(err, ErrPriceNotFound) {
+			price = h.Price
+		} else if err != nil {
+			return fmt.Errorf("failed to get price for %s: %w", h.CompanyTicker, err)
+		}
+		endValue, err := ConvertMoney(price.Scale(h.Shares), begin.BaseCurrency, rates, to)
+		if err != nil {
+			return fmt.Errorf("failed to value holding %s: %w", h.CompanyTicker, err)
+		}
+		weight := float64(h.BaseMarketValue.Amount) / total
+		s := segment(h.CompanyTicker)
+		s.portfolioWeight += weight
+		s.portfolioWeighted += weight * (float64(endValue.Amount)/float64(h.BaseMarketValue.Amount) - 1)
+	}
+	if begin.CashValue.Amount != 0 {
+		segments[CashSegment] = &attributionSegment{portfolioWeight: float64(begin.CashValue.Amount) / total}
+	}
+
+	benchmarkTotal := 0.0
+	for _, constituent := range benchmark.Constituents {
+		r, err := priceReturn(prices, constituent.Ticker, begin.AsOf, to)
+		if err != nil {
+			return err
+		}
+		s := segment(constituent.Ticker)
+		s.benchmarkWeight += constituent.Weight
+		s.benchmarkWeighted += constituent.Weight * r
+		benchmarkTotal += constituent.Weight * r
+	}
+
+	names := make([]string, 0, len(segments))
+	for name := range segments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := segments[name]
+		a := SectorAttribution{Sector: name, PortfolioWeight: s.portfolioWeight, BenchmarkWeight: s.benchmarkWeight, BenchmarkReturn: s.benchmarkReturn(name, benchmarkTotal)}
+		a.PortfolioReturn = a.BenchmarkReturn
+		if s.portfolioWeight > 0 {
+			a.PortfolioReturn = s.portfolioWeighted / s.portfolioWeight
+		}
+		a.Allocation = (a.PortfolioWeight - a.BenchmarkWeight) * (a.BenchmarkReturn - benchmarkTotal)
+		a.Selection = a.BenchmarkWeight * (a.PortfolioReturn - a.BenchmarkReturn)
+		a.Interaction = (a.PortfolioWeight - a.BenchmarkWeight) * (a.PortfolioReturn - a.BenchmarkReturn)
+		c.Allocation += a.Allocation
+		c.Selection += a.Selection
+		c.Interaction += a.Interaction
+		c.Attribution = append(c.Attribution, a)
+	}
+	return nil
+}
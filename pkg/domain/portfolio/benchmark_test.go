@@ -0,0 +1,115 @@
+package portfolio_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestBenchmark_Validate(t *testing.T) {
+	if err := portfolio.TickerBenchmark("SPY").Validate(); err != nil {
+		t.Errorf("TickerBenchmark Validate() error = %v", err)
+	}
+	invalid := []portfolio.Benchmark{
+		{Constituents: []portfolio.BenchmarkConstituent{{Ticker: "SPY", Weight: 1}}},
+		{Name: "Empty"},
+		{Name: "Short", Constituents: []portfolio.BenchmarkConstituent{{Ticker: "MSFT", Weight: 0.5}, {Ticker: "XOM", Weight: 0.4}}},
+		{Name: "Duplicate", Constituents: []portfolio.BenchmarkConstituent{{Ticker: "XOM", Weight: 0.5}, {Ticker: "XOM", Weight: 0.5}}},
+		{Name: "Negative", Constituents: []portfolio.BenchmarkConstituent{{Ticker: "MSFT", Weight: 1.5}, {Ticker: "XOM", Weight: -0.5}}},
+	}
+	for _, b := range invalid {
+		if err := b.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil, want an error", b)
+		}
+	}
+
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	if err := p.SetBenchmark(invalid[2]); err == nil {
+		t.Error("SetBenchmark(invalid) error = nil, want an error")
+	}
+	if err := p.SetBenchmark(portfolio.TickerBenchmark("SPY")); err != nil || p.Benchmark.Name != "SPY" {
+		t.Errorf("SetBenchmark(SPY) error = %v, Benchmark = %+v", err, p.Benchmark)
+	}
+	if err := p.SetBenchmark(portfolio.Benchmark{}); err != nil || !p.Benchmark.IsZero() {
+		t.Errorf("SetBenchmark(zero) error = %v, want the benchmark cleared", err)
+	}
+}
+
+func TestPortfolio_CompareWithBenchmark(t *testing.T) {
+	days := []time.Time{
+		time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 4, 22, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 5, 22, 0, 0, 0, time.UTC),
+	}
+	closes := map[string][]int64{
+		"AAPL": {10000, 11000, 12000},
+		"XOM":  {10000, 9000, 9000},
+		"MSFT": {10000, 10500, 11000},
+	}
+	prices := priceFunc(func(ticker string, on time.Time) (portfolio.Money, error) {
+		for i := len(days) - 1; i >= 0; i-- {
+			if !days[i].After(on) {
+				return portfolio.Money{Amount: closes[ticker][i], Currency: "USD"}, nil
+			}
+		}
+		return portfolio.Money{}, portfolio.ErrPriceNotFound
+	})
+
+	// 600.00 of AAPL, 200.00 of XOM and 200.00 of cash on the first day.
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 20000, Currency: "USD"})
+	p.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 6, PurchasePrice: portfolio.Money{Amount: 10000, Currency: "USD"}}
+	p.Holdings["XOM"] = portfolio.Position{CompanyTicker: "XOM", Shares: 2, PurchasePrice: portfolio.Money{Amount: 10000, Currency: "USD"}}
+	var history []*portfolio.Valuation
+	for _, day := range days {
+		quotes := map[string]portfolio.Money{}
+		for ticker := range p.Holdings {
+			quotes[ticker], _ = prices.Price(ticker, day)
+		}
+		v, err := p.Valuate(quotes, nil, day)
+		if err != nil {
+			t.Fatalf("Valuate() error = %v", err)
+		}
+		history = append(history, v)
+	}
+	benchmark := portfolio.Benchmark{Name: "Value50", Constituents: []portfolio.BenchmarkConstituent{{Ticker: "MSFT", Weight: 0.5}, {Ticker: "XOM", Weight: 0.5}}}
+	sectors := map[string]string{"AAPL": "Technology", "MSFT": "Technology", "XOM": "Energy"}
+
+	c, err := p.CompareWithBenchmark(history, benchmark, prices, nil, sectors, time.Time{}, days[2])
+	if err != nil {
+		t.Fatalf("CompareWithBenchmark() error = %v", err)
+	}
+	// The portfolio grows 1000.00 → 1040.00 → 1100.00; the basket returns −2.5% then +2.38%.
+	if c.Observations != 2 || math.Abs(c.PortfolioReturn-0.1) > 1e-9 || math.Abs(c.BenchmarkReturn-(0.975*(1+0.5*(11000.0/10500-1))-1)) > 1e-9 {
+		t.Errorf("CompareWithBenchmark() = %+v, want returns of 10%% and about -0.2%%", c)
+	}
+	if math.Abs(c.ActiveReturn-(c.PortfolioReturn-c.BenchmarkReturn)) > 1e-12 || c.TrackingError <= 0 || c.InformationRatio <= 0 {
+		t.Errorf("CompareWithBenchmark() = %+v, want a positive information ratio", c)
+	}
+
+	// Buy-and-hold: Technology 60% at +20% vs 50% at +10%, Energy 20% vs 50% both at −10%,
+	// cash 20% at 0%. The effects add up to the 10% active return against the basket's 0%.
+	if total := c.Allocation + c.Selection + c.Interaction; math.Abs(total-0.1) > 1e-9 {
+		t.Errorf("attribution effects sum to %v, want 0.1", total)
+	}
+	want := map[string][3]float64{
+		"Cash":       {0, 0, 0},
+		"Energy":     {0.03, 0, 0},
+		"Technology": {0.01, 0.05, 0.01},
+	}
+	if len(c.Attribution) != len(want) {
+		t.Fatalf("Attribution = %+v, want Cash, Energy and Technology", c.Attribution)
+	}
+	for _, a := range c.Attribution {
+		w := want[a.Sector]
+		if math.Abs(a.Allocation-w[0]) > 1e-9 || math.Abs(a.Selection-w[1]) > 1e-9 || math.Abs(a.Interaction-w[2]) > 1e-9 {
+			t.Errorf("%s attribution = %+v, want allocation/selection/interaction %v", a.Sector, a, w)
+		}
+	}
+
+	if _, err := p.CompareWithBenchmark(history, benchmark, prices, nil, sectors, days[0].AddDate(0, 0, -1), days[2]); !errors.Is(err, portfolio.ErrInsufficientHistory) {
+		t.Errorf("CompareWithBenchmark(before the history) error = %v, want ErrInsufficientHistory", err)
+	}
+}
@@ -0,0 +1,203 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrInsufficientCash is matched (via errors.Is) by every InsufficientCashError.
+var ErrInsufficientCash = errors.New("insufficient cash balance")
+
+// InsufficientCashError is returned when an operation would take a cash balance below zero,
+// breaking the CashBalance ≥ 0 invariant.
+type InsufficientCashError struct {
+	Requested Money // Amount the operation needed
+	Available Money // Cash held in that currency at the time
+}
+
+// Error returns the error message string.
+func (e *InsufficientCashError) Error() string {
+	return fmt.Sprintf("insufficient %s cash balance: requested %d, available %d",
+		e.Requested.Currency, e.Requested.Amount, e.Available.Amount)
+}
+
+// Is makes errors.Is(err, ErrInsufficientCash) match any InsufficientCashError.
+func (e *InsufficientCashError) Is(target error) bool {
+	return target == ErrInsufficientCash
+}
+
+// CashIn returns the cash held in the given currency (zero if none is held).
+func (p *Portfolio) CashIn(currency string) Money {
+	if currency == p.BaseCurrency {
+		return p.CashBalance
+	}
+	if cash, ok := p.ForeignCash[currency]; ok {
+		return cash
+	}
+	return Money{Amount: 0, Currency: currency}
+}
+
+// creditCash adds amount to the cash balance held in amount's currency.
+func (p *Portfolio) creditCash(amount Money) {
+	if amount.Currency == p.BaseCurrency {
+		p.CashBalance.Amount += amount.Amount
+		return
+	}
+	if p.ForeignCash == nil {
+		p.ForeignCash = make(map[string]Money)
+	}
+	cash := p.CashIn(amount.Currency)
+	cash.Amount += amount.Amount
+	p.ForeignCash[amount.Currency] = cash
+}
+
+// debitCash removes amount from the cash balance held in amount's currency.
+// Callers are responsible for checking that enough cash is available.
+func (p *Portfolio) debitCash(amount Money) {
+	p.creditCash(Money{Amount: -amount.Amount, Currency: amount.Currency})
+}
+
+// ensureCash returns an *InsufficientCashError if less than amount is held in its currency.
+func (p *Portfolio) ensureCash(amount Money) error {
+	available := p.CashIn(amount.Currency)
+	if !p.ValidateCashBalance() || available.Amount < amount.Amount {
+		return &InsufficientCashError{Requested: amount, Available: available}
+	}
+	return nil
+}
+
+// Deposit adds external cash to the portfolio, effective on the given date.
+// Deposits in a currency other than the base currency are held as foreign cash.
+func (p *Portfolio) Deposit(amount Money, on time.Time, description string) error {
+	if amount.Currency == "" {
+		return Errors.New("deposit currency cannot be empty")
+	}
+	if !amount.IsPositive() {
+		return Errors.New("deposit amount must be positive")
+	}
+	p.creditCash(amount)
+	p.record(LedgerEntry{Type: Deposit, Amount: amount, Description: description, Timestamp: on})
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Withdraw takes external cash out of the portfolio, effective on the given date.
+// It returns an *InsufficientCashError if less than amount is held in that currency.
+func (p *Portfolio) Withdraw(amount Money, on time.Time, description string) error {
+	if amount.Currency == "" {
+		return Errors.New("withdrawal currency cannot be empty")
+	}
+	if !amount.IsPositive() {
+		return Errors.New("withdrawal amount must be positive")
+	}
+	if err := p.ensureCash(amount); err != nil {
+		return err
+	}
+	p.debitCash(amount)
+	p.record(LedgerEntry{Type: Withdrawal, Amount: Money{Amount: -amount.Amount, Currency: amount.Currency}, Description: description, Timestamp: on})
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// ExchangeCash converts part of the cash held in one currency into another at the rate in
+// effect on the given date, returning the amount credited in the target currency after the
+// FX spread of the fee schedule.
+func (p *Portfolio) ExchangeCash(amount Money, to string, rates FXRateProvider, on time.Time) (Money, error) {
+	if !amount.IsPositive() {
+		return Money{}, Errors.New("exchange amount must be positive")
+	}
+	if amount.Currency == to {
+		return Money{}, Errors.New("cannot exchange cash into the same currency")
+	}
+	if err := p.ensureCash(amount); err != nil {
+		return Money{}, err
+	}
+	converted, err := ConvertMoney(amount, to, rates, on)
+	if err != nil {
+		return Money{}, err
+	}
+	p.debitCash(amount)
+	p.creditCash(converted)
+	description := fmt.Sprintf("%s → %s", amount.Currency, to)
+	p.record(LedgerEntry{Type: FXExchange, Amount: Money{Amount: -amount.Amount, Currency: amount.Currency}, Description: description, Timestamp: on})
+	p.record(LedgerEntry{Type: FXExchange, Amount: converted, Description: description, Timestamp: on})
+	spread := p.FeeSchedule.FXSpreadCost(converted)
+	p.recordFee(spread, "", "FX spread "+description, false, on)
+	p.UpdatedAt = time.Now()
+	return Money{Amount: converted.Amount - spread.Amount, Currency: to}, nil
+}
+
+// SetInterestRate sets the annual interest rate paid on idle cash in a currency
+// (e.g., 0.03 for 3%). Interest earned at the previous rate is accrued up to the given
+// date first; a rate of zero stops accrual for that currency.
+func (p *Portfolio) SetInterestRate(currency string, annualRate float64, on time.Time) error {
+	if currency == "" {
+		return Errors.New("interest currency cannot be empty")
+	}
+	if annualRate < 0 || annualRate > 1 {
+		return Errors.New("annual interest rate must be between 0 and 1")
+	}
+	p.AccrueInterest(on)
+	if p.InterestRates == nil {
+		p.InterestRates = make(map[string]float64)
+	}
+	if annualRate == 0 {
+		delete(p.InterestRates, currency)
+	} else {
+		p.InterestRates[currency] = annualRate
+	}
+	p.LastInterestAccrual = on
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// AccrueInterest credits simple interest on the cash held in each currency with a configured
+// rate, for the whole days elapsed since the last accrual (actual/365). It is meant to be run
+// daily so that interest follows the end-of-day balances, and returns the amounts credited.
+func (p *Portfolio) AccrueInterest(asOf time.Time) []Money {
+	if len(p.InterestRates) == 0 {
+		return nil
+	}
+	if p.LastInterestAccrual.IsZero() {
+		p.LastInterestAccrual = asOf
+		return nil
+	}
+	days := int(asOf.Sub(p.LastInterestAccrual).Hours() / 24)
+	if days <= 0 {
+		return nil
+	}
+
+	currencies := make([]string, 0, len(p.InterestRates))
+	for currency := range p.InterestRates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var credited []Money
+	for _, currency := range currencies {
+		balance := p.CashIn(currency)
+		if !balance.IsPositive() {
+			continue
+		}
+		rate := p.InterestRates[currency]
+		amount := int64(math.Round(float64(balance.Amount) * rate * float64(days) / 365))
+		if amount == 0 {
+			continue
+		}
+		interest := Money{Amount: amount, Currency: currency}
+		p.creditCash(interest)
+		p.record(LedgerEntry{
+			Type:        Interest,
+			Amount:      interest,
+			Description: fmt.Sprintf("%d days at %.4f%%", days, rate*100),
+			Timestamp:   asOf,
+		})
+		credited = append(credited, interest)
+	}
+	p.LastInterestAccrual = p.LastInterestAccrual.Add(time.Duration(days) * 24 * time.Hour)
+	p.UpdatedAt = time.Now()
+	return credited
+}
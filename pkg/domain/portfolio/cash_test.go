@@ -0,0 +1,115 @@
+package portfolio_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestPortfolio_DepositAndWithdraw(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("InitialCashIsRecordedAsDeposit", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("cash", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		flows := p.ExternalCashFlows(time.Time{}, time.Time{})
+		if len(flows) != 1 || flows[0].Amount.Amount != 1000 {
+			t.Errorf("ExternalCashFlows() = %+v, want the initial 1000 USD deposit", flows)
+		}
+	})
+
+	t.Run("DepositForeignCurrency", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("cash", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		if err := p.Deposit(portfolio.Money{Amount: 500, Currency: "EUR"}, jan1, "transfer"); err != nil {
+			t.Fatalf("Deposit() error = %v, wantErr nil", err)
+		}
+		if p.CashBalance.Amount != 1000 || p.CashIn("EUR").Amount != 500 {
+			t.Errorf("Cash = %d USD / %d EUR, want 1000 USD / 500 EUR", p.CashBalance.Amount, p.CashIn("EUR").Amount)
+		}
+		last := p.Ledger[len(p.Ledger)-1]
+		if last.Type != portfolio.Deposit || last.Sequence != len(p.Ledger) || !last.Timestamp.Equal(jan1) {
+			t.Errorf("Last ledger entry = %+v, want a deposit on %v", last, jan1)
+		}
+	})
+
+	t.Run("DepositNonPositive", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("cash", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		if err := p.Deposit(portfolio.Money{Amount: 0, Currency: "USD"}, jan1, ""); err == nil {
+			t.Error("Deposit() of zero expected error, got nil")
+		}
+	})
+
+	t.Run("Withdraw", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("cash", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		if err := p.Withdraw(portfolio.Money{Amount: 400, Currency: "USD"}, jan1, "fees"); err != nil {
+			t.Fatalf("Withdraw() error = %v, wantErr nil", err)
+		}
+		if p.CashBalance.Amount != 600 {
+			t.Errorf("CashBalance = %d, want 600", p.CashBalance.Amount)
+		}
+		flows := p.ExternalCashFlows(jan1, jan1)
+		if len(flows) != 1 || flows[0].Amount.Amount != -400 {
+			t.Errorf("ExternalCashFlows() = %+v, want a single -400 withdrawal", flows)
+		}
+	})
+
+	t.Run("WithdrawMoreThanHeld", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("cash", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		err := p.Withdraw(portfolio.Money{Amount: 1001, Currency: "USD"}, jan1, "")
+		var cashErr *portfolio.InsufficientCashError
+		if !errors.As(err, &cashErr) {
+			t.Fatalf("Withdraw() error = %v, want *InsufficientCashError", err)
+		}
+		if cashErr.Available.Amount != 1000 || cashErr.Requested.Amount != 1001 {
+			t.Errorf("InsufficientCashError = %+v, want available 1000 / requested 1001", cashErr)
+		}
+		if !errors.Is(err, portfolio.ErrInsufficientCash) {
+			t.Error("errors.Is(err, ErrInsufficientCash) = false, want true")
+		}
+		if p.CashBalance.Amount != 1000 {
+			t.Errorf("CashBalance changed after failed withdrawal: %d", p.CashBalance.Amount)
+		}
+	})
+}
+
+func TestPortfolio_AccrueInterest(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, _ := portfolio.NewPortfolio("interest", portfolio.Conservative, portfolio.Money{Amount: 3650000, Currency: "USD"})
+
+	if credited := p.AccrueInterest(start.Add(48 * time.Hour)); credited != nil {
+		t.Errorf("AccrueInterest() without a rate credited %v, want nothing", credited)
+	}
+
+	if err := p.SetInterestRate("USD", 0.05, start); err != nil {
+		t.Fatalf("SetInterestRate() error = %v", err)
+	}
+	if err := p.SetInterestRate("USD", 1.5, start); err == nil {
+		t.Error("SetInterestRate() above 100% expected error, got nil")
+	}
+
+	// 10 days at 5% on 36,500.00 = 50.00
+	credited := p.AccrueInterest(start.Add(10*24*time.Hour + 3*time.Hour))
+	if len(credited) != 1 || credited[0].Amount != 5000 {
+		t.Fatalf("AccrueInterest() = %v, want 5000 USD", credited)
+	}
+	if p.CashBalance.Amount != 3655000 {
+		t.Errorf("CashBalance = %d, want 3655000", p.CashBalance.Amount)
+	}
+	if !p.LastInterestAccrual.Equal(start.Add(10 * 24 * time.Hour)) {
+		t.Errorf("LastInterestAccrual = %v, want whole days only", p.LastInterestAccrual)
+	}
+	if last := p.Ledger[len(p.Ledger)-1]; last.Type != portfolio.Interest {
+		t.Errorf("Last ledger entry type = %v, want Interest", last.Type)
+	}
+
+	// Interest is not an external cash flow.
+	if flows := p.ExternalCashFlows(time.Time{}, time.Time{}); len(flows) != 1 {
+		t.Errorf("ExternalCashFlows() = %+v, want only the initial deposit", flows)
+	}
+
+	// Accruing again on the same day credits nothing.
+	if credited := p.AccrueInterest(start.Add(10*24*time.Hour + 5*time.Hour)); credited != nil {
+		t.Errorf("AccrueInterest() twice on the same day credited %v", credited)
+	}
+}
@@ -0,0 +1,114 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrCorporateActionAlreadyApplied is returned when a corporate action has already been applied to a portfolio.
+var ErrCorporateActionAlreadyApplied = errors.New("corporate action already applied")
+
+// ApplySplit applies a stock split or reverse split to the holding in ticker: the holding
+// becomes ratioTo shares for every ratioFrom held, and the average purchase price is adjusted
+// so that the cost basis is unchanged. Fractions of a share left by a reverse split are kept
+// as fractional shares. It reports whether the portfolio held the ticker.
+func (p *Portfolio) ApplySplit(ticker string, ratioTo, ratioFrom int, reference string, on time.Time) (bool, error) {
+	if ratioTo <= 0 || ratioFrom <= 0 {
+		return false, Errors.New("split ratio must be positive")
+	}
+	if p.hasReference(reference) {
+		return false, fmt.Errorf("%w: %s in portfolio %s", ErrCorporateActionAlreadyApplied, reference, p.ID)
+	}
+	pos, ok := p.Holdings[ticker]
+	if !ok {
+		return false, nil
+	}
+
+	basis := pos.CostBasis()
+	before := pos.Quantity()
+	after := before * float64(ratioTo) / float64(ratioFrom)
+	pos.setQuantity(after)
+	pos.PurchasePrice = Money{Amount: int64(math.Round(float64(basis.Amount) / after)), Currency: basis.Currency}
+	p.Holdings[ticker] = pos
+
+	p.record(LedgerEntry{Type: CorporateAction, Amount: Money{Currency: basis.Currency}, Ticker: ticker, Shares: after - before,
+		Reference: reference, Description: fmt.Sprintf("%d-for-%d split", ratioTo, ratioFrom), Timestamp: on})
+	p.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// ApplySpinoff distributes ratioTo shares of newTicker for every ratioFrom shares held of
+// parent, moving basisAllocation of the parent's cost basis to the new holding. The total cost
+// basis across both holdings is unchanged. It reports whether the portfolio held the parent.
+func (p *Portfolio) ApplySpinoff(parent, newTicker string, ratioTo, ratioFrom int, basisAllocation float64, reference string, on time.Time) (bool, error) {
+	if ratioTo <= 0 || ratioFrom <= 0 {
+		return false, Errors.New("spinoff ratio must be positive")
+	}
+	if basisAllocation <= 0 || basisAllocation >= 1 {
+		return false, Errors.New("spinoff basis allocation must be between 0 and 1 (exclusive)")
+	}
+	if p.hasReference(reference) {
+		return false, fmt.Errorf("%w: %s in portfolio %s", ErrCorporateActionAlreadyApplied, reference, p.ID)
+	}
+	pos, ok := p.Holdings[parent]
+	if !ok {
+		return false, nil
+	}
+	existing, holdsNew := p.Holdings[newTicker]
+	if holdsNew && existing.PurchasePrice.Currency != pos.PurchasePrice.Currency {
+		return false, Errors.New("spun-off holding currency does not match existing holding")
+	}
+
+	basis := pos.CostBasis()
+	allocated := basis.Scale(basisAllocation)
+	received := pos.Quantity() * float64(ratioTo) / float64(ratioFrom)
+
+	// The parent keeps its shares; only its cost basis shrinks.
+	pos.PurchasePrice.Amount = int64(math.Round(float64(basis.Amount-allocated.Amount) / pos.Quantity()))
+	p.Holdings[parent] = pos
+
+	if !holdsNew {
+		existing = Position{CompanyTicker: newTicker, PurchasePrice: Money{Currency: basis.Currency}}
+	}
+	existing.addQuantity(received, allocated)
+	p.Holdings[newTicker] = existing
+
+	description := fmt.Sprintf("spinoff of %s from %s, %d for %d, %.2f%% of basis", newTicker, parent, ratioTo, ratioFrom, basisAllocation*100)
+	p.record(LedgerEntry{Type: CorporateAction, Amount: Money{Currency: basis.Currency}, Ticker: parent, Reference: reference, Description: description, Timestamp: on})
+	p.record(LedgerEntry{Type: CorporateAction, Amount: Money{Currency: basis.Currency}, Ticker: newTicker, Shares: received, Reference: reference, Description: description, Timestamp: on})
+	p.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// RenameTicker moves the holding in oldTicker to newTicker after a ticker change.
+// It reports whether the portfolio held oldTicker.
+func (p *Portfolio) RenameTicker(oldTicker, newTicker string, reference string, on time.Time) (bool, error) {
+	if newTicker == "" || newTicker == oldTicker {
+		return false, Errors.New("new ticker must be non-empty and differ from the old one")
+	}
+	if p.hasReference(reference) {
+		return false, fmt.Errorf("%w: %s in portfolio %s", ErrCorporateActionAlreadyApplied, reference, p.ID)
+	}
+	pos, ok := p.Holdings[oldTicker]
+	if !ok {
+		return false, nil
+	}
+	if _, taken := p.Holdings[newTicker]; taken {
+		return false, Errors.New("portfolio already holds " + newTicker)
+	}
+
+	delete(p.Holdings, oldTicker)
+	pos.CompanyTicker = newTicker
+	p.Holdings[newTicker] = pos
+
+	// Recorded as shares leaving the old ticker and arriving under the new one, so that
+	// holdings can still be reconstructed per ticker from the ledger.
+	description := fmt.Sprintf("ticker change %s → %s", oldTicker, newTicker)
+	zero := Money{Currency: pos.PurchasePrice.Currency}
+	p.record(LedgerEntry{Type: CorporateAction, Amount: zero, Ticker: oldTicker, Shares: -pos.Quantity(), Reference: reference, Description: description, Timestamp: on})
+	p.record(LedgerEntry{Type: CorporateAction, Amount: zero, Ticker: newTicker, Shares: pos.Quantity(), Reference: reference, Description: description, Timestamp: on})
+	p.UpdatedAt = time.Now()
+	return true, nil
+}
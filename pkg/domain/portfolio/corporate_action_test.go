@@ -0,0 +1,102 @@
+package portfolio_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestPortfolio_ApplySplit(t *testing.T) {
+	on := time.Now().Add(time.Hour)
+
+	t.Run("ForwardSplitKeepsCostBasis", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+		applied, err := p.ApplySplit("AAPL", 4, 1, "AAPL@split", on)
+		if err != nil || !applied {
+			t.Fatalf("ApplySplit() = %v, %v; want true, nil", applied, err)
+		}
+		pos := p.Holdings["AAPL"]
+		if pos.Shares != 40 || pos.PurchasePrice.Amount != 250 || pos.CostBasis().Amount != 10000 {
+			t.Errorf("Holding = %+v, want 40 shares at 250 (basis 10000)", pos)
+		}
+		last := p.Ledger[len(p.Ledger)-1]
+		if last.Type != portfolio.CorporateAction || last.Shares != 30 || last.Reference != "AAPL@split" {
+			t.Errorf("Last ledger entry = %+v, want a corporate action adding 30 shares", last)
+		}
+		if got := p.SharesHeldAt("AAPL", on.Add(-time.Minute)); got != 10 {
+			t.Errorf("SharesHeldAt(before split) = %v, want 10", got)
+		}
+	})
+
+	t.Run("ReverseSplitKeepsFraction", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+		if _, err := p.ApplySplit("AAPL", 1, 4, "AAPL@reverse", on); err != nil {
+			t.Fatalf("ApplySplit() error = %v", err)
+		}
+		pos := p.Holdings["AAPL"]
+		if pos.Shares != 2 || math.Abs(pos.FractionalShares-0.5) > 1e-9 || pos.PurchasePrice.Amount != 4000 {
+			t.Errorf("Holding = %+v, want 2.5 shares at 4000", pos)
+		}
+	})
+
+	t.Run("AppliedOnlyOnce", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+		_, _ = p.ApplySplit("AAPL", 2, 1, "AAPL@split", on)
+		_, err := p.ApplySplit("AAPL", 2, 1, "AAPL@split", on)
+		if !errors.Is(err, portfolio.ErrCorporateActionAlreadyApplied) {
+			t.Errorf("second ApplySplit() error = %v, want ErrCorporateActionAlreadyApplied", err)
+		}
+		if p.Holdings["AAPL"].Shares != 20 {
+			t.Errorf("Shares = %d, want 20", p.Holdings["AAPL"].Shares)
+		}
+	})
+
+	t.Run("NotHeld", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+		applied, err := p.ApplySplit("MSFT", 2, 1, "MSFT@split", on)
+		if err != nil || applied {
+			t.Errorf("ApplySplit() = %v, %v; want false, nil", applied, err)
+		}
+	})
+}
+
+func TestPortfolio_ApplySpinoff(t *testing.T) {
+	p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+	// One NEW share for every two AAPL shares, with 20% of the basis moving to NEW.
+	applied, err := p.ApplySpinoff("AAPL", "NEW", 1, 2, 0.2, "AAPL@spinoff", time.Now())
+	if err != nil || !applied {
+		t.Fatalf("ApplySpinoff() = %v, %v; want true, nil", applied, err)
+	}
+	parent, spun := p.Holdings["AAPL"], p.Holdings["NEW"]
+	if parent.Shares != 10 || parent.PurchasePrice.Amount != 800 {
+		t.Errorf("Parent = %+v, want 10 shares at 800", parent)
+	}
+	if spun.Shares != 5 || spun.PurchasePrice.Amount != 400 || spun.PurchasePrice.Currency != "USD" {
+		t.Errorf("Spun-off holding = %+v, want 5 shares at 400 USD", spun)
+	}
+	if total := parent.CostBasis().Amount + spun.CostBasis().Amount; total != 10000 {
+		t.Errorf("Total cost basis = %d, want 10000", total)
+	}
+}
+
+func TestPortfolio_RenameTicker(t *testing.T) {
+	p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+	on := time.Now().Add(time.Hour)
+	applied, err := p.RenameTicker("AAPL", "APPL", "AAPL@rename", on)
+	if err != nil || !applied {
+		t.Fatalf("RenameTicker() = %v, %v; want true, nil", applied, err)
+	}
+	if _, ok := p.Holdings["AAPL"]; ok {
+		t.Error("Holdings still contain the old ticker")
+	}
+	if pos := p.Holdings["APPL"]; pos.CompanyTicker != "APPL" || pos.Shares != 10 {
+		t.Errorf("Renamed holding = %+v, want 10 APPL shares", pos)
+	}
+	// Shares held under the old ticker before the change are still known for dividend entitlement.
+	if got := p.SharesHeldAt("AAPL", on.Add(-time.Minute)); got != 10 {
+		t.Errorf("SharesHeldAt(old ticker, before change) = %v, want 10", got)
+	}
+}
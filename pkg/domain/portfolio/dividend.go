@@ -0,0 +1,196 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrDividendAlreadyCredited is returned when a dividend has already been credited to a portfolio.
+var ErrDividendAlreadyCredited = errors.New("dividend already credited")
+
+// ReinvestmentMode selects what happens to the net cash of a dividend.
+type ReinvestmentMode int
+
+// Defines the dividend reinvestment modes.
+const (
+	NoReinvestment           ReinvestmentMode = iota // Dividends are kept as cash
+	ReinvestWholeShares                              // Buy as many whole shares as the dividend pays for; the rest stays as cash
+	ReinvestFractionalShares                         // Reinvest the whole dividend, holding fractional shares
+)
+
+// String returns the string representation of a ReinvestmentMode.
+func (m ReinvestmentMode) String() string {
+	switch m {
+	case ReinvestWholeShares:
+		return "WholeShares"
+	case ReinvestFractionalShares:
+		return "FractionalShares"
+	default:
+		return "None"
+	}
+}
+
+// ParseReinvestmentMode converts a string (as returned by String) to a ReinvestmentMode.
+func ParseReinvestmentMode(s string) (ReinvestmentMode, error) {
+	switch s {
+	case "", "None":
+		return NoReinvestment, nil
+	case "WholeShares":
+		return ReinvestWholeShares, nil
+	case "FractionalShares":
+		return ReinvestFractionalShares, nil
+	default:
+		return NoReinvestment, Errors.New("invalid reinvestment mode: " + s)
+	}
+}
+
+// DividendPolicy configures how a portfolio receives dividends.
+// This is a value object.
+type DividendPolicy struct {
+	Reinvestment        ReinvestmentMode   // Dividend reinvestment plan (DRIP) setting
+	WithholdingTaxRate  float64            // Default tax withheld at source (e.g., 0.15 for 15%)
+	WithholdingTaxRates map[string]float64 // Per-currency overrides of the default rate, keyed by currency code
+}
+
+// TaxRateFor returns the withholding tax rate applied to dividends paid in the given currency.
+func (dp DividendPolicy) TaxRateFor(currency string) float64 {
+	if rate, ok := dp.WithholdingTaxRates[currency]; ok {
+		return rate
+	}
+	return dp.WithholdingTaxRate
+}
+
+// Validate checks that every tax rate is between 0 and 1.
+func (dp DividendPolicy) Validate() error {
+	if dp.WithholdingTaxRate < 0 || dp.WithholdingTaxRate > 1 {
+		return Errors.New("withholding tax rate must be between 0 and 1")
+	}
+	for currency, rate := range dp.WithholdingTaxRates {
+		if rate < 0 || rate > 1 {
+			return Errors.New("withholding tax rate for " + currency + " must be between 0 and 1")
+		}
+	}
+	return nil
+}
+
+// SetDividendPolicy replaces the portfolio's dividend policy.
+func (p *Portfolio) SetDividendPolicy(policy DividendPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	p.DividendPolicy = policy
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// DividendPayment is the outcome of crediting one dividend to a portfolio.
+// This is a value object.
+type DividendPayment struct {
+	PortfolioID      string
+	CompanyTicker    string
+	DividendID       string
+	EligibleShares   float64 // Shares held before the ex-date
+	Gross            Money
+	WithholdingTax   Money
+	Net              Money
+	ReinvestedShares float64 // Shares bought with the dividend (zero without reinvestment)
+	ReinvestedAmount Money   // Cash spent on reinvestment
+	PayDate          time.Time
+}
+
+// CreditDividend credits a declared dividend to the portfolio, effective on the pay date.
+// Entitlement follows the shares held just before the ex-date. The tax in the dividend policy
+// is withheld from the gross amount and, when reinvestment is enabled and a price is given,
+// the net amount buys more shares of the company. Crediting the same dividend twice returns
+// ErrDividendAlreadyCredited; a portfolio that held no shares at the ex-date is left unchanged
+// and nil is returned.
+func (p *Portfolio) CreditDividend(ticker, dividendID string, exDate, payDate time.Time, amountPerShare Money, reinvestPrice *Money) (*DividendPayment, error) {
+	if !amountPerShare.IsPositive() {
+		return nil, Errors.New("dividend amount per share must be positive")
+	}
+	if p.hasReference(dividendID) {
+		return nil, fmt.Errorf("%w: %s in portfolio %s", ErrDividendAlreadyCredited, dividendID, p.ID)
+	}
+	eligible := p.SharesHeldAt(ticker, exDate)
+	if eligible == 0 {
+		return nil, nil
+	}
+
+	gross := amountPerShare.Scale(eligible)
+	tax := gross.Scale(p.DividendPolicy.TaxRateFor(gross.Currency))
+	payment := &DividendPayment{
+		PortfolioID:      p.ID,
+		CompanyTicker:    ticker,
+		DividendID:       dividendID,
+		EligibleShares:   eligible,
+		Gross:            gross,
+		WithholdingTax:   tax,
+		Net:              Money{Amount: gross.Amount - tax.Amount, Currency: gross.Currency},
+		ReinvestedAmount: Money{Currency: gross.Currency},
+		PayDate:          payDate,
+	}
+
+	p.creditCash(gross)
+	p.record(LedgerEntry{Type: Dividend, Amount: gross, Ticker: ticker, Reference: dividendID,
+		Description: fmt.Sprintf("%.4f shares × %d", eligible, amountPerShare.Amount), Timestamp: payDate})
+	if tax.IsPositive() {
+		p.debitCash(tax)
+		p.record(LedgerEntry{Type: WithholdingTax, Amount: Money{Amount: -tax.Amount, Currency: tax.Currency}, Ticker: ticker, Reference: dividendID,
+			Description: fmt.Sprintf("%.2f%% withheld", p.DividendPolicy.TaxRateFor(gross.Currency)*100), Timestamp: payDate})
+	}
+	if err := p.reinvestDividend(payment, reinvestPrice); err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = time.Now()
+	return payment, nil
+}
+
+// reinvestDividend buys shares of the paying company with the net dividend, according to the
+// portfolio's reinvestment mode. Holdings that were sold out before the pay date are not rebought.
+func (p *Portfolio) reinvestDividend(payment *DividendPayment, price *Money) error {
+	mode := p.DividendPolicy.Reinvestment
+	if mode == NoReinvestment || price == nil || !price.IsPositive() || !payment.Net.IsPositive() {
+		return nil
+	}
+	pos, ok := p.Holdings[payment.CompanyTicker]
+	if !ok {
+		return nil
+	}
+	if price.Currency != pos.PurchasePrice.Currency || price.Currency != payment.Net.Currency {
+		return Errors.New("reinvestment price currency does not match the dividend and holding currency")
+	}
+
+	shares := float64(payment.Net.Amount) / float64(price.Amount)
+	if mode == ReinvestWholeShares {
+		shares = math.Floor(shares + fractionEpsilon)
+	}
+	if shares <= 0 {
+		return nil
+	}
+	cost := price.Scale(shares)
+	if cost.Amount > payment.Net.Amount {
+		cost.Amount = payment.Net.Amount // Rounding must not spend more than the dividend paid
+	}
+
+	pos.addQuantity(shares, cost)
+	p.Holdings[payment.CompanyTicker] = pos
+	p.debitCash(cost)
+	p.record(LedgerEntry{Type: Buy, Amount: Money{Amount: -cost.Amount, Currency: cost.Currency}, Ticker: payment.CompanyTicker,
+		Shares: shares, Reference: payment.DividendID, Description: "dividend reinvestment", Timestamp: payment.PayDate})
+	payment.ReinvestedShares = shares
+	payment.ReinvestedAmount = cost
+	return nil
+}
+
+// DividendReceivedEvent indicates that a dividend was credited to a portfolio.
+type DividendReceivedEvent struct {
+	Payment   DividendPayment
+	Timestamp time.Time
+}
+
+// NewDividendReceivedEvent creates a new DividendReceivedEvent.
+func NewDividendReceivedEvent(payment DividendPayment) DividendReceivedEvent {
+	return DividendReceivedEvent{Payment: payment, Timestamp: time.Now()}
+}
@@ -0,0 +1,139 @@
+package portfolio_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// newDividendTestPortfolio returns a portfolio holding 10 AAPL bought at 10.00 USD.
+func newDividendTestPortfolio(t *testing.T, policy portfolio.DividendPolicy) *portfolio.Portfolio {
+	t.Helper()
+	p, _ := portfolio.NewPortfolio("div", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	pos, _ := portfolio.NewPosition("AAPL", 10, portfolio.Money{Amount: 1000, Currency: "USD"})
+	if err := p.AddPosition(*pos, portfolio.Money{Amount: 10000, Currency: "USD"}); err != nil {
+		t.Fatalf("AddPosition() error = %v", err)
+	}
+	if err := p.SetDividendPolicy(policy); err != nil {
+		t.Fatalf("SetDividendPolicy() error = %v", err)
+	}
+	return p
+}
+
+func TestPortfolio_CreditDividend(t *testing.T) {
+	exDate := time.Now().Add(24 * time.Hour)
+	payDate := exDate.Add(7 * 24 * time.Hour)
+	perShare := portfolio.Money{Amount: 50, Currency: "USD"}
+
+	t.Run("WithholdsTaxAndKeepsCash", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{WithholdingTaxRate: 0.15})
+		payment, err := p.CreditDividend("AAPL", "AAPL@x", exDate, payDate, perShare, nil)
+		if err != nil {
+			t.Fatalf("CreditDividend() error = %v, wantErr nil", err)
+		}
+		if payment.Gross.Amount != 500 || payment.WithholdingTax.Amount != 75 || payment.Net.Amount != 425 {
+			t.Errorf("Payment = %+v, want gross 500, tax 75, net 425", payment)
+		}
+		if p.CashBalance.Amount != 90425 {
+			t.Errorf("CashBalance = %d, want 90425", p.CashBalance.Amount)
+		}
+		n := len(p.Ledger)
+		if p.Ledger[n-2].Type != portfolio.Dividend || p.Ledger[n-1].Type != portfolio.WithholdingTax || !p.Ledger[n-1].Timestamp.Equal(payDate) {
+			t.Errorf("Last ledger entries = %+v, want a dividend and a withholding tax entry on the pay date", p.Ledger[n-2:])
+		}
+	})
+
+	t.Run("PerCurrencyTaxRate", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{WithholdingTaxRate: 0.15, WithholdingTaxRates: map[string]float64{"USD": 0.30}})
+		payment, _ := p.CreditDividend("AAPL", "AAPL@x", exDate, payDate, perShare, nil)
+		if payment.WithholdingTax.Amount != 150 {
+			t.Errorf("WithholdingTax = %d, want 150", payment.WithholdingTax.Amount)
+		}
+	})
+
+	t.Run("CreditedOnlyOnce", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+		if _, err := p.CreditDividend("AAPL", "AAPL@x", exDate, payDate, perShare, nil); err != nil {
+			t.Fatalf("CreditDividend() error = %v, wantErr nil", err)
+		}
+		_, err := p.CreditDividend("AAPL", "AAPL@x", exDate, payDate, perShare, nil)
+		if !errors.Is(err, portfolio.ErrDividendAlreadyCredited) {
+			t.Errorf("second CreditDividend() error = %v, want ErrDividendAlreadyCredited", err)
+		}
+	})
+
+	t.Run("NotEntitledWhenBoughtOnOrAfterExDate", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+		pastExDate := time.Now().Add(-24 * time.Hour)
+		payment, err := p.CreditDividend("AAPL", "AAPL@x", pastExDate, payDate, perShare, nil)
+		if err != nil || payment != nil {
+			t.Errorf("CreditDividend() = %+v, %v; want nil, nil", payment, err)
+		}
+	})
+
+	t.Run("ReinvestFractionalShares", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{Reinvestment: portfolio.ReinvestFractionalShares, WithholdingTaxRate: 0.15})
+		price := portfolio.Money{Amount: 2000, Currency: "USD"}
+		payment, err := p.CreditDividend("AAPL", "AAPL@x", exDate, payDate, perShare, &price)
+		if err != nil {
+			t.Fatalf("CreditDividend() error = %v, wantErr nil", err)
+		}
+		if math.Abs(payment.ReinvestedShares-0.2125) > 1e-9 || payment.ReinvestedAmount.Amount != 425 {
+			t.Errorf("Reinvested %v shares for %d, want 0.2125 shares for 425", payment.ReinvestedShares, payment.ReinvestedAmount.Amount)
+		}
+		pos := p.Holdings["AAPL"]
+		if pos.Shares != 10 || math.Abs(pos.FractionalShares-0.2125) > 1e-9 {
+			t.Errorf("Holding = %+v, want 10.2125 shares", pos)
+		}
+		if p.CashBalance.Amount != 90000 {
+			t.Errorf("CashBalance = %d, want 90000 (dividend fully reinvested)", p.CashBalance.Amount)
+		}
+		// Cost basis grows by the reinvested amount: (10000 + 425) / 10.2125 ≈ 1021
+		if pos.PurchasePrice.Amount != 1021 {
+			t.Errorf("PurchasePrice = %d, want 1021", pos.PurchasePrice.Amount)
+		}
+	})
+
+	t.Run("ReinvestWholeSharesKeepsRemainder", func(t *testing.T) {
+		p := newDividendTestPortfolio(t, portfolio.DividendPolicy{Reinvestment: portfolio.ReinvestWholeShares})
+		price := portfolio.Money{Amount: 200, Currency: "USD"}
+		payment, _ := p.CreditDividend("AAPL", "AAPL@x", exDate, payDate, portfolio.Money{Amount: 45, Currency: "USD"}, &price)
+		if payment.ReinvestedShares != 2 || payment.ReinvestedAmount.Amount != 400 {
+			t.Errorf("Reinvested %v shares for %d, want 2 shares for 400", payment.ReinvestedShares, payment.ReinvestedAmount.Amount)
+		}
+		if p.Holdings["AAPL"].Shares != 12 || p.CashBalance.Amount != 90050 {
+			t.Errorf("Holding = %d shares, cash = %d; want 12 shares and 90050", p.Holdings["AAPL"].Shares, p.CashBalance.Amount)
+		}
+	})
+}
+
+func TestPortfolio_SharesHeldAt(t *testing.T) {
+	p := newDividendTestPortfolio(t, portfolio.DividendPolicy{})
+	before := time.Now().Add(-time.Hour)
+	after := time.Now().Add(time.Hour)
+	if got := p.SharesHeldAt("AAPL", before); got != 0 {
+		t.Errorf("SharesHeldAt(before purchase) = %v, want 0", got)
+	}
+	if got := p.SharesHeldAt("AAPL", after); got != 10 {
+		t.Errorf("SharesHeldAt(after purchase) = %v, want 10", got)
+	}
+	if err := p.RemovePosition("AAPL", 4, portfolio.Money{Amount: 5000, Currency: "USD"}); err != nil {
+		t.Fatalf("RemovePosition() error = %v", err)
+	}
+	if got := p.SharesHeldAt("AAPL", time.Now().Add(time.Hour)); got != 6 {
+		t.Errorf("SharesHeldAt(after sale) = %v, want 6", got)
+	}
+}
+
+func TestDividendPolicy_Validate(t *testing.T) {
+	p, _ := portfolio.NewPortfolio("div", portfolio.Moderate, portfolio.Money{Amount: 0, Currency: "USD"})
+	if err := p.SetDividendPolicy(portfolio.DividendPolicy{WithholdingTaxRate: 1.5}); err == nil {
+		t.Error("SetDividendPolicy() with a 150% tax rate expected error, got nil")
+	}
+	if err := p.SetDividendPolicy(portfolio.DividendPolicy{WithholdingTaxRates: map[string]float64{"EUR": -0.1}}); err == nil {
+		t.Error("SetDividendPolicy() with a negative EUR tax rate expected error, got nil")
+	}
+}
@@ -0,0 +1,330 @@
+package portfolio
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// EventType classifies an event in a portfolio's event stream by the main change it records.
+type EventType int
+
+// Defines the kinds of events recorded in a portfolio's event stream.
+const (
+	UndefinedEvent         EventType = iota // Default or unknown event type
+	PortfolioCreated                        // The portfolio was saved for the first time
+	PositionOpened                          // A holding was bought into
+	PositionAdjusted                        // The shares of a holding changed
+	PositionClosed                          // A holding was sold out
+	CashDeposited                           // External cash was paid in
+	CashWithdrawn                           // External cash was taken out
+	CashExchanged                           // Cash was converted between currencies
+	InterestAccrued                         // Interest was credited on idle cash
+	DividendReceived                        // A dividend was credited
+	CorporateActionApplied                  // A split, spinoff or ticker change was applied to a holding
+	Rebalanced                              // A rebalancing recommendation was executed
+	SettingsChanged                         // Risk, fee, dividend, interest or benchmark settings changed
+	PortfolioUpdated                        // The portfolio was saved without any of the changes above
+	PortfolioDeleted                        // The portfolio was deleted; its history is kept
+)
+
+// String returns the string representation of an EventType.
+func (t EventType) String() string {
+	switch t {
+	case PortfolioCreated:
+		return "PortfolioCreated"
+	case PositionOpened:
+		return "PositionOpened"
+	case PositionAdjusted:
+		return "PositionAdjusted"
+	case PositionClosed:
+		return "PositionClosed"
+	case CashDeposited:
+		return "CashDeposited"
+	case CashWithdrawn:
+		return "CashWithdrawn"
+	case CashExchanged:
+		return "CashExchanged"
+	case InterestAccrued:
+		return "InterestAccrued"
+	case DividendReceived:
+		return "DividendReceived"
+	case CorporateActionApplied:
+		return "CorporateActionApplied"
+	case Rebalanced:
+		return "Rebalanced"
+	case SettingsChanged:
+		return "SettingsChanged"
+	case PortfolioUpdated:
+		return "PortfolioUpdated"
+	case PortfolioDeleted:
+		return "PortfolioDeleted"
+	default:
+		return "UndefinedEvent"
+	}
+}
+
+// Settings holds the parts of a portfolio that are neither holdings, cash nor ledger.
+// This is a value object.
+type Settings struct {
+	BaseCurrency        string
+	RiskProfile         RiskProfile
+	RiskPolicy          RiskPolicy
+	InterestRates       map[string]float64
+	LastInterestAccrual time.Time
+	DividendPolicy      DividendPolicy
+	FeeSchedule         FeeSchedule
+	Benchmark           Benchmark
+	LastRebalanceTime   time.Time
+}
+
+// Event is one saved change to a portfolio. Applied in order, the events of a portfolio's stream
+// rebuild it. An event carries the state of what changed as it was after the change, so
+// replaying it does not depend on domain rules that may have changed since.
+// This is a value object.
+type Event struct {
+	PortfolioID string
+	Version     int // Version of the portfolio after the change; the stream's first event is version 1
+	Type        EventType
+	RecordedAt  time.Time           // When the change was saved
+	UpdatedAt   time.Time           // The portfolio's UpdatedAt after the change
+	Entries     []LedgerEntry       // Ledger entries appended
+	Positions   map[string]Position // Holdings opened or changed, keyed by ticker
+	Closed      []string            // Tickers no longer held, sorted
+	Cash        map[string]Money    // Cash balances that changed, keyed by currency; a zero Money for one no longer held
+	Settings    *Settings           // All the settings, when any of them changed
+}
+
+// Clone returns a deep copy of the event that shares no maps or slices with the original.
+func (e Event) Clone() Event {
+	e.Entries = slices.Clone(e.Entries)
+	e.Positions = maps.Clone(e.Positions)
+	e.Closed = slices.Clone(e.Closed)
+	e.Cash = maps.Clone(e.Cash)
+	if e.Settings != nil {
+		settings := *e.Settings
+		settings.InterestRates = maps.Clone(settings.InterestRates)
+		settings.DividendPolicy.WithholdingTaxRates = maps.Clone(settings.DividendPolicy.WithholdingTaxRates)
+		settings.Benchmark.Constituents = slices.Clone(settings.Benchmark.Constituents)
+		e.Settings = &settings
+	}
+	return e
+}
+
+// NewEvent returns the event changing before into after, which is saved as version at
+// recordedAt. A nil before means after is being created. Ledger entries are append-only, so
+// after's ledger must extend before's.
+func NewEvent(before, after *Portfolio, version int, recordedAt time.Time) (Event, error) {
+	created := before == nil
+	if created {
+		before = &Portfolio{ID: after.ID}
+	}
+	if len(after.Ledger) < len(before.Ledger) {
+		return Event{}, fmt.Errorf("ledger of portfolio %s lost %d entries: entries are append-only", after.ID, len(before.Ledger)-len(after.Ledger))
+	}
+
+	e := Event{
+		PortfolioID: after.ID,
+		Version:     version,
+		RecordedAt:  recordedAt,
+		UpdatedAt:   after.UpdatedAt,
+		Entries:     append([]LedgerEntry(nil), after.Ledger[len(before.Ledger):]...),
+	}
+	var opened, adjusted bool
+	for ticker, pos := range after.Holdings {
+		old, held := before.Holdings[ticker]
+		if held && old == pos {
+			continue
+		}
+		if e.Positions == nil {
+			e.Positions = make(map[string]Position)
+		}
+		e.Positions[ticker] = pos
+		opened = opened || !held
+		adjusted = adjusted || held
+	}
+	for ticker := range before.Holdings {
+		if _, held := after.Holdings[ticker]; !held {
+			e.Closed = append(e.Closed, ticker)
+		}
+	}
+	sort.Strings(e.Closed)
+	beforeCash, afterCash := before.cashBalances(), after.cashBalances()
+	for currency, cash := range afterCash {
+		if old, held := beforeCash[currency]; !held || old != cash {
+			if e.Cash == nil {
+				e.Cash = make(map[string]Money)
+			}
+			e.Cash[currency] = cash
+		}
+	}
+	for currency := range beforeCash {
+		if _, held := afterCash[currency]; !held {
+			if e.Cash == nil {
+				e.Cash = make(map[string]Money)
+			}
+			e.Cash[currency] = Money{}
+		}
+	}
+	settings := after.settings()
+	settingsChanged := !settings.equal(before.settings())
+	if settingsChanged {
+		e.Settings = &settings
+	}
+
+	entries := make(map[EntryType]bool)
+	for _, entry := range e.Entries {
+		entries[entry.Type] = true
+	}
+	switch {
+	case created:
+		e.Type = PortfolioCreated
+	case !after.LastRebalanceTime.Equal(before.LastRebalanceTime):
+		e.Type = Rebalanced
+	case entries[CorporateAction]:
+		e.Type = CorporateActionApplied
+	case entries[Dividend]:
+		e.Type = DividendReceived
+	case opened:
+		e.Type = PositionOpened
+	case len(e.Closed) > 0:
+		e.Type = PositionClosed
+	case adjusted:
+		e.Type = PositionAdjusted
+	case entries[Deposit]:
+		e.Type = CashDeposited
+	case entries[Withdrawal]:
+		e.Type = CashWithdrawn
+	case entries[FXExchange]:
+		e.Type = CashExchanged
+	case entries[Interest]:
+		e.Type = InterestAccrued
+	case settingsChanged:
+		e.Type = SettingsChanged
+	default:
+		e.Type = PortfolioUpdated
+	}
+	return e, nil
+}
+
+// NewDeletedEvent returns the event recording the deletion of a portfolio as version.
+func NewDeletedEvent(portfolioID string, version int, recordedAt time.Time) Event {
+	return Event{PortfolioID: portfolioID, Version: version, Type: PortfolioDeleted, RecordedAt: recordedAt, UpdatedAt: recordedAt}
+}
+
+// Replay applies events, in order, to a snapshot of a portfolio, or to nothing when snapshot is
+// nil, and returns the resulting portfolio; nil if it does not exist after the last event. The
+// snapshot is not changed. The events must continue the snapshot's version without gaps.
+func Replay(snapshot *Portfolio, events []Event) (*Portfolio, error) {
+	var p *Portfolio
+	version := 0
+	if snapshot != nil {
+		p = snapshot.Clone()
+		version = snapshot.Version
+	}
+	for _, e := range events {
+		if version != 0 && e.Version != version+1 {
+			return nil, fmt.Errorf("event stream of portfolio %s jumps from version %d to %d", e.PortfolioID, version, e.Version)
+		}
+		version = e.Version
+		p = e.apply(p)
+	}
+	return p, nil
+}
+
+// apply returns the portfolio p becomes through the event. p is changed in place, unless it is
+// created or deleted.
+func (e Event) apply(p *Portfolio) *Portfolio {
+	switch {
+	case e.Type == PortfolioDeleted:
+		return nil
+	case p == nil || e.Type == PortfolioCreated:
+		p = &Portfolio{ID: e.PortfolioID, Holdings: make(map[string]Position), ForeignCash: make(map[string]Money)}
+	}
+
+	if e.Settings != nil {
+		p.applySettings(*e.Settings)
+	}
+	p.Ledger = append(p.Ledger, e.Entries...)
+	for ticker, pos := range e.Positions {
+		p.Holdings[ticker] = pos
+	}
+	for _, ticker := range e.Closed {
+		delete(p.Holdings, ticker)
+	}
+	for currency, cash := range e.Cash {
+		switch {
+		case currency == p.BaseCurrency:
+			p.CashBalance = cash
+		case cash.Currency == "":
+			delete(p.ForeignCash, currency)
+		default:
+			p.ForeignCash[currency] = cash
+		}
+	}
+	p.UpdatedAt = e.UpdatedAt
+	p.Version = e.Version
+	return p
+}
+
+// cashBalances returns the cash held in each currency, the base currency included.
+func (p *Portfolio) cashBalances() map[string]Money {
+	balances := make(map[string]Money, len(p.ForeignCash)+1)
+	for currency, cash := range p.ForeignCash {
+		balances[currency] = cash
+	}
+	if p.BaseCurrency != "" {
+		balances[p.BaseCurrency] = p.CashBalance
+	}
+	return balances
+}
+
+// settings returns a copy of the portfolio's settings.
+func (p *Portfolio) settings() Settings {
+	s := Settings{
+		BaseCurrency:        p.BaseCurrency,
+		RiskProfile:         p.RiskProfile,
+		RiskPolicy:          p.RiskPolicy,
+		InterestRates:       maps.Clone(p.InterestRates),
+		LastInterestAccrual: p.LastInterestAccrual,
+		DividendPolicy:      p.DividendPolicy,
+		FeeSchedule:         p.FeeSchedule,
+		Benchmark:           Benchmark{Name: p.Benchmark.Name, Constituents: slices.Clone(p.Benchmark.Constituents)},
+		LastRebalanceTime:   p.LastRebalanceTime,
+	}
+	s.DividendPolicy.WithholdingTaxRates = maps.Clone(p.DividendPolicy.WithholdingTaxRates)
+	return s
+}
+
+// applySettings replaces the portfolio's settings with copies of s.
+func (p *Portfolio) applySettings(s Settings) {
+	p.BaseCurrency = s.BaseCurrency
+	p.RiskProfile = s.RiskProfile
+	p.RiskPolicy = s.RiskPolicy
+	p.InterestRates = maps.Clone(s.InterestRates)
+	p.LastInterestAccrual = s.LastInterestAccrual
+	p.DividendPolicy = s.DividendPolicy
+	p.DividendPolicy.WithholdingTaxRates = maps.Clone(s.DividendPolicy.WithholdingTaxRates)
+	p.FeeSchedule = s.FeeSchedule
+	p.Benchmark = Benchmark{Name: s.Benchmark.Name, Constituents: slices.Clone(s.Benchmark.Constituents)}
+	p.LastRebalanceTime = s.LastRebalanceTime
+}
+
+// equal reports whether two settings are the same. Times are compared as instants, and empty
+// maps equal missing ones, so settings read back from storage equal those saved.
+func (s Settings) equal(o Settings) bool {
+	return s.BaseCurrency == o.BaseCurrency &&
+		s.RiskProfile == o.RiskProfile &&
+		s.RiskPolicy == o.RiskPolicy &&
+		maps.Equal(s.InterestRates, o.InterestRates) &&
+		s.LastInterestAccrual.Equal(o.LastInterestAccrual) &&
+		s.DividendPolicy.Reinvestment == o.DividendPolicy.Reinvestment &&
+		s.DividendPolicy.WithholdingTaxRate == o.DividendPolicy.WithholdingTaxRate &&
+		maps.Equal(s.DividendPolicy.WithholdingTaxRates, o.DividendPolicy.WithholdingTaxRates) &&
+		s.FeeSchedule == o.FeeSchedule &&
+		s.Benchmark.Name == o.Benchmark.Name &&
+		slices.Equal(s.Benchmark.Constituents, o.Benchmark.Constituents) &&
+		s.LastRebalanceTime.Equal(o.LastRebalanceTime)
+}
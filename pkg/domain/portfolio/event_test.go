@@ -0,0 +1,151 @@
+package portfolio_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// eventStream saves the portfolio built by each step as the next version, returning the events
+// and the state after every step.
+func eventStream(t *testing.T, steps ...func(p *portfolio.Portfolio) error) ([]portfolio.Event, []*portfolio.Portfolio) {
+	t.Helper()
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, usd(1000000))
+	var (
+		events []portfolio.Event
+		states []*portfolio.Portfolio
+		before *portfolio.Portfolio
+	)
+	recordedAt := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	for i, step := range append([]func(*portfolio.Portfolio) error{func(*portfolio.Portfolio) error { return nil }}, steps...) {
+		if err := step(p); err != nil {
+			t.Fatalf("step %d error = %v", i, err)
+		}
+		e, err := portfolio.NewEvent(before, p, i+1, recordedAt.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("NewEvent(step %d) error = %v", i, err)
+		}
+		p.Version = i + 1
+		events = append(events, e)
+		states = append(states, p.Clone())
+		before = p.Clone()
+	}
+	return events, states
+}
+
+func TestNewEvent_Types(t *testing.T) {
+	events, _ := eventStream(t,
+		func(p *portfolio.Portfolio) error {
+			return p.AddPosition(portfolio.Position{CompanyTicker: "AAPL", Shares: 10, PurchasePrice: usd(1000)}, usd(10000))
+		},
+		func(p *portfolio.Portfolio) error {
+			return p.AddPosition(portfolio.Position{CompanyTicker: "AAPL", Shares: 5, PurchasePrice: usd(1200)}, usd(6000))
+		},
+		func(p *portfolio.Portfolio) error { return p.Deposit(usd(5000), time.Now(), "Bonus") },
+		func(p *portfolio.Portfolio) error { return p.Withdraw(usd(1000), time.Now(), "Rent") },
+		func(p *portfolio.Portfolio) error { return p.SetFeeSchedule(portfolio.FeeSchedule{FlatFee: 100}) },
+		func(p *portfolio.Portfolio) error { return p.RemovePosition("AAPL", 15, usd(18000)) },
+		func(p *portfolio.Portfolio) error {
+			trades := []portfolio.TradeRecommendation{{Action: portfolio.Enter, Ticker: "MSFT", Quantity: 2}}
+			_, err := p.ExecuteTrades(trades, map[string]portfolio.Money{"MSFT": usd(2000)}, "r1", time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC))
+			return err
+		},
+		func(p *portfolio.Portfolio) error { return nil },
+	)
+
+	want := []portfolio.EventType{
+		portfolio.PortfolioCreated, portfolio.PositionOpened, portfolio.PositionAdjusted, portfolio.CashDeposited,
+		portfolio.CashWithdrawn, portfolio.SettingsChanged, portfolio.PositionClosed, portfolio.Rebalanced, portfolio.PortfolioUpdated,
+	}
+	for i, e := range events {
+		if e.Type != want[i] || e.Version != i+1 {
+			t.Errorf("event %d = %v at version %d, want %v at version %d", i, e.Type, e.Version, want[i], i+1)
+		}
+	}
+	if closed := events[6]; len(closed.Closed) != 1 || closed.Closed[0] != "AAPL" || len(closed.Entries) != 2 || closed.Cash["USD"].Amount == 0 {
+		t.Errorf("PositionClosed event = %+v, want AAPL closed with the sale and its fee", closed)
+	}
+	if settings := events[5]; settings.Settings == nil || settings.Settings.FeeSchedule.FlatFee != 100 || len(settings.Entries) != 0 || settings.Cash != nil {
+		t.Errorf("SettingsChanged event = %+v, want only the new settings", settings)
+	}
+	if unchanged := events[8]; unchanged.Settings != nil || unchanged.Positions != nil || unchanged.Cash != nil || len(unchanged.Entries) != 0 {
+		t.Errorf("PortfolioUpdated event = %+v, want no changes", unchanged)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	events, states := eventStream(t,
+		func(p *portfolio.Portfolio) error {
+			return p.AddPosition(portfolio.Position{CompanyTicker: "AAPL", Shares: 10, PurchasePrice: usd(1000)}, usd(10000))
+		},
+		func(p *portfolio.Portfolio) error {
+			p.ForeignCash["EUR"] = portfolio.Money{Amount: 2500, Currency: "EUR"}
+			return p.SetInterestRate("EUR", 0.02, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC))
+		},
+		func(p *portfolio.Portfolio) error { return p.RemovePosition("AAPL", 4, usd(5000)) },
+		func(p *portfolio.Portfolio) error {
+			delete(p.ForeignCash, "EUR")
+			return p.SetDividendPolicy(portfolio.DividendPolicy{WithholdingTaxRates: map[string]float64{"USD": 0.15}})
+		},
+	)
+
+	t.Run("FromStart", func(t *testing.T) {
+		for i := range events {
+			got, err := portfolio.Replay(nil, events[:i+1])
+			if err != nil {
+				t.Fatalf("Replay(%d events) error = %v", i+1, err)
+			}
+			if !reflect.DeepEqual(got, states[i]) {
+				t.Errorf("Replay(%d events) = %+v, want %+v", i+1, got, states[i])
+			}
+		}
+	})
+
+	t.Run("FromSnapshot", func(t *testing.T) {
+		snapshot := states[1].Clone()
+		got, err := portfolio.Replay(snapshot, events[2:])
+		if err != nil {
+			t.Fatalf("Replay() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, states[len(states)-1]) {
+			t.Errorf("Replay() = %+v, want %+v", got, states[len(states)-1])
+		}
+		if !reflect.DeepEqual(snapshot, states[1]) {
+			t.Error("Replay() changed the snapshot")
+		}
+	})
+
+	t.Run("DeletedAndCreatedAgain", func(t *testing.T) {
+		deleted := portfolio.NewDeletedEvent("p1", len(events)+1, time.Now())
+		if got, err := portfolio.Replay(nil, append(events[:len(events):len(events)], deleted)); err != nil || got != nil {
+			t.Errorf("Replay(deleted) = %+v, %v, want nil", got, err)
+		}
+		again, _ := portfolio.NewPortfolio("p1", portfolio.Aggressive, usd(500))
+		created, err := portfolio.NewEvent(nil, again, deleted.Version+1, time.Now())
+		if err != nil {
+			t.Fatalf("NewEvent() error = %v", err)
+		}
+		got, err := portfolio.Replay(nil, append(events[:len(events):len(events)], deleted, created))
+		if err != nil || got == nil || got.RiskProfile != portfolio.Aggressive || len(got.Ledger) != 1 || len(got.Holdings) != 0 || got.Version != created.Version {
+			t.Errorf("Replay(created again) = %+v, %v, want the new portfolio at version %d", got, err, created.Version)
+		}
+	})
+
+	t.Run("Gap", func(t *testing.T) {
+		if _, err := portfolio.Replay(nil, []portfolio.Event{events[0], events[2]}); err == nil || !strings.Contains(err.Error(), "jumps") {
+			t.Errorf("Replay(gap) error = %v, want a gap error", err)
+		}
+	})
+}
+
+func TestNewEvent_LedgerIsAppendOnly(t *testing.T) {
+	_, states := eventStream(t, func(p *portfolio.Portfolio) error { return p.Deposit(usd(5000), time.Now(), "Bonus") })
+	truncated := states[1].Clone()
+	truncated.Ledger = truncated.Ledger[:1]
+	if _, err := portfolio.NewEvent(states[1], truncated, 3, time.Now()); err == nil {
+		t.Error("NewEvent() with entries removed from the ledger error = nil, want an error")
+	}
+}
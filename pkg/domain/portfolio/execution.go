@@ -0,0 +1,123 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Fill is a trade executed while applying a recommendation.
+// This is a value object.
+type Fill struct {
+	Action RecommendationAction
+	Ticker string
+	Shares int
+	Price  Money // Price per share, including slippage
+	Value  Money // Shares × Price
+	Fee    Money
+}
+
+// ExecutionReport describes the outcome of executing a set of trade recommendations.
+// This is a value object.
+type ExecutionReport struct {
+	PortfolioID  string
+	Reference    string  // Identifier of what was executed (e.g., a recommendation ID)
+	Fills        []Fill  // In execution order: sells, then buys
+	Fees         []Money // Total fees paid, per currency
+	ResidualCash []Money // Cash left per currency, base currency first
+	Opened       []PositionOpenedEvent
+	Adjusted     []PositionAdjustedEvent
+	ExecutedAt   time.Time
+}
+
+// ExecuteTrades applies trade recommendations through AddPosition and RemovePosition, at the
+// given prices (keyed by ticker) adjusted for the fee schedule's slippage. Sells are executed
+// before buys so that their proceeds can fund them; a Liquidate sells whatever is held.
+// Execution is all-or-nothing: if any trade fails, the portfolio is left unchanged.
+// Every ledger entry written references reference, and LastRebalanceTime is set to at.
+func (p *Portfolio) ExecuteTrades(trades []TradeRecommendation, prices map[string]Money, reference string, at time.Time) (*ExecutionReport, error) {
+	ordered := append([]TradeRecommendation(nil), trades...)
+	sort.SliceStable(ordered, func(i, j int) bool { return isSale(ordered[i].Action) && !isSale(ordered[j].Action) })
+
+	work := p.Clone()
+	report := &ExecutionReport{PortfolioID: p.ID, Reference: reference, ExecutedAt: at}
+	fees := make(map[string]int64)
+	for _, trade := range ordered {
+		fill, err := work.executeTrade(trade, prices, reference, report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to %s %s: %w", trade.Action, trade.Ticker, err)
+		}
+		report.Fills = append(report.Fills, fill)
+		fees[fill.Fee.Currency] += fill.Fee.Amount
+	}
+
+	work.LastRebalanceTime = at
+	work.UpdatedAt = at
+	*p = *work
+
+	currencies := make([]string, 0, len(fees))
+	for currency := range fees {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		report.Fees = append(report.Fees, Money{Amount: fees[currency], Currency: currency})
+	}
+	report.ResidualCash = p.cashByCurrency()
+	return report, nil
+}
+
+// executeTrade applies a single trade, stamping the ledger entries it writes with the reference
+// and the report's execution time and adding the position event it raises to the report.
+func (p *Portfolio) executeTrade(trade TradeRecommendation, prices map[string]Money, reference string, report *ExecutionReport) (Fill, error) {
+	quote, ok := prices[trade.Ticker]
+	if !ok || !quote.IsPositive() {
+		return Fill{}, Errors.New("no price available for " + trade.Ticker)
+	}
+	sale := isSale(trade.Action)
+	if trade.Action == UndefinedAction {
+		return Fill{}, Errors.New("trade action must be defined")
+	}
+	held := p.Holdings[trade.Ticker].Shares
+	shares := trade.Quantity
+	if trade.Action == Liquidate {
+		shares = held
+	}
+	if shares <= 0 {
+		return Fill{}, Errors.New("quantity must be positive")
+	}
+
+	price := p.FeeSchedule.FillPrice(quote, !sale)
+	value := price.Multiply(int64(shares))
+	first := len(p.Ledger)
+	var err error
+	if sale {
+		err = p.RemovePosition(trade.Ticker, shares, value)
+	} else {
+		err = p.AddPosition(Position{CompanyTicker: trade.Ticker, Shares: shares, PurchasePrice: price}, value)
+	}
+	if err != nil {
+		return Fill{}, err
+	}
+
+	fill := Fill{Action: trade.Action, Ticker: trade.Ticker, Shares: shares, Price: price, Value: value, Fee: Money{Currency: price.Currency}}
+	for i := first; i < len(p.Ledger); i++ {
+		p.Ledger[i].Reference = reference
+		p.Ledger[i].Timestamp = report.ExecutedAt
+		if p.Ledger[i].Type == Fee {
+			fill.Fee.Amount -= p.Ledger[i].Amount.Amount
+		}
+	}
+
+	if pos, ok := p.Holdings[trade.Ticker]; ok && held == 0 {
+		report.Opened = append(report.Opened, NewPositionOpenedEvent(p.ID, pos, report.ExecutedAt))
+	} else {
+		report.Adjusted = append(report.Adjusted, NewPositionAdjustedEvent(p.ID, trade.Ticker, held, pos.Shares, report.ExecutedAt))
+	}
+	return fill, nil
+}
+
+// isSale reports whether a recommendation action sells shares.
+func isSale(a RecommendationAction) bool {
+	return a == Reduce || a == Liquidate
+}
@@ -0,0 +1,99 @@
+package portfolio_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestPortfolio_ExecuteTrades(t *testing.T) {
+	at := time.Date(2024, 6, 3, 15, 30, 0, 0, time.UTC)
+	prices := map[string]portfolio.Money{
+		"AAPL": {Amount: 1000, Currency: "USD"},
+		"MSFT": {Amount: 2000, Currency: "USD"},
+		"GOOG": {Amount: 1000, Currency: "USD"},
+	}
+
+	t.Run("SellsFirstAndReports", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{FlatFee: 100}) // 10 AAPL, 900.00 cash
+		trades := []portfolio.TradeRecommendation{
+			{Action: portfolio.Enter, Ticker: "MSFT", Quantity: 10},
+			{Action: portfolio.Reduce, Ticker: "AAPL", Quantity: 4},
+		}
+		report, err := p.ExecuteTrades(trades, prices, "r1", at)
+		if err != nil {
+			t.Fatalf("ExecuteTrades() error = %v", err)
+		}
+		if len(report.Fills) != 2 || report.Fills[0].Ticker != "AAPL" || report.Fills[1].Ticker != "MSFT" {
+			t.Fatalf("ExecuteTrades() fills = %+v, want the AAPL sale before the MSFT purchase", report.Fills)
+		}
+		if report.Fills[1].Fee.Amount != 100 || len(report.Fees) != 1 || report.Fees[0].Amount != 200 {
+			t.Errorf("ExecuteTrades() fees = %+v (fill fee %+v), want 2.00 USD in total", report.Fees, report.Fills[1].Fee)
+		}
+		wantCash := int64(90000 + 4000 - 100 - 20000 - 100)
+		if p.CashBalance.Amount != wantCash || report.ResidualCash[0].Amount != wantCash {
+			t.Errorf("cash = %d, residual %+v; want %d", p.CashBalance.Amount, report.ResidualCash, wantCash)
+		}
+		if len(report.Opened) != 1 || report.Opened[0].CompanyTicker != "MSFT" || report.Opened[0].Shares != 10 {
+			t.Errorf("ExecuteTrades() opened = %+v, want MSFT with 10 shares", report.Opened)
+		}
+		if len(report.Adjusted) != 1 || report.Adjusted[0].OldShares != 10 || report.Adjusted[0].NewShares != 6 {
+			t.Errorf("ExecuteTrades() adjusted = %+v, want AAPL from 10 to 6 shares", report.Adjusted)
+		}
+		for _, entry := range p.Ledger[len(p.Ledger)-4:] {
+			if entry.Reference != "r1" || !entry.Timestamp.Equal(at) {
+				t.Errorf("ledger entry %+v, want reference r1 at %v", entry, at)
+			}
+		}
+		if !p.LastRebalanceTime.Equal(at) {
+			t.Errorf("LastRebalanceTime = %v, want %v", p.LastRebalanceTime, at)
+		}
+	})
+
+	t.Run("AllOrNothing", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		ledgerLen := len(p.Ledger)
+		trades := []portfolio.TradeRecommendation{
+			{Action: portfolio.Enter, Ticker: "MSFT", Quantity: 10},
+			{Action: portfolio.Enter, Ticker: "GOOG", Quantity: 100}, // Cannot be afforded after MSFT
+		}
+		_, err := p.ExecuteTrades(trades, prices, "r1", at)
+		if !errors.Is(err, portfolio.ErrInsufficientCash) {
+			t.Fatalf("ExecuteTrades() error = %v, want ErrInsufficientCash", err)
+		}
+		if _, ok := p.Holdings["MSFT"]; ok || p.CashBalance.Amount != 90000 || len(p.Ledger) != ledgerLen {
+			t.Errorf("portfolio changed by a failed execution: holdings %v, cash %d, %d ledger entries", p.Holdings, p.CashBalance.Amount, len(p.Ledger))
+		}
+	})
+
+	t.Run("LiquidateSellsEverythingHeld", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		report, err := p.ExecuteTrades([]portfolio.TradeRecommendation{{Action: portfolio.Liquidate, Ticker: "AAPL", Quantity: 7}}, prices, "r1", at)
+		if err != nil {
+			t.Fatalf("ExecuteTrades() error = %v", err)
+		}
+		if _, ok := p.Holdings["AAPL"]; ok || report.Fills[0].Shares != 10 {
+			t.Errorf("ExecuteTrades() fills = %+v, want all 10 AAPL sold", report.Fills)
+		}
+	})
+
+	t.Run("MissingPrice", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		if _, err := p.ExecuteTrades([]portfolio.TradeRecommendation{{Action: portfolio.Enter, Ticker: "TSLA", Quantity: 1}}, prices, "r1", at); err == nil {
+			t.Error("ExecuteTrades() expected an error without a price")
+		}
+	})
+}
+
+func TestPortfolio_Clone(t *testing.T) {
+	p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+	c := p.Clone()
+	c.Holdings["MSFT"] = portfolio.Position{CompanyTicker: "MSFT", Shares: 1}
+	c.Ledger[0].Description = "changed"
+	c.ForeignCash["EUR"] = portfolio.Money{Amount: 1, Currency: "EUR"}
+	if _, ok := p.Holdings["MSFT"]; ok || p.Ledger[0].Description == "changed" || len(p.ForeignCash) != 0 {
+		t.Error("Clone() shares state with the original portfolio")
+	}
+}
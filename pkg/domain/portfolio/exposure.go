@@ -0,0 +1,173 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Severity grades how far a portfolio is past a risk limit.
+type Severity int
+
+// Defines the severities of a risk threshold breach.
+const (
+	UndefinedSeverity Severity = iota // Default or unknown severity
+	Warning                           // Past the limit by less than a quarter of it
+	Critical                          // Past the limit by a quarter of it or more
+)
+
+// criticalOvershoot is the share of a limit by which a breach becomes critical.
+const criticalOvershoot = 0.25
+
+// String returns the string representation of a Severity.
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "Warning"
+	case Critical:
+		return "Critical"
+	default:
+		return "UndefinedSeverity"
+	}
+}
+
+// ParseSeverity converts a string to a Severity type.
+// It returns UndefinedSeverity if the string does not match any known severity.
+func ParseSeverity(s string) Severity {
+	switch s {
+	case "Warning":
+		return Warning
+	case "Critical":
+		return Critical
+	default:
+		return UndefinedSeverity
+	}
+}
+
+// severityOf grades a breach by how far actual is past limit, relative to the limit.
+func severityOf(limit, actual float64) Severity {
+	if limit == 0 || math.Abs(actual-limit)/limit >= criticalOvershoot {
+		return Critical
+	}
+	return Warning
+}
+
+// UnclassifiedSector groups the holdings whose sector is unknown. It is not subject to the
+// maximum sector weight.
+const UnclassifiedSector = "Unclassified"
+
+// PositionExposure is a holding's share of the portfolio.
+// This is a value object.
+type PositionExposure struct {
+	Ticker string
+	Sector string
+	Value  Money   // Market value in the base currency
+	Weight float64 // Share of the portfolio's total value
+}
+
+// SectorExposure is a sector's share of the portfolio.
+// This is a value object.
+type SectorExposure struct {
+	Sector  string
+	Value   Money   // Market value in the base currency
+	Weight  float64 // Share of the portfolio's total value
+	Tickers []string
+}
+
+// Exposure breaks a portfolio down by position and sector, and lists the limits of its risk
+// policy the portfolio breaches.
+// This is a value object.
+type Exposure struct {
+	PortfolioID string
+	Policy      RiskPolicy
+	AsOf        time.Time
+	TotalValue  Money
+	CashWeight  float64
+	Positions   []PositionExposure // Largest weight first
+	Sectors     []SectorExposure   // Largest weight first
+	Breaches    []RiskThresholdBreachedEvent
+}
+
+// Exposure computes the position and sector weights of the portfolio from a valuation and the
+// sector of each held ticker, and checks them and the cash buffer against the risk policy.
+// Tickers without a sector are grouped under UnclassifiedSector.
+func (p *Portfolio) Exposure(valuation *Valuation, sectors map[string]string) *Exposure {
+	policy := p.Policy()
+	exp := &Exposure{
+		PortfolioID: p.ID,
+		Policy:      policy,
+		AsOf:        valuation.AsOf,
+		TotalValue:  valuation.TotalValue,
+	}
+	total := float64(valuation.TotalValue.Amount)
+	weight := func(m Money) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return float64(m.Amount) / total
+	}
+	exp.CashWeight = weight(valuation.CashValue)
+
+	bySector := make(map[string]*SectorExposure)
+	for _, h := range valuation.Holdings {
+		sector := sectors[h.CompanyTicker]
+		if sector == "" {
+			sector = UnclassifiedSector
+		}
+		exp.Positions = append(exp.Positions, PositionExposure{
+			Ticker: h.CompanyTicker,
+			Sector: sector,
+			Value:  h.BaseMarketValue,
+			Weight: weight(h.BaseMarketValue),
+		})
+		se, ok := bySector[sector]
+		if !ok {
+			se = &SectorExposure{Sector: sector, Value: Money{Currency: valuation.BaseCurrency}}
+			bySector[sector] = se
+		}
+		se.Value.Amount += h.BaseMarketValue.Amount
+		se.Tickers = append(se.Tickers, h.CompanyTicker)
+	}
+	for _, se := range bySector {
+		se.Weight = weight(se.Value)
+		exp.Sectors = append(exp.Sectors, *se)
+	}
+	sort.SliceStable(exp.Positions, func(i, j int) bool { return exp.Positions[i].Weight > exp.Positions[j].Weight })
+	sort.Slice(exp.Sectors, func(i, j int) bool {
+		if exp.Sectors[i].Weight != exp.Sectors[j].Weight {
+			return exp.Sectors[i].Weight > exp.Sectors[j].Weight
+		}
+		return exp.Sectors[i].Sector < exp.Sectors[j].Sector
+	})
+
+	if total <= 0 {
+		return exp
+	}
+	breach := func(rule string, limit, actual float64, holdings []string, subject string) {
+		exp.Breaches = append(exp.Breaches, RiskThresholdBreachedEvent{
+			PortfolioID: p.ID,
+			Rule:        rule,
+			Severity:    severityOf(limit, actual),
+			Limit:       limit,
+			Actual:      actual,
+			Holdings:    holdings,
+			Description: fmt.Sprintf("%s is at %.2f%% against the %s policy's %s of %.2f%%", subject, actual*100, policy.Name, rule, limit*100),
+			Timestamp:   valuation.AsOf,
+		})
+	}
+	for _, pe := range exp.Positions {
+		if pe.Weight > policy.MaxPositionWeight {
+			breach("max position weight", policy.MaxPositionWeight, pe.Weight, []string{pe.Ticker}, pe.Ticker)
+		}
+	}
+	for _, se := range exp.Sectors {
+		if se.Sector != UnclassifiedSector && se.Weight > policy.MaxSectorWeight {
+			breach("max sector weight", policy.MaxSectorWeight, se.Weight, se.Tickers, se.Sector)
+		}
+	}
+	if exp.CashWeight < policy.MinCashBuffer {
+		breach("min cash buffer", policy.MinCashBuffer, exp.CashWeight, nil, "cash")
+	}
+	return exp
+}
@@ -0,0 +1,74 @@
+package portfolio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestPortfolio_Exposure(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	// 1000.00 split into AAPL 300.00, MSFT 160.00, XOM 100.00, an unclassified 50.00 and 390.00 cash.
+	p.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 30, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	p.Holdings["MSFT"] = portfolio.Position{CompanyTicker: "MSFT", Shares: 16, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	p.Holdings["XOM"] = portfolio.Position{CompanyTicker: "XOM", Shares: 10, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	p.Holdings["ZZZ"] = portfolio.Position{CompanyTicker: "ZZZ", Shares: 5, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	p.CashBalance.Amount = 39000
+
+	valuation, err := p.Valuate(nil, nil, asOf)
+	if err != nil {
+		t.Fatalf("Valuate() error = %v", err)
+	}
+	sectors := map[string]string{"AAPL": "Technology", "MSFT": "Technology", "XOM": "Energy"}
+	exp := p.Exposure(valuation, sectors)
+
+	if exp.Positions[0].Ticker != "AAPL" || exp.Positions[0].Weight != 0.30 {
+		t.Errorf("largest position = %+v, want AAPL at 0.30", exp.Positions[0])
+	}
+	if exp.Sectors[0].Sector != "Technology" || exp.Sectors[0].Weight != 0.46 || len(exp.Sectors[0].Tickers) != 2 {
+		t.Errorf("largest sector = %+v, want Technology at 0.46 with AAPL and MSFT", exp.Sectors[0])
+	}
+	if exp.CashWeight != 0.39 {
+		t.Errorf("CashWeight = %v, want 0.39", exp.CashWeight)
+	}
+
+	// Moderate: positions up to 15%, sectors up to 35%, at least 20% cash.
+	want := map[string]struct {
+		severity portfolio.Severity
+		holdings int
+	}{
+		"AAPL":       {portfolio.Critical, 1}, // 30% is double the limit
+		"MSFT":       {portfolio.Warning, 1},  // 16% is just past it
+		"Technology": {portfolio.Critical, 2}, // 46% is more than a quarter past 35%
+	}
+	if len(exp.Breaches) != len(want) {
+		t.Fatalf("Breaches = %+v, want %d", exp.Breaches, len(want))
+	}
+	for _, b := range exp.Breaches {
+		subject := b.Holdings[0]
+		if b.Rule == "max sector weight" {
+			subject = "Technology"
+		}
+		w, ok := want[subject]
+		if !ok || b.Severity != w.severity || len(b.Holdings) != w.holdings || b.PortfolioID != "p1" {
+			t.Errorf("unexpected breach %+v", b)
+		}
+	}
+}
+
+func TestPortfolio_Exposure_CashBuffer(t *testing.T) {
+	p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+	if err := p.SetRiskPolicy(portfolio.RiskPolicy{Name: "CashHeavy", MaxPositionWeight: 1, MaxSectorWeight: 1, MinCashBuffer: 0.95}); err != nil {
+		t.Fatalf("SetRiskPolicy() error = %v", err)
+	}
+	valuation, _ := p.Valuate(nil, nil, time.Now())
+	exp := p.Exposure(valuation, nil)
+	if len(exp.Breaches) != 1 || exp.Breaches[0].Rule != "min cash buffer" || exp.Breaches[0].Severity != portfolio.Warning {
+		t.Errorf("Breaches = %+v, want a min cash buffer warning", exp.Breaches)
+	}
+	if exp.Positions[0].Sector != portfolio.UnclassifiedSector {
+		t.Errorf("Sector = %q, want %q", exp.Positions[0].Sector, portfolio.UnclassifiedSector)
+	}
+}
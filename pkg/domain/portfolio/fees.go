@@ -0,0 +1,124 @@
+package portfolio
+
+import (
+	"math"
+	"time"
+)
+
+// FeeSchedule describes what a portfolio's broker charges for trading and currency conversion.
+// Fixed amounts are in the smallest unit of the trade currency. The zero value charges nothing.
+// This is a value object.
+type FeeSchedule struct {
+	FlatFee       int64   // Charged once per trade
+	PerShareFee   int64   // Charged per share traded
+	PercentageFee float64 // Fraction of the trade value (e.g., 0.001 for 0.1%)
+	MinFee        int64   // Lower bound for the fee of a trade (0 = none)
+	MaxFee        int64   // Upper bound for the fee of a trade (0 = none)
+	FXSpread      float64 // Fraction of the converted amount kept by the broker on currency exchanges
+	Slippage      float64 // Expected adverse price move when trading at market, as a fraction of the price
+}
+
+// Validate checks that the schedule's amounts and rates are in range.
+func (fs FeeSchedule) Validate() error {
+	if fs.FlatFee < 0 || fs.PerShareFee < 0 || fs.MinFee < 0 || fs.MaxFee < 0 {
+		return Errors.New("fee amounts cannot be negative")
+	}
+	if fs.MaxFee > 0 && fs.MinFee > fs.MaxFee {
+		return Errors.New("minimum fee cannot be greater than maximum fee")
+	}
+	if fs.PercentageFee < 0 || fs.PercentageFee >= 1 {
+		return Errors.New("percentage fee must be between 0 and 1")
+	}
+	if fs.FXSpread < 0 || fs.FXSpread >= 1 {
+		return Errors.New("FX spread must be between 0 and 1")
+	}
+	if fs.Slippage < 0 || fs.Slippage >= 1 {
+		return Errors.New("slippage must be between 0 and 1")
+	}
+	return nil
+}
+
+// TradeFee returns the fee for trading the given number of shares at price per share,
+// in the price's currency.
+func (fs FeeSchedule) TradeFee(shares float64, price Money) Money {
+	value := float64(price.Amount) * shares
+	fee := float64(fs.FlatFee) + float64(fs.PerShareFee)*shares + fs.PercentageFee*value
+	if fs.MinFee > 0 && fee < float64(fs.MinFee) {
+		fee = float64(fs.MinFee)
+	}
+	if fs.MaxFee > 0 && fee > float64(fs.MaxFee) {
+		fee = float64(fs.MaxFee)
+	}
+	return Money{Amount: int64(math.Round(fee)), Currency: price.Currency}
+}
+
+// tradeCost returns the value of buying the given number of shares at price per share plus
+// its fee, in the price's currency.
+func (fs FeeSchedule) tradeCost(shares int, price Money) int64 {
+	return price.Amount*int64(shares) + fs.TradeFee(float64(shares), price).Amount
+}
+
+// affordableShares returns the largest number of shares that can be bought at price per share
+// with budget, fee included, in the price's currency.
+func (fs FeeSchedule) affordableShares(price Money, budget int64) int {
+	if !price.IsPositive() || budget <= 0 {
+		return 0
+	}
+	unit, b := float64(price.Amount), float64(budget)
+	// The fee is linear in the shares unless held to its minimum or maximum. Paying each of these
+	// fees on the whole budget bounds the purchase from above, and the bound of the fee that
+	// applies to the largest affordable purchase is that purchase.
+	bounds := []float64{(b - float64(fs.FlatFee)) / (unit*(1+fs.PercentageFee) + float64(fs.PerShareFee))}
+	if fs.MinFee > 0 {
+		bounds = append(bounds, (b-float64(fs.MinFee))/unit)
+	}
+	if fs.MaxFee > 0 {
+		bounds = append(bounds, (b-float64(fs.MaxFee))/unit)
+	}
+	best := 0
+	for _, bound := range bounds {
+		// The fee is rounded to the smallest unit, which can cost the last share.
+		for n, steps := int(math.Floor(bound)), 0; n > best && steps < 2; n, steps = n-1, steps+1 {
+			if fs.tradeCost(n, price) <= budget {
+				best = n
+				break
+			}
+		}
+	}
+	return best
+}
+
+// FXSpreadCost returns the part of a converted amount kept by the broker.
+func (fs FeeSchedule) FXSpreadCost(converted Money) Money {
+	return converted.Scale(fs.FXSpread)
+}
+
+// FillPrice returns the expected execution price of a market order after slippage:
+// buys fill above the quoted price and sells below it.
+func (fs FeeSchedule) FillPrice(quote Money, buy bool) Money {
+	if buy {
+		return quote.Scale(1 + fs.Slippage)
+	}
+	return quote.Scale(1 - fs.Slippage)
+}
+
+// SetFeeSchedule replaces the portfolio's fee schedule. It applies to subsequent trades and exchanges.
+func (p *Portfolio) SetFeeSchedule(fs FeeSchedule) error {
+	if err := fs.Validate(); err != nil {
+		return err
+	}
+	p.FeeSchedule = fs
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// recordFee debits a fee from cash and records it in the ledger. Capitalized fees are part of
+// the cost basis of the shares bought; the others are expenses.
+func (p *Portfolio) recordFee(fee Money, ticker, description string, capitalized bool, on time.Time) {
+	if !fee.IsPositive() {
+		return
+	}
+	p.debitCash(fee)
+	p.record(LedgerEntry{Type: Fee, Amount: Money{Amount: -fee.Amount, Currency: fee.Currency}, Ticker: ticker,
+		Description: description, Capitalized: capitalized, Timestamp: on})
+}
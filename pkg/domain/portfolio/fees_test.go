@@ -0,0 +1,122 @@
+package portfolio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestFeeSchedule_TradeFee(t *testing.T) {
+	price := portfolio.Money{Amount: 10000, Currency: "USD"} // 100.00 per share
+	tests := []struct {
+		name     string
+		schedule portfolio.FeeSchedule
+		shares   float64
+		want     int64
+	}{
+		{"NoFees", portfolio.FeeSchedule{}, 10, 0},
+		{"FlatAndPerShare", portfolio.FeeSchedule{FlatFee: 100, PerShareFee: 1}, 10, 110},
+		{"Percentage", portfolio.FeeSchedule{PercentageFee: 0.001}, 10, 100},
+		{"MinimumApplies", portfolio.FeeSchedule{PercentageFee: 0.001, MinFee: 500}, 10, 500},
+		{"MaximumApplies", portfolio.FeeSchedule{PercentageFee: 0.01, MaxFee: 500}, 100, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.TradeFee(tt.shares, price); got.Amount != tt.want || got.Currency != "USD" {
+				t.Errorf("TradeFee() = %+v, want %d USD", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeeSchedule_Validate(t *testing.T) {
+	invalid := []portfolio.FeeSchedule{
+		{FlatFee: -1},
+		{MinFee: 500, MaxFee: 100},
+		{PercentageFee: 1.5},
+		{FXSpread: -0.01},
+		{Slippage: 1},
+	}
+	for _, fs := range invalid {
+		if err := fs.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error, got nil", fs)
+		}
+	}
+	if err := (portfolio.FeeSchedule{FlatFee: 100, MinFee: 100, MaxFee: 1000, PercentageFee: 0.001}).Validate(); err != nil {
+		t.Errorf("Validate() of a valid schedule error = %v", err)
+	}
+}
+
+func TestPortfolio_TradingFees(t *testing.T) {
+	usd := func(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+	newPortfolio := func(t *testing.T) *portfolio.Portfolio {
+		t.Helper()
+		p, _ := portfolio.NewPortfolio("fees", portfolio.Moderate, usd(100000))
+		if err := p.SetFeeSchedule(portfolio.FeeSchedule{FlatFee: 100}); err != nil {
+			t.Fatalf("SetFeeSchedule() error = %v", err)
+		}
+		pos, _ := portfolio.NewPosition("AAPL", 10, usd(1000))
+		if err := p.AddPosition(*pos, usd(10000)); err != nil {
+			t.Fatalf("AddPosition() error = %v", err)
+		}
+		return p
+	}
+
+	t.Run("BuyFeeIsPaidAndCapitalized", func(t *testing.T) {
+		p := newPortfolio(t)
+		if p.CashBalance.Amount != 89900 {
+			t.Errorf("CashBalance = %d, want 89900", p.CashBalance.Amount)
+		}
+		if pos := p.Holdings["AAPL"]; pos.PurchasePrice.Amount != 1010 {
+			t.Errorf("PurchasePrice = %d, want 1010 (fee included in cost basis)", pos.PurchasePrice.Amount)
+		}
+		last := p.Ledger[len(p.Ledger)-1]
+		if last.Type != portfolio.Fee || last.Amount.Amount != -100 || !last.Capitalized {
+			t.Errorf("Last ledger entry = %+v, want a capitalized fee of 100", last)
+		}
+	})
+
+	t.Run("BuyFailsWhenFeeIsNotCovered", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fees", portfolio.Moderate, usd(10000))
+		_ = p.SetFeeSchedule(portfolio.FeeSchedule{FlatFee: 1})
+		pos, _ := portfolio.NewPosition("AAPL", 10, usd(1000))
+		if err := p.AddPosition(*pos, usd(10000)); err == nil {
+			t.Error("AddPosition() without cash for the fee expected error, got nil")
+		}
+	})
+
+	t.Run("SellFeeAndProfitAndLoss", func(t *testing.T) {
+		p := newPortfolio(t)
+		if err := p.RemovePosition("AAPL", 5, usd(6000)); err != nil {
+			t.Fatalf("RemovePosition() error = %v", err)
+		}
+		if p.CashBalance.Amount != 95800 || p.Holdings["AAPL"].Shares != 5 {
+			t.Errorf("Cash = %d, shares = %d; want 95800 and 5", p.CashBalance.Amount, p.Holdings["AAPL"].Shares)
+		}
+
+		pnl, err := p.ProfitAndLoss(time.Time{}, time.Time{}, map[string]portfolio.Money{"AAPL": usd(1200)}, nil)
+		if err != nil {
+			t.Fatalf("ProfitAndLoss() error = %v", err)
+		}
+		// Realized: 6000 − 5 × 1010; unrealized: 5 × 1200 − 5 × 1010.
+		if pnl.RealizedGains.Amount != 950 || pnl.UnrealizedGains.Amount != 950 {
+			t.Errorf("Realized = %d, unrealized = %d; want 950 and 950", pnl.RealizedGains.Amount, pnl.UnrealizedGains.Amount)
+		}
+		if pnl.Fees.Amount != 200 || pnl.CapitalizedFees.Amount != 100 || pnl.NetProfit.Amount != 1800 {
+			t.Errorf("Fees = %d (capitalized %d), net = %d; want 200 (100), 1800", pnl.Fees.Amount, pnl.CapitalizedFees.Amount, pnl.NetProfit.Amount)
+		}
+	})
+
+	t.Run("FXSpread", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fees", portfolio.Moderate, usd(100000))
+		_ = p.SetFeeSchedule(portfolio.FeeSchedule{FXSpread: 0.01})
+		credited, err := p.ExchangeCash(usd(11000), "EUR", stubFXRates{"USD/EUR": 1 / 1.1}, time.Now())
+		if err != nil {
+			t.Fatalf("ExchangeCash() error = %v", err)
+		}
+		if credited.Amount != 9900 || p.CashIn("EUR").Amount != 9900 {
+			t.Errorf("Credited %d, EUR cash %d; want 9900 after a 1%% spread", credited.Amount, p.CashIn("EUR").Amount)
+		}
+	})
+}
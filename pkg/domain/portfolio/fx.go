@@ -0,0 +1,38 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFXRateNotFound is returned when no exchange rate is known for a currency pair on a date.
+var ErrFXRateNotFound = errors.New("fx rate not found")
+
+// FXRateProvider is the port through which the Portfolio context obtains exchange rates.
+// Implementations live in the infrastructure layer (e.g., a file-backed table or a market data API).
+type FXRateProvider interface {
+	// Rate returns how many units of the 'to' currency one unit of the 'from' currency
+	// was worth on the given date. Implementations should return the most recent rate
+	// known on or before that date, and ErrFXRateNotFound if there is none.
+	Rate(from, to string, on time.Time) (float64, error)
+}
+
+// ConvertMoney converts m into the target currency using the rate in effect on the given date.
+// Amounts already in the target currency are returned unchanged without consulting the provider.
+func ConvertMoney(m Money, to string, rates FXRateProvider, on time.Time) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	if rates == nil {
+		return Money{}, fmt.Errorf("cannot convert %s to %s without an fx rate provider: %w", m.Currency, to, ErrFXRateNotFound)
+	}
+	rate, err := rates.Rate(m.Currency, to, on)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to get %s/%s rate: %w", m.Currency, to, err)
+	}
+	if rate <= 0 {
+		return Money{}, fmt.Errorf("invalid %s/%s rate %v", m.Currency, to, rate)
+	}
+	return m.Convert(to, rate), nil
+}
@@ -0,0 +1,128 @@
+package portfolio
+
+import "time"
+
+// EntryType classifies a ledger entry.
+type EntryType int
+
+// Defines the kinds of movements recorded in a portfolio ledger.
+const (
+	UndefinedEntry  EntryType = iota // Default or unknown entry type
+	Deposit                          // External cash paid into the portfolio
+	Withdrawal                       // External cash taken out of the portfolio
+	Interest                         // Interest earned on idle cash
+	Buy                              // Purchase of shares
+	Sell                             // Sale of shares
+	FXExchange                       // One leg of a currency conversion
+	Dividend                         // Gross dividend received
+	WithholdingTax                   // Tax withheld at source from a dividend
+	CorporateAction                  // Share movement caused by a split, spinoff or ticker change
+	Fee                              // Trading commission or FX spread
+)
+
+// String returns the string representation of an EntryType.
+func (t EntryType) String() string {
+	switch t {
+	case Deposit:
+		return "Deposit"
+	case Withdrawal:
+		return "Withdrawal"
+	case Interest:
+		return "Interest"
+	case Buy:
+		return "Buy"
+	case Sell:
+		return "Sell"
+	case FXExchange:
+		return "FXExchange"
+	case Dividend:
+		return "Dividend"
+	case WithholdingTax:
+		return "WithholdingTax"
+	case CorporateAction:
+		return "CorporateAction"
+	case Fee:
+		return "Fee"
+	default:
+		return "UndefinedEntry"
+	}
+}
+
+// IsExternal reports whether the entry moves money across the portfolio boundary.
+// External flows are excluded from investment returns.
+func (t EntryType) IsExternal() bool {
+	return t == Deposit || t == Withdrawal
+}
+
+// LedgerEntry records a single movement of cash or shares in a portfolio.
+// Entries are append-only; corrections are recorded as new entries.
+// This is a value object.
+type LedgerEntry struct {
+	Sequence    int       // Position of the entry in the ledger, starting at 1
+	Type        EntryType // Kind of movement
+	Amount      Money     // Signed cash effect: positive when cash comes in, negative when it goes out
+	Ticker      string    // Company ticker for share movements, empty otherwise
+	Shares      float64   // Signed share quantity for share movements (fractional for reinvested dividends)
+	Reference   string    // Identifier of the business event behind the entry (e.g., a dividend ID)
+	CostBasis   Money     // Sell entries: cost basis of the shares sold, including capitalized fees
+	Capitalized bool      // Fee entries: the fee was added to the cost basis of the shares bought
+	Description string    // Free-text note (e.g., the source of a deposit)
+	Timestamp   time.Time // Effective time of the movement
+}
+
+// CashFlow is an external movement of money into (positive) or out of (negative) a portfolio.
+// This is a value object.
+type CashFlow struct {
+	Amount Money
+	Date   time.Time
+}
+
+// record appends an entry to the portfolio ledger, assigning its sequence number.
+func (p *Portfolio) record(entry LedgerEntry) {
+	entry.Sequence = len(p.Ledger) + 1
+	p.Ledger = append(p.Ledger, entry)
+}
+
+// hasReference reports whether any ledger entry was recorded for the given reference.
+func (p *Portfolio) hasReference(reference string) bool {
+	for _, entry := range p.Ledger {
+		if entry.Reference == reference {
+			return true
+		}
+	}
+	return false
+}
+
+// SharesHeldAt returns how many shares of ticker the portfolio held just before the given
+// time, by rolling the current holding back through the later share movements in the ledger.
+func (p *Portfolio) SharesHeldAt(ticker string, at time.Time) float64 {
+	held := 0.0
+	if pos, ok := p.Holdings[ticker]; ok {
+		held = pos.Quantity()
+	}
+	for _, entry := range p.Ledger {
+		if entry.Ticker == ticker && !entry.Timestamp.Before(at) {
+			held -= entry.Shares
+		}
+	}
+	if held < fractionEpsilon {
+		return 0
+	}
+	return held
+}
+
+// ExternalCashFlows returns the deposits and withdrawals whose effective time falls in
+// [from, to], in ledger order. A zero from or to leaves that side of the range open.
+func (p *Portfolio) ExternalCashFlows(from, to time.Time) []CashFlow {
+	var flows []CashFlow
+	for _, entry := range p.Ledger {
+		if !entry.Type.IsExternal() {
+			continue
+		}
+		if (!from.IsZero() && entry.Timestamp.Before(from)) || (!to.IsZero() && entry.Timestamp.After(to)) {
+			continue
+		}
+		flows = append(flows, CashFlow{Amount: entry.Amount, Date: entry.Timestamp})
+	}
+	return flows
+}
@@ -1,6 +1,12 @@
 package portfolio
 
-import "errors" // Standard Go errors package
+import (
+	"errors" // Standard Go errors package
+	"math"
+)
+
+// ErrCurrencyMismatch is returned when an operation combines amounts in different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
 
 // Money represents a monetary value, including currency.
 // This is a value object.
@@ -25,7 +31,7 @@ func NewMoney(amount int64, currency string) (*Money, error) {
 // It returns an error if the currencies do not match.
 func (m Money) Add(other Money) (Money, error) {
 	if m.Currency != other.Currency {
-		return Money{}, errors.New("currency mismatch")
+		return Money{}, ErrCurrencyMismatch
 	}
 	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
 }
@@ -34,11 +40,33 @@ func (m Money) Add(other Money) (Money, error) {
 // It returns an error if the currencies do not match.
 func (m Money) Subtract(other Money) (Money, error) {
 	if m.Currency != other.Currency {
-		return Money{}, errors.New("currency mismatch")
+		return Money{}, ErrCurrencyMismatch
 	}
 	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
 }
 
+// Multiply returns a new Money object representing m multiplied by a whole quantity
+// (e.g., a per-share price times a number of shares).
+func (m Money) Multiply(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// Scale returns a new Money object representing m multiplied by a fractional factor
+// (e.g., a tax rate or a fractional share quantity), rounded to the nearest smallest unit.
+func (m Money) Scale(factor float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: m.Currency}
+}
+
+// Convert returns the equivalent of m in the target currency using the given rate,
+// expressed as units of the target currency per unit of m's currency.
+// The result is rounded to the nearest smallest currency unit.
+func (m Money) Convert(currency string, rate float64) Money {
+	if m.Currency == currency {
+		return m
+	}
+	return Money{Amount: int64(math.Round(float64(m.Amount) * rate)), Currency: currency}
+}
+
 // IsZero checks if the monetary amount is zero.
 func (m Money) IsZero() bool {
 	return m.Amount == 0
@@ -0,0 +1,309 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// daysPerYear converts elapsed time into years for annualization and XIRR discounting.
+const daysPerYear = 365
+
+// Period identifies the window a return is measured over.
+type Period int
+
+// Defines the reporting periods.
+const (
+	UndefinedPeriod Period = iota // Default or unknown period
+	MonthToDate                   // Since the start of the current month
+	QuarterToDate                 // Since the start of the current quarter
+	YearToDate                    // Since the start of the current year
+	OneYear                       // The trailing twelve months
+	SinceInception                // Since the portfolio's first ledger entry
+	CustomPeriod                  // An arbitrary start date
+)
+
+// StandardPeriods are the periods reported by Performance.
+var StandardPeriods = []Period{MonthToDate, QuarterToDate, YearToDate, OneYear, SinceInception}
+
+// String returns the string representation of a Period.
+func (pe Period) String() string {
+	switch pe {
+	case MonthToDate:
+		return "MTD"
+	case QuarterToDate:
+		return "QTD"
+	case YearToDate:
+		return "YTD"
+	case OneYear:
+		return "1Y"
+	case SinceInception:
+		return "Inception"
+	case CustomPeriod:
+		return "Custom"
+	default:
+		return "UndefinedPeriod"
+	}
+}
+
+// ParsePeriod converts a string to a Period type.
+// It returns UndefinedPeriod if the string does not match any known period.
+func ParsePeriod(s string) Period {
+	switch s {
+	case "MTD":
+		return MonthToDate
+	case "QTD":
+		return QuarterToDate
+	case "YTD":
+		return YearToDate
+	case "1Y":
+		return OneYear
+	case "Inception":
+		return SinceInception
+	case "Custom":
+		return CustomPeriod
+	default:
+		return UndefinedPeriod
+	}
+}
+
+// Start returns when the period ending at asOf begins. SinceInception and CustomPeriod have no
+// fixed start and return the zero time.
+func (pe Period) Start(asOf time.Time) time.Time {
+	y, m, _ := asOf.Date()
+	switch pe {
+	case MonthToDate:
+		return time.Date(y, m, 1, 0, 0, 0, 0, asOf.Location())
+	case QuarterToDate:
+		return time.Date(y, (m-1)/3*3+1, 1, 0, 0, 0, 0, asOf.Location())
+	case YearToDate:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, asOf.Location())
+	case OneYear:
+		return asOf.AddDate(-1, 0, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// PeriodReturn is the performance of a portfolio over one period, in its base currency.
+// Periods shorter than a year are not annualized: their annualized figures equal the
+// cumulative ones.
+// This is a value object.
+type PeriodReturn struct {
+	Period        Period
+	From          time.Time // Start actually measured from: a snapshot, or inception
+	To            time.Time // Time of the valuation the period ends with
+	StartValue    Money     // Portfolio value at From (zero at inception)
+	EndValue      Money     // Portfolio value at To
+	NetFlows      Money     // Deposits minus withdrawals in (From, To]
+	TWR           float64   // Cumulative time-weighted return, chain-linked across external flows
+	MWR           float64   // Cumulative money-weighted return, implied by XIRR
+	Annualized    bool      // True when the period spans at least a year
+	AnnualizedTWR float64
+	AnnualizedMWR float64 // The XIRR for periods of a year or more
+}
+
+// Performance reports a portfolio's returns over the standard periods.
+// This is a value object.
+type Performance struct {
+	PortfolioID  string
+	BaseCurrency string
+	AsOf         time.Time
+	Returns      []PeriodReturn // In the order of StandardPeriods, followed by any custom period
+}
+
+// valuePoint is a portfolio value in the base currency at a point in time.
+type valuePoint struct {
+	at    time.Time
+	value float64
+}
+
+// Performance measures the portfolio's returns over each of the StandardPeriods ending at asOf.
+// history holds its valuations (typically the daily snapshots, oldest first); each period ends
+// with the latest one at or before asOf. Periods that start before the history does, but after
+// inception, cannot be measured and are left out.
+func (p *Portfolio) Performance(history []*Valuation, rates FXRateProvider, asOf time.Time) (*Performance, error) {
+	perf := &Performance{PortfolioID: p.ID, BaseCurrency: p.BaseCurrency, AsOf: asOf}
+	for _, period := range StandardPeriods {
+		r, err := p.periodReturn(period, history, rates, period.Start(asOf), asOf)
+		if errors.Is(err, ErrInsufficientHistory) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		perf.Returns = append(perf.Returns, *r)
+	}
+	return perf, nil
+}
+
+// ReturnOver measures the portfolio's return over [from, to] from its valuation history.
+// A zero from measures since inception.
+func (p *Portfolio) ReturnOver(history []*Valuation, rates FXRateProvider, from, to time.Time) (*PeriodReturn, error) {
+	period := CustomPeriod
+	if from.IsZero() {
+		period = SinceInception
+	}
+	return p.periodReturn(period, history, rates, from, to)
+}
+
+// periodReturn measures one period. The period begins at the latest valuation at or before
+// from or, when from is not after inception, at inception with a value of zero. The TWR
+// chain-links the sub-periods between consecutive valuations, treating the external flows of
+// each sub-period as invested at its start; the MWR solves the XIRR of the start value, the
+// flows and the end value.
+func (p *Portfolio) periodReturn(period Period, history []*Valuation, rates FXRateProvider, from, to time.Time) (*PeriodReturn, error) {
+	points := make([]valuePoint, 0, len(history))
+	for _, v := range history {
+		if v.PortfolioID != p.ID || v.AsOf.After(to) {
+			continue
+		}
+		if v.TotalValue.Currency != p.BaseCurrency {
+			return nil, fmt.Errorf("valuation of %v is in %s, not the base currency %s", v.AsOf, v.TotalValue.Currency, p.BaseCurrency)
+		}
+		points = append(points, valuePoint{at: v.AsOf, value: float64(v.TotalValue.Amount)})
+	}
+	if len(points) == 0 {
+		return nil, ErrInsufficientHistory
+	}
+
+	// Pick the point the period begins with; at inception the portfolio held nothing yet.
+	var begin valuePoint
+	rest := points
+	inception := p.inception()
+	fromInception := !inception.IsZero() && !from.After(inception)
+	if fromInception {
+		begin = valuePoint{at: inception}
+	} else {
+		i := len(points) - 1
+		for i >= 0 && points[i].at.After(from) {
+			i--
+		}
+		if i < 0 {
+			return nil, ErrInsufficientHistory
+		}
+		begin, rest = points[i], points[i+1:]
+	}
+	if len(rest) == 0 || !rest[len(rest)-1].at.After(begin.at) {
+		return nil, ErrInsufficientHistory
+	}
+	end := rest[len(rest)-1]
+
+	flows, err := p.baseFlows(rates, begin.at, end.at, fromInception)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chain-link the sub-periods between consecutive valuations.
+	returns, netFlows := subPeriodReturns(begin, rest, flows)
+	growth := 1.0
+	for _, r := range returns {
+		growth *= 1 + r
+	}
+
+	years := end.at.Sub(begin.at).Hours() / 24 / daysPerYear
+	xirr, err := solveXIRR(begin, flows, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the money-weighted return of portfolio %s: %w", p.ID, err)
+	}
+	r := &PeriodReturn{
+		Period:     period,
+		From:       begin.at,
+		To:         end.at,
+		StartValue: Money{Amount: int64(math.Round(begin.value)), Currency: p.BaseCurrency},
+		EndValue:   Money{Amount: int64(math.Round(end.value)), Currency: p.BaseCurrency},
+		NetFlows:   Money{Amount: int64(math.Round(netFlows)), Currency: p.BaseCurrency},
+		TWR:        growth - 1,
+		MWR:        math.Pow(1+xirr, years) - 1,
+		Annualized: years >= 1,
+	}
+	r.AnnualizedTWR, r.AnnualizedMWR = r.TWR, r.MWR
+	if r.Annualized {
+		r.AnnualizedTWR = math.Pow(growth, 1/years) - 1
+		r.AnnualizedMWR = xirr
+	}
+	return r, nil
+}
+
+// subPeriodReturns returns the return of each sub-period between begin and the following points,
+// treating the flows of a sub-period as invested at its start, and the total of the flows.
+// Sub-periods with no capital invested return zero.
+func subPeriodReturns(begin valuePoint, points, flows []valuePoint) ([]float64, float64) {
+	returns := make([]float64, 0, len(points))
+	prev, next, netFlows := begin, 0, 0.0
+	for _, point := range points {
+		subFlows := 0.0
+		for ; next < len(flows) && !flows[next].at.After(point.at); next++ {
+			subFlows += flows[next].value
+		}
+		netFlows += subFlows
+		r := 0.0
+		if invested := prev.value + subFlows; invested > 0 {
+			r = point.value/invested - 1
+		}
+		returns = append(returns, r)
+		prev = point
+	}
+	return returns, netFlows
+}
+
+// inception returns the time of the portfolio's first ledger entry, or the zero time.
+func (p *Portfolio) inception() time.Time {
+	if len(p.Ledger) == 0 {
+		return time.Time{}
+	}
+	return p.Ledger[0].Timestamp
+}
+
+// baseFlows returns the external cash flows in (from, to], or [from, to] when inclusive, in
+// the base currency at the rate on the day of each flow, oldest first.
+func (p *Portfolio) baseFlows(rates FXRateProvider, from, to time.Time, inclusive bool) ([]valuePoint, error) {
+	var flows []valuePoint
+	for _, flow := range p.ExternalCashFlows(from, to) {
+		if flow.Date.Equal(from) && !inclusive {
+			continue
+		}
+		base, err := ConvertMoney(flow.Amount, p.BaseCurrency, rates, flow.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the cash flow of %v: %w", flow.Date, err)
+		}
+		flows = append(flows, valuePoint{at: flow.Date, value: float64(base.Amount)})
+	}
+	sort.SliceStable(flows, func(i, j int) bool { return flows[i].at.Before(flows[j].at) })
+	return flows, nil
+}
+
+// solveXIRR returns the annual rate r at which the start value and the flows, compounded to
+// the end, equal the end value: the rate making
+//
+//	begin·(1+r)^-0 + Σ flow·(1+r)^-t − end·(1+r)^-T = 0
+//
+// with times in years since the start. It is found by bisection, which cannot diverge.
+func solveXIRR(begin valuePoint, flows []valuePoint, end valuePoint) (float64, error) {
+	years := func(at time.Time) float64 { return at.Sub(begin.at).Hours() / 24 / daysPerYear }
+	npv := func(r float64) float64 {
+		total := begin.value
+		for _, f := range flows {
+			total += f.value * math.Pow(1+r, -years(f.at))
+		}
+		return total - end.value*math.Pow(1+r, -years(end.at))
+	}
+	lo, hi := -0.999999, 1.0
+	for npv(lo)*npv(hi) > 0 {
+		if hi > 1e12 {
+			return 0, errors.New("no rate of return equates the cash flows")
+		}
+		hi *= 10
+	}
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if npv(lo)*npv(mid) <= 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
@@ -0,0 +1,121 @@
+package portfolio_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// newPerformanceTestPortfolio returns a USD portfolio whose ledger holds the given deposits,
+// oldest first; the first one marks its inception.
+func newPerformanceTestPortfolio(deposits ...portfolio.CashFlow) *portfolio.Portfolio {
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Currency: "USD"})
+	p.Ledger = nil
+	for i, d := range deposits {
+		p.Ledger = append(p.Ledger, portfolio.LedgerEntry{Sequence: i + 1, Type: portfolio.Deposit, Amount: d.Amount, Timestamp: d.Date})
+	}
+	return p
+}
+
+func usd(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+
+func snapshot(at time.Time, total int64) *portfolio.Valuation {
+	return &portfolio.Valuation{PortfolioID: "p1", BaseCurrency: "USD", AsOf: at, TotalValue: usd(total)}
+}
+
+func TestPortfolio_Performance_DepositTiming(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	// 1000.00 gains 50% in January; a 10000.00 deposit on February 1 then loses 20% with the rest.
+	p := newPerformanceTestPortfolio(portfolio.CashFlow{Amount: usd(100000), Date: day(1, 1)}, portfolio.CashFlow{Amount: usd(1000000), Date: day(2, 1)})
+	history := []*portfolio.Valuation{snapshot(day(1, 31), 150000), snapshot(day(2, 29), 920000)}
+
+	perf, err := p.Performance(history, nil, day(2, 29))
+	if err != nil {
+		t.Fatalf("Performance() error = %v", err)
+	}
+	got := make(map[portfolio.Period]portfolio.PeriodReturn)
+	for _, r := range perf.Returns {
+		got[r.Period] = r
+	}
+	if len(got) != len(portfolio.StandardPeriods) {
+		t.Fatalf("Returns = %+v, want every standard period", perf.Returns)
+	}
+
+	mtd := got[portfolio.MonthToDate]
+	if !mtd.From.Equal(day(1, 31)) || mtd.StartValue.Amount != 150000 || mtd.NetFlows.Amount != 1000000 {
+		t.Errorf("MTD = %+v, want it to start from the January 31 snapshot with the February deposit", mtd)
+	}
+	if math.Abs(mtd.TWR-(-0.2)) > 1e-9 {
+		t.Errorf("MTD TWR = %v, want -0.2", mtd.TWR)
+	}
+
+	// The deposit must not distort the TWR: 1.5 × 0.8 − 1 = 20%, although most of the money lost.
+	ytd := got[portfolio.YearToDate]
+	if !ytd.From.Equal(day(1, 1)) || ytd.StartValue.Amount != 0 || ytd.NetFlows.Amount != 1100000 {
+		t.Errorf("YTD = %+v, want it to start at inception with both deposits", ytd)
+	}
+	if math.Abs(ytd.TWR-0.2) > 1e-9 {
+		t.Errorf("YTD TWR = %v, want 0.2", ytd.TWR)
+	}
+	if ytd.MWR >= 0 {
+		t.Errorf("YTD MWR = %v, want a loss", ytd.MWR)
+	}
+	if ytd.Annualized || ytd.AnnualizedTWR != ytd.TWR || ytd.AnnualizedMWR != ytd.MWR {
+		t.Errorf("YTD = %+v, want returns under a year left unannualized", ytd)
+	}
+}
+
+func TestPortfolio_Performance_Annualized(t *testing.T) {
+	day := func(y int) time.Time { return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	p := newPerformanceTestPortfolio(portfolio.CashFlow{Amount: usd(100000), Date: day(2022)})
+	history := []*portfolio.Valuation{snapshot(day(2023), 110000), snapshot(day(2024), 121000)}
+
+	r, err := p.ReturnOver(history, nil, time.Time{}, day(2024))
+	if err != nil {
+		t.Fatalf("ReturnOver() error = %v", err)
+	}
+	// 730 days at 10% a year; without intermediate flows MWR and TWR agree.
+	if r.Period != portfolio.SinceInception || !r.Annualized {
+		t.Errorf("ReturnOver() = %+v, want an annualized since-inception return", r)
+	}
+	if math.Abs(r.TWR-0.21) > 1e-9 || math.Abs(r.AnnualizedTWR-0.1) > 1e-9 || math.Abs(r.AnnualizedMWR-0.1) > 1e-6 || math.Abs(r.MWR-0.21) > 1e-6 {
+		t.Errorf("ReturnOver() = %+v, want 21%% cumulative and 10%% annualized", r)
+	}
+
+	custom, err := p.ReturnOver(history, nil, day(2023), day(2024))
+	if err != nil {
+		t.Fatalf("ReturnOver(custom) error = %v", err)
+	}
+	if custom.Period != portfolio.CustomPeriod || math.Abs(custom.TWR-0.1) > 1e-9 || !custom.Annualized {
+		t.Errorf("ReturnOver(custom) = %+v, want a 10%% annualized custom return", custom)
+	}
+
+	// Without a snapshot before mid-2022 the period cannot be measured.
+	if _, err := p.ReturnOver(history, nil, day(2022).AddDate(0, 6, 0), day(2024)); err != portfolio.ErrInsufficientHistory {
+		t.Errorf("ReturnOver(mid-2022) error = %v, want ErrInsufficientHistory", err)
+	}
+}
+
+func TestPeriod_Start(t *testing.T) {
+	asOf := time.Date(2024, 8, 20, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period portfolio.Period
+		want   time.Time
+	}{
+		{portfolio.MonthToDate, time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{portfolio.QuarterToDate, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{portfolio.YearToDate, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{portfolio.OneYear, time.Date(2023, 8, 20, 15, 0, 0, 0, time.UTC)},
+		{portfolio.SinceInception, time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Start(asOf); !got.Equal(tt.want) {
+			t.Errorf("%s.Start() = %v, want %v", tt.period, got, tt.want)
+		}
+		if got := portfolio.ParsePeriod(tt.period.String()); got != tt.period {
+			t.Errorf("ParsePeriod(%q) = %v, want %v", tt.period.String(), got, tt.period)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProfitAndLoss breaks down a portfolio's result in its base currency. Realized gains, income
+// and fees cover the ledger entries in [From, To]; unrealized gains are those of the holdings
+// as of To. Trading fees are reported on their own line: capitalized fees are already part of
+// the cost basis behind the gains, the rest (selling commissions, FX spreads) are expenses.
+// This is a value object.
+type ProfitAndLoss struct {
+	PortfolioID     string
+	BaseCurrency    string
+	From            time.Time
+	To              time.Time
+	RealizedGains   Money // Sale proceeds minus the cost basis of the shares sold
+	UnrealizedGains Money // Market value minus cost basis of the current holdings
+	Dividends       Money // Gross dividends received
+	WithholdingTax  Money // Tax withheld from dividends (positive = paid)
+	Interest        Money // Interest earned on cash
+	Fees            Money // All trading fees and FX spreads paid (positive = paid)
+	CapitalizedFees Money // Part of Fees included in cost basis
+	NetProfit       Money // Realized + Unrealized + Dividends − WithholdingTax + Interest − expensed fees
+}
+
+// ProfitAndLoss computes the portfolio's P&L for the ledger entries in [from, to] (zero bounds
+// are open) and the unrealized gains of its holdings at the given prices. Amounts in other
+// currencies are converted into the base currency at the rate on the day of each entry.
+func (p *Portfolio) ProfitAndLoss(from, to time.Time, prices map[string]Money, rates FXRateProvider) (*ProfitAndLoss, error) {
+	asOf := to
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	zero := Money{Currency: p.BaseCurrency}
+	pnl := &ProfitAndLoss{
+		PortfolioID: p.ID, BaseCurrency: p.BaseCurrency, From: from, To: to,
+		RealizedGains: zero, UnrealizedGains: zero, Dividends: zero, WithholdingTax: zero,
+		Interest: zero, Fees: zero, CapitalizedFees: zero, NetProfit: zero,
+	}
+
+	for _, entry := range p.Ledger {
+		if (!from.IsZero() && entry.Timestamp.Before(from)) || (!to.IsZero() && entry.Timestamp.After(to)) {
+			continue
+		}
+		var target *Money
+		amount := entry.Amount
+		switch entry.Type {
+		case Sell:
+			target = &pnl.RealizedGains
+			amount = Money{Amount: entry.Amount.Amount - entry.CostBasis.Amount, Currency: entry.Amount.Currency}
+		case Dividend:
+			target = &pnl.Dividends
+		case WithholdingTax:
+			target = &pnl.WithholdingTax
+			amount.Amount = -amount.Amount
+		case Interest:
+			target = &pnl.Interest
+		case Fee:
+			target = &pnl.Fees
+			amount.Amount = -amount.Amount
+		default:
+			continue
+		}
+		base, err := ConvertMoney(amount, p.BaseCurrency, rates, entry.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert ledger entry %d: %w", entry.Sequence, err)
+		}
+		target.Amount += base.Amount
+		if entry.Type == Fee && entry.Capitalized {
+			pnl.CapitalizedFees.Amount += base.Amount
+		}
+	}
+
+	valuation, err := p.Valuate(prices, rates, asOf)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range valuation.Holdings {
+		basis, err := ConvertMoney(p.Holdings[h.CompanyTicker].CostBasis(), p.BaseCurrency, rates, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert cost basis of %s: %w", h.CompanyTicker, err)
+		}
+		pnl.UnrealizedGains.Amount += h.BaseMarketValue.Amount - basis.Amount
+	}
+
+	expensedFees := pnl.Fees.Amount - pnl.CapitalizedFees.Amount
+	pnl.NetProfit.Amount = pnl.RealizedGains.Amount + pnl.UnrealizedGains.Amount + pnl.Dividends.Amount -
+		pnl.WithholdingTax.Amount + pnl.Interest.Amount - expensedFees
+	return pnl, nil
+}
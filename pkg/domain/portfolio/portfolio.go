@@ -2,6 +2,7 @@ package portfolio
 
 import (
 	"errors"
+	"math"
 	"time"
 	// "github.com/google/uuid" // Example if using UUID for ID
 )
@@ -14,13 +15,26 @@ import (
 type Portfolio struct {
 	ID                string              // Unique identifier for the portfolio
 	Holdings          map[string]Position // Keyed by company ticker
-	CashBalance       Money               // Current cash balance
+	BaseCurrency      string              // Reporting currency for valuations (e.g., "USD")
+	CashBalance       Money               // Current cash balance in the base currency
+	ForeignCash       map[string]Money    // Cash held in other currencies, keyed by currency code
 	RiskProfile       RiskProfile         // Investor's risk tolerance
+	RiskPolicy        RiskPolicy          // Limits in force; the profile's default policy when unset
 	LastRebalanceTime time.Time           // Timestamp of the last rebalance
 	UpdatedAt         time.Time           // Timestamp of the last update to the portfolio
+
+	Ledger              []LedgerEntry      // Append-only record of cash and share movements
+	InterestRates       map[string]float64 // Annual interest rate paid on idle cash, keyed by currency code
+	LastInterestAccrual time.Time          // Time up to which interest has been credited
+	DividendPolicy      DividendPolicy     // Withholding tax and reinvestment settings for dividends
+	FeeSchedule         FeeSchedule        // Broker fees applied to trades and currency exchanges
+	Benchmark           Benchmark          // What performance is compared against; the default benchmark when unset
+
+	Version int // Number of times the portfolio has been saved; repositories use it to reject stale writes
 }
 
 // NewPortfolio creates a new Portfolio instance.
+// The currency of the initial cash becomes the portfolio's base reporting currency.
 func NewPortfolio(id string, riskProfile RiskProfile, initialCash Money) (*Portfolio, error) {
 	if id == "" {
 		// id = uuid.NewString() // Generate a new UUID if not provided
@@ -29,23 +43,71 @@ func NewPortfolio(id string, riskProfile RiskProfile, initialCash Money) (*Portf
 	if initialCash.Amount < 0 {
 		return nil, errors.New("initial cash balance cannot be negative") // Standard lib error
 	}
+	if initialCash.Currency == "" {
+		return nil, errors.New("invalid initial cash: currency cannot be empty")
+	}
 
-	return &Portfolio{
+	now := time.Now()
+	p := &Portfolio{
 		ID:                id,
 		Holdings:          make(map[string]Position),
+		BaseCurrency:      initialCash.Currency,
 		CashBalance:       initialCash,
+		ForeignCash:       make(map[string]Money),
 		RiskProfile:       riskProfile,
+		RiskPolicy:        riskProfile.DefaultPolicy(),
 		LastRebalanceTime: time.Time{}, // Zero value, indicating never rebalanced
-		UpdatedAt:         time.Now(),
-	}, nil
+		UpdatedAt:         now,
+	}
+	if initialCash.IsPositive() {
+		// The initial cash is the first external cash flow of the portfolio.
+		p.record(LedgerEntry{Type: Deposit, Amount: initialCash, Description: "initial cash", Timestamp: now})
+	}
+	return p, nil
+}
+
+// Clone returns a deep copy of the portfolio that shares no maps or slices with the original.
+func (p *Portfolio) Clone() *Portfolio {
+	c := *p
+	c.Holdings = make(map[string]Position, len(p.Holdings))
+	for ticker, pos := range p.Holdings {
+		c.Holdings[ticker] = pos
+	}
+	c.ForeignCash = make(map[string]Money, len(p.ForeignCash))
+	for currency, cash := range p.ForeignCash {
+		c.ForeignCash[currency] = cash
+	}
+	c.Ledger = append([]LedgerEntry(nil), p.Ledger...)
+	c.Benchmark.Constituents = append([]BenchmarkConstituent(nil), p.Benchmark.Constituents...)
+	if p.InterestRates != nil {
+		c.InterestRates = make(map[string]float64, len(p.InterestRates))
+		for currency, rate := range p.InterestRates {
+			c.InterestRates[currency] = rate
+		}
+	}
+	if p.DividendPolicy.WithholdingTaxRates != nil {
+		c.DividendPolicy.WithholdingTaxRates = make(map[string]float64, len(p.DividendPolicy.WithholdingTaxRates))
+		for currency, rate := range p.DividendPolicy.WithholdingTaxRates {
+			c.DividendPolicy.WithholdingTaxRates[currency] = rate
+		}
+	}
+	return &c
 }
 
 // --- Invariant Enforcement Methods (Placeholders) ---
 
-// ValidateCashBalance ensures the cash balance is not negative.
+// ValidateCashBalance ensures the cash balance is not negative in any currency.
 // This is an example of an invariant.
 func (p *Portfolio) ValidateCashBalance() bool {
-	return p.CashBalance.Amount >= 0
+	if p.CashBalance.Amount < 0 {
+		return false
+	}
+	for _, cash := range p.ForeignCash {
+		if cash.Amount < 0 {
+			return false
+		}
+	}
+	return true
 }
 
 // CheckRebalanceTrigger determines if a rebalance is needed based on certain criteria.
@@ -63,47 +125,90 @@ func (p *Portfolio) CheckRebalanceTrigger() bool {
 // --- Corrective Policy Methods (Placeholders) ---
 
 // AddPosition adds a new position or updates an existing one.
+// The cost and the trading fee from the fee schedule are paid from the cash held in the
+// position's trading currency. The fee is added to the cost basis, and buying into an existing
+// holding averages the purchase price over the combined shares.
+// It raises no event: only the ExecutionReport of ExecuteTrades carries the positions opened
+// and adjusted, and an event-sourced repository stores every saved change as an Event.
 func (p *Portfolio) AddPosition(position Position, cost Money) error {
-	if !p.ValidateCashBalance() || p.CashBalance.Amount < cost.Amount {
-		return Errors.New("insufficient cash balance to add position") // Custom error
+	if cost.Currency != position.PurchasePrice.Currency {
+		return Errors.New("cost currency does not match position currency")
+	}
+	fee := p.FeeSchedule.TradeFee(position.Quantity(), position.PurchasePrice)
+	if err := p.ensureCash(Money{Amount: cost.Amount + fee.Amount, Currency: cost.Currency}); err != nil {
+		return err
 	}
-	// More logic here: update holdings, subtract cost from cash balance
-	p.CashBalance.Amount -= cost.Amount // Assuming same currency
-	p.Holdings[position.CompanyTicker] = position // This is simplified; proper handling of existing positions needed
+	bought := position.Quantity()
+	basis := Money{Amount: cost.Amount + fee.Amount, Currency: cost.Currency}
+	if existing, ok := p.Holdings[position.CompanyTicker]; ok {
+		if existing.PurchasePrice.Currency != position.PurchasePrice.Currency {
+			return Errors.New("position currency does not match existing holding")
+		}
+		existing.addQuantity(bought, basis)
+		position = existing
+	} else {
+		position.PurchasePrice = Money{Currency: position.PurchasePrice.Currency}
+		position.Shares, position.FractionalShares = 0, 0
+		position.addQuantity(bought, basis)
+	}
+	p.debitCash(cost)
+	p.Holdings[position.CompanyTicker] = position
 	p.UpdatedAt = time.Now()
-	// Publish PositionOpenedEvent or PositionAdjustedEvent
+	p.record(LedgerEntry{Type: Buy, Amount: Money{Amount: -cost.Amount, Currency: cost.Currency}, Ticker: position.CompanyTicker, Shares: bought, Timestamp: p.UpdatedAt})
+	p.recordFee(fee, position.CompanyTicker, "buy commission", true, p.UpdatedAt)
 	return nil
 }
 
 // RemovePosition removes or reduces a position.
+// Selling every whole share closes the position; any fractional share is sold with it, so
+// a position holding only a fractional share is closed by removing zero shares.
+// The trading fee from the fee schedule is paid out of the proceeds.
+// Like AddPosition, it raises no event.
 func (p *Portfolio) RemovePosition(ticker string, sharesToRemove int, proceeds Money) error {
-	// More logic here: update holdings, add proceeds to cash balance
-	// Validate if position exists and has enough shares
-	p.CashBalance.Amount += proceeds.Amount // Assuming same currency
-	p.UpdatedAt = time.Now()
-	// Publish PositionAdjustedEvent or PositionClosedEvent
-	return nil
-}
+	existing, ok := p.Holdings[ticker]
+	if !ok {
+		return Errors.New("position for ticker " + ticker + " not found")
+	}
+	if sharesToRemove < 0 || sharesToRemove == 0 && existing.Shares > 0 {
+		return Errors.New("shares to remove must be positive")
+	}
+	if sharesToRemove > existing.Shares {
+		return Errors.New("cannot remove more shares than held")
+	}
+	if proceeds.Currency != existing.PurchasePrice.Currency {
+		return Errors.New("proceeds currency does not match position currency")
+	}
 
-// GenerateRebalanceRecommendations creates recommendations if a rebalance is triggered.
-func (p *Portfolio) GenerateRebalanceRecommendations() ([]string, error) {
-	// Placeholder: Implement logic to generate rebalancing recommendations.
-	// This would involve comparing current allocations to target allocations
-	// based on RiskProfile and company value scores (from another context).
-	// A Domain Event (RebalanceRecommendationCreatedEvent) should be published.
-	if p.CheckRebalanceTrigger() {
-		// recommendations := calculateRecommendations()
-		// p.LastRebalanceTime = time.Now() // Update after rebalance is *applied*, not just recommended
-		// Publish RebalanceRecommendationCreatedEvent
-		return []string{"Recommendation: Sell X, Buy Y"}, nil // Placeholder
+	sold := float64(sharesToRemove)
+	if sharesToRemove == existing.Shares {
+		sold = existing.Quantity()
+	}
+	fee := p.FeeSchedule.TradeFee(sold, Money{Amount: int64(math.Round(float64(proceeds.Amount) / sold)), Currency: proceeds.Currency})
+	if fee.Amount > proceeds.Amount {
+		if err := p.ensureCash(Money{Amount: fee.Amount - proceeds.Amount, Currency: fee.Currency}); err != nil {
+			return err
+		}
 	}
-	return nil, Errors.New("rebalance not currently triggered") // Custom error
+
+	soldBasis := existing.PurchasePrice.Scale(sold)
+	if sharesToRemove == existing.Shares {
+		delete(p.Holdings, ticker)
+	} else {
+		existing.Shares -= sharesToRemove // Average purchase price is unchanged by a sale
+		p.Holdings[ticker] = existing
+	}
+	p.creditCash(proceeds) // Proceeds are held in the currency they were received in
+	p.UpdatedAt = time.Now()
+	p.record(LedgerEntry{Type: Sell, Amount: proceeds, Ticker: ticker, Shares: -sold, CostBasis: soldBasis, Timestamp: p.UpdatedAt})
+	p.recordFee(fee, ticker, "sell commission", false, p.UpdatedAt)
+	return nil
 }
 
-// UpdateRiskProfile changes the portfolio's risk profile.
-// This might trigger a need for rebalancing.
+// UpdateRiskProfile changes the portfolio's risk profile and resets its risk policy to the
+// profile's default. This might trigger a need for rebalancing.
 func (p *Portfolio) UpdateRiskProfile(newProfile RiskProfile) {
 	p.RiskProfile = newProfile
+	p.RiskPolicy = newProfile.DefaultPolicy()
 	p.UpdatedAt = time.Now()
 	// Potentially publish RiskProfileChangedEvent
 	// May also trigger CheckRebalanceTrigger
@@ -120,6 +225,17 @@ type PositionOpenedEvent struct {
 	Timestamp     time.Time
 }
 
+// NewPositionOpenedEvent creates a new PositionOpenedEvent.
+func NewPositionOpenedEvent(portfolioID string, pos Position, at time.Time) PositionOpenedEvent {
+	return PositionOpenedEvent{
+		PortfolioID:   portfolioID,
+		CompanyTicker: pos.CompanyTicker,
+		Shares:        pos.Shares,
+		PurchasePrice: pos.PurchasePrice,
+		Timestamp:     at,
+	}
+}
+
 // PositionAdjustedEvent indicates an existing position was modified.
 type PositionAdjustedEvent struct {
 	PortfolioID   string
@@ -129,17 +245,41 @@ type PositionAdjustedEvent struct {
 	Timestamp     time.Time
 }
 
+// NewPositionAdjustedEvent creates a new PositionAdjustedEvent.
+func NewPositionAdjustedEvent(portfolioID, ticker string, oldShares, newShares int, at time.Time) PositionAdjustedEvent {
+	return PositionAdjustedEvent{
+		PortfolioID:   portfolioID,
+		CompanyTicker: ticker,
+		NewShares:     newShares,
+		OldShares:     oldShares,
+		Timestamp:     at,
+	}
+}
+
 // RebalanceRecommendationCreatedEvent indicates rebalancing recommendations have been generated.
 type RebalanceRecommendationCreatedEvent struct {
-	PortfolioID    string
-	Recommendations []string // Simplified representation
-	Timestamp      time.Time
+	PortfolioID     string
+	Recommendations []TradeRecommendation
+	Timestamp       time.Time
+}
+
+// NewRebalanceRecommendationCreatedEvent creates a new RebalanceRecommendationCreatedEvent.
+func NewRebalanceRecommendationCreatedEvent(portfolioID string, recs []TradeRecommendation) RebalanceRecommendationCreatedEvent {
+	return RebalanceRecommendationCreatedEvent{
+		PortfolioID:     portfolioID,
+		Recommendations: recs,
+		Timestamp:       time.Now(),
+	}
 }
 
-// RiskThresholdBreachedEvent indicates a risk limit or threshold has been breached.
-// (This is a more advanced event, might not be MVP).
+// RiskThresholdBreachedEvent indicates a limit of the portfolio's risk policy has been breached.
 type RiskThresholdBreachedEvent struct {
 	PortfolioID string
+	Rule        string   // Limit breached, e.g., "max sector weight"
+	Severity    Severity // How far past the limit the portfolio is
+	Limit       float64
+	Actual      float64
+	Holdings    []string // Tickers causing the breach, sorted
 	Description string
 	Timestamp   time.Time
 }
@@ -189,33 +189,6 @@ func TestPortfolio_RemovePosition(t *testing.T) {
 	})
 }
 
-func TestPortfolio_GenerateRebalanceRecommendations(t *testing.T) {
-	p, _ := portfolio.NewPortfolio("test", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
-
-	t.Run("RebalanceTriggered", func(t *testing.T) {
-		p.LastRebalanceTime = time.Time{} 
-		recs, err := p.GenerateRebalanceRecommendations()
-
-		if err != nil {
-			t.Fatalf("GenerateRebalanceRecommendations() error = %v, wantErr nil (for triggered rebalance)", err)
-		}
-		if len(recs) == 0 { 
-			t.Errorf("Expected recommendations, got empty slice")
-		}
-		if recs[0] != "Recommendation: Sell X, Buy Y" { 
-			t.Errorf("Unexpected recommendation content: %s", recs[0])
-		}
-	})
-
-	t.Run("RebalanceNotTriggered", func(t *testing.T) {
-		p.LastRebalanceTime = time.Now().Add(-10 * 24 * time.Hour) 
-		_, err := p.GenerateRebalanceRecommendations()
-		if err == nil {
-			t.Errorf("GenerateRebalanceRecommendations() expected error for non-triggered rebalance, got nil")
-		}
-	})
-}
-
 func TestPortfolio_UpdateRiskProfile(t *testing.T) {
 	p, _ := portfolio.NewPortfolio("test", portfolio.Conservative, portfolio.Money{Amount: 1000, Currency: "USD"})
 	originalUpdatedAt := p.UpdatedAt
@@ -1,15 +1,49 @@
 package portfolio
 
+import "math"
+
 // Position represents a holding of a specific company's stock within a portfolio.
 // This is a value object when considered within the context of a single portfolio,
 // but might be an entity if it had its own lifecycle and identity across portfolios (not the case here).
 type Position struct {
-	CompanyTicker string // Stock ticker of the company
-	Shares        int    // Number of shares held
-	PurchasePrice Money  // Average purchase price per share for this position
+	CompanyTicker    string  // Stock ticker of the company
+	Shares           int     // Number of whole shares held
+	FractionalShares float64 // Fraction of a share (0 ≤ f < 1) acquired through dividend reinvestment
+	PurchasePrice    Money   // Average purchase price per share for this position
 	// CurrentMarketValue could be added if needed, but might be calculated dynamically.
 }
 
+// Quantity returns the number of shares held, including any fractional share.
+func (pos Position) Quantity() float64 {
+	return float64(pos.Shares) + pos.FractionalShares
+}
+
+// CostBasis returns the total purchase cost of the position (average price × quantity).
+func (pos Position) CostBasis() Money {
+	return pos.PurchasePrice.Scale(pos.Quantity())
+}
+
+// addQuantity adds shares bought at the given total cost, re-averaging the purchase price
+// and carrying whole shares out of the fractional part.
+func (pos *Position) addQuantity(quantity float64, cost Money) {
+	totalCost := float64(pos.CostBasis().Amount) + float64(cost.Amount)
+	total := pos.Quantity() + quantity
+	pos.setQuantity(total)
+	if total > 0 {
+		pos.PurchasePrice.Amount = int64(math.Round(totalCost / total))
+	}
+}
+
+// setQuantity splits a share quantity into whole and fractional shares.
+func (pos *Position) setQuantity(quantity float64) {
+	whole := math.Floor(quantity + fractionEpsilon)
+	pos.Shares = int(whole)
+	pos.FractionalShares = math.Max(0, quantity-whole)
+}
+
+// fractionEpsilon absorbs floating point noise when splitting quantities into whole and fractional shares.
+const fractionEpsilon = 1e-9
+
 // NewPosition creates a new Position instance.
 // Basic validation can be added here.
 func NewPosition(ticker string, shares int, purchasePrice Money) (*Position, error) {
@@ -0,0 +1,17 @@
+package portfolio
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPriceNotFound is returned when no market price is known for a ticker on a date.
+var ErrPriceNotFound = errors.New("price not found")
+
+// PriceProvider is the port through which the Portfolio context obtains market prices.
+// Prices are quoted per share in the currency the security trades in.
+type PriceProvider interface {
+	// Price returns the most recent closing price known on or before the given date,
+	// or ErrPriceNotFound if there is none.
+	Price(ticker string, on time.Time) (Money, error)
+}
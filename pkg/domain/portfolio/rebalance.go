@@ -0,0 +1,300 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrRebalanceNotTriggered is returned when no holding has drifted far enough from its target
+// weight to be worth trading.
+var ErrRebalanceNotTriggered = errors.New("rebalance not currently triggered")
+
+// OrderSide tells whether an order buys or sells shares.
+type OrderSide int
+
+// Defines the order sides.
+const (
+	BuySide OrderSide = iota + 1
+	SellSide
+)
+
+// String returns the string representation of an OrderSide.
+func (s OrderSide) String() string {
+	switch s {
+	case BuySide:
+		return "Buy"
+	case SellSide:
+		return "Sell"
+	default:
+		return "UndefinedSide"
+	}
+}
+
+// RebalanceConstraints limits which trades the rebalancing engine proposes.
+// This is a value object.
+type RebalanceConstraints struct {
+	ToleranceBand float64 // Absolute weight drift tolerated before a holding is traded (e.g., 0.02 = 2 points)
+	MinTradeValue int64   // Smallest trade worth placing, in the smallest unit of the base currency
+	// PriceDriftTolerance is the largest relative price move allowed between recommending a
+	// trade and executing it (e.g., 0.02 = 2%).
+	PriceDriftTolerance float64
+}
+
+// DefaultRebalanceConstraints returns the constraints used when none are configured.
+func DefaultRebalanceConstraints() RebalanceConstraints {
+	return RebalanceConstraints{ToleranceBand: 0.02, MinTradeValue: 10000, PriceDriftTolerance: 0.02}
+}
+
+// RebalanceOrder is a whole-share trade proposed by the rebalancing engine.
+// This is a value object.
+type RebalanceOrder struct {
+	Ticker        string
+	Side          OrderSide
+	Shares        int
+	Quote         Money   // Market price per share the order was sized at
+	Price         Money   // Expected fill price per share in the trading currency, after slippage
+	Value         Money   // Shares × Price
+	EstimatedFee  Money   // Fee from the portfolio's fee schedule
+	CurrentWeight float64 // Weight of the holding in the portfolio before the trade
+	TargetWeight  float64
+}
+
+// TargetWeights turns the score inputs of candidate companies (keyed by ticker) into target
+// portfolio weights under a risk policy. Companies scoring below the policy's entry threshold or
+// carrying more debt than it allows get no weight; the rest share what is left after the cash
+// buffer in proportion to their scores, capped at the maximum position weight. Sectors above the
+// maximum sector weight are scaled down to it. Whatever cannot be allocated stays in cash.
+func TargetWeights(companies map[string]ScoreInputs, policy RiskPolicy) map[string]float64 {
+	eligible := make(map[string]float64)
+	for ticker, in := range companies {
+		if in.Score >= policy.MinEntryScore && in.Score > 0 && policy.allowsDebt(in.DebtToEquity) {
+			eligible[ticker] = in.Score
+		}
+	}
+	weights := make(map[string]float64, len(eligible))
+	remaining := 1 - policy.MinCashBuffer
+	maxWeight := policy.MaxPositionWeight
+
+	// Water-filling: allocate proportionally, cap the largest and redistribute the excess.
+	for len(eligible) > 0 && remaining > 1e-12 {
+		total := 0.0
+		for _, score := range eligible {
+			total += score
+		}
+		capped := false
+		for ticker, score := range eligible {
+			if remaining*score/total > maxWeight {
+				remaining -= maxWeight
+				weights[ticker] = maxWeight
+				delete(eligible, ticker)
+				capped = true
+			}
+		}
+		if capped {
+			continue
+		}
+		for ticker, score := range eligible {
+			weights[ticker] = remaining * score / total
+		}
+		break
+	}
+
+	sectors := make(map[string]float64)
+	for ticker, w := range weights {
+		if sector := companies[ticker].Sector; sector != "" {
+			sectors[sector] += w
+		}
+	}
+	for ticker := range weights {
+		if sum := sectors[companies[ticker].Sector]; sum > policy.MaxSectorWeight {
+			weights[ticker] *= policy.MaxSectorWeight / sum
+		}
+	}
+	return weights
+}
+
+// GenerateRebalanceRecommendations proposes the whole-share orders that bring the portfolio
+// towards the target weights (keyed by ticker; holdings without a target are sold). Orders are
+// sized at the given prices adjusted for the fee schedule's slippage, only holdings that drifted
+// beyond the tolerance band are traded, orders below the minimum trade value are dropped, and
+// buys are limited to the cash available in their currency after sells and fees and may not
+// draw the total cash below the risk policy's minimum cash buffer.
+// Holdings without a price are left untouched. Sells come first, then buys by size.
+func (p *Portfolio) GenerateRebalanceRecommendations(targets map[string]float64, prices map[string]Money, rates FXRateProvider, constraints RebalanceConstraints, asOf time.Time) ([]RebalanceOrder, error) {
+	sum := 0.0
+	for ticker, w := range targets {
+		if w < 0 || w > 1 {
+			return nil, Errors.New("target weight for " + ticker + " must be between 0 and 1")
+		}
+		sum += w
+	}
+	if sum > 1+1e-9 {
+		return nil, Errors.New("target weights cannot add up to more than 1")
+	}
+
+	valuation, err := p.Valuate(prices, rates, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if !valuation.TotalValue.IsPositive() {
+		return nil, ErrRebalanceNotTriggered
+	}
+	total := float64(valuation.TotalValue.Amount)
+	current := make(map[string]HoldingValuation, len(valuation.Holdings))
+	for _, h := range valuation.Holdings {
+		current[h.CompanyTicker] = h
+	}
+
+	tickers := make([]string, 0, len(targets)+len(p.Holdings))
+	for ticker := range targets {
+		tickers = append(tickers, ticker)
+	}
+	for ticker := range p.Holdings {
+		if _, ok := targets[ticker]; !ok {
+			tickers = append(tickers, ticker)
+		}
+	}
+	sort.Strings(tickers)
+
+	type candidate struct {
+		order RebalanceOrder
+		drift float64 // Base currency amount to trade
+	}
+	var sells, buys []candidate
+	for _, ticker := range tickers {
+		quote, ok := prices[ticker]
+		if !ok || !quote.IsPositive() {
+			continue // Cannot trade without a market price
+		}
+		held := current[ticker]
+		curW := float64(held.BaseMarketValue.Amount) / total
+		tgtW := targets[ticker]
+		liquidate := tgtW == 0 && p.Holdings[ticker].Shares > 0
+		if !liquidate && math.Abs(curW-tgtW) <= constraints.ToleranceBand {
+			continue
+		}
+		// Convert the base currency drift into the trading currency via the quote's base value.
+		unit, err := ConvertMoney(Money{Amount: quote.Amount, Currency: quote.Currency}, p.BaseCurrency, rates, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert price of %s: %w", ticker, err)
+		}
+		if !unit.IsPositive() {
+			continue
+		}
+		drift := (tgtW - curW) * total
+		order := RebalanceOrder{Ticker: ticker, Quote: quote, CurrentWeight: curW, TargetWeight: tgtW}
+		if drift < 0 {
+			order.Side = SellSide
+			order.Price = p.FeeSchedule.FillPrice(quote, false)
+			order.Shares = int(math.Floor(-drift / float64(unit.Amount)))
+			if liquidate || order.Shares > p.Holdings[ticker].Shares {
+				order.Shares = p.Holdings[ticker].Shares
+			}
+			sells = append(sells, candidate{order, -drift})
+		} else {
+			order.Side = BuySide
+			order.Price = p.FeeSchedule.FillPrice(quote, true)
+			order.Shares = int(math.Floor(drift / (float64(unit.Amount) * float64(order.Price.Amount) / float64(quote.Amount))))
+			buys = append(buys, candidate{order, drift})
+		}
+	}
+	sort.SliceStable(buys, func(i, j int) bool { return buys[i].drift > buys[j].drift })
+
+	cash := make(map[string]int64)
+	for _, m := range p.cashByCurrency() {
+		cash[m.Currency] = m.Amount
+	}
+	// Base currency cash that buys may spend without breaking the cash buffer.
+	spendable := float64(valuation.CashValue.Amount) - p.Policy().MinCashBuffer*total
+	baseAmount := func(amount int64, currency string) (float64, error) {
+		m, err := ConvertMoney(Money{Amount: amount, Currency: currency}, p.BaseCurrency, rates, asOf)
+		return float64(m.Amount), err
+	}
+	var orders []RebalanceOrder
+	accept := func(order RebalanceOrder) (bool, error) {
+		if order.Shares <= 0 {
+			return false, nil
+		}
+		order.Value = order.Price.Multiply(int64(order.Shares))
+		order.EstimatedFee = p.FeeSchedule.TradeFee(float64(order.Shares), order.Price)
+		baseValue, err := ConvertMoney(order.Value, p.BaseCurrency, rates, asOf)
+		if err != nil {
+			return false, err
+		}
+		if baseValue.Amount < constraints.MinTradeValue {
+			return false, nil
+		}
+		orders = append(orders, order)
+		return true, nil
+	}
+
+	for _, c := range sells {
+		ok, err := accept(c.order)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			last := orders[len(orders)-1]
+			proceeds := last.Value.Amount - last.EstimatedFee.Amount
+			cash[last.Price.Currency] += proceeds
+			base, err := baseAmount(proceeds, last.Price.Currency)
+			if err != nil {
+				return nil, err
+			}
+			spendable += base
+		}
+	}
+	for _, c := range buys {
+		order := c.order
+		available := cash[order.Price.Currency]
+		if order.Shares > 0 {
+			cost := p.FeeSchedule.tradeCost(order.Shares, order.Price)
+			base, err := baseAmount(cost, order.Price.Currency)
+			if err != nil {
+				return nil, err
+			}
+			if cost > available || base > spendable {
+				// Size the order to the budget in its currency, the spendable cash converted at
+				// the rate of its full cost; rounding may then leave it a share too big.
+				budget := available
+				if spendableHere := int64(math.Floor(spendable * float64(cost) / base)); spendableHere < budget {
+					budget = spendableHere
+				}
+				order.Shares = min(order.Shares, p.FeeSchedule.affordableShares(order.Price, budget))
+				for order.Shares > 0 {
+					cost := p.FeeSchedule.tradeCost(order.Shares, order.Price)
+					base, err := baseAmount(cost, order.Price.Currency)
+					if err != nil {
+						return nil, err
+					}
+					if cost <= available && base <= spendable {
+						break
+					}
+					order.Shares--
+				}
+			}
+		}
+		ok, err := accept(order)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			last := orders[len(orders)-1]
+			cost := last.Value.Amount + last.EstimatedFee.Amount
+			cash[last.Price.Currency] -= cost
+			base, err := baseAmount(cost, last.Price.Currency)
+			if err != nil {
+				return nil, err
+			}
+			spendable -= base
+		}
+	}
+
+	if len(orders) == 0 {
+		return nil, ErrRebalanceNotTriggered
+	}
+	return orders, nil
+}
@@ -0,0 +1,207 @@
+package portfolio_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestTargetWeights(t *testing.T) {
+	t.Run("CappedAtMaxPositionWeight", func(t *testing.T) {
+		companies := map[string]portfolio.ScoreInputs{"A": {Score: 90}, "B": {Score: 60}, "C": {Score: 50}}
+		weights := portfolio.TargetWeights(companies, portfolio.Moderate.DefaultPolicy())
+		if len(weights) != 2 {
+			t.Fatalf("TargetWeights() = %v, want only A and B (C is below the entry score)", weights)
+		}
+		if math.Abs(weights["A"]-0.15) > 1e-9 || math.Abs(weights["B"]-0.15) > 1e-9 {
+			t.Errorf("TargetWeights() = %v, want A and B capped at 0.15", weights)
+		}
+	})
+
+	t.Run("ProportionalToScore", func(t *testing.T) {
+		companies := map[string]portfolio.ScoreInputs{
+			"A": {Score: 80}, "B": {Score: 80}, "C": {Score: 80}, "D": {Score: 80}, "E": {Score: 40}, "F": {Score: 60},
+		}
+		weights := portfolio.TargetWeights(companies, portfolio.Aggressive.DefaultPolicy())
+		sum := 0.0
+		for _, w := range weights {
+			sum += w
+		}
+		if math.Abs(sum-0.95) > 1e-9 {
+			t.Errorf("TargetWeights() sum = %v, want the aggressive equity weight 0.95", sum)
+		}
+		if _, ok := weights["E"]; ok {
+			t.Errorf("TargetWeights() included E with a score below the entry threshold")
+		}
+		if math.Abs(weights["A"]/weights["F"]-80.0/60) > 1e-9 {
+			t.Errorf("TargetWeights() A/F = %v, want %v", weights["A"]/weights["F"], 80.0/60)
+		}
+	})
+
+	t.Run("ExcludesExcessiveDebt", func(t *testing.T) {
+		companies := map[string]portfolio.ScoreInputs{"A": {Score: 90, DebtToEquity: 2.5}, "B": {Score: 90, DebtToEquity: 0.5}}
+		weights := portfolio.TargetWeights(companies, portfolio.Moderate.DefaultPolicy())
+		if _, ok := weights["A"]; ok || len(weights) != 1 {
+			t.Errorf("TargetWeights() = %v, want only B (A is above the max debt-to-equity)", weights)
+		}
+	})
+
+	t.Run("ScalesDownCrowdedSector", func(t *testing.T) {
+		companies := map[string]portfolio.ScoreInputs{
+			"A": {Score: 90, Sector: "Technology"}, "B": {Score: 90, Sector: "Technology"},
+			"C": {Score: 90, Sector: "Technology"}, "D": {Score: 90, Sector: "Energy"},
+		}
+		weights := portfolio.TargetWeights(companies, portfolio.Moderate.DefaultPolicy())
+		tech := weights["A"] + weights["B"] + weights["C"]
+		if math.Abs(tech-0.35) > 1e-9 {
+			t.Errorf("TargetWeights() technology weight = %v, want the max sector weight 0.35", tech)
+		}
+		if math.Abs(weights["D"]-0.15) > 1e-9 {
+			t.Errorf("TargetWeights() D = %v, want 0.15", weights["D"])
+		}
+	})
+}
+
+// newRebalanceTestPortfolio returns a USD portfolio holding 10 AAPL bought at 10.00 with
+// 900.00 cash left, worth 1000.00 at the prices used below.
+func newRebalanceTestPortfolio(t *testing.T, fees portfolio.FeeSchedule) *portfolio.Portfolio {
+	t.Helper()
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	pos := portfolio.Position{CompanyTicker: "AAPL", Shares: 10, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	if err := p.AddPosition(pos, portfolio.Money{Amount: 10000, Currency: "USD"}); err != nil {
+		t.Fatalf("AddPosition() error = %v", err)
+	}
+	if err := p.SetFeeSchedule(fees); err != nil {
+		t.Fatalf("SetFeeSchedule() error = %v", err)
+	}
+	return p
+}
+
+func TestPortfolio_GenerateRebalanceRecommendations(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	prices := map[string]portfolio.Money{
+		"AAPL": {Amount: 1000, Currency: "USD"},
+		"MSFT": {Amount: 2000, Currency: "USD"},
+	}
+	constraints := portfolio.DefaultRebalanceConstraints()
+
+	t.Run("BuysTowardsTargets", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		orders, err := p.GenerateRebalanceRecommendations(map[string]float64{"AAPL": 0.5, "MSFT": 0.3}, prices, nil, constraints, asOf)
+		if err != nil {
+			t.Fatalf("GenerateRebalanceRecommendations() error = %v", err)
+		}
+		if len(orders) != 2 {
+			t.Fatalf("GenerateRebalanceRecommendations() = %+v, want 2 orders", orders)
+		}
+		if orders[0].Ticker != "AAPL" || orders[0].Side != portfolio.BuySide || orders[0].Shares != 40 {
+			t.Errorf("first order = %+v, want Buy 40 AAPL", orders[0])
+		}
+		if orders[1].Ticker != "MSFT" || orders[1].Side != portfolio.BuySide || orders[1].Shares != 15 {
+			t.Errorf("second order = %+v, want Buy 15 MSFT", orders[1])
+		}
+	})
+
+	t.Run("LimitedByCashAfterFees", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{FlatFee: 100})
+		allIn := portfolio.RiskPolicy{Name: "AllIn", MaxPositionWeight: 1, MaxSectorWeight: 1}
+		if err := p.SetRiskPolicy(allIn); err != nil {
+			t.Fatalf("SetRiskPolicy() error = %v", err)
+		}
+		orders, err := p.GenerateRebalanceRecommendations(map[string]float64{"AAPL": 0.5, "MSFT": 0.5}, prices, nil, constraints, asOf)
+		if err != nil {
+			t.Fatalf("GenerateRebalanceRecommendations() error = %v", err)
+		}
+		spent := int64(0)
+		for _, o := range orders {
+			spent += o.Value.Amount + o.EstimatedFee.Amount
+		}
+		if spent > p.CashBalance.Amount {
+			t.Errorf("orders spend %d, more than the %d cash available", spent, p.CashBalance.Amount)
+		}
+		if len(orders) != 2 || orders[0].Ticker != "MSFT" || orders[0].Shares != 25 || orders[1].Shares != 39 {
+			t.Errorf("GenerateRebalanceRecommendations() = %+v, want Buy 25 MSFT then 39 AAPL (trimmed to the cash left)", orders)
+		}
+	})
+
+	t.Run("KeepsCashBuffer", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{FlatFee: 100})
+		orders, err := p.GenerateRebalanceRecommendations(map[string]float64{"AAPL": 0.5, "MSFT": 0.5}, prices, nil, constraints, asOf)
+		if err != nil {
+			t.Fatalf("GenerateRebalanceRecommendations() error = %v", err)
+		}
+		// The moderate policy keeps 20% of the 1000.00 portfolio in cash, leaving 700.00 to spend.
+		if len(orders) != 2 || orders[0].Ticker != "MSFT" || orders[0].Shares != 25 || orders[1].Shares != 19 {
+			t.Errorf("GenerateRebalanceRecommendations() = %+v, want Buy 25 MSFT then 19 AAPL (trimmed to the cash buffer)", orders)
+		}
+	})
+
+	t.Run("SizedToLargeCashBalance", func(t *testing.T) {
+		allIn := portfolio.RiskPolicy{Name: "AllIn", MaxPositionWeight: 1, MaxSectorWeight: 1}
+		penny := map[string]portfolio.Money{"PNNY": {Amount: 1, Currency: "USD"}}
+		tests := []struct {
+			name string
+			cash int64
+			fees portfolio.FeeSchedule
+			want int
+		}{
+			{"PercentageFee", 100_000_000_000, portfolio.FeeSchedule{PercentageFee: 0.001, MinFee: 500}, 99_900_099_900},
+			{"MinFee", 1_000_000, portfolio.FeeSchedule{PercentageFee: 0.001, MinFee: 100_000}, 900_000},
+			{"MaxFee", 100_000_000_000, portfolio.FeeSchedule{PercentageFee: 0.001, MaxFee: 10_000}, 99_999_990_000},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				p, _ := portfolio.NewPortfolio("big", portfolio.Aggressive, portfolio.Money{Amount: tt.cash, Currency: "USD"})
+				_ = p.SetRiskPolicy(allIn)
+				_ = p.SetFeeSchedule(tt.fees)
+				orders, err := p.GenerateRebalanceRecommendations(map[string]float64{"PNNY": 1}, penny, nil, constraints, asOf)
+				if err != nil {
+					t.Fatalf("GenerateRebalanceRecommendations() error = %v", err)
+				}
+				if len(orders) != 1 || orders[0].Shares != tt.want {
+					t.Fatalf("GenerateRebalanceRecommendations() = %+v, want Buy %d PNNY", orders, tt.want)
+				}
+				if spent := orders[0].Value.Amount + orders[0].EstimatedFee.Amount; spent > tt.cash {
+					t.Errorf("order spends %d, more than the %d cash available", spent, tt.cash)
+				}
+			})
+		}
+	})
+
+	t.Run("LiquidatesUntargetedHoldings", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		orders, err := p.GenerateRebalanceRecommendations(map[string]float64{}, prices, nil, constraints, asOf)
+		if err != nil {
+			t.Fatalf("GenerateRebalanceRecommendations() error = %v", err)
+		}
+		if len(orders) != 1 || orders[0].Side != portfolio.SellSide || orders[0].Shares != 10 {
+			t.Errorf("GenerateRebalanceRecommendations() = %+v, want Sell 10 AAPL", orders)
+		}
+	})
+
+	t.Run("WithinToleranceBand", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		_, err := p.GenerateRebalanceRecommendations(map[string]float64{"AAPL": 0.11}, prices, nil, constraints, asOf)
+		if !errors.Is(err, portfolio.ErrRebalanceNotTriggered) {
+			t.Errorf("GenerateRebalanceRecommendations() error = %v, want ErrRebalanceNotTriggered", err)
+		}
+	})
+
+	t.Run("BelowMinimumTradeValue", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		_, err := p.GenerateRebalanceRecommendations(map[string]float64{"AAPL": 0.15}, prices, nil, constraints, asOf)
+		if !errors.Is(err, portfolio.ErrRebalanceNotTriggered) {
+			t.Errorf("GenerateRebalanceRecommendations() error = %v, want ErrRebalanceNotTriggered for a 50.00 trade", err)
+		}
+	})
+
+	t.Run("InvalidTargets", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		if _, err := p.GenerateRebalanceRecommendations(map[string]float64{"AAPL": 0.7, "MSFT": 0.6}, prices, nil, constraints, asOf); err == nil {
+			t.Error("GenerateRebalanceRecommendations() expected an error for weights adding up to more than 1")
+		}
+	})
+}
@@ -1,28 +1,103 @@
 package portfolio
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 // Import company.Sector if direct type usage is intended and allowed.
 // For now, we'll assume sector is a string that can be matched or
 // that a more sophisticated cross-context communication mechanism would be used later.
 // import "github.com/path-to-your-repo/pkg/domain/company"
 
+// ErrPortfolioNotFound is returned, possibly wrapped, by repositories asked for a portfolio they
+// do not have.
+var ErrPortfolioNotFound = errors.New("portfolio not found")
+
+// ErrVersionConflict is matched (via errors.Is) by every VersionConflictError.
+var ErrVersionConflict = errors.New("portfolio version conflict")
+
+// VersionConflictError is returned by Save when the stored portfolio is not the version the one
+// being saved was loaded from: someone else saved it, or deleted it, in the meantime.
+type VersionConflictError struct {
+	PortfolioID    string
+	Version        int // Version of the portfolio being saved
+	CurrentVersion int // Version stored, 0 when there is none
+}
+
+// Error returns the error message string.
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("portfolio %s was changed concurrently: saving version %d, stored version is %d",
+		e.PortfolioID, e.Version, e.CurrentVersion)
+}
+
+// Is makes errors.Is(err, ErrVersionConflict) match any VersionConflictError.
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ErrPreconditionFailed is matched (via errors.Is) by every PreconditionFailedError.
+var ErrPreconditionFailed = errors.New("portfolio version precondition failed")
+
+// PreconditionFailedError is returned by a change asked to apply only to some versions of a
+// portfolio, as read earlier by the client, when the portfolio loaded for the change is at
+// none of them.
+type PreconditionFailedError struct {
+	PortfolioID      string
+	ExpectedVersions []int // Versions the change was allowed to apply to
+	CurrentVersion   int   // Version of the portfolio loaded for the change
+}
+
+// Error returns the error message string.
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("portfolio %s is at version %d, not %v", e.PortfolioID, e.CurrentVersion, e.ExpectedVersions)
+}
+
+// Is makes errors.Is(err, ErrPreconditionFailed) match any PreconditionFailedError.
+func (e *PreconditionFailedError) Is(target error) bool {
+	return target == ErrPreconditionFailed
+}
+
+// CheckVersion returns a *PreconditionFailedError unless p is at one of the expected versions.
+// Without expected versions, any version is.
+func CheckVersion(p *Portfolio, expectedVersions ...int) error {
+	if len(expectedVersions) == 0 {
+		return nil
+	}
+	for _, v := range expectedVersions {
+		if v == p.Version {
+			return nil
+		}
+	}
+	return &PreconditionFailedError{PortfolioID: p.ID, ExpectedVersions: expectedVersions, CurrentVersion: p.Version}
+}
+
 // PortfolioRepository defines the interface for accessing and persisting Portfolio aggregates.
+// Portfolios passed in and returned are copies: changing one, its holdings included, has no
+// effect on the repository until it is saved. Searches return an empty, non-nil slice when
+// nothing matches.
 type PortfolioRepository interface {
-	// FindByID retrieves a portfolio by its unique identifier.
+	// FindByID retrieves a portfolio by its unique identifier. When there is none, it returns a
+	// nil portfolio and an error matching ErrPortfolioNotFound.
 	FindByID(id string) (*Portfolio, error)
 
-	// FindAll retrieves all portfolios.
+	// FindAll retrieves all portfolios, ordered by ID.
 	// This might be resource-intensive and should be used judiciously or with pagination in a real system.
 	FindAll() ([]*Portfolio, error)
 
-	// SearchByRiskProfile retrieves portfolios matching a specific risk profile.
+	// SearchByRiskProfile retrieves portfolios matching a specific risk profile, ordered by ID.
 	SearchByRiskProfile(riskProfile RiskProfile) ([]*Portfolio, error)
 
 	// Save creates a new portfolio or updates an existing one in the repository.
 	// Implementations should handle the logic for differentiating between create and update.
+	// The portfolio's Version must be the stored one (0 to create it); otherwise Save fails
+	// with a *VersionConflictError and stores nothing. On success the Version is incremented.
 	Save(portfolio *Portfolio) error
 
 	// Delete removes a portfolio from the repository by its ID.
 	// This method is optional for the initial MVP but good to define for completeness.
+	// Deleting a portfolio that is not there fails with an error matching ErrPortfolioNotFound.
 	Delete(id string) error
 
 	// Note: SearchBySector from the prompt implies a dependency on the Company context.
@@ -35,3 +110,62 @@ type PortfolioRepository interface {
 	// and the infrastructure layer would handle the join or multi-step query.
 	// Example: SearchByCompanySector(sectorName string) ([]*Portfolio, error)
 }
+
+// EventStore keeps the event streams of event-sourced portfolios, along with snapshots of their
+// state taken every so often so that rebuilding a portfolio does not replay its whole stream.
+// Events and snapshots passed in and returned are copies.
+type EventStore interface {
+	// Append adds an event to the end of its portfolio's stream and, when snapshot is not nil,
+	// stores it as the portfolio's state at the event's version, atomically. The event's Version
+	// must follow the last one of the stream (1 to start it); otherwise Append fails with a
+	// *VersionConflictError whose CurrentVersion is the stream's last version, and stores nothing.
+	Append(event Event, snapshot *Portfolio) error
+
+	// Load returns the latest snapshot of a portfolio taken at or before at, nil if there is
+	// none, and the events of its stream that follow the snapshot, up to the first one recorded
+	// after at. A zero at loads up to the end of the stream. A portfolio without a stream has
+	// neither snapshot nor events.
+	Load(portfolioID string, at time.Time) (*Portfolio, []Event, error)
+
+	// Events returns the whole stream of a portfolio, oldest first; an empty, non-nil slice
+	// when it has none.
+	Events(portfolioID string) ([]Event, error)
+
+	// StreamIDs returns the IDs of the portfolios that have a stream, deleted ones included,
+	// ordered.
+	StreamIDs() ([]string, error)
+}
+
+// PortfolioHistory gives access to the past of portfolios whose changes are kept as events.
+type PortfolioHistory interface {
+	// FindByIDAsOf rebuilds a portfolio as it was at the given time. When it did not exist then,
+	// it returns a nil portfolio and an error matching ErrPortfolioNotFound.
+	FindByIDAsOf(id string, at time.Time) (*Portfolio, error)
+
+	// Events returns the changes made to a portfolio, oldest first. When it has none, it returns
+	// an error matching ErrPortfolioNotFound.
+	Events(id string) ([]Event, error)
+}
+
+// RiskMetricsRepository stores the daily risk metrics of portfolios so that trends can be charted.
+type RiskMetricsRepository interface {
+	// Save stores the metrics of a portfolio for the day of their AsOf, replacing any metrics
+	// already stored for that portfolio and day.
+	Save(metrics *RiskMetrics) error
+
+	// FindByPortfolio retrieves a portfolio's metrics with AsOf in [from, to], oldest first.
+	// Zero dates leave that side of the range open.
+	FindByPortfolio(portfolioID string, from, to time.Time) ([]*RiskMetrics, error)
+}
+
+// ValuationSnapshotRepository stores end-of-day valuations of portfolios, building the history
+// performance analytics rely on.
+type ValuationSnapshotRepository interface {
+	// Save stores a valuation as the snapshot of its portfolio for the day of its AsOf,
+	// replacing any snapshot already stored for that portfolio and day.
+	Save(snapshot *Valuation) error
+
+	// FindByPortfolio retrieves a portfolio's snapshots with AsOf in [from, to], oldest first.
+	// Zero dates leave that side of the range open.
+	FindByPortfolio(portfolioID string, from, to time.Time) ([]*Valuation, error)
+}
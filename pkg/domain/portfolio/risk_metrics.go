@@ -0,0 +1,244 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrInsufficientHistory is returned when there are too few daily values to measure risk.
+var ErrInsufficientHistory = errors.New("insufficient price history")
+
+// TradingDaysPerYear annualizes daily statistics.
+const TradingDaysPerYear = 252
+
+// minRiskObservations is the smallest number of daily values risk metrics are computed from.
+const minRiskObservations = 3
+
+// VaR confidence levels and horizons reported by ComputeRiskMetrics.
+var (
+	varConfidences = []float64{0.95, 0.99}
+	varHorizons    = []int{1, 10}
+)
+
+// VaRMethod identifies how a value at risk was estimated.
+type VaRMethod int
+
+// Defines the value at risk estimation methods.
+const (
+	UndefinedVaRMethod VaRMethod = iota // Default or unknown method
+	Historical                          // Empirical quantile of past daily returns
+	Parametric                          // Normal distribution fitted to past daily returns
+)
+
+// String returns the string representation of a VaRMethod.
+func (m VaRMethod) String() string {
+	switch m {
+	case Historical:
+		return "Historical"
+	case Parametric:
+		return "Parametric"
+	default:
+		return "UndefinedVaRMethod"
+	}
+}
+
+// ParseVaRMethod converts a string to a VaRMethod type.
+// It returns UndefinedVaRMethod if the string does not match any known method.
+func ParseVaRMethod(s string) VaRMethod {
+	switch s {
+	case "Historical":
+		return Historical
+	case "Parametric":
+		return Parametric
+	default:
+		return UndefinedVaRMethod
+	}
+}
+
+// ValueAtRisk is the loss the portfolio should not exceed over a horizon with a given confidence.
+// This is a value object.
+type ValueAtRisk struct {
+	Method      VaRMethod
+	Confidence  float64 // e.g., 0.99
+	HorizonDays int
+	Ratio       float64 // Loss as a fraction of the portfolio's value
+	Loss        Money   // Loss in the base currency
+}
+
+// RiskMetrics summarizes the risk of a portfolio measured over its recent daily values.
+// This is a value object.
+type RiskMetrics struct {
+	PortfolioID  string
+	AsOf         time.Time
+	Benchmark    string  // Ticker beta is measured against; empty when beta is unavailable
+	Observations int     // Number of daily values used
+	Value        Money   // Portfolio value on AsOf, in the base currency
+	Volatility   float64 // Annualized standard deviation of daily returns
+	Beta         float64 // Sensitivity of daily returns to the benchmark's
+	MaxDrawdown  float64 // Largest peak-to-trough fall, as a fraction of the peak
+	VaR          []ValueAtRisk
+}
+
+// ValueHistory values the portfolio's current holdings and cash on each of the given days at
+// that day's closing prices and exchange rates, in the base currency. Holdings without a price
+// on a day are valued at their purchase price.
+func (p *Portfolio) ValueHistory(prices PriceProvider, rates FXRateProvider, days []time.Time) ([]float64, error) {
+	values := make([]float64, 0, len(days))
+	for _, day := range days {
+		quotes := make(map[string]Money, len(p.Holdings))
+		for ticker := range p.Holdings {
+			price, err := prices.Price(ticker, day)
+			if errors.Is(err, ErrPriceNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get price for %s: %w", ticker, err)
+			}
+			quotes[ticker] = price
+		}
+		valuation, err := p.Valuate(quotes, rates, day)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, float64(valuation.TotalValue.Amount))
+	}
+	return values, nil
+}
+
+// ComputeRiskMetrics measures risk from daily portfolio values (oldest first, in the smallest
+// unit of currency) and, optionally, the benchmark's closing prices on the same days.
+// Volatility is annualized over TradingDaysPerYear. VaR is reported at 95% and 99% over 1 and
+// 10 days, historically from the empirical quantile of daily returns and parametrically from a
+// normal fit; 10-day figures scale the daily return distribution by the square root of time.
+func ComputeRiskMetrics(portfolioID string, asOf time.Time, currency string, values []float64, benchmark string, benchmarkPrices []float64) (*RiskMetrics, error) {
+	if len(values) < minRiskObservations {
+		return nil, ErrInsufficientHistory
+	}
+	returns, err := dailyReturns(values)
+	if err != nil {
+		return nil, err
+	}
+	mean, stdev := meanStdev(returns)
+	last := values[len(values)-1]
+
+	m := &RiskMetrics{
+		PortfolioID:  portfolioID,
+		AsOf:         asOf,
+		Observations: len(values),
+		Value:        Money{Amount: int64(math.Round(last)), Currency: currency},
+		Volatility:   stdev * math.Sqrt(TradingDaysPerYear),
+		MaxDrawdown:  maxDrawdown(values),
+	}
+	if len(benchmarkPrices) == len(values) {
+		if benchReturns, err := dailyReturns(benchmarkPrices); err == nil {
+			if beta, ok := betaOf(returns, benchReturns); ok {
+				m.Benchmark, m.Beta = benchmark, beta
+			}
+		}
+	}
+
+	sorted := append([]float64(nil), returns...)
+	sort.Float64s(sorted)
+	for _, confidence := range varConfidences {
+		historical := -quantile(sorted, 1-confidence)
+		z := normalQuantile(confidence)
+		for _, horizon := range varHorizons {
+			scale := math.Sqrt(float64(horizon))
+			m.VaR = append(m.VaR,
+				newValueAtRisk(Historical, confidence, horizon, historical*scale, last, currency),
+				newValueAtRisk(Parametric, confidence, horizon, z*stdev*scale-mean*float64(horizon), last, currency),
+			)
+		}
+	}
+	return m, nil
+}
+
+// newValueAtRisk builds a ValueAtRisk from a loss ratio, flooring it at zero.
+func newValueAtRisk(method VaRMethod, confidence float64, horizon int, ratio, value float64, currency string) ValueAtRisk {
+	ratio = math.Max(ratio, 0)
+	return ValueAtRisk{
+		Method:      method,
+		Confidence:  confidence,
+		HorizonDays: horizon,
+		Ratio:       ratio,
+		Loss:        Money{Amount: int64(math.Round(ratio * value)), Currency: currency},
+	}
+}
+
+// dailyReturns returns the simple returns between consecutive values.
+func dailyReturns(values []float64) ([]float64, error) {
+	returns := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] <= 0 {
+			return nil, Errors.New("cannot compute returns from a non-positive value")
+		}
+		returns = append(returns, values[i]/values[i-1]-1)
+	}
+	return returns, nil
+}
+
+// meanStdev returns the mean and the sample standard deviation of xs.
+func meanStdev(xs []float64) (float64, float64) {
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	ss := 0.0
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(xs)-1))
+}
+
+// betaOf returns the covariance of returns with the benchmark's divided by the benchmark's
+// variance; ok is false when the benchmark did not move.
+func betaOf(returns, bench []float64) (float64, bool) {
+	meanR, _ := meanStdev(returns)
+	meanB, _ := meanStdev(bench)
+	cov, variance := 0.0, 0.0
+	for i := range returns {
+		cov += (returns[i] - meanR) * (bench[i] - meanB)
+		variance += (bench[i] - meanB) * (bench[i] - meanB)
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return cov / variance, true
+}
+
+// maxDrawdown returns the largest fall from a running peak, as a fraction of the peak.
+func maxDrawdown(values []float64) float64 {
+	peak, worst := values[0], 0.0
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+		if peak > 0 {
+			worst = math.Max(worst, (peak-v)/peak)
+		}
+	}
+	return worst
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly between ranks.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
+
+// normalQuantile returns the standard normal quantile for a confidence level.
+func normalQuantile(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*confidence-1)
+}
@@ -0,0 +1,93 @@
+package portfolio_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// priceFunc adapts a function to the PriceProvider port.
+type priceFunc func(ticker string, on time.Time) (portfolio.Money, error)
+
+func (f priceFunc) Price(ticker string, on time.Time) (portfolio.Money, error) { return f(ticker, on) }
+
+func TestComputeRiskMetrics(t *testing.T) {
+	asOf := time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)
+	// Daily returns +10%, -20%, +10%, +25%.
+	values := []float64{10000, 11000, 8800, 9680, 12100}
+	// The benchmark moves half as much as the portfolio every day.
+	benchmark := []float64{10000, 10500, 9450, 9922.5, 11162.8125}
+
+	m, err := portfolio.ComputeRiskMetrics("p1", asOf, "USD", values, "SPY", benchmark)
+	if err != nil {
+		t.Fatalf("ComputeRiskMetrics() error = %v", err)
+	}
+	if m.Value.Amount != 12100 || m.Observations != 5 {
+		t.Errorf("Value = %d, Observations = %d, want 12100 and 5", m.Value.Amount, m.Observations)
+	}
+	if math.Abs(m.MaxDrawdown-0.20) > 1e-9 {
+		t.Errorf("MaxDrawdown = %v, want 0.20", m.MaxDrawdown)
+	}
+	// Sample standard deviation of the returns is 0.1887.
+	if want := 0.18874586088176873 * math.Sqrt(252); math.Abs(m.Volatility-want) > 1e-9 {
+		t.Errorf("Volatility = %v, want %v", m.Volatility, want)
+	}
+	if m.Benchmark != "SPY" || math.Abs(m.Beta-2) > 1e-9 {
+		t.Errorf("Beta = %v against %q, want 2 against SPY", m.Beta, m.Benchmark)
+	}
+
+	if len(m.VaR) != 8 {
+		t.Fatalf("VaR has %d figures, want 2 methods × 2 confidences × 2 horizons", len(m.VaR))
+	}
+	for _, v := range m.VaR {
+		if v.Method == portfolio.Historical && v.Confidence == 0.95 && v.HorizonDays == 1 {
+			// 5% quantile interpolated between -20% and +10%.
+			if math.Abs(v.Ratio-0.155) > 1e-9 || math.Abs(float64(v.Loss.Amount)-1875.5) > 1 {
+				t.Errorf("1-day 95%% historical VaR = %+v, want 15.5%% of 121.00", v)
+			}
+		}
+		if v.Method == portfolio.Parametric && v.Confidence == 0.99 && v.HorizonDays == 10 {
+			want := 2.3263478740408408*0.18874586088176873*math.Sqrt(10) - 0.0625*10
+			if math.Abs(v.Ratio-want) > 1e-9 {
+				t.Errorf("10-day 99%% parametric VaR ratio = %v, want %v", v.Ratio, want)
+			}
+		}
+	}
+
+	t.Run("NoBenchmark", func(t *testing.T) {
+		m, err := portfolio.ComputeRiskMetrics("p1", asOf, "USD", values, "SPY", nil)
+		if err != nil || m.Benchmark != "" || m.Beta != 0 {
+			t.Errorf("ComputeRiskMetrics() = %+v, %v, want no beta without benchmark prices", m, err)
+		}
+	})
+
+	t.Run("InsufficientHistory", func(t *testing.T) {
+		if _, err := portfolio.ComputeRiskMetrics("p1", asOf, "USD", values[:2], "", nil); !errors.Is(err, portfolio.ErrInsufficientHistory) {
+			t.Errorf("ComputeRiskMetrics() error = %v, want ErrInsufficientHistory", err)
+		}
+	})
+}
+
+func TestPortfolio_ValueHistory(t *testing.T) {
+	p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{}) // 10 AAPL and 900.00 cash
+	day1 := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	prices := priceFunc(func(ticker string, on time.Time) (portfolio.Money, error) {
+		if on.Before(day2) {
+			return portfolio.Money{}, portfolio.ErrPriceNotFound
+		}
+		return portfolio.Money{Amount: 1500, Currency: "USD"}, nil
+	})
+
+	values, err := p.ValueHistory(prices, nil, []time.Time{day1, day2})
+	if err != nil {
+		t.Fatalf("ValueHistory() error = %v", err)
+	}
+	// Day 1 has no price, so AAPL is valued at cost.
+	if len(values) != 2 || values[0] != 100000 || values[1] != 105000 {
+		t.Errorf("ValueHistory() = %v, want [100000 105000]", values)
+	}
+}
@@ -0,0 +1,138 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRiskPolicyViolation is matched (via errors.Is) by every RiskPolicyViolationError.
+var ErrRiskPolicyViolation = errors.New("risk policy violation")
+
+// RiskPolicy holds the concrete limits a portfolio must respect. Each RiskProfile has a default
+// policy; custom policies can be defined through configuration.
+// This is a value object.
+type RiskPolicy struct {
+	Name              string
+	MaxPositionWeight float64 // Largest share of the portfolio's value in a single company
+	MaxSectorWeight   float64 // Largest share of the portfolio's value in a single sector
+	MinCashBuffer     float64 // Smallest share of the portfolio's value kept in cash
+	MinEntryScore     float64 // Lowest value score (0-100) for opening a position
+	MaxDebtToEquity   float64 // Highest debt-to-equity ratio of a company bought; 0 = no limit
+}
+
+// Validate checks that the policy's limits are consistent.
+func (rp RiskPolicy) Validate() error {
+	if rp.Name == "" {
+		return Errors.New("risk policy name cannot be empty")
+	}
+	if rp.MaxPositionWeight <= 0 || rp.MaxPositionWeight > 1 {
+		return Errors.New("max position weight must be greater than 0 and at most 1")
+	}
+	if rp.MaxSectorWeight < rp.MaxPositionWeight || rp.MaxSectorWeight > 1 {
+		return Errors.New("max sector weight must be between the max position weight and 1")
+	}
+	if rp.MinCashBuffer < 0 || rp.MinCashBuffer >= 1 {
+		return Errors.New("min cash buffer must be at least 0 and below 1")
+	}
+	if rp.MinEntryScore < 0 || rp.MinEntryScore > 100 {
+		return Errors.New("min entry score must be between 0 and 100")
+	}
+	if rp.MaxDebtToEquity < 0 {
+		return Errors.New("max debt-to-equity cannot be negative")
+	}
+	return nil
+}
+
+// allowsDebt reports whether a company with the given debt-to-equity ratio may be bought.
+func (rp RiskPolicy) allowsDebt(debtToEquity float64) bool {
+	return rp.MaxDebtToEquity == 0 || debtToEquity <= rp.MaxDebtToEquity
+}
+
+// RiskPolicyViolationError is returned when a purchase would break a rule of the risk policy.
+type RiskPolicyViolationError struct {
+	Policy string  // Name of the policy
+	Rule   string  // e.g., "max position weight"
+	Ticker string  // Company being bought
+	Limit  float64 // Limit set by the policy
+	Actual float64 // Value the purchase would reach
+}
+
+// Error returns the error message string.
+func (e *RiskPolicyViolationError) Error() string {
+	return fmt.Sprintf("buying %s would break the %s policy's %s: %.4g against a limit of %.4g",
+		e.Ticker, e.Policy, e.Rule, e.Actual, e.Limit)
+}
+
+// Is makes errors.Is(err, ErrRiskPolicyViolation) match any RiskPolicyViolationError.
+func (e *RiskPolicyViolationError) Is(target error) bool {
+	return target == ErrRiskPolicyViolation
+}
+
+// Policy returns the risk policy in force: the one set on the portfolio, or the default policy
+// of its risk profile if none was set.
+func (p *Portfolio) Policy() RiskPolicy {
+	if p.RiskPolicy.Name == "" {
+		return p.RiskProfile.DefaultPolicy()
+	}
+	return p.RiskPolicy
+}
+
+// SetRiskPolicy replaces the risk policy of the portfolio.
+func (p *Portfolio) SetRiskPolicy(policy RiskPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	p.RiskPolicy = policy
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// CheckPurchase verifies that buying ticker for baseCost (in the base currency) keeps the
+// portfolio within its risk policy. valuation is the portfolio's current valuation and companies
+// holds the score inputs of the company bought and of the companies held, keyed by ticker.
+// Opening a position requires the entry score; every purchase is checked against the debt,
+// position weight, sector weight and cash buffer limits. Purchases the cash cannot pay for are
+// left to AddPosition, which reports the shortfall.
+func (p *Portfolio) CheckPurchase(ticker string, baseCost Money, companies map[string]ScoreInputs, valuation *Valuation) error {
+	policy := p.Policy()
+	info := companies[ticker]
+	violation := func(rule string, limit, actual float64) error {
+		return &RiskPolicyViolationError{Policy: policy.Name, Rule: rule, Ticker: ticker, Limit: limit, Actual: actual}
+	}
+
+	if _, held := p.Holdings[ticker]; !held && info.Score < policy.MinEntryScore {
+		return violation("min entry score", policy.MinEntryScore, info.Score)
+	}
+	if !policy.allowsDebt(info.DebtToEquity) {
+		return violation("max debt-to-equity", policy.MaxDebtToEquity, info.DebtToEquity)
+	}
+
+	total := float64(valuation.TotalValue.Amount)
+	cost := float64(baseCost.Amount)
+	if total <= 0 || cost > float64(valuation.CashValue.Amount) {
+		return nil
+	}
+	position, sector := cost, cost
+	for _, h := range valuation.Holdings {
+		value := float64(h.BaseMarketValue.Amount)
+		if h.CompanyTicker == ticker {
+			position += value
+		} else if info.Sector != "" && companies[h.CompanyTicker].Sector == info.Sector {
+			sector += value
+		}
+	}
+	if sector < position {
+		sector = position
+	}
+	if weight := position / total; weight > policy.MaxPositionWeight {
+		return violation("max position weight", policy.MaxPositionWeight, weight)
+	}
+	if weight := sector / total; info.Sector != "" && weight > policy.MaxSectorWeight {
+		return violation("max sector weight", policy.MaxSectorWeight, weight)
+	}
+	if buffer := (float64(valuation.CashValue.Amount) - cost) / total; buffer < policy.MinCashBuffer {
+		return violation("min cash buffer", policy.MinCashBuffer, buffer)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package portfolio_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestRiskPolicy_Validate(t *testing.T) {
+	for _, profile := range []portfolio.RiskProfile{portfolio.Conservative, portfolio.Moderate, portfolio.Aggressive} {
+		if err := profile.DefaultPolicy().Validate(); err != nil {
+			t.Errorf("%s default policy Validate() error = %v", profile, err)
+		}
+	}
+	invalid := []portfolio.RiskPolicy{
+		{MaxPositionWeight: 0.1, MaxSectorWeight: 0.3},
+		{Name: "Zero", MaxSectorWeight: 0.3},
+		{Name: "SectorBelowPosition", MaxPositionWeight: 0.3, MaxSectorWeight: 0.2},
+		{Name: "AllCash", MaxPositionWeight: 0.1, MaxSectorWeight: 0.3, MinCashBuffer: 1},
+		{Name: "Score", MaxPositionWeight: 0.1, MaxSectorWeight: 0.3, MinEntryScore: 101},
+		{Name: "Debt", MaxPositionWeight: 0.1, MaxSectorWeight: 0.3, MaxDebtToEquity: -1},
+	}
+	for _, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil, want an error", policy)
+		}
+	}
+}
+
+func TestPortfolio_Policy(t *testing.T) {
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Conservative, portfolio.Money{Amount: 100000, Currency: "USD"})
+	if got := p.Policy(); got != portfolio.Conservative.DefaultPolicy() {
+		t.Errorf("Policy() = %+v, want the conservative default", got)
+	}
+	custom := portfolio.RiskPolicy{Name: "Income", MaxPositionWeight: 0.05, MaxSectorWeight: 0.2, MinCashBuffer: 0.1}
+	if err := p.SetRiskPolicy(custom); err != nil {
+		t.Fatalf("SetRiskPolicy() error = %v", err)
+	}
+	if got := p.Policy(); got != custom {
+		t.Errorf("Policy() = %+v, want %+v", got, custom)
+	}
+	p.UpdateRiskProfile(portfolio.Aggressive)
+	if got := p.Policy(); got != portfolio.Aggressive.DefaultPolicy() {
+		t.Errorf("Policy() after UpdateRiskProfile = %+v, want the aggressive default", got)
+	}
+}
+
+func TestPortfolio_CheckPurchase(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	prices := map[string]portfolio.Money{"AAPL": {Amount: 1000, Currency: "USD"}}
+	narrow := portfolio.RiskPolicy{Name: "Narrow", MaxPositionWeight: 0.15, MaxSectorWeight: 0.20, MinCashBuffer: 0.10}
+	bufferOnly := portfolio.RiskPolicy{Name: "BufferOnly", MaxPositionWeight: 1, MaxSectorWeight: 1, MinCashBuffer: 0.20}
+
+	// The moderate portfolio is worth 1000.00: 100.00 in AAPL and 900.00 in cash.
+	tests := []struct {
+		name     string
+		policy   *portfolio.RiskPolicy
+		ticker   string
+		cost     int64
+		company  portfolio.ScoreInputs
+		wantRule string
+	}{
+		{"WithinPolicy", nil, "MSFT", 10000, portfolio.ScoreInputs{Score: 80, DebtToEquity: 0.5}, ""},
+		{"EntryScore", nil, "MSFT", 5000, portfolio.ScoreInputs{Score: 50}, "min entry score"},
+		{"HeldTickerSkipsEntryScore", nil, "AAPL", 2000, portfolio.ScoreInputs{Score: 50, Sector: "Technology"}, ""},
+		{"DebtToEquity", nil, "MSFT", 5000, portfolio.ScoreInputs{Score: 80, DebtToEquity: 2.5}, "max debt-to-equity"},
+		{"PositionWeight", nil, "AAPL", 6000, portfolio.ScoreInputs{Score: 80, Sector: "Technology"}, "max position weight"},
+		{"SectorWeight", &narrow, "MSFT", 12000, portfolio.ScoreInputs{Score: 80, Sector: "Technology"}, "max sector weight"},
+		{"OtherSector", &narrow, "XOM", 12000, portfolio.ScoreInputs{Score: 80, Sector: "Energy"}, ""},
+		{"CashBuffer", &bufferOnly, "MSFT", 75000, portfolio.ScoreInputs{Score: 80}, "min cash buffer"},
+		{"UnaffordableLeftToAddPosition", &bufferOnly, "MSFT", 95000, portfolio.ScoreInputs{Score: 80}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+			if tt.policy != nil {
+				if err := p.SetRiskPolicy(*tt.policy); err != nil {
+					t.Fatalf("SetRiskPolicy() error = %v", err)
+				}
+			}
+			valuation, err := p.Valuate(prices, nil, asOf)
+			if err != nil {
+				t.Fatalf("Valuate() error = %v", err)
+			}
+			companies := map[string]portfolio.ScoreInputs{"AAPL": {Score: 80, Sector: "Technology"}, tt.ticker: tt.company}
+
+			err = p.CheckPurchase(tt.ticker, portfolio.Money{Amount: tt.cost, Currency: "USD"}, companies, valuation)
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Errorf("CheckPurchase() error = %v, want nil", err)
+				}
+				return
+			}
+			var violation *portfolio.RiskPolicyViolationError
+			if !errors.As(err, &violation) || violation.Rule != tt.wantRule {
+				t.Fatalf("CheckPurchase() error = %v, want a %s violation", err, tt.wantRule)
+			}
+			if !errors.Is(err, portfolio.ErrRiskPolicyViolation) {
+				t.Errorf("errors.Is(err, ErrRiskPolicyViolation) = false, want true")
+			}
+		})
+	}
+}
@@ -39,3 +39,16 @@ func ParseRiskProfile(s string) RiskProfile {
 		return UndefinedProfile
 	}
 }
+
+// DefaultPolicy returns the risk policy attached to the profile.
+// Undefined profiles get the moderate policy.
+func (rp RiskProfile) DefaultPolicy() RiskPolicy {
+	switch rp {
+	case Conservative:
+		return RiskPolicy{Name: "Conservative", MaxPositionWeight: 0.10, MaxSectorWeight: 0.25, MinCashBuffer: 0.40, MinEntryScore: 70, MaxDebtToEquity: 1.0}
+	case Aggressive:
+		return RiskPolicy{Name: "Aggressive", MaxPositionWeight: 0.25, MaxSectorWeight: 0.50, MinCashBuffer: 0.05, MinEntryScore: 50, MaxDebtToEquity: 3.0}
+	default:
+		return RiskPolicy{Name: "Moderate", MaxPositionWeight: 0.15, MaxSectorWeight: 0.35, MinCashBuffer: 0.20, MinEntryScore: 60, MaxDebtToEquity: 2.0}
+	}
+}
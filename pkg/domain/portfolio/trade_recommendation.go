@@ -0,0 +1,182 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RecommendationAction is the kind of decision a trade recommendation asks for.
+type RecommendationAction int
+
+// Defines the four recommendation types.
+const (
+	UndefinedAction RecommendationAction = iota // Default or unknown action
+	Enter                                       // Open a new position
+	Increase                                    // Add to an existing position
+	Reduce                                      // Sell part of a position
+	Liquidate                                   // Sell the whole position
+)
+
+// String returns the string representation of a RecommendationAction.
+func (a RecommendationAction) String() string {
+	switch a {
+	case Enter:
+		return "Enter"
+	case Increase:
+		return "Increase"
+	case Reduce:
+		return "Reduce"
+	case Liquidate:
+		return "Liquidate"
+	default:
+		return "UndefinedAction"
+	}
+}
+
+// ParseRecommendationAction converts a string to a RecommendationAction.
+// It is case-insensitive. Returns UndefinedAction if the string doesn't match.
+func ParseRecommendationAction(s string) RecommendationAction {
+	switch strings.ToLower(s) {
+	case "enter":
+		return Enter
+	case "increase":
+		return Increase
+	case "reduce":
+		return Reduce
+	case "liquidate":
+		return Liquidate
+	default:
+		return UndefinedAction
+	}
+}
+
+// ScoreInputs are the company figures a recommendation was based on, copied from the
+// investment analysis context at the time of the recommendation.
+// This is a value object.
+type ScoreInputs struct {
+	Score        float64 // Value score, 0-100; zero when the company is unknown
+	PERatio      float64
+	PBRatio      float64
+	DebtToEquity float64
+	Sector       string // Sector name; empty when unknown
+}
+
+// TradeRecommendation is a single actionable rebalancing decision.
+// This is a value object.
+type TradeRecommendation struct {
+	Action        RecommendationAction
+	Ticker        string
+	Quantity      int     // Whole shares to buy or sell
+	MarketPrice   Money   // Quote the recommendation was based on
+	LimitPrice    Money   // Highest price to pay when buying, lowest to accept when selling
+	EstimatedFee  Money   // Fee from the portfolio's fee schedule
+	CurrentWeight float64 // Weight of the holding before the trade
+	TargetWeight  float64
+	Rationale     string
+	ScoreInputs   ScoreInputs
+	Confidence    float64 // 0-1; grows with the drift from target and with the score's support for the trade
+}
+
+// confidenceDriftCap is the weight drift at which the drift component of a recommendation's
+// confidence is maxed out.
+const confidenceDriftCap = 0.10
+
+// Recommend turns rebalancing orders into trade recommendations, classifying each order
+// against the current holdings and attaching the score inputs of its company (keyed by ticker).
+func (p *Portfolio) Recommend(orders []RebalanceOrder, inputs map[string]ScoreInputs) []TradeRecommendation {
+	recs := make([]TradeRecommendation, 0, len(orders))
+	for _, o := range orders {
+		in := inputs[o.Ticker]
+		rec := TradeRecommendation{
+			Ticker:        o.Ticker,
+			Quantity:      o.Shares,
+			MarketPrice:   o.Quote,
+			LimitPrice:    o.Price,
+			EstimatedFee:  o.EstimatedFee,
+			CurrentWeight: o.CurrentWeight,
+			TargetWeight:  o.TargetWeight,
+			ScoreInputs:   in,
+		}
+		held := p.Holdings[o.Ticker].Shares
+		support := in.Score / 100 // How strongly the score backs a buy
+		switch {
+		case o.Side == BuySide && held == 0:
+			rec.Action = Enter
+		case o.Side == BuySide:
+			rec.Action = Increase
+		case o.Shares >= held:
+			rec.Action = Liquidate
+			support = 1 - support
+		default:
+			rec.Action = Reduce
+			support = 1 - support
+		}
+
+		drift := math.Min(1, math.Abs(o.TargetWeight-o.CurrentWeight)/confidenceDriftCap)
+		rec.Confidence = math.Round((drift+support)/2*100) / 100
+		rec.Rationale = fmt.Sprintf("%s is %.1f%% of the portfolio against a target of %.1f%% (score %.0f)",
+			o.Ticker, o.CurrentWeight*100, o.TargetWeight*100, in.Score)
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// RecommendEntry sizes a purchase of ticker at price (per share, in its trading currency) as a
+// trade recommendation: as many whole shares as the risk policy allows, up to the max position
+// weight and without drawing cash below the buffer, fees and slippage included. valuation is
+// the portfolio's current valuation. It is an Enter for a new position and an Increase otherwise.
+func (p *Portfolio) RecommendEntry(ticker string, price Money, inputs ScoreInputs, valuation *Valuation, rates FXRateProvider, rationale string) (TradeRecommendation, error) {
+	if !price.IsPositive() {
+		return TradeRecommendation{}, fmt.Errorf("price of %s must be positive", ticker)
+	}
+	total := float64(valuation.TotalValue.Amount)
+	if total <= 0 {
+		return TradeRecommendation{}, Errors.New("cannot size an entry into a portfolio with no value")
+	}
+	fill := p.FeeSchedule.FillPrice(price, true)
+	baseFill, err := ConvertMoney(fill, p.BaseCurrency, rates, valuation.AsOf)
+	if err != nil {
+		return TradeRecommendation{}, err
+	}
+	current := 0.0
+	for _, h := range valuation.Holdings {
+		if h.CompanyTicker == ticker {
+			current = float64(h.BaseMarketValue.Amount)
+		}
+	}
+
+	policy := p.Policy()
+	budget := math.Min(policy.MaxPositionWeight*total-current, float64(valuation.CashValue.Amount)-policy.MinCashBuffer*total)
+	shares := int(math.Floor(budget / float64(baseFill.Amount)))
+	for ; shares > 0; shares-- {
+		cost, err := ConvertMoney(p.FeeSchedule.TradeFee(float64(shares), fill), p.BaseCurrency, rates, valuation.AsOf)
+		if err != nil {
+			return TradeRecommendation{}, err
+		}
+		if float64(baseFill.Amount)*float64(shares)+float64(cost.Amount) <= budget {
+			break
+		}
+	}
+	if shares < 1 {
+		return TradeRecommendation{}, fmt.Errorf("the %s risk policy leaves no room to buy a share of %s", policy.Name, ticker)
+	}
+
+	rec := TradeRecommendation{
+		Action:        Enter,
+		Ticker:        ticker,
+		Quantity:      shares,
+		MarketPrice:   price,
+		LimitPrice:    fill,
+		EstimatedFee:  p.FeeSchedule.TradeFee(float64(shares), fill),
+		CurrentWeight: current / total,
+		TargetWeight:  (current + float64(baseFill.Amount)*float64(shares)) / total,
+		Rationale:     rationale,
+		ScoreInputs:   inputs,
+		Confidence:    math.Round(math.Min(1, math.Max(0, inputs.Score/100))*100) / 100,
+	}
+	if p.Holdings[ticker].Shares > 0 {
+		rec.Action = Increase
+	}
+	return rec, nil
+}
@@ -0,0 +1,109 @@
+package portfolio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+func TestParseRecommendationAction(t *testing.T) {
+	for _, a := range []portfolio.RecommendationAction{portfolio.Enter, portfolio.Increase, portfolio.Reduce, portfolio.Liquidate} {
+		if got := portfolio.ParseRecommendationAction(a.String()); got != a {
+			t.Errorf("ParseRecommendationAction(%q) = %v, want %v", a.String(), got, a)
+		}
+	}
+	if got := portfolio.ParseRecommendationAction("hold"); got != portfolio.UndefinedAction {
+		t.Errorf("ParseRecommendationAction(\"hold\") = %v, want UndefinedAction", got)
+	}
+}
+
+func TestPortfolio_Recommend(t *testing.T) {
+	p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{}) // Holds 10 AAPL
+	price := portfolio.Money{Amount: 1000, Currency: "USD"}
+	inputs := map[string]portfolio.ScoreInputs{"AAPL": {Score: 80, PERatio: 15}, "MSFT": {Score: 90}}
+
+	tests := []struct {
+		name  string
+		order portfolio.RebalanceOrder
+		want  portfolio.RecommendationAction
+	}{
+		{"NewHolding", portfolio.RebalanceOrder{Ticker: "MSFT", Side: portfolio.BuySide, Shares: 5, Price: price, TargetWeight: 0.1}, portfolio.Enter},
+		{"ExistingHolding", portfolio.RebalanceOrder{Ticker: "AAPL", Side: portfolio.BuySide, Shares: 5, Price: price, CurrentWeight: 0.1, TargetWeight: 0.15}, portfolio.Increase},
+		{"PartialSale", portfolio.RebalanceOrder{Ticker: "AAPL", Side: portfolio.SellSide, Shares: 4, Price: price, CurrentWeight: 0.1, TargetWeight: 0.06}, portfolio.Reduce},
+		{"FullSale", portfolio.RebalanceOrder{Ticker: "AAPL", Side: portfolio.SellSide, Shares: 10, Price: price, CurrentWeight: 0.1}, portfolio.Liquidate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recs := p.Recommend([]portfolio.RebalanceOrder{tt.order}, inputs)
+			if len(recs) != 1 {
+				t.Fatalf("Recommend() returned %d recommendations, want 1", len(recs))
+			}
+			rec := recs[0]
+			if rec.Action != tt.want || rec.Quantity != tt.order.Shares || rec.LimitPrice != price {
+				t.Errorf("Recommend() = %+v, want %v %d shares at %v", rec, tt.want, tt.order.Shares, price)
+			}
+			if rec.ScoreInputs != inputs[tt.order.Ticker] || rec.Rationale == "" {
+				t.Errorf("Recommend() score inputs = %+v, rationale %q", rec.ScoreInputs, rec.Rationale)
+			}
+			if rec.Confidence < 0 || rec.Confidence > 1 {
+				t.Errorf("Recommend() confidence = %v, want within [0, 1]", rec.Confidence)
+			}
+		})
+	}
+
+	t.Run("ConfidenceFollowsScore", func(t *testing.T) {
+		buy := portfolio.RebalanceOrder{Side: portfolio.BuySide, Shares: 5, Price: price, TargetWeight: 0.1}
+		strong, weak := buy, buy
+		strong.Ticker, weak.Ticker = "MSFT", "AAPL"
+		recs := p.Recommend([]portfolio.RebalanceOrder{strong, weak}, inputs)
+		if recs[0].Confidence <= recs[1].Confidence {
+			t.Errorf("Recommend() confidence %v for score 90 should exceed %v for score 80", recs[0].Confidence, recs[1].Confidence)
+		}
+	})
+}
+
+func TestPortfolio_RecommendEntry(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	prices := map[string]portfolio.Money{"AAPL": {Amount: 1000, Currency: "USD"}}
+	msft := portfolio.Money{Amount: 2000, Currency: "USD"}
+
+	// The moderate portfolio is worth 1000.00, so a position may reach 150.00.
+	tests := []struct {
+		name       string
+		ticker     string
+		price      portfolio.Money
+		flatFee    int64
+		wantAction portfolio.RecommendationAction
+		wantShares int
+	}{
+		{"NewPosition", "MSFT", msft, 0, portfolio.Enter, 7},
+		{"FeeFitsExactly", "MSFT", msft, 1000, portfolio.Enter, 7},
+		{"FeeCostsAShare", "MSFT", msft, 1001, portfolio.Enter, 6},
+		{"HeldPosition", "AAPL", prices["AAPL"], 0, portfolio.Increase, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{FlatFee: tt.flatFee})
+			valuation, err := p.Valuate(prices, nil, asOf)
+			if err != nil {
+				t.Fatalf("Valuate() error = %v", err)
+			}
+			rec, err := p.RecommendEntry(tt.ticker, tt.price, portfolio.ScoreInputs{Score: 80}, valuation, nil, "watched")
+			if err != nil {
+				t.Fatalf("RecommendEntry() error = %v", err)
+			}
+			if rec.Action != tt.wantAction || rec.Quantity != tt.wantShares || rec.Confidence != 0.8 || rec.Rationale != "watched" {
+				t.Errorf("RecommendEntry() = %+v, want %s of %d shares", rec, tt.wantAction, tt.wantShares)
+			}
+		})
+	}
+
+	t.Run("NoRoom", func(t *testing.T) {
+		p := newRebalanceTestPortfolio(t, portfolio.FeeSchedule{})
+		valuation, _ := p.Valuate(map[string]portfolio.Money{"AAPL": {Amount: 1500, Currency: "USD"}}, nil, asOf)
+		if _, err := p.RecommendEntry("AAPL", portfolio.Money{Amount: 1500, Currency: "USD"}, portfolio.ScoreInputs{}, valuation, nil, ""); err == nil {
+			t.Error("RecommendEntry() at the position limit error = nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HoldingValuation is the market value of a single holding, both in the currency the
+// security trades in and converted into the portfolio's base currency.
+// This is a value object.
+type HoldingValuation struct {
+	CompanyTicker   string
+	Shares          float64 // Including any fractional share
+	Price           Money   // Price per share in the trading currency
+	PriceIsCost     bool    // True when no market price was available and the purchase price was used
+	MarketValue     Money   // Shares × Price in the trading currency
+	FXRate          float64 // Trading currency → base currency rate applied (1 for base currency holdings)
+	BaseMarketValue Money   // MarketValue converted into the base currency
+}
+
+// Valuation is a point-in-time statement of a portfolio's worth in its base currency.
+// This is a value object.
+type Valuation struct {
+	PortfolioID  string
+	BaseCurrency string
+	AsOf         time.Time
+	Holdings     []HoldingValuation // Sorted by ticker
+	Cash         []Money            // Cash per currency, base currency first
+	MarketValue  Money              // Sum of holdings in the base currency
+	CashValue    Money              // Sum of cash in the base currency
+	TotalValue   Money              // MarketValue + CashValue
+}
+
+// Valuate values the portfolio in its base currency as of the given date.
+// prices holds the per-share price for each ticker in its trading currency; holdings without
+// a price are valued at their purchase price. Foreign amounts are converted with rates.
+func (p *Portfolio) Valuate(prices map[string]Money, rates FXRateProvider, asOf time.Time) (*Valuation, error) {
+	v := &Valuation{
+		PortfolioID:  p.ID,
+		BaseCurrency: p.BaseCurrency,
+		AsOf:         asOf,
+		Holdings:     make([]HoldingValuation, 0, len(p.Holdings)),
+		MarketValue:  Money{Currency: p.BaseCurrency},
+		CashValue:    Money{Currency: p.BaseCurrency},
+	}
+
+	tickers := make([]string, 0, len(p.Holdings))
+	for ticker := range p.Holdings {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	for _, ticker := range tickers {
+		pos := p.Holdings[ticker]
+		price, ok := prices[ticker]
+		if ok && price.Currency != pos.PurchasePrice.Currency {
+			return nil, fmt.Errorf("price for %s is quoted in %s but the holding trades in %s", ticker, price.Currency, pos.PurchasePrice.Currency)
+		}
+		if !ok {
+			price = pos.PurchasePrice
+		}
+		local := price.Scale(pos.Quantity())
+		base, err := ConvertMoney(local, p.BaseCurrency, rates, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value holding %s: %w", ticker, err)
+		}
+		rate := 1.0
+		if local.Currency != p.BaseCurrency && local.Amount != 0 {
+			rate = float64(base.Amount) / float64(local.Amount)
+		}
+		v.Holdings = append(v.Holdings, HoldingValuation{
+			CompanyTicker:   ticker,
+			Shares:          pos.Quantity(),
+			Price:           price,
+			PriceIsCost:     !ok,
+			MarketValue:     local,
+			FXRate:          rate,
+			BaseMarketValue: base,
+		})
+		v.MarketValue.Amount += base.Amount
+	}
+
+	cashValue, err := p.TotalCash(rates, asOf)
+	if err != nil {
+		return nil, err
+	}
+	v.CashValue = cashValue
+	v.Cash = p.cashByCurrency()
+	v.TotalValue = Money{Amount: v.MarketValue.Amount + v.CashValue.Amount, Currency: p.BaseCurrency}
+	return v, nil
+}
+
+// TotalCash returns the sum of all cash balances converted into the base currency.
+func (p *Portfolio) TotalCash(rates FXRateProvider, on time.Time) (Money, error) {
+	total := Money{Amount: p.CashBalance.Amount, Currency: p.BaseCurrency}
+	for currency, cash := range p.ForeignCash {
+		converted, err := ConvertMoney(cash, p.BaseCurrency, rates, on)
+		if err != nil {
+			return Money{}, fmt.Errorf("failed to value %s cash: %w", currency, err)
+		}
+		total.Amount += converted.Amount
+	}
+	return total, nil
+}
+
+// cashByCurrency lists the cash balances held, base currency first and the rest sorted by code.
+func (p *Portfolio) cashByCurrency() []Money {
+	cash := []Money{{Amount: p.CashBalance.Amount, Currency: p.BaseCurrency}}
+	currencies := make([]string, 0, len(p.ForeignCash))
+	for currency := range p.ForeignCash {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		cash = append(cash, p.ForeignCash[currency])
+	}
+	return cash
+}
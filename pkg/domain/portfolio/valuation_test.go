@@ -0,0 +1,154 @@
+package portfolio_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// stubFXRates is a fixed-rate FXRateProvider for tests, keyed by "FROM/TO".
+type stubFXRates map[string]float64
+
+func (s stubFXRates) Rate(from, to string, on time.Time) (float64, error) {
+	if rate, ok := s[from+"/"+to]; ok {
+		return rate, nil
+	}
+	return 0, portfolio.ErrFXRateNotFound
+}
+
+func TestPortfolio_MultiCurrencyCash(t *testing.T) {
+	rates := stubFXRates{"USD/EUR": 0.9, "EUR/USD": 1.1}
+
+	t.Run("BaseCurrencyFromInitialCash", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "EUR"})
+		if p.BaseCurrency != "EUR" {
+			t.Errorf("BaseCurrency = %s, want EUR", p.BaseCurrency)
+		}
+	})
+
+	t.Run("EmptyCurrencyRejected", func(t *testing.T) {
+		_, err := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 1000})
+		if err == nil {
+			t.Error("NewPortfolio() with empty currency expected error, got nil")
+		}
+	})
+
+	t.Run("ExchangeCash", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+		credited, err := p.ExchangeCash(portfolio.Money{Amount: 50000, Currency: "USD"}, "EUR", rates, time.Now())
+		if err != nil {
+			t.Fatalf("ExchangeCash() error = %v, wantErr nil", err)
+		}
+		if credited.Amount != 45000 || credited.Currency != "EUR" {
+			t.Errorf("ExchangeCash() credited = %v, want 45000 EUR", credited)
+		}
+		if p.CashBalance.Amount != 50000 {
+			t.Errorf("USD cash = %d, want 50000", p.CashBalance.Amount)
+		}
+		if eur := p.CashIn("EUR"); eur.Amount != 45000 {
+			t.Errorf("EUR cash = %d, want 45000", eur.Amount)
+		}
+	})
+
+	t.Run("ExchangeCashInsufficient", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 100, Currency: "USD"})
+		if _, err := p.ExchangeCash(portfolio.Money{Amount: 500, Currency: "USD"}, "EUR", rates, time.Now()); err == nil {
+			t.Error("ExchangeCash() with insufficient cash expected error, got nil")
+		}
+	})
+
+	t.Run("ExchangeCashMissingRate", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 1000, Currency: "USD"})
+		_, err := p.ExchangeCash(portfolio.Money{Amount: 500, Currency: "USD"}, "JPY", rates, time.Now())
+		if !errors.Is(err, portfolio.ErrFXRateNotFound) {
+			t.Errorf("ExchangeCash() error = %v, want ErrFXRateNotFound", err)
+		}
+		if p.CashBalance.Amount != 1000 {
+			t.Errorf("USD cash changed after failed exchange: %d", p.CashBalance.Amount)
+		}
+	})
+
+	t.Run("AddForeignPositionUsesForeignCash", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+		price := portfolio.Money{Amount: 1000, Currency: "EUR"}
+		pos, _ := portfolio.NewPosition("SAP", 10, price)
+
+		if err := p.AddPosition(*pos, price.Multiply(10)); err == nil {
+			t.Fatal("AddPosition() without EUR cash expected error, got nil")
+		}
+		if _, err := p.ExchangeCash(portfolio.Money{Amount: 20000, Currency: "USD"}, "EUR", rates, time.Now()); err != nil {
+			t.Fatalf("Setup: ExchangeCash failed: %v", err)
+		}
+		if err := p.AddPosition(*pos, price.Multiply(10)); err != nil {
+			t.Fatalf("AddPosition() error = %v, wantErr nil", err)
+		}
+		if eur := p.CashIn("EUR"); eur.Amount != 8000 {
+			t.Errorf("EUR cash = %d, want 8000", eur.Amount)
+		}
+		if p.CashBalance.Amount != 80000 {
+			t.Errorf("USD cash = %d, want 80000", p.CashBalance.Amount)
+		}
+	})
+
+	t.Run("AddToExistingPositionAveragesPrice", func(t *testing.T) {
+		p, _ := portfolio.NewPortfolio("fx", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+		first, _ := portfolio.NewPosition("AAPL", 10, portfolio.Money{Amount: 1000, Currency: "USD"})
+		second, _ := portfolio.NewPosition("AAPL", 10, portfolio.Money{Amount: 2000, Currency: "USD"})
+		_ = p.AddPosition(*first, first.PurchasePrice.Multiply(10))
+		if err := p.AddPosition(*second, second.PurchasePrice.Multiply(10)); err != nil {
+			t.Fatalf("AddPosition() error = %v, wantErr nil", err)
+		}
+		got := p.Holdings["AAPL"]
+		if got.Shares != 20 || got.PurchasePrice.Amount != 1500 {
+			t.Errorf("Holding = %d shares @ %d, want 20 @ 1500", got.Shares, got.PurchasePrice.Amount)
+		}
+	})
+}
+
+func TestPortfolio_Valuate(t *testing.T) {
+	rates := stubFXRates{"EUR/USD": 1.1}
+	p, _ := portfolio.NewPortfolio("val", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	p.ForeignCash["EUR"] = portfolio.Money{Amount: 10000, Currency: "EUR"}
+	p.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 10, PurchasePrice: portfolio.Money{Amount: 1000, Currency: "USD"}}
+	p.Holdings["SAP"] = portfolio.Position{CompanyTicker: "SAP", Shares: 5, PurchasePrice: portfolio.Money{Amount: 2000, Currency: "EUR"}}
+
+	t.Run("ConvertsIntoBaseCurrency", func(t *testing.T) {
+		prices := map[string]portfolio.Money{"SAP": {Amount: 3000, Currency: "EUR"}}
+		v, err := p.Valuate(prices, rates, time.Now())
+		if err != nil {
+			t.Fatalf("Valuate() error = %v, wantErr nil", err)
+		}
+		if len(v.Holdings) != 2 || v.Holdings[0].CompanyTicker != "AAPL" {
+			t.Fatalf("Valuate() holdings = %+v, want AAPL and SAP sorted", v.Holdings)
+		}
+		if !v.Holdings[0].PriceIsCost {
+			t.Error("AAPL should be valued at cost when no price is given")
+		}
+		// AAPL 10 × 1000 USD + SAP 5 × 3000 EUR × 1.1
+		if v.MarketValue.Amount != 10000+16500 {
+			t.Errorf("MarketValue = %d, want %d", v.MarketValue.Amount, 10000+16500)
+		}
+		if v.CashValue.Amount != 100000+11000 {
+			t.Errorf("CashValue = %d, want %d", v.CashValue.Amount, 100000+11000)
+		}
+		if v.TotalValue.Amount != v.MarketValue.Amount+v.CashValue.Amount || v.TotalValue.Currency != "USD" {
+			t.Errorf("TotalValue = %v, want sum in USD", v.TotalValue)
+		}
+	})
+
+	t.Run("MissingRate", func(t *testing.T) {
+		_, err := p.Valuate(nil, stubFXRates{}, time.Now())
+		if !errors.Is(err, portfolio.ErrFXRateNotFound) {
+			t.Errorf("Valuate() error = %v, want ErrFXRateNotFound", err)
+		}
+	})
+
+	t.Run("PriceCurrencyMismatch", func(t *testing.T) {
+		prices := map[string]portfolio.Money{"SAP": {Amount: 3000, Currency: "USD"}}
+		if _, err := p.Valuate(prices, rates, time.Now()); err == nil {
+			t.Error("Valuate() with mismatched price currency expected error, got nil")
+		}
+	})
+}
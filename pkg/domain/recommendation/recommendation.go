@@ -0,0 +1,292 @@
+// Package recommendation implements the Recommendation Context: rebalancing proposals and the
+// approval workflow that stands between generating them and trading on them.
+package recommendation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// Status is the stage of a recommendation in its lifecycle.
+type Status int
+
+// Defines the lifecycle states: Proposed → Approved/Rejected → Executed/Expired.
+const (
+	UndefinedStatus Status = iota // Default or unknown status
+	Proposed                      // Waiting for a decision
+	Approved                      // Accepted for execution
+	Rejected                      // Turned down; final
+	Executed                      // Traded; final
+	Expired                       // Not executed before its expiry; final
+)
+
+// String returns the string representation of a Status.
+func (s Status) String() string {
+	switch s {
+	case Proposed:
+		return "Proposed"
+	case Approved:
+		return "Approved"
+	case Rejected:
+		return "Rejected"
+	case Executed:
+		return "Executed"
+	case Expired:
+		return "Expired"
+	default:
+		return "UndefinedStatus"
+	}
+}
+
+// ParseStatus converts a string to a Status.
+// It is case-insensitive. Returns UndefinedStatus if the string doesn't match.
+func ParseStatus(s string) Status {
+	switch strings.ToLower(s) {
+	case "proposed":
+		return Proposed
+	case "approved":
+		return Approved
+	case "rejected":
+		return Rejected
+	case "executed":
+		return Executed
+	case "expired":
+		return Expired
+	default:
+		return UndefinedStatus
+	}
+}
+
+// IsFinal reports whether no further transition is possible from the status.
+func (s Status) IsFinal() bool {
+	return s == Rejected || s == Executed || s == Expired
+}
+
+// DefaultTTL is how long a recommendation stays actionable when no expiry is configured.
+const DefaultTTL = 24 * time.Hour
+
+var (
+	// ErrExpired is returned when acting on a recommendation past its expiry time.
+	ErrExpired = errors.New("recommendation has expired")
+	// ErrNotApproved is returned when executing a recommendation that has not been approved.
+	ErrNotApproved = errors.New("recommendation has not been approved")
+	// ErrInvalidTransition is returned when a status change is not allowed from the current status.
+	ErrInvalidTransition = errors.New("invalid recommendation status transition")
+	// ErrPriceDrifted is matched (via errors.Is) by every PriceDriftError.
+	ErrPriceDrifted = errors.New("price drifted beyond tolerance")
+)
+
+// PriceDriftError is returned when a market price moved too far from the price a recommendation
+// was based on for it to be executed as approved.
+type PriceDriftError struct {
+	Ticker    string
+	Quoted    portfolio.Money // Price the recommendation was based on
+	Current   portfolio.Money // Latest market price
+	Drift     float64         // |Current - Quoted| / Quoted
+	Tolerance float64
+}
+
+// Error returns the error message string.
+func (e *PriceDriftError) Error() string {
+	return fmt.Sprintf("price of %s drifted %.2f%% (from %d to %d %s), beyond the %.2f%% tolerance",
+		e.Ticker, e.Drift*100, e.Quoted.Amount, e.Current.Amount, e.Current.Currency, e.Tolerance*100)
+}
+
+// Is makes errors.Is(err, ErrPriceDrifted) match any PriceDriftError.
+func (e *PriceDriftError) Is(target error) bool {
+	return target == ErrPriceDrifted
+}
+
+// Recommendation is the aggregate root of the Recommendation Context: a set of trades proposed
+// for a portfolio, which must be approved before it expires in order to be executed.
+type Recommendation struct {
+	ID          string
+	PortfolioID string
+	Trades      []portfolio.TradeRecommendation
+	Status      Status
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	DecidedBy   string    // Who approved or rejected it
+	DecidedAt   time.Time // When it was approved or rejected
+	Reason      string    // Why it was rejected, if it was
+	ExecutedAt  time.Time
+	UpdatedAt   time.Time
+}
+
+// NewRecommendation creates a proposed recommendation that expires ttl after createdAt.
+func NewRecommendation(id, portfolioID string, trades []portfolio.TradeRecommendation, createdAt time.Time, ttl time.Duration) (*Recommendation, error) {
+	if id == "" {
+		return nil, Errors.New("recommendation ID cannot be empty")
+	}
+	if portfolioID == "" {
+		return nil, Errors.New("portfolio ID cannot be empty")
+	}
+	if len(trades) == 0 {
+		return nil, Errors.New("recommendation must contain at least one trade")
+	}
+	if ttl <= 0 {
+		return nil, Errors.New("recommendation time to live must be positive")
+	}
+	return &Recommendation{
+		ID:          id,
+		PortfolioID: portfolioID,
+		Trades:      trades,
+		Status:      Proposed,
+		CreatedAt:   createdAt,
+		ExpiresAt:   createdAt.Add(ttl),
+		UpdatedAt:   createdAt,
+	}, nil
+}
+
+// Clone returns a deep copy of the recommendation that shares no slices with the original.
+func (r *Recommendation) Clone() *Recommendation {
+	clone := *r
+	clone.Trades = append([]portfolio.TradeRecommendation(nil), r.Trades...)
+	return &clone
+}
+
+// IsExpired reports whether the recommendation's expiry time has passed at the given time.
+func (r *Recommendation) IsExpired(at time.Time) bool {
+	return !at.Before(r.ExpiresAt)
+}
+
+// Approve accepts a proposed recommendation for execution.
+// An expired recommendation is moved to Expired instead and ErrExpired is returned.
+func (r *Recommendation) Approve(approver string, at time.Time) error {
+	if approver == "" {
+		return Errors.New("approver cannot be empty")
+	}
+	if err := r.decide(at); err != nil {
+		return err
+	}
+	r.Status = Approved
+	r.DecidedBy = approver
+	r.DecidedAt = at
+	r.UpdatedAt = at
+	return nil
+}
+
+// Reject turns down a proposed recommendation.
+func (r *Recommendation) Reject(by, reason string, at time.Time) error {
+	if by == "" {
+		return Errors.New("rejecting user cannot be empty")
+	}
+	if err := r.decide(at); err != nil {
+		return err
+	}
+	r.Status = Rejected
+	r.DecidedBy = by
+	r.DecidedAt = at
+	r.Reason = reason
+	r.UpdatedAt = at
+	return nil
+}
+
+// decide checks that a decision can be taken on the recommendation at the given time.
+func (r *Recommendation) decide(at time.Time) error {
+	if r.Status != Proposed {
+		return fmt.Errorf("%w: recommendation is %s, not Proposed", ErrInvalidTransition, r.Status)
+	}
+	if r.Expire(at) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Expire moves a proposed or approved recommendation past its expiry time to Expired.
+// It reports whether the status changed.
+func (r *Recommendation) Expire(at time.Time) bool {
+	if r.Status.IsFinal() || !r.IsExpired(at) {
+		return false
+	}
+	r.Status = Expired
+	r.UpdatedAt = at
+	return true
+}
+
+// CheckExecutable verifies that the recommendation can be executed at the given time: it must
+// be approved, not expired, and the latest price of every trade (keyed by ticker) must be within
+// tolerance (a fraction, e.g. 0.02) of the price the trade was based on. Trades without a
+// current price fail the check.
+func (r *Recommendation) CheckExecutable(prices map[string]portfolio.Money, tolerance float64, at time.Time) error {
+	if r.Status == Approved && r.IsExpired(at) {
+		return ErrExpired
+	}
+	if r.Status != Approved {
+		return fmt.Errorf("%w: recommendation is %s", ErrNotApproved, r.Status)
+	}
+	for _, trade := range r.Trades {
+		current, ok := prices[trade.Ticker]
+		if !ok {
+			return fmt.Errorf("no current price for %s", trade.Ticker)
+		}
+		quoted := trade.MarketPrice
+		if current.Currency != quoted.Currency || !quoted.IsPositive() {
+			return fmt.Errorf("cannot compare the current price of %s with the recommended one", trade.Ticker)
+		}
+		drift := math.Abs(float64(current.Amount-quoted.Amount)) / float64(quoted.Amount)
+		if drift > tolerance {
+			return &PriceDriftError{Ticker: trade.Ticker, Quoted: quoted, Current: current, Drift: drift, Tolerance: tolerance}
+		}
+	}
+	return nil
+}
+
+// MarkExecuted records that an approved recommendation has been traded.
+func (r *Recommendation) MarkExecuted(at time.Time) error {
+	if r.Status != Approved {
+		return fmt.Errorf("%w: recommendation is %s, not Approved", ErrInvalidTransition, r.Status)
+	}
+	r.Status = Executed
+	r.ExecutedAt = at
+	r.UpdatedAt = at
+	return nil
+}
+
+// --- Domain Event Types ---
+
+// RecommendationStatusChangedEvent indicates that a recommendation moved to a new status.
+type RecommendationStatusChangedEvent struct {
+	RecommendationID string
+	PortfolioID      string
+	Status           Status
+	Actor            string // Who caused the change, empty for expiry and execution
+	Timestamp        time.Time
+}
+
+// NewRecommendationStatusChangedEvent creates a new RecommendationStatusChangedEvent.
+func NewRecommendationStatusChangedEvent(r *Recommendation, actor string) RecommendationStatusChangedEvent {
+	return RecommendationStatusChangedEvent{
+		RecommendationID: r.ID,
+		PortfolioID:      r.PortfolioID,
+		Status:           r.Status,
+		Actor:            actor,
+		Timestamp:        r.UpdatedAt,
+	}
+}
+
+// domainError is a custom error type for the recommendation package.
+type domainError struct{}
+
+// New creates a new custom error message formatted as a standard error.
+func (e *domainError) New(text string) error {
+	return &customRecommendationError{s: text}
+}
+
+// customRecommendationError is the underlying type for errors created by domainError.New.
+type customRecommendationError struct {
+	s string
+}
+
+// Error returns the error message string.
+func (e *customRecommendationError) Error() string {
+	return e.s
+}
+
+// Errors provides access to constructors for custom domain errors within the recommendation package.
+var Errors = &domainError{}
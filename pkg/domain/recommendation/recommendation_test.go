@@ -0,0 +1,142 @@
+package recommendation_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+var created = time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+func newTestRecommendation(t *testing.T) *recommendation.Recommendation {
+	t.Helper()
+	trades := []portfolio.TradeRecommendation{
+		{Action: portfolio.Enter, Ticker: "AAPL", Quantity: 10, MarketPrice: portfolio.Money{Amount: 10000, Currency: "USD"}},
+	}
+	rec, err := recommendation.NewRecommendation("r1", "p1", trades, created, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRecommendation() error = %v", err)
+	}
+	return rec
+}
+
+func TestNewRecommendation(t *testing.T) {
+	rec := newTestRecommendation(t)
+	if rec.Status != recommendation.Proposed || !rec.ExpiresAt.Equal(created.Add(time.Hour)) {
+		t.Errorf("NewRecommendation() = status %v, expires %v; want Proposed, expiring an hour later", rec.Status, rec.ExpiresAt)
+	}
+	if _, err := recommendation.NewRecommendation("r2", "p1", nil, created, time.Hour); err == nil {
+		t.Error("NewRecommendation() expected an error without trades")
+	}
+}
+
+func TestRecommendation_Clone(t *testing.T) {
+	rec := newTestRecommendation(t)
+	c := rec.Clone()
+	c.Trades[0].Quantity = 99
+	if rec.Trades[0].Quantity != 10 {
+		t.Error("Clone() shares trades with the original recommendation")
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	for _, s := range []recommendation.Status{recommendation.Proposed, recommendation.Approved, recommendation.Rejected, recommendation.Executed, recommendation.Expired} {
+		if got := recommendation.ParseStatus(s.String()); got != s {
+			t.Errorf("ParseStatus(%q) = %v, want %v", s.String(), got, s)
+		}
+	}
+}
+
+func TestRecommendation_Lifecycle(t *testing.T) {
+	t.Run("ApproveThenExecute", func(t *testing.T) {
+		rec := newTestRecommendation(t)
+		if err := rec.Approve("jane", created.Add(time.Minute)); err != nil {
+			t.Fatalf("Approve() error = %v", err)
+		}
+		if rec.Status != recommendation.Approved || rec.DecidedBy != "jane" {
+			t.Errorf("after Approve() status = %v, decided by %q", rec.Status, rec.DecidedBy)
+		}
+		if err := rec.MarkExecuted(created.Add(2 * time.Minute)); err != nil {
+			t.Fatalf("MarkExecuted() error = %v", err)
+		}
+		if rec.Status != recommendation.Executed {
+			t.Errorf("after MarkExecuted() status = %v, want Executed", rec.Status)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		rec := newTestRecommendation(t)
+		if err := rec.Reject("jane", "too risky", created.Add(time.Minute)); err != nil {
+			t.Fatalf("Reject() error = %v", err)
+		}
+		if err := rec.Approve("john", created.Add(2*time.Minute)); !errors.Is(err, recommendation.ErrInvalidTransition) {
+			t.Errorf("Approve() after Reject() error = %v, want ErrInvalidTransition", err)
+		}
+		if rec.Status != recommendation.Rejected || rec.Reason != "too risky" {
+			t.Errorf("status = %v, reason %q; want Rejected with its reason", rec.Status, rec.Reason)
+		}
+	})
+
+	t.Run("ApproveAfterExpiry", func(t *testing.T) {
+		rec := newTestRecommendation(t)
+		if err := rec.Approve("jane", created.Add(2*time.Hour)); !errors.Is(err, recommendation.ErrExpired) {
+			t.Errorf("Approve() error = %v, want ErrExpired", err)
+		}
+		if rec.Status != recommendation.Expired {
+			t.Errorf("status = %v, want Expired", rec.Status)
+		}
+	})
+
+	t.Run("ExecuteWithoutApproval", func(t *testing.T) {
+		rec := newTestRecommendation(t)
+		if err := rec.MarkExecuted(created.Add(time.Minute)); !errors.Is(err, recommendation.ErrInvalidTransition) {
+			t.Errorf("MarkExecuted() error = %v, want ErrInvalidTransition", err)
+		}
+	})
+}
+
+func TestRecommendation_CheckExecutable(t *testing.T) {
+	at := created.Add(10 * time.Minute)
+	within := map[string]portfolio.Money{"AAPL": {Amount: 10150, Currency: "USD"}}
+
+	t.Run("NotApproved", func(t *testing.T) {
+		rec := newTestRecommendation(t)
+		if err := rec.CheckExecutable(within, 0.02, at); !errors.Is(err, recommendation.ErrNotApproved) {
+			t.Errorf("CheckExecutable() error = %v, want ErrNotApproved", err)
+		}
+	})
+
+	rec := newTestRecommendation(t)
+	if err := rec.Approve("jane", created.Add(time.Minute)); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	t.Run("WithinTolerance", func(t *testing.T) {
+		if err := rec.CheckExecutable(within, 0.02, at); err != nil {
+			t.Errorf("CheckExecutable() error = %v, want nil for a 1.5%% move", err)
+		}
+	})
+
+	t.Run("PriceDrifted", func(t *testing.T) {
+		err := rec.CheckExecutable(map[string]portfolio.Money{"AAPL": {Amount: 9700, Currency: "USD"}}, 0.02, at)
+		var drift *recommendation.PriceDriftError
+		if !errors.As(err, &drift) || !errors.Is(err, recommendation.ErrPriceDrifted) || drift.Ticker != "AAPL" {
+			t.Errorf("CheckExecutable() error = %v, want a PriceDriftError for AAPL", err)
+		}
+	})
+
+	t.Run("MissingPrice", func(t *testing.T) {
+		if err := rec.CheckExecutable(map[string]portfolio.Money{}, 0.02, at); err == nil {
+			t.Error("CheckExecutable() expected an error without a current price")
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		if err := rec.CheckExecutable(within, 0.02, created.Add(2*time.Hour)); !errors.Is(err, recommendation.ErrExpired) {
+			t.Errorf("CheckExecutable() error = %v, want ErrExpired", err)
+		}
+	})
+}
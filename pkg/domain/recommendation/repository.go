@@ -0,0 +1,16 @@
+package recommendation
+
+// RecommendationRepository defines the interface for accessing and persisting Recommendation aggregates.
+type RecommendationRepository interface {
+	// FindByID retrieves a recommendation by its unique identifier.
+	FindByID(id string) (*Recommendation, error)
+
+	// FindByPortfolio retrieves the recommendations made for a portfolio, oldest first.
+	FindByPortfolio(portfolioID string) ([]*Recommendation, error)
+
+	// FindByStatus retrieves the recommendations in the given status (e.g., to expire stale ones).
+	FindByStatus(status Status) ([]*Recommendation, error)
+
+	// Save creates a new recommendation or updates an existing one in the repository.
+	Save(r *Recommendation) error
+}
@@ -0,0 +1,22 @@
+package watchlist
+
+// WatchlistRepository defines the interface for accessing and persisting Watchlist aggregates.
+type WatchlistRepository interface {
+	// FindByID retrieves a watchlist by its unique identifier.
+	FindByID(id string) (*Watchlist, error)
+
+	// FindAll retrieves all watchlists (e.g., to check their entry targets).
+	FindAll() ([]*Watchlist, error)
+
+	// FindByOwner retrieves the watchlists belonging to a user, oldest first.
+	FindByOwner(owner string) ([]*Watchlist, error)
+
+	// FindByPortfolio retrieves the watchlists kept for a portfolio, oldest first.
+	FindByPortfolio(portfolioID string) ([]*Watchlist, error)
+
+	// Save creates a new watchlist or updates an existing one in the repository.
+	Save(w *Watchlist) error
+
+	// Delete removes a watchlist from the repository by its ID.
+	Delete(id string) error
+}
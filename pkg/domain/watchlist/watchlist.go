@@ -0,0 +1,228 @@
+// Package watchlist implements the Watchlist Context: companies followed as potential
+// investments, each with the target that turns it into a buy candidate.
+package watchlist
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// ErrNotWatched is returned when acting on a ticker that is not on the watchlist.
+var ErrNotWatched = errors.New("ticker is not on the watchlist")
+
+// WatchItem is a company on a watchlist and its entry target: a price to buy at or below, a
+// score to reach, or both (either one met is enough).
+// This is a value object.
+type WatchItem struct {
+	Ticker      string
+	TargetPrice portfolio.Money // Buy at or below this price per share; zero when unused
+	TargetScore float64         // Buy once the value score reaches this; zero when unused
+	Note        string          // Free-text investment thesis
+	AddedAt     time.Time
+	Triggered   bool      // The target is met and its entry signal has fired
+	TriggeredAt time.Time // When the entry signal last fired
+}
+
+// Observation is what is currently known about a watched company.
+// This is a value object.
+type Observation struct {
+	Price portfolio.Money // Latest price per share; zero when unknown
+	Score float64         // Current value score, 0-100
+}
+
+// Watchlist is the aggregate root of the Watchlist Context: the companies a user, or the
+// manager of a portfolio, is waiting to buy. Entry signals of a watchlist kept for a portfolio
+// become position entry recommendations for that portfolio.
+type Watchlist struct {
+	ID          string
+	Name        string
+	Owner       string               // User the watchlist belongs to; empty for a portfolio's watchlist
+	PortfolioID string               // Portfolio entry recommendations are made for; empty for a personal watchlist
+	Items       map[string]WatchItem // Keyed by ticker
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewWatchlist creates an empty watchlist belonging to a user, a portfolio, or both.
+func NewWatchlist(id, name, owner, portfolioID string, createdAt time.Time) (*Watchlist, error) {
+	if id == "" {
+		return nil, Errors.New("watchlist ID cannot be empty")
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, Errors.New("watchlist name cannot be empty")
+	}
+	if owner == "" && portfolioID == "" {
+		return nil, Errors.New("watchlist must belong to a user or a portfolio")
+	}
+	return &Watchlist{
+		ID:          id,
+		Name:        name,
+		Owner:       owner,
+		PortfolioID: portfolioID,
+		Items:       make(map[string]WatchItem),
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}, nil
+}
+
+// Clone returns a deep copy of the watchlist that shares no maps with the original.
+func (w *Watchlist) Clone() *Watchlist {
+	clone := *w
+	clone.Items = make(map[string]WatchItem, len(w.Items))
+	for ticker, item := range w.Items {
+		clone.Items[ticker] = item
+	}
+	return &clone
+}
+
+// Watch adds a ticker to the watchlist, or replaces its target if it is already watched.
+// At least one of targetPrice and targetScore must be set. A replaced target is re-armed.
+func (w *Watchlist) Watch(ticker string, targetPrice portfolio.Money, targetScore float64, note string, at time.Time) error {
+	if ticker == "" {
+		return Errors.New("ticker cannot be empty")
+	}
+	if targetPrice.IsNegative() {
+		return Errors.New("target price cannot be negative")
+	}
+	if targetScore < 0 || targetScore > 100 {
+		return fmt.Errorf("target score must be between 0 and 100, got %v", targetScore)
+	}
+	if targetPrice.IsZero() && targetScore == 0 {
+		return Errors.New("watch item must have a target price or a target score")
+	}
+	if targetPrice.IsPositive() && targetPrice.Currency == "" {
+		return Errors.New("target price currency cannot be empty")
+	}
+	addedAt := at
+	if existing, ok := w.Items[ticker]; ok {
+		addedAt = existing.AddedAt
+	}
+	w.Items[ticker] = WatchItem{Ticker: ticker, TargetPrice: targetPrice, TargetScore: targetScore, Note: note, AddedAt: addedAt}
+	w.UpdatedAt = at
+	return nil
+}
+
+// Unwatch removes a ticker from the watchlist.
+func (w *Watchlist) Unwatch(ticker string, at time.Time) error {
+	if _, ok := w.Items[ticker]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotWatched, ticker)
+	}
+	delete(w.Items, ticker)
+	w.UpdatedAt = at
+	return nil
+}
+
+// Tickers returns the watched tickers, sorted.
+func (w *Watchlist) Tickers() []string {
+	tickers := make([]string, 0, len(w.Items))
+	for ticker := range w.Items {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// MarginOfSafety returns how far price is below the target price, as a fraction of the target
+// (negative when above it). It is zero when either is unknown or they are in different currencies.
+func MarginOfSafety(targetPrice, price portfolio.Money) float64 {
+	if !targetPrice.IsPositive() || !price.IsPositive() || targetPrice.Currency != price.Currency {
+		return 0
+	}
+	return float64(targetPrice.Amount-price.Amount) / float64(targetPrice.Amount)
+}
+
+// Evaluate checks every watched company's observation (keyed by ticker) against its target.
+// A signal fires when a target is crossed: the price falls to or below the target price, or
+// the score reaches the target score. It fires once; the item re-arms when its target is no
+// longer met. Tickers without an observation are skipped. It reports whether it changed the
+// watchlist, by triggering or re-arming an item, so that the change can be saved.
+func (w *Watchlist) Evaluate(observations map[string]Observation, at time.Time) (signals []EntrySignalTriggeredEvent, changed bool) {
+	for _, ticker := range w.Tickers() {
+		obs, ok := observations[ticker]
+		if !ok {
+			continue
+		}
+		item := w.Items[ticker]
+		priceMet := item.TargetPrice.IsPositive() && obs.Price.IsPositive() &&
+			obs.Price.Currency == item.TargetPrice.Currency && obs.Price.Amount <= item.TargetPrice.Amount
+		scoreMet := item.TargetScore > 0 && obs.Score >= item.TargetScore
+
+		switch met := priceMet || scoreMet; {
+		case met && !item.Triggered:
+			item.Triggered, item.TriggeredAt = true, at
+			signals = append(signals, newEntrySignalTriggeredEvent(w, item, obs, priceMet, scoreMet, at))
+		case !met && item.Triggered:
+			item.Triggered = false
+		default:
+			continue
+		}
+		w.Items[ticker] = item
+		w.UpdatedAt = at
+		changed = true
+	}
+	return signals, changed
+}
+
+// --- Domain Event Types ---
+
+// EntrySignalTriggeredEvent indicates that a watched company crossed its entry target and is
+// a candidate for a new position.
+type EntrySignalTriggeredEvent struct {
+	WatchlistID    string
+	PortfolioID    string // Empty for a personal watchlist
+	Ticker         string
+	Price          portfolio.Money
+	Score          float64
+	MarginOfSafety float64 // Below the target price, as a fraction of it
+	Rationale      string
+	Timestamp      time.Time
+}
+
+// newEntrySignalTriggeredEvent creates the entry signal of an item whose target was crossed.
+func newEntrySignalTriggeredEvent(w *Watchlist, item WatchItem, obs Observation, priceMet, scoreMet bool, at time.Time) EntrySignalTriggeredEvent {
+	margin := MarginOfSafety(item.TargetPrice, obs.Price)
+	var reasons []string
+	if priceMet {
+		reasons = append(reasons, fmt.Sprintf("trades at %d %s, %.1f%% below the target buy price of %d",
+			obs.Price.Amount, obs.Price.Currency, margin*100, item.TargetPrice.Amount))
+	}
+	if scoreMet {
+		reasons = append(reasons, fmt.Sprintf("scores %.0f against a target of %.0f", obs.Score, item.TargetScore))
+	}
+	return EntrySignalTriggeredEvent{
+		WatchlistID:    w.ID,
+		PortfolioID:    w.PortfolioID,
+		Ticker:         item.Ticker,
+		Price:          obs.Price,
+		Score:          obs.Score,
+		MarginOfSafety: margin,
+		Rationale:      fmt.Sprintf("%s on watchlist %s %s", item.Ticker, w.Name, strings.Join(reasons, " and ")),
+		Timestamp:      at,
+	}
+}
+
+// domainError is a custom error type for the watchlist package.
+type domainError struct{}
+
+// New creates a new custom error message formatted as a standard error.
+func (e *domainError) New(text string) error {
+	return &customWatchlistError{s: text}
+}
+
+// customWatchlistError is the underlying type for errors created by domainError.New.
+type customWatchlistError struct {
+	s string
+}
+
+// Error returns the error message string.
+func (e *customWatchlistError) Error() string {
+	return e.s
+}
+
+// Errors provides access to constructors for custom domain errors within the watchlist package.
+var Errors = &domainError{}
@@ -0,0 +1,118 @@
+package watchlist_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/watchlist"
+)
+
+func usd(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+
+func TestNewWatchlist(t *testing.T) {
+	now := time.Now()
+	if _, err := watchlist.NewWatchlist("w1", "Value ideas", "alice", "", now); err != nil {
+		t.Errorf("NewWatchlist() error = %v", err)
+	}
+	if _, err := watchlist.NewWatchlist("w1", "Value ideas", "", "", now); err == nil {
+		t.Error("NewWatchlist() without an owner or portfolio error = nil, want an error")
+	}
+	if _, err := watchlist.NewWatchlist("", "Value ideas", "alice", "", now); err == nil {
+		t.Error("NewWatchlist() without an ID error = nil, want an error")
+	}
+}
+
+func TestWatchlist_Clone(t *testing.T) {
+	w, _ := watchlist.NewWatchlist("w1", "Value ideas", "alice", "", time.Now())
+	c := w.Clone()
+	if err := c.Watch("AAPL", usd(10000), 0, "", time.Now()); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if len(w.Items) != 0 {
+		t.Error("Clone() shares items with the original watchlist")
+	}
+}
+
+func TestWatchlist_WatchAndUnwatch(t *testing.T) {
+	now := time.Now()
+	w, _ := watchlist.NewWatchlist("w1", "Value ideas", "alice", "p1", now)
+
+	invalid := []struct {
+		name   string
+		ticker string
+		price  portfolio.Money
+		score  float64
+	}{
+		{"NoTicker", "", usd(10000), 0},
+		{"NoTarget", "AAPL", portfolio.Money{}, 0},
+		{"NegativePrice", "AAPL", usd(-1), 0},
+		{"ScoreAbove100", "AAPL", portfolio.Money{}, 120},
+	}
+	for _, tt := range invalid {
+		if err := w.Watch(tt.ticker, tt.price, tt.score, "", now); err == nil {
+			t.Errorf("%s: Watch() error = nil, want an error", tt.name)
+		}
+	}
+
+	if err := w.Watch("MSFT", portfolio.Money{}, 70, "", now); err != nil {
+		t.Fatalf("Watch(MSFT) error = %v", err)
+	}
+	if err := w.Watch("AAPL", usd(15000), 0, "moat", now); err != nil {
+		t.Fatalf("Watch(AAPL) error = %v", err)
+	}
+	if got := w.Tickers(); len(got) != 2 || got[0] != "AAPL" || got[1] != "MSFT" {
+		t.Errorf("Tickers() = %v, want [AAPL MSFT]", got)
+	}
+	if err := w.Unwatch("MSFT", now); err != nil {
+		t.Errorf("Unwatch(MSFT) error = %v", err)
+	}
+	if err := w.Unwatch("MSFT", now); !errors.Is(err, watchlist.ErrNotWatched) {
+		t.Errorf("Unwatch(MSFT) again error = %v, want ErrNotWatched", err)
+	}
+}
+
+func TestWatchlist_Evaluate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 22, 0, 0, 0, time.UTC) }
+	w, _ := watchlist.NewWatchlist("w1", "Value ideas", "", "p1", day(1))
+	_ = w.Watch("AAPL", usd(15000), 0, "", day(1))
+	_ = w.Watch("MSFT", portfolio.Money{}, 70, "", day(1))
+
+	// Neither target is met yet.
+	if signals, changed := w.Evaluate(map[string]watchlist.Observation{"AAPL": {Price: usd(16000)}, "MSFT": {Score: 65}}, day(2)); len(signals) != 0 || changed {
+		t.Fatalf("Evaluate() = %+v, %v, want no signals and no change", signals, changed)
+	}
+
+	// AAPL falls 20% below its target and MSFT's score reaches 70.
+	signals, changed := w.Evaluate(map[string]watchlist.Observation{"AAPL": {Price: usd(12000), Score: 50}, "MSFT": {Price: usd(40000), Score: 70}}, day(3))
+	if !changed || len(signals) != 2 || signals[0].Ticker != "AAPL" || signals[1].Ticker != "MSFT" {
+		t.Fatalf("Evaluate() = %+v, want signals for AAPL and MSFT", signals)
+	}
+	if s := signals[0]; s.PortfolioID != "p1" || math.Abs(s.MarginOfSafety-0.2) > 1e-9 || !s.Timestamp.Equal(day(3)) || s.Rationale == "" {
+		t.Errorf("AAPL signal = %+v, want a 20%% margin of safety for p1", s)
+	}
+	if !w.Items["AAPL"].Triggered || !w.Items["AAPL"].TriggeredAt.Equal(day(3)) {
+		t.Errorf("AAPL item = %+v, want it triggered", w.Items["AAPL"])
+	}
+
+	// Still below target: the signal does not fire twice.
+	if signals, _ := w.Evaluate(map[string]watchlist.Observation{"AAPL": {Price: usd(11000)}}, day(4)); len(signals) != 0 {
+		t.Errorf("Evaluate() while still below target = %+v, want no signals", signals)
+	}
+	// Back above target re-arms it, and the next cross fires again.
+	signals, changed = w.Evaluate(map[string]watchlist.Observation{"AAPL": {Price: usd(15500)}}, day(5))
+	if w.Items["AAPL"].Triggered || len(signals) != 0 || !changed {
+		t.Errorf("AAPL item = %+v, changed = %v, want it re-armed without a signal", w.Items["AAPL"], changed)
+	}
+	if signals, _ := w.Evaluate(map[string]watchlist.Observation{"AAPL": {Price: usd(14900)}}, day(6)); len(signals) != 1 {
+		t.Errorf("Evaluate() after re-arming = %+v, want one signal", signals)
+	}
+
+	// A price in another currency cannot meet the target.
+	_ = w.Watch("SAP", portfolio.Money{Amount: 15000, Currency: "EUR"}, 0, "", day(6))
+	if signals, _ := w.Evaluate(map[string]watchlist.Observation{"SAP": {Price: usd(100)}}, day(7)); len(signals) != 0 {
+		t.Errorf("Evaluate() with a price in another currency = %+v, want no signals", signals)
+	}
+}
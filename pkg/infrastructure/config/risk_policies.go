@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// riskPolicyEntry is the file representation of a custom risk policy.
+type riskPolicyEntry struct {
+	Name              string  `json:"name"`
+	MaxPositionWeight float64 `json:"maxPositionWeight"`
+	MaxSectorWeight   float64 `json:"maxSectorWeight"`
+	MinCashBuffer     float64 `json:"minCashBuffer"`
+	MinEntryScore     float64 `json:"minEntryScore"`
+	MaxDebtToEquity   float64 `json:"maxDebtToEquity"` // 0 = no limit
+}
+
+// LoadRiskPolicies reads the custom risk policies in the JSON file at path, keyed by name.
+// The file holds an array of policies, e.g.
+// [{"name":"Income","maxPositionWeight":0.05,"maxSectorWeight":0.2,"minCashBuffer":0.1,"minEntryScore":65,"maxDebtToEquity":1.5}].
+// A policy named after a risk profile ("Conservative", "Moderate", "Aggressive") replaces that
+// profile's default policy.
+func LoadRiskPolicies(path string) (map[string]portfolio.RiskPolicy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open risk policies file: %w", err)
+	}
+	defer f.Close()
+	return LoadRiskPoliciesFromReader(f)
+}
+
+// LoadRiskPoliciesFromReader reads custom risk policies from r.
+func LoadRiskPoliciesFromReader(r io.Reader) (map[string]portfolio.RiskPolicy, error) {
+	var entries []riskPolicyEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to read risk policies: %w", err)
+	}
+	policies := make(map[string]portfolio.RiskPolicy, len(entries))
+	for i, e := range entries {
+		policy := portfolio.RiskPolicy{
+			Name:              e.Name,
+			MaxPositionWeight: e.MaxPositionWeight,
+			MaxSectorWeight:   e.MaxSectorWeight,
+			MinCashBuffer:     e.MinCashBuffer,
+			MinEntryScore:     e.MinEntryScore,
+			MaxDebtToEquity:   e.MaxDebtToEquity,
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("risk policy %d: %w", i+1, err)
+		}
+		if _, dup := policies[policy.Name]; dup {
+			return nil, fmt.Errorf("risk policy %d: duplicate name %q", i+1, policy.Name)
+		}
+		policies[policy.Name] = policy
+	}
+	return policies, nil
+}
@@ -0,0 +1,32 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jizumer/expedition-value/pkg/infrastructure/config"
+)
+
+func TestLoadRiskPoliciesFromReader(t *testing.T) {
+	policies, err := config.LoadRiskPoliciesFromReader(strings.NewReader(`[
+		{"name":"Income","maxPositionWeight":0.05,"maxSectorWeight":0.2,"minCashBuffer":0.1,"minEntryScore":65,"maxDebtToEquity":1.5}
+	]`))
+	if err != nil {
+		t.Fatalf("LoadRiskPoliciesFromReader() error = %v", err)
+	}
+	income, ok := policies["Income"]
+	if !ok || income.MaxPositionWeight != 0.05 || income.MinEntryScore != 65 || income.MaxDebtToEquity != 1.5 {
+		t.Errorf("LoadRiskPoliciesFromReader() = %+v, want the Income policy", policies)
+	}
+
+	invalid := []string{
+		`{"name":"NotAnArray"}`,
+		`[{"name":"Income","maxPositionWeight":0,"maxSectorWeight":0.2}]`,
+		`[{"name":"A","maxPositionWeight":0.1,"maxSectorWeight":0.2},{"name":"A","maxPositionWeight":0.1,"maxSectorWeight":0.2}]`,
+	}
+	for _, in := range invalid {
+		if _, err := config.LoadRiskPoliciesFromReader(strings.NewReader(in)); err == nil {
+			t.Errorf("LoadRiskPoliciesFromReader(%s) error = nil, want an error", in)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+// ActorHeader names who a request is made on behalf of, as recorded in the audit log. There is
+// no authentication yet, so the header is taken on trust; requests without it are recorded as
+// made by AnonymousActor.
+const ActorHeader = "X-Actor"
+
+// AnonymousActor is the actor recorded for requests that do not name one.
+const AnonymousActor = "anonymous"
+
+// AuditSource returns who makes a request and through which endpoint, for the audit log.
+func AuditSource(r *http.Request) audit.Source {
+	actor := strings.TrimSpace(r.Header.Get(ActorHeader))
+	if actor == "" {
+		actor = AnonymousActor
+	}
+	return audit.Source{Actor: actor, Endpoint: r.Method + " " + r.URL.Path}
+}
+
+// AuditServiceProvider defines the interface for audit log queries needed by handlers.
+type AuditServiceProvider interface {
+	FindAuditEntries(filter audit.Filter) ([]*audit.Entry, error)
+	VerifyAuditLog() (int, error)
+}
+
+// AuditHandler holds dependencies for the audit log HTTP handlers, meant for administrators.
+type AuditHandler struct {
+	service AuditServiceProvider
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(as AuditServiceProvider) *AuditHandler {
+	return &AuditHandler{service: as}
+}
+
+// AuditChangeResponse is one value of an aggregate that a change set, replaced or removed.
+type AuditChangeResponse struct {
+	Path   string `json:"path" example:"CashBalance.Amount"`
+	Before any    `json:"before,omitempty"` // Absent when the value was set
+	After  any    `json:"after,omitempty"`  // Absent when the value was removed
+}
+
+// AuditEntryResponse is one recorded change to a company or portfolio, as listed by GetAuditLog.
+type AuditEntryResponse struct {
+	Sequence      int                   `json:"sequence" example:"42"`
+	RecordedAt    time.Time             `json:"recordedAt"`
+	Actor         string                `json:"actor" example:"jane.doe"`
+	Endpoint      string                `json:"endpoint" example:"POST /portfolio/cash/deposit"`
+	AggregateType string                `json:"aggregateType" example:"portfolio"`
+	AggregateID   string                `json:"aggregateId" example:"p1"`
+	Version       int                   `json:"version" example:"3"` // 0 when the change deleted the aggregate
+	Changes       []AuditChangeResponse `json:"changes"`
+	PreviousHash  string                `json:"previousHash"`
+	Hash          string                `json:"hash"`
+}
+
+// AuditVerificationResponse reports whether the audit log is as it was recorded.
+type AuditVerificationResponse struct {
+	Entries  int    `json:"entries" example:"42"` // Entries checked
+	Intact   bool   `json:"intact" example:"true"`
+	BrokenAt int    `json:"brokenAt,omitempty" example:"0"` // First entry changed or out of place
+	Reason   string `json:"reason,omitempty" example:""`
+}
+
+// GetAuditLog godoc
+// @Summary      Get the audit log
+// @Description  Lists the recorded changes to companies and portfolios, oldest first: who made each one, through which endpoint, and how the aggregate differs from before. Filters combine; without any, the whole log is listed.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        aggregateType query string false "company or portfolio"
+// @Param        aggregateId query string false "Ticker or portfolio ID; needs aggregateType"
+// @Param        actor query string false "Who made the changes, as sent in X-Actor"
+// @Param        from query string false "Recorded at or after (RFC 3339)"
+// @Param        to query string false "Recorded at or before (RFC 3339)"
+// @Success      200  {array}   AuditEntryResponse "Matching entries of the audit log"
+// @Failure      400  {object}  ErrorResponse "Invalid filter"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/audit [get]
+func (ah *AuditHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := audit.Filter{AggregateID: query.Get("aggregateId"), Actor: query.Get("actor")}
+	if s := query.Get("aggregateType"); s != "" {
+		aggregateType, err := audit.ParseAggregateType(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.AggregateType = aggregateType
+	}
+	for name, bound := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if s := query.Get(name); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "invalid "+name+" time, expected RFC 3339")
+				return
+			}
+			*bound = t
+		}
+	}
+
+	entries, err := ah.service.FindAuditEntries(filter)
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to read") {
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response := make([]AuditEntryResponse, len(entries))
+	for i, e := range entries {
+		changes := make([]AuditChangeResponse, len(e.Changes))
+		for j, c := range e.Changes {
+			changes[j] = AuditChangeResponse{Path: c.Path, Before: c.Before, After: c.After}
+		}
+		response[i] = AuditEntryResponse{
+			Sequence:      e.Sequence,
+			RecordedAt:    e.RecordedAt,
+			Actor:         e.Actor,
+			Endpoint:      e.Endpoint,
+			AggregateType: string(e.AggregateType),
+			AggregateID:   e.AggregateID,
+			Version:       e.Version,
+			Changes:       changes,
+			PreviousHash:  e.PreviousHash,
+			Hash:          e.Hash,
+		}
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// VerifyAuditLog godoc
+// @Summary      Verify the audit log
+// @Description  Checks the hash chain of the whole audit log, reporting the first entry that was changed, removed or moved since it was recorded.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  AuditVerificationResponse "Result of the check"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /admin/audit/verify [get]
+func (ah *AuditHandler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := ah.service.VerifyAuditLog()
+	var tampered *audit.TamperedError
+	switch {
+	case errors.As(err, &tampered):
+		respondWithJSON(w, http.StatusOK, AuditVerificationResponse{Entries: entries, BrokenAt: tampered.Sequence, Reason: tampered.Reason})
+	case err != nil:
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+	default:
+		respondWithJSON(w, http.StatusOK, AuditVerificationResponse{Entries: entries, Intact: true})
+	}
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// BenchmarkConstituentRequest DTO for one ticker of a benchmark basket.
+type BenchmarkConstituentRequest struct {
+	Ticker string  `json:"ticker" example:"MSFT"`
+	Weight float64 `json:"weight" example:"0.5"`
+}
+
+// BenchmarkRequest DTO for designating a portfolio's benchmark: either an index proxy ticker or
+// a named basket of weighted constituents. Leaving both empty reverts to the default benchmark.
+type BenchmarkRequest struct {
+	PortfolioID  string                        `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	Ticker       string                        `json:"ticker,omitempty" example:"SPY"`
+	Name         string                        `json:"name,omitempty" example:"Value basket"`
+	Constituents []BenchmarkConstituentRequest `json:"constituents,omitempty"`
+}
+
+// SetBenchmark godoc
+// @Summary      Set portfolio benchmark
+// @Description  Designates what a portfolio's performance is compared against: an index proxy ticker, or a named basket of tickers whose weights sum to 1. An empty ticker and basket reverts to the configured default benchmark.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        benchmark body BenchmarkRequest true "Benchmark designation"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/benchmark [post]
+func (ph *PortfolioHandler) SetBenchmark(w http.ResponseWriter, r *http.Request) {
+	var req BenchmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return
+	}
+	if req.Ticker != "" && len(req.Constituents) > 0 {
+		respondWithError(w, http.StatusBadRequest, "give either a ticker or constituents, not both")
+		return
+	}
+
+	var benchmark portfolio.Benchmark
+	if req.Ticker != "" {
+		benchmark = portfolio.TickerBenchmark(req.Ticker)
+	} else if len(req.Constituents) > 0 {
+		benchmark.Name = req.Name
+		for _, c := range req.Constituents {
+			benchmark.Constituents = append(benchmark.Constituents, portfolio.BenchmarkConstituent{Ticker: c.Ticker, Weight: c.Weight})
+		}
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.SetBenchmark(req.PortfolioID, benchmark, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// CompareWithBenchmark godoc
+// @Summary      Compare portfolio with its benchmark
+// @Description  Reports a portfolio's time-weighted return against its benchmark's, the active return, the annualized tracking error and information ratio, and a Brinson-Fachler attribution of the active return to sector allocation, selection and interaction, from its end-of-day valuation snapshots.
+// @Tags         portfolios
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        from query string false "Start date (YYYY-MM-DD), defaults to the first snapshot"
+// @Param        to query string false "End date (YYYY-MM-DD), inclusive; defaults to today"
+// @Success      200  {object}  portfolio.BenchmarkComparison "Relative performance and attribution"
+// @Failure      400  {object}  ErrorResponse "Invalid request or no benchmark designated"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "Not enough valuation history or no FX rate available"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/benchmark/comparison [get]
+func (ph *PortfolioHandler) CompareWithBenchmark(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	from, err := parseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		return
+	}
+	to, err := parseOptionalDate(r.URL.Query().Get("to"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !to.IsZero() {
+		to = to.Add(24*time.Hour - time.Nanosecond) // Include the whole end day
+	}
+
+	comparison, err := ph.service.CompareWithBenchmark(portfolioID, from, to)
+	if err != nil {
+		respondWithRiskError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
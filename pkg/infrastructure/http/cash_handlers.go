@@ -0,0 +1,340 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// dateLayout is the date format accepted in request payloads and query parameters.
+const dateLayout = "2006-01-02"
+
+// ExchangeCashRequest DTO for converting portfolio cash between currencies
+type ExchangeCashRequest struct {
+	PortfolioID string          `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	Amount      portfolio.Money `json:"amount"` // e.g. {"amount": 50000, "currency": "USD"}
+	ToCurrency  string          `json:"toCurrency" example:"EUR"`
+}
+
+// CashFlowRequest DTO for depositing or withdrawing cash
+type CashFlowRequest struct {
+	PortfolioID string          `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	Amount      portfolio.Money `json:"amount"`                              // e.g. {"amount": 100000, "currency": "USD"}
+	Date        string          `json:"date,omitempty" example:"2024-01-31"` // Effective date, defaults to today
+	Description string          `json:"description,omitempty" example:"Monthly savings"`
+}
+
+// InterestRateRequest DTO for setting the interest paid on idle cash
+type InterestRateRequest struct {
+	PortfolioID string  `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	Currency    string  `json:"currency" example:"USD"`
+	AnnualRate  float64 `json:"annualRate" example:"0.035"` // 0.035 = 3.5% per year
+}
+
+// AccrueInterestRequest DTO for crediting interest earned on idle cash
+type AccrueInterestRequest struct {
+	PortfolioID string `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	AsOf        string `json:"asOf,omitempty" example:"2024-01-31"` // Defaults to today
+}
+
+// ExchangeCash godoc
+// @Summary      Exchange portfolio cash
+// @Description  Converts cash held in one currency into another at the current FX rate.
+// @Tags         cash
+// @Accept       json
+// @Produce      json
+// @Param        exchange body ExchangeCashRequest true "Amount to convert and target currency"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      422  {object}  ErrorResponse "Insufficient cash or no FX rate for the currency pair"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/cash/exchange [post]
+func (ph *PortfolioHandler) ExchangeCash(w http.ResponseWriter, r *http.Request) {
+	var req ExchangeCashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.ExchangeCash(req.PortfolioID, req.Amount, req.ToCurrency, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// Deposit godoc
+// @Summary      Deposit cash
+// @Description  Adds external cash to a portfolio. Recorded as an external cash flow for performance calculations.
+// @Tags         cash
+// @Accept       json
+// @Produce      json
+// @Param        deposit body CashFlowRequest true "Deposit details"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/cash/deposit [post]
+func (ph *PortfolioHandler) Deposit(w http.ResponseWriter, r *http.Request) {
+	req, on, ok := decodeCashFlowRequest(w, r)
+	if !ok {
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.Deposit(req.PortfolioID, req.Amount, on, req.Description, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// Withdraw godoc
+// @Summary      Withdraw cash
+// @Description  Takes external cash out of a portfolio. The cash balance can never go negative.
+// @Tags         cash
+// @Accept       json
+// @Produce      json
+// @Param        withdrawal body CashFlowRequest true "Withdrawal details"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      422  {object}  ErrorResponse "Insufficient cash"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/cash/withdraw [post]
+func (ph *PortfolioHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	req, on, ok := decodeCashFlowRequest(w, r)
+	if !ok {
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.Withdraw(req.PortfolioID, req.Amount, on, req.Description, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// SetInterestRate godoc
+// @Summary      Set cash interest rate
+// @Description  Sets the annual interest rate earned on idle cash in a currency. A rate of 0 disables accrual.
+// @Tags         cash
+// @Accept       json
+// @Produce      json
+// @Param        rate body InterestRateRequest true "Currency and annual rate"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/cash/interest [post]
+func (ph *PortfolioHandler) SetInterestRate(w http.ResponseWriter, r *http.Request) {
+	var req InterestRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.SetCashInterestRate(req.PortfolioID, req.Currency, req.AnnualRate, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// AccrueInterest godoc
+// @Summary      Accrue cash interest
+// @Description  Credits the interest earned on idle cash since the last accrual.
+// @Tags         cash
+// @Accept       json
+// @Produce      json
+// @Param        accrual body AccrueInterestRequest true "Portfolio and accrual date"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/cash/interest/accrue [post]
+func (ph *PortfolioHandler) AccrueInterest(w http.ResponseWriter, r *http.Request) {
+	var req AccrueInterestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return
+	}
+	asOf, err := parseOptionalDate(req.AsOf)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "asOf must be a date in YYYY-MM-DD format")
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.AccrueInterest(req.PortfolioID, asOf, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// GetCashFlows godoc
+// @Summary      List external cash flows
+// @Description  Lists the deposits and withdrawals of a portfolio, optionally within a date range.
+// @Tags         cash
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        from query string false "Start date (YYYY-MM-DD)"
+// @Param        to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success      200  {array}   portfolio.CashFlow "External cash flows"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/cash/flows [get]
+func (ph *PortfolioHandler) GetCashFlows(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	from, err := parseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		return
+	}
+	to, err := parseOptionalDate(r.URL.Query().Get("to"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !to.IsZero() {
+		to = to.Add(24*time.Hour - time.Nanosecond) // Include the whole end day
+	}
+
+	flows, err := ph.service.GetExternalCashFlows(portfolioID, from, to)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+	if flows == nil {
+		flows = []portfolio.CashFlow{}
+	}
+
+	respondWithJSON(w, http.StatusOK, flows)
+}
+
+// decodeCashFlowRequest decodes and validates a deposit or withdrawal request,
+// writing an error response and returning ok=false if it is invalid.
+func decodeCashFlowRequest(w http.ResponseWriter, r *http.Request) (req CashFlowRequest, on time.Time, ok bool) {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return req, on, false
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return req, on, false
+	}
+	on, err := parseOptionalDate(req.Date)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "date must be in YYYY-MM-DD format")
+		return req, on, false
+	}
+	return req, on, true
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, returning the zero time for an empty string.
+func parseOptionalDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(dateLayout, s)
+}
+
+// respondWithCashError maps errors from cash operations to HTTP responses.
+func respondWithCashError(w http.ResponseWriter, err error) {
+	if respondWithVersionError(w, err) {
+		return
+	}
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, portfolio.ErrInsufficientCash), errors.Is(err, portfolio.ErrFXRateNotFound):
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+	case strings.Contains(errStr, "portfolio") && strings.Contains(errStr, "not found"):
+		respondWithError(w, http.StatusNotFound, "portfolio not found")
+	case strings.Contains(errStr, "domain error") ||
+		strings.Contains(errStr, "must be") ||
+		strings.Contains(errStr, "cannot be empty"):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
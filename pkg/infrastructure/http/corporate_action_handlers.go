@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+)
+
+// CorporateActionServiceProvider defines the interface for corporate action operations needed by handlers.
+type CorporateActionServiceProvider interface {
+	ApplyCorporateAction(action company.CorporateAction) (*application.CorporateActionResult, error)
+}
+
+// CorporateActionHandler holds dependencies for corporate action HTTP handlers.
+type CorporateActionHandler struct {
+	service CorporateActionServiceProvider
+}
+
+// NewCorporateActionHandler creates a new CorporateActionHandler.
+func NewCorporateActionHandler(cas CorporateActionServiceProvider) *CorporateActionHandler {
+	return &CorporateActionHandler{service: cas}
+}
+
+// CorporateActionRequest DTO for applying a split, reverse split, spinoff or ticker change
+type CorporateActionRequest struct {
+	Ticker          string  `json:"ticker" example:"AAPL"`
+	Type            string  `json:"type" example:"Split"` // Split, ReverseSplit, Spinoff or TickerChange
+	EffectiveDate   string  `json:"effectiveDate" example:"2020-08-31"`
+	RatioTo         int     `json:"ratioTo,omitempty" example:"4"`         // New shares received...
+	RatioFrom       int     `json:"ratioFrom,omitempty" example:"1"`       // ...for every this many shares held
+	NewTicker       string  `json:"newTicker,omitempty" example:""`        // Spinoff or TickerChange only
+	BasisAllocation float64 `json:"basisAllocation,omitempty" example:"0"` // Spinoff only: fraction of cost basis moved to the new company
+}
+
+// ApplyCorporateAction godoc
+// @Summary      Apply a corporate action
+// @Description  Records a split, reverse split, spinoff or ticker change on a company and adjusts every portfolio holding its shares. Cost bases are preserved and each adjustment is recorded in the portfolio ledger.
+// @Tags         corporate-actions
+// @Accept       json
+// @Produce      json
+// @Param        action body CorporateActionRequest true "Corporate action"
+// @Success      200  {object}  application.CorporateActionResult "Applied action and affected portfolios"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Company not found"
+// @Failure      409  {object}  ErrorResponse "The company or a portfolio changed concurrently"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /company/corporate-actions [post]
+func (h *CorporateActionHandler) ApplyCorporateAction(w http.ResponseWriter, r *http.Request) {
+	var req CorporateActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	actionType, err := company.ParseCorporateActionType(req.Type)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	effective, err := time.Parse(dateLayout, req.EffectiveDate)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "effectiveDate must be a date in YYYY-MM-DD format")
+		return
+	}
+
+	result, err := h.service.ApplyCorporateAction(company.CorporateAction{
+		Type:            actionType,
+		Ticker:          req.Ticker,
+		EffectiveDate:   effective,
+		RatioTo:         req.RatioTo,
+		RatioFrom:       req.RatioFrom,
+		NewTicker:       req.NewTicker,
+		BasisAllocation: req.BasisAllocation,
+	})
+	if err != nil {
+		if respondWithVersionError(w, err) {
+			return
+		}
+		errStr := strings.ToLower(err.Error())
+		switch {
+		case strings.Contains(errStr, "domain error"):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(errStr, "not found"):
+			respondWithError(w, http.StatusNotFound, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// DividendServiceProvider defines the interface for dividend service operations needed by handlers.
+type DividendServiceProvider interface {
+	DeclareDividend(ticker string, exDate, payDate time.Time, amountPerShare portfolio.Money) (company.Dividend, error)
+	GetDividendSummary(ticker string) (*application.DividendSummary, error)
+	ProcessDividends(asOf time.Time) ([]portfolio.DividendPayment, error)
+}
+
+// DividendHandler holds dependencies for dividend-related HTTP handlers.
+type DividendHandler struct {
+	service DividendServiceProvider
+}
+
+// NewDividendHandler creates a new DividendHandler.
+func NewDividendHandler(ds DividendServiceProvider) *DividendHandler {
+	return &DividendHandler{service: ds}
+}
+
+// DeclareDividendRequest DTO for recording a dividend declared by a company
+type DeclareDividendRequest struct {
+	Ticker         string          `json:"ticker" example:"AAPL"`
+	ExDate         string          `json:"exDate" example:"2024-02-09"`
+	PayDate        string          `json:"payDate" example:"2024-02-15"`
+	AmountPerShare portfolio.Money `json:"amountPerShare"` // e.g. {"amount": 24, "currency": "USD"}
+}
+
+// ProcessDividendsRequest DTO for crediting paid dividends to portfolios
+type ProcessDividendsRequest struct {
+	AsOf string `json:"asOf,omitempty" example:"2024-02-15"` // Defaults to today
+}
+
+// DividendPolicyRequest DTO for configuring how a portfolio receives dividends
+type DividendPolicyRequest struct {
+	PortfolioID         string             `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	Reinvestment        string             `json:"reinvestment" example:"FractionalShares"` // None, WholeShares or FractionalShares
+	WithholdingTaxRate  float64            `json:"withholdingTaxRate" example:"0.15"`
+	WithholdingTaxRates map[string]float64 `json:"withholdingTaxRates,omitempty"` // Per-currency overrides, e.g. {"EUR": 0.19}
+}
+
+// DeclareDividend godoc
+// @Summary      Declare a dividend
+// @Description  Records a cash dividend declared by a company, with its ex-date, pay date and amount per share.
+// @Tags         dividends
+// @Accept       json
+// @Produce      json
+// @Param        dividend body DeclareDividendRequest true "Dividend declaration"
+// @Success      201  {object}  company.Dividend "Recorded dividend"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Company not found"
+// @Failure      409  {object}  ErrorResponse "Company changed concurrently"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /company/dividends/declare [post]
+func (dh *DividendHandler) DeclareDividend(w http.ResponseWriter, r *http.Request) {
+	var req DeclareDividendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Ticker == "" {
+		respondWithError(w, http.StatusBadRequest, "ticker is required")
+		return
+	}
+	exDate, err := time.Parse(dateLayout, req.ExDate)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "exDate must be a date in YYYY-MM-DD format")
+		return
+	}
+	payDate, err := time.Parse(dateLayout, req.PayDate)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "payDate must be a date in YYYY-MM-DD format")
+		return
+	}
+
+	d, err := dh.service.DeclareDividend(req.Ticker, exDate, payDate, req.AmountPerShare)
+	if err != nil {
+		respondWithDividendError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, d)
+}
+
+// GetDividendSummary godoc
+// @Summary      Get company dividends
+// @Description  Lists a company's declared dividends with its trailing twelve-month dividend per share and dividend yield.
+// @Tags         dividends
+// @Accept       json
+// @Produce      json
+// @Param        ticker query string true "Company Ticker"
+// @Success      200  {object}  application.DividendSummary "Dividend summary"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Company not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /company/dividends [get]
+func (dh *DividendHandler) GetDividendSummary(w http.ResponseWriter, r *http.Request) {
+	ticker := r.URL.Query().Get("ticker")
+	if ticker == "" {
+		respondWithError(w, http.StatusBadRequest, "ticker query parameter is required")
+		return
+	}
+
+	summary, err := dh.service.GetDividendSummary(ticker)
+	if err != nil {
+		respondWithDividendError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// ProcessDividends godoc
+// @Summary      Process paid dividends
+// @Description  Credits every dividend paid up to the given date to the portfolios that held the shares before the ex-date, applying withholding tax and reinvestment. Dividends are never credited twice.
+// @Tags         dividends
+// @Accept       json
+// @Produce      json
+// @Param        process body ProcessDividendsRequest false "Processing date"
+// @Success      200  {array}   portfolio.DividendPayment "Dividends credited by this run"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      409  {object}  ErrorResponse "A portfolio changed concurrently; run again"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /dividends/process [post]
+func (dh *DividendHandler) ProcessDividends(w http.ResponseWriter, r *http.Request) {
+	var req ProcessDividendsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request payload")
+			return
+		}
+		defer r.Body.Close()
+	}
+	asOf, err := parseOptionalDate(req.AsOf)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "asOf must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !asOf.IsZero() {
+		asOf = asOf.Add(24*time.Hour - time.Nanosecond) // Include dividends paid on that day
+	}
+
+	payments, err := dh.service.ProcessDividends(asOf)
+	if err != nil {
+		respondWithDividendError(w, err)
+		return
+	}
+	if payments == nil {
+		payments = []portfolio.DividendPayment{}
+	}
+
+	respondWithJSON(w, http.StatusOK, payments)
+}
+
+// SetDividendPolicy godoc
+// @Summary      Set dividend policy
+// @Description  Configures the withholding tax applied to a portfolio's dividends and whether they are reinvested (DRIP).
+// @Tags         dividends
+// @Accept       json
+// @Produce      json
+// @Param        policy body DividendPolicyRequest true "Dividend policy"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/dividends/policy [post]
+func (ph *PortfolioHandler) SetDividendPolicy(w http.ResponseWriter, r *http.Request) {
+	var req DividendPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return
+	}
+	mode, err := portfolio.ParseReinvestmentMode(req.Reinvestment)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.SetDividendPolicy(req.PortfolioID, portfolio.DividendPolicy{
+		Reinvestment:        mode,
+		WithholdingTaxRate:  req.WithholdingTaxRate,
+		WithholdingTaxRates: req.WithholdingTaxRates,
+	}, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// respondWithDividendError maps errors from dividend operations to HTTP responses.
+func respondWithDividendError(w http.ResponseWriter, err error) {
+	if respondWithVersionError(w, err) {
+		return
+	}
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(errStr, "not found"):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case strings.Contains(errStr, "domain error") || strings.Contains(errStr, "cannot be empty"):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
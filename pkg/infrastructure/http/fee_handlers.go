@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// FeeScheduleRequest DTO for configuring the broker fees of a portfolio.
+// Fixed amounts are in the smallest unit of the trade currency.
+type FeeScheduleRequest struct {
+	PortfolioID   string  `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	FlatFee       int64   `json:"flatFee" example:"100"`          // Per trade
+	PerShareFee   int64   `json:"perShareFee" example:"0"`        // Per share traded
+	PercentageFee float64 `json:"percentageFee" example:"0.0005"` // Fraction of trade value
+	MinFee        int64   `json:"minFee" example:"100"`
+	MaxFee        int64   `json:"maxFee" example:"2500"` // 0 = no maximum
+	FXSpread      float64 `json:"fxSpread" example:"0.0025"`
+	Slippage      float64 `json:"slippage" example:"0.001"`
+}
+
+// SetFeeSchedule godoc
+// @Summary      Set fee schedule
+// @Description  Configures the commissions charged on a portfolio's trades (flat, per share, percentage, with min/max), the FX spread on currency exchanges and the slippage assumed for market orders.
+// @Tags         fees
+// @Accept       json
+// @Produce      json
+// @Param        fees body FeeScheduleRequest true "Fee schedule"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/fees [post]
+func (ph *PortfolioHandler) SetFeeSchedule(w http.ResponseWriter, r *http.Request) {
+	var req FeeScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId is required")
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.SetFeeSchedule(req.PortfolioID, portfolio.FeeSchedule{
+		FlatFee:       req.FlatFee,
+		PerShareFee:   req.PerShareFee,
+		PercentageFee: req.PercentageFee,
+		MinFee:        req.MinFee,
+		MaxFee:        req.MaxFee,
+		FXSpread:      req.FXSpread,
+		Slippage:      req.Slippage,
+	}, versions...)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// GetProfitAndLoss godoc
+// @Summary      Get profit and loss
+// @Description  Breaks down a portfolio's result into realized and unrealized gains, dividends, withholding tax, interest and fees, in the base currency.
+// @Tags         fees
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        from query string false "Start date (YYYY-MM-DD)"
+// @Param        to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success      200  {object}  portfolio.ProfitAndLoss "Profit and loss"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "No FX rate available for a currency held"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/pnl [get]
+func (ph *PortfolioHandler) GetProfitAndLoss(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	from, err := parseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		return
+	}
+	to, err := parseOptionalDate(r.URL.Query().Get("to"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !to.IsZero() {
+		to = to.Add(24*time.Hour - time.Nanosecond) // Include the whole end day
+	}
+
+	pnl, err := ph.service.GetProfitAndLoss(portfolioID, from, to)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pnl)
+}
@@ -2,14 +2,15 @@ package http
 
 import (
 	"encoding/json"
-	// "errors" // Unused, removed
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	// "github.com/gorilla/mux" // Example router, not strictly needed for placeholders
 
 	// "context" // No longer needed as service interfaces don't use context yet
-	// "github.com/jizumer/expedition-value/pkg/application" // No longer needed as handlers use local interfaces
+	"github.com/jizumer/expedition-value/pkg/application"
 	"github.com/jizumer/expedition-value/pkg/domain/company"
 	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
 )
@@ -19,7 +20,9 @@ import (
 // CompanyServiceProvider defines the interface for company service operations needed by handlers.
 type CompanyServiceProvider interface {
 	GetCompanyByTicker(ticker string) (*company.Company, error)
+	GetCompanyAsKnownAt(ticker string, at time.Time) (*company.Company, error)
 	CreateCompany(ticker string, metrics company.FinancialMetrics, sector company.Sector) (*company.Company, error)
+	ReviseCompanyMetrics(ticker string, metrics company.FinancialMetrics, periodEnd, knownAt time.Time) (*company.Company, error)
 	// Add other methods from application.CompanyService that handlers might use
 }
 
@@ -27,6 +30,28 @@ type CompanyServiceProvider interface {
 type PortfolioServiceProvider interface {
 	CreatePortfolio(cashBalance portfolio.Money, riskProfile portfolio.RiskProfile) (*portfolio.Portfolio, error)
 	GetPortfolioDetails(portfolioID string) (*portfolio.Portfolio, error)
+	GetPortfolioAsOf(portfolioID string, at time.Time) (*portfolio.Portfolio, error)
+	GetPortfolioEvents(portfolioID string) ([]portfolio.Event, error)
+	GetValuation(portfolioID string) (*portfolio.Valuation, error)
+	ExchangeCash(portfolioID string, amount portfolio.Money, toCurrency string, expectedVersions ...int) (*portfolio.Portfolio, error)
+	Deposit(portfolioID string, amount portfolio.Money, on time.Time, description string, expectedVersions ...int) (*portfolio.Portfolio, error)
+	Withdraw(portfolioID string, amount portfolio.Money, on time.Time, description string, expectedVersions ...int) (*portfolio.Portfolio, error)
+	SetCashInterestRate(portfolioID string, currency string, annualRate float64, expectedVersions ...int) (*portfolio.Portfolio, error)
+	AccrueInterest(portfolioID string, asOf time.Time, expectedVersions ...int) (*portfolio.Portfolio, error)
+	GetExternalCashFlows(portfolioID string, from, to time.Time) ([]portfolio.CashFlow, error)
+	SetDividendPolicy(portfolioID string, policy portfolio.DividendPolicy, expectedVersions ...int) (*portfolio.Portfolio, error)
+	SetFeeSchedule(portfolioID string, fees portfolio.FeeSchedule, expectedVersions ...int) (*portfolio.Portfolio, error)
+	SetRiskPolicy(portfolioID string, name string, expectedVersions ...int) (*portfolio.Portfolio, error)
+	RiskPolicies() []portfolio.RiskPolicy
+	GetExposure(portfolioID string) (*portfolio.Exposure, error)
+	GetValuationHistory(portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error)
+	GetPerformance(portfolioID string, asOf, from time.Time) (*portfolio.Performance, error)
+	SetBenchmark(portfolioID string, benchmark portfolio.Benchmark, expectedVersions ...int) (*portfolio.Portfolio, error)
+	CompareWithBenchmark(portfolioID string, from, to time.Time) (*portfolio.BenchmarkComparison, error)
+	GetProfitAndLoss(portfolioID string, from, to time.Time) (*portfolio.ProfitAndLoss, error)
+	RecommendRebalance(portfolioID string) (*application.RebalanceRecommendation, error)
+	GetTargetWeights(portfolioID string, knownAt time.Time) (map[string]float64, error)
+	ExecuteRebalance(portfolioID string, recommendationID string, expectedVersions ...int) (*portfolio.ExecutionReport, error)
 	// Add other methods from application.PortfolioService that handlers might use
 }
 
@@ -58,14 +83,16 @@ type CreateCompanyRequest struct {
 
 // GetCompanyByTicker godoc
 // @Summary      Get company by ticker
-// @Description  Get company details by its stock ticker
+// @Description  Get company details by its stock ticker. With knownAt, the company is returned as it was known at that time: with the financial metrics and score known then, free of later restatements. Past states carry no ETag, as they cannot be changed.
 // @Tags         companies
 // @Accept       json
 // @Produce      json
 // @Param        ticker query string true "Company Ticker"
+// @Param        knownAt query string false "Point in time (RFC 3339, e.g. 2024-06-03T10:00:00Z)"
 // @Success      200  {object}  company.Company "Successfully retrieved company"
+// @Header       200  {string}  ETag "Version of the company"
 // @Failure      400  {object}  ErrorResponse "Invalid request (e.g., missing ticker)"
-// @Failure      404  {object}  ErrorResponse "Company not found"
+// @Failure      404  {object}  ErrorResponse "Company not found, or none of its metrics known at knownAt"
 // @Failure      500  {object}  ErrorResponse "Internal server error"
 // @Router       /company [get]
 func (h *CompanyHandler) GetCompanyByTicker(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +101,24 @@ func (h *CompanyHandler) GetCompanyByTicker(w http.ResponseWriter, r *http.Reque
 		respondWithError(w, http.StatusBadRequest, "ticker query parameter is required")
 		return
 	}
+	if knownAt := r.URL.Query().Get("knownAt"); knownAt != "" {
+		at, err := time.Parse(time.RFC3339, knownAt)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "knownAt must be a time in RFC 3339 format")
+			return
+		}
+		comp, err := h.service.GetCompanyAsKnownAt(ticker, at)
+		if err != nil {
+			if errors.Is(err, company.ErrCompanyNotFound) {
+				respondWithError(w, http.StatusNotFound, "company not found")
+			} else {
+				respondWithError(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
+		}
+		respondWithJSON(w, http.StatusOK, comp)
+		return
+	}
 
 	comp, err := h.service.GetCompanyByTicker(ticker) // Removed r.Context()
 	if err != nil {
@@ -88,7 +133,7 @@ func (h *CompanyHandler) GetCompanyByTicker(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, comp)
+	respondWithCompany(w, http.StatusOK, comp)
 }
 
 // CreateCompany godoc
@@ -99,6 +144,7 @@ func (h *CompanyHandler) GetCompanyByTicker(w http.ResponseWriter, r *http.Reque
 // @Produce      json
 // @Param        company body CreateCompanyRequest true "Company data to create"
 // @Success      201  {object}  company.Company "Successfully created company"
+// @Header       201  {string}  ETag "Version of the company"
 // @Failure      400  {object}  ErrorResponse "Invalid company data provided"
 // @Failure      409  {object}  ErrorResponse "Company already exists"
 // @Failure      500  {object}  ErrorResponse "Internal server error"
@@ -126,7 +172,7 @@ func (h *CompanyHandler) CreateCompany(w http.ResponseWriter, r *http.Request) {
 	comp, err := h.service.CreateCompany(req.Ticker, metrics, sector) // Removed r.Context()
 	if err != nil {
 		errStr := strings.ToLower(err.Error())
-		if strings.Contains(errStr, "already exists") || strings.Contains(errStr, "conflict") {
+		if errors.Is(err, company.ErrVersionConflict) || strings.Contains(errStr, "already exists") || strings.Contains(errStr, "conflict") {
 			respondWithError(w, http.StatusConflict, "company already exists")
 		} else if strings.Contains(errStr, "validation failed") || strings.Contains(errStr, "invalid ticker") { // Example validation checks
 			respondWithError(w, http.StatusBadRequest, err.Error()) // Or a more generic "invalid data"
@@ -136,7 +182,7 @@ func (h *CompanyHandler) CreateCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, comp)
+	respondWithCompany(w, http.StatusCreated, comp)
 }
 
 // PortfolioHandler holds dependencies for portfolio-related HTTP handlers.
@@ -163,6 +209,7 @@ type CreatePortfolioRequest struct {
 // @Produce      json
 // @Param        portfolio body CreatePortfolioRequest true "Portfolio data to create"
 // @Success      201  {object}  portfolio.Portfolio "Successfully created portfolio"
+// @Header       201  {string}  ETag "Version of the portfolio"
 // @Failure      400  {object}  ErrorResponse "Invalid portfolio data provided"
 // @Failure      500  {object}  ErrorResponse "Internal server error"
 // @Router       /portfolio/create [post]
@@ -200,20 +247,23 @@ func (ph *PortfolioHandler) CreatePortfolio(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, p)
+	respondWithPortfolio(w, http.StatusCreated, p)
 }
 
 // GetPortfolioDetails godoc
 // @Summary      Get portfolio details
-// @Description  Get details of a specific portfolio by its ID.
+// @Description  Get details of a specific portfolio by its ID. With asOf, an event-sourced portfolio is rebuilt as it was at that time; past states carry no ETag, as they cannot be changed.
 // @Tags         portfolios
 // @Accept       json
 // @Produce      json
 // @Param        id query string true "Portfolio ID"
+// @Param        asOf query string false "Point in time (RFC 3339, e.g. 2024-06-03T10:00:00Z)"
 // @Success      200  {object}  portfolio.Portfolio "Successfully retrieved portfolio"
+// @Header       200  {string}  ETag "Version of the portfolio, for If-Match on changes"
 // @Failure      400  {object}  ErrorResponse "Invalid request (e.g., missing ID)"
 // @Failure      404  {object}  ErrorResponse "Portfolio not found"
 // @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Failure      501  {object}  ErrorResponse "asOf given but portfolios are not event-sourced"
 // @Router       /portfolio [get]
 func (ph *PortfolioHandler) GetPortfolioDetails(w http.ResponseWriter, r *http.Request) {
 	portfolioID := r.URL.Query().Get("id")
@@ -221,6 +271,20 @@ func (ph *PortfolioHandler) GetPortfolioDetails(w http.ResponseWriter, r *http.R
 		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
 		return
 	}
+	if asOf := r.URL.Query().Get("asOf"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "asOf must be a time in RFC 3339 format")
+			return
+		}
+		p, err := ph.service.GetPortfolioAsOf(portfolioID, at)
+		if err != nil {
+			respondWithHistoryError(w, err)
+			return
+		}
+		respondWithJSON(w, http.StatusOK, p)
+		return
+	}
 
 	p, err := ph.service.GetPortfolioDetails(portfolioID) // Removed r.Context()
 	if err != nil {
@@ -234,7 +298,87 @@ func (ph *PortfolioHandler) GetPortfolioDetails(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, p)
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// GetPortfolioValuation godoc
+// @Summary      Get portfolio valuation
+// @Description  Values a portfolio's holdings and cash in its base currency using the latest prices and FX rates.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Success      200  {object}  portfolio.Valuation "Successfully valued portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request (e.g., missing ID)"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "Missing FX rate for a held currency"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/valuation [get]
+func (ph *PortfolioHandler) GetPortfolioValuation(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+
+	v, err := ph.service.GetValuation(portfolioID)
+	if err != nil {
+		if errors.Is(err, portfolio.ErrFXRateNotFound) {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		} else if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			respondWithError(w, http.StatusNotFound, "portfolio not found")
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, v)
+}
+
+// GetValuationHistory godoc
+// @Summary      Get portfolio valuation history
+// @Description  Lists the end-of-day valuation snapshots of a portfolio (holdings, prices, market value, cash and base-currency total), oldest first.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        from query string false "Start date (YYYY-MM-DD)"
+// @Param        to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success      200  {array}   portfolio.Valuation "Daily valuation snapshots"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/valuation/history [get]
+func (ph *PortfolioHandler) GetValuationHistory(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	from, err := parseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		return
+	}
+	to, err := parseOptionalDate(r.URL.Query().Get("to"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !to.IsZero() {
+		to = to.Add(24*time.Hour - time.Nanosecond) // Include the whole end day
+	}
+
+	history, err := ph.service.GetValuationHistory(portfolioID, from, to)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+	if history == nil {
+		history = []*portfolio.Valuation{}
+	}
+
+	respondWithJSON(w, http.StatusOK, history)
 }
 
 // --- Utility functions for handlers (optional, can be in a separate file) ---
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// EventResponse is one saved change to a portfolio, as listed by GetPortfolioEvents.
+type EventResponse struct {
+	Version    int                           `json:"version" example:"3"`
+	Type       string                        `json:"type" example:"PositionOpened"`
+	RecordedAt time.Time                     `json:"recordedAt"`
+	Entries    []portfolio.LedgerEntry       `json:"entries,omitempty"`   // Ledger entries appended
+	Positions  map[string]portfolio.Position `json:"positions,omitempty"` // Holdings opened or changed, by ticker
+	Closed     []string                      `json:"closed,omitempty"`    // Tickers no longer held
+	Cash       map[string]portfolio.Money    `json:"cash,omitempty"`      // Cash balances that changed, by currency
+	Settings   *portfolio.Settings           `json:"settings,omitempty"`  // All the settings, when any of them changed
+}
+
+// GetPortfolioEvents godoc
+// @Summary      Get portfolio events
+// @Description  Lists the changes saved to an event-sourced portfolio, oldest first, its deletion included. Each event carries the state of what it changed.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Success      200  {array}   EventResponse "Events of the portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Failure      501  {object}  ErrorResponse "Portfolios are not event-sourced"
+// @Router       /portfolio/events [get]
+func (ph *PortfolioHandler) GetPortfolioEvents(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+
+	events, err := ph.service.GetPortfolioEvents(portfolioID)
+	if err != nil {
+		respondWithHistoryError(w, err)
+		return
+	}
+
+	response := make([]EventResponse, len(events))
+	for i, e := range events {
+		response[i] = EventResponse{
+			Version:    e.Version,
+			Type:       e.Type.String(),
+			RecordedAt: e.RecordedAt,
+			Entries:    e.Entries,
+			Positions:  e.Positions,
+			Closed:     e.Closed,
+			Cash:       e.Cash,
+			Settings:   e.Settings,
+		}
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// respondWithHistoryError maps errors from portfolio history queries to HTTP responses.
+func respondWithHistoryError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "needs event-sourced persistence") {
+		respondWithError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	respondWithCashError(w, err)
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+)
+
+// ReviseMetricsRequest DTO for recording a company's financial metrics for a reporting period
+type ReviseMetricsRequest struct {
+	Ticker       string  `json:"ticker" example:"AAPL"`
+	PeriodEnd    string  `json:"periodEnd" example:"2024-03-31"`                   // End of the reporting period described
+	KnownAt      string  `json:"knownAt,omitempty" example:"2024-05-02T20:30:00Z"` // When the figures became known (RFC 3339); defaults to now
+	PERatio      float64 `json:"peRatio" example:"28.5"`
+	PBRatio      float64 `json:"pbRatio" example:"45.1"`
+	DebtToEquity float64 `json:"debtToEquity" example:"1.8"`
+}
+
+// ReviseCompanyMetrics godoc
+// @Summary      Revise company metrics
+// @Description  Records a company's financial metrics for a reporting period as they became known. A restatement of a period is recorded beside the earlier figures rather than replacing them, so that the company can still be read as known before it. Revisions known earlier than others already recorded may be loaded late.
+// @Tags         companies
+// @Accept       json
+// @Produce      json
+// @Param        revision body ReviseMetricsRequest true "Metrics revision"
+// @Success      200  {object}  company.Company "Company with the revision recorded"
+// @Header       200  {string}  ETag "Version of the company"
+// @Failure      400  {object}  ErrorResponse "Invalid request, e.g. figures known before their period ended"
+// @Failure      404  {object}  ErrorResponse "Company not found"
+// @Failure      409  {object}  ErrorResponse "Company changed concurrently"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /company/metrics [post]
+func (h *CompanyHandler) ReviseCompanyMetrics(w http.ResponseWriter, r *http.Request) {
+	var req ReviseMetricsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Ticker == "" {
+		respondWithError(w, http.StatusBadRequest, "ticker is required")
+		return
+	}
+	periodEnd, err := time.Parse(dateLayout, req.PeriodEnd)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "periodEnd must be a date in YYYY-MM-DD format")
+		return
+	}
+	knownAt := time.Now().UTC()
+	if req.KnownAt != "" {
+		if knownAt, err = time.Parse(time.RFC3339, req.KnownAt); err != nil {
+			respondWithError(w, http.StatusBadRequest, "knownAt must be a time in RFC 3339 format")
+			return
+		}
+	}
+
+	metrics := company.FinancialMetrics{PERatio: req.PERatio, PBRatio: req.PBRatio, DebtToEquity: req.DebtToEquity}
+	c, err := h.service.ReviseCompanyMetrics(req.Ticker, metrics, periodEnd, knownAt)
+	if err != nil {
+		if respondWithVersionError(w, err) {
+			return
+		}
+		errStr := strings.ToLower(err.Error())
+		switch {
+		case strings.Contains(errStr, "not found"):
+			respondWithError(w, http.StatusNotFound, "company not found")
+		case strings.Contains(errStr, "domain error"):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	respondWithCompany(w, http.StatusOK, c)
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// GetPerformance godoc
+// @Summary      Get portfolio performance
+// @Description  Reports a portfolio's time-weighted return (chain-linked across deposits and withdrawals) and money-weighted return (XIRR) month-to-date, quarter-to-date, year-to-date, over one year and since inception, computed from its ledger and end-of-day valuation snapshots. Periods of a year or more are also annualized.
+// @Tags         portfolios
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        asOf query string false "Last day of the periods (YYYY-MM-DD); defaults to now, valued at the latest prices"
+// @Param        from query string false "Start date of an additional custom period (YYYY-MM-DD)"
+// @Success      200  {object}  portfolio.Performance "Returns per period"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "No valuation history for the custom period or no FX rate available"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/performance [get]
+func (ph *PortfolioHandler) GetPerformance(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	asOf, err := parseOptionalDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "asOf must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !asOf.IsZero() {
+		asOf = asOf.Add(24*time.Hour - time.Nanosecond) // Include the day's snapshot
+	}
+	from, err := parseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		return
+	}
+
+	perf, err := ph.service.GetPerformance(portfolioID, asOf, from)
+	if err != nil {
+		respondWithRiskError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, perf)
+}
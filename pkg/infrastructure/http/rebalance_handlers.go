@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// RecommendRebalance godoc
+// @Summary      Recommend a rebalance
+// @Description  Proposes the whole-share orders that move a portfolio towards the target weights derived from company scores and its risk profile, within the available cash, minimum trade size and tolerance band.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Success      200  {object}  application.RebalanceRecommendation "Rebalancing orders"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "No holding drifted beyond the tolerance band, or an FX rate is missing"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/rebalance [get]
+func (ph *PortfolioHandler) RecommendRebalance(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+
+	rec, err := ph.service.RecommendRebalance(portfolioID)
+	if err != nil {
+		if errors.Is(err, portfolio.ErrRebalanceNotTriggered) {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// TargetWeightsResponse DTO for the weights a portfolio's risk policy gives the companies
+type TargetWeightsResponse struct {
+	PortfolioID string             `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	KnownAt     *time.Time         `json:"knownAt,omitempty"` // Absent when the companies are scored as they are now
+	Weights     map[string]float64 `json:"weights"`           // Target weight by ticker, e.g. {"AAPL": 0.15}
+}
+
+// GetTargetWeights godoc
+// @Summary      Get target weights
+// @Description  Scores the companies under the portfolio's risk policy into the target weights a rebalance moves towards. With knownAt, they are scored on the financial metrics and scores known then, without look-ahead from later reports or restatements; companies with nothing known then get no weight.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        knownAt query string false "Point in time (RFC 3339, e.g. 2024-06-03T10:00:00Z)"
+// @Success      200  {object}  TargetWeightsResponse "Target weights"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/target-weights [get]
+func (ph *PortfolioHandler) GetTargetWeights(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	var knownAt time.Time // Zero scores the companies as they are now
+	if s := r.URL.Query().Get("knownAt"); s != "" {
+		var err error
+		if knownAt, err = time.Parse(time.RFC3339, s); err != nil {
+			respondWithError(w, http.StatusBadRequest, "knownAt must be a time in RFC 3339 format")
+			return
+		}
+	}
+
+	weights, err := ph.service.GetTargetWeights(portfolioID, knownAt)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			respondWithError(w, http.StatusNotFound, "portfolio not found")
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := TargetWeightsResponse{PortfolioID: portfolioID, Weights: weights}
+	if !knownAt.IsZero() {
+		response.KnownAt = &knownAt
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,242 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+// RecommendationServiceProvider defines the interface for recommendation workflow operations needed by handlers.
+type RecommendationServiceProvider interface {
+	GetRecommendation(id string) (*application.RebalanceRecommendation, error)
+	ListRecommendations(portfolioID string) ([]*application.RebalanceRecommendation, error)
+	ApproveRecommendation(id, approver string) (*application.RebalanceRecommendation, error)
+	RejectRecommendation(id, by, reason string) (*application.RebalanceRecommendation, error)
+}
+
+// RecommendationHandler holds dependencies for recommendation-related HTTP handlers.
+type RecommendationHandler struct {
+	service RecommendationServiceProvider
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler.
+func NewRecommendationHandler(rs RecommendationServiceProvider) *RecommendationHandler {
+	return &RecommendationHandler{service: rs}
+}
+
+// ApproveRecommendationRequest DTO for approving a proposed recommendation
+type ApproveRecommendationRequest struct {
+	ID       string `json:"id" example:"9b1d6c2e-3f4a-4e5b-8c7d-1a2b3c4d5e6f"`
+	Approver string `json:"approver" example:"jane.doe"`
+}
+
+// RejectRecommendationRequest DTO for rejecting a proposed recommendation
+type RejectRecommendationRequest struct {
+	ID         string `json:"id" example:"9b1d6c2e-3f4a-4e5b-8c7d-1a2b3c4d5e6f"`
+	RejectedBy string `json:"rejectedBy" example:"jane.doe"`
+	Reason     string `json:"reason,omitempty" example:"Waiting for earnings"`
+}
+
+// ExecuteRebalanceRequest DTO for executing an approved recommendation
+type ExecuteRebalanceRequest struct {
+	PortfolioID      string `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	RecommendationID string `json:"recommendationId" example:"9b1d6c2e-3f4a-4e5b-8c7d-1a2b3c4d5e6f"`
+}
+
+// GetRecommendation godoc
+// @Summary      Get a recommendation
+// @Description  Retrieves a rebalancing recommendation with its trades and approval status.
+// @Tags         recommendations
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Recommendation ID"
+// @Success      200  {object}  application.RebalanceRecommendation "Recommendation"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Recommendation not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /recommendation [get]
+func (rh *RecommendationHandler) GetRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		respondWithError(w, http.StatusBadRequest, "recommendation id query parameter is required")
+		return
+	}
+
+	rec, err := rh.service.GetRecommendation(id)
+	if err != nil {
+		respondWithRecommendationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// ListRecommendations godoc
+// @Summary      List portfolio recommendations
+// @Description  Lists the rebalancing recommendations made for a portfolio, oldest first.
+// @Tags         recommendations
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Success      200  {array}   application.RebalanceRecommendation "Recommendations"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/recommendations [get]
+func (rh *RecommendationHandler) ListRecommendations(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+
+	recs, err := rh.service.ListRecommendations(portfolioID)
+	if err != nil {
+		respondWithRecommendationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, recs)
+}
+
+// ApproveRecommendation godoc
+// @Summary      Approve a recommendation
+// @Description  Approves a proposed recommendation so that it can be executed before it expires.
+// @Tags         recommendations
+// @Accept       json
+// @Produce      json
+// @Param        approval body ApproveRecommendationRequest true "Approval"
+// @Success      200  {object}  application.RebalanceRecommendation "Approved recommendation"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Recommendation not found"
+// @Failure      409  {object}  ErrorResponse "Recommendation is not proposed or has expired"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /recommendation/approve [post]
+func (rh *RecommendationHandler) ApproveRecommendation(w http.ResponseWriter, r *http.Request) {
+	var req ApproveRecommendationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ID == "" || req.Approver == "" {
+		respondWithError(w, http.StatusBadRequest, "id and approver are required")
+		return
+	}
+
+	rec, err := rh.service.ApproveRecommendation(req.ID, req.Approver)
+	if err != nil {
+		respondWithRecommendationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// RejectRecommendation godoc
+// @Summary      Reject a recommendation
+// @Description  Rejects a proposed recommendation, recording who rejected it and why.
+// @Tags         recommendations
+// @Accept       json
+// @Produce      json
+// @Param        rejection body RejectRecommendationRequest true "Rejection"
+// @Success      200  {object}  application.RebalanceRecommendation "Rejected recommendation"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Recommendation not found"
+// @Failure      409  {object}  ErrorResponse "Recommendation is not proposed or has expired"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /recommendation/reject [post]
+func (rh *RecommendationHandler) RejectRecommendation(w http.ResponseWriter, r *http.Request) {
+	var req RejectRecommendationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ID == "" || req.RejectedBy == "" {
+		respondWithError(w, http.StatusBadRequest, "id and rejectedBy are required")
+		return
+	}
+
+	rec, err := rh.service.RejectRecommendation(req.ID, req.RejectedBy, req.Reason)
+	if err != nil {
+		respondWithRecommendationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// ExecuteRebalance godoc
+// @Summary      Execute a rebalance
+// @Description  Executes an approved, unexpired recommendation whose prices have not drifted beyond the configured tolerance. All trades are applied or none is.
+// @Tags         recommendations
+// @Accept       json
+// @Produce      json
+// @Param        execution body ExecuteRebalanceRequest true "Recommendation to execute"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.ExecutionReport "Fills, fees and residual cash"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio or recommendation not found"
+// @Failure      409  {object}  ErrorResponse "Recommendation is not approved or has expired, or the portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      422  {object}  ErrorResponse "Prices drifted beyond the tolerance, or not enough cash"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/rebalance/execute [post]
+func (ph *PortfolioHandler) ExecuteRebalance(w http.ResponseWriter, r *http.Request) {
+	var req ExecuteRebalanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" || req.RecommendationID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId and recommendationId are required")
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	report, err := ph.service.ExecuteRebalance(req.PortfolioID, req.RecommendationID, versions...)
+	if err != nil {
+		respondWithRecommendationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// respondWithRecommendationError maps errors from the recommendation workflow to HTTP statuses:
+// 409 when the recommendation's status does not allow the operation, 422 when market
+// conditions (prices, cash) prevent it, 404 for unknown IDs and 400 for invalid input.
+func respondWithRecommendationError(w http.ResponseWriter, err error) {
+	if respondWithVersionError(w, err) {
+		return
+	}
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, recommendation.ErrExpired),
+		errors.Is(err, recommendation.ErrNotApproved),
+		errors.Is(err, recommendation.ErrInvalidTransition):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, recommendation.ErrPriceDrifted), errors.Is(err, portfolio.ErrInsufficientCash):
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+	case strings.Contains(errStr, "not found"):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case strings.Contains(errStr, "domain error") ||
+		strings.Contains(errStr, "cannot be empty") ||
+		strings.Contains(errStr, "does not match"):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
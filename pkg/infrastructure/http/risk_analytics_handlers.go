@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// RiskAnalyticsServiceProvider defines the interface for risk analytics operations needed by handlers.
+type RiskAnalyticsServiceProvider interface {
+	GetRiskMetrics(portfolioID string, asOf time.Time) (*portfolio.RiskMetrics, error)
+	GetRiskMetricsHistory(portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error)
+}
+
+// RiskAnalyticsHandler holds dependencies for risk analytics HTTP handlers.
+type RiskAnalyticsHandler struct {
+	service RiskAnalyticsServiceProvider
+}
+
+// NewRiskAnalyticsHandler creates a new RiskAnalyticsHandler.
+func NewRiskAnalyticsHandler(rs RiskAnalyticsServiceProvider) *RiskAnalyticsHandler {
+	return &RiskAnalyticsHandler{service: rs}
+}
+
+// GetRiskMetrics godoc
+// @Summary      Get portfolio risk metrics
+// @Description  Computes a portfolio's annualized volatility, beta against the configured benchmark, maximum drawdown and 1-day/10-day historical and parametric value at risk at 95% and 99%, from the daily prices of its current holdings.
+// @Tags         risk
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        asOf query string false "Date to measure risk at (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  portfolio.RiskMetrics "Risk metrics"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "Not enough price history or no FX rate available"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/risk [get]
+func (h *RiskAnalyticsHandler) GetRiskMetrics(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	asOf, err := parseOptionalDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "asOf must be a date in YYYY-MM-DD format")
+		return
+	}
+
+	metrics, err := h.service.GetRiskMetrics(portfolioID, asOf)
+	if err != nil {
+		respondWithRiskError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, metrics)
+}
+
+// GetRiskMetricsHistory godoc
+// @Summary      Get portfolio risk metrics history
+// @Description  Lists the risk metrics recorded daily for a portfolio, oldest first, so that trends can be charted.
+// @Tags         risk
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Param        from query string false "Start date (YYYY-MM-DD)"
+// @Param        to query string false "End date (YYYY-MM-DD), inclusive"
+// @Success      200  {array}   portfolio.RiskMetrics "Daily risk metrics"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/risk/history [get]
+func (h *RiskAnalyticsHandler) GetRiskMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolio id query parameter is required")
+		return
+	}
+	from, err := parseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+		return
+	}
+	to, err := parseOptionalDate(r.URL.Query().Get("to"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "to must be a date in YYYY-MM-DD format")
+		return
+	}
+	if !to.IsZero() {
+		to = to.Add(24*time.Hour - time.Nanosecond) // Include the whole end day
+	}
+
+	history, err := h.service.GetRiskMetricsHistory(portfolioID, from, to)
+	if err != nil {
+		respondWithRiskError(w, err)
+		return
+	}
+	if history == nil {
+		history = []*portfolio.RiskMetrics{}
+	}
+
+	respondWithJSON(w, http.StatusOK, history)
+}
+
+// respondWithRiskError maps errors from risk analytics to HTTP responses.
+func respondWithRiskError(w http.ResponseWriter, err error) {
+	if errors.Is(err, portfolio.ErrInsufficientHistory) {
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	respondWithCashError(w, err)
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RiskPolicyRequest DTO for assigning a risk policy to a portfolio by name.
+type RiskPolicyRequest struct {
+	PortfolioID string `json:"portfolioId" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"`
+	Policy      string `json:"policy" example:"Conservative"` // A built-in risk profile or a configured policy
+}
+
+// SetRiskPolicy godoc
+// @Summary      Set risk policy
+// @Description  Assigns a risk policy to a portfolio: the default policy of a built-in risk profile or a custom policy from the configuration. The policy limits position and sector weights, the cash buffer, the entry score and the debt-to-equity of companies bought.
+// @Tags         portfolios
+// @Accept       json
+// @Produce      json
+// @Param        policy body RiskPolicyRequest true "Risk policy assignment"
+// @Param        If-Match header string false "Only apply to this version of the portfolio, as in its ETag"
+// @Success      200  {object}  portfolio.Portfolio "Updated portfolio"
+// @Header       200  {string}  ETag "Version of the updated portfolio"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio or risk policy not found"
+// @Failure      409  {object}  ErrorResponse "Portfolio changed concurrently"
+// @Failure      412  {object}  ErrorResponse "Portfolio is not at the If-Match version"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/risk-policy [post]
+func (ph *PortfolioHandler) SetRiskPolicy(w http.ResponseWriter, r *http.Request) {
+	var req RiskPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.PortfolioID == "" || req.Policy == "" {
+		respondWithError(w, http.StatusBadRequest, "portfolioId and policy are required")
+		return
+	}
+
+	versions, ok := ph.expectedVersions(w, r, req.PortfolioID)
+	if !ok {
+		return
+	}
+	p, err := ph.service.SetRiskPolicy(req.PortfolioID, req.Policy, versions...)
+	if err != nil {
+		if errStr := strings.ToLower(err.Error()); strings.HasPrefix(errStr, "risk policy") && strings.Contains(errStr, "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithPortfolio(w, http.StatusOK, p)
+}
+
+// ListRiskPolicies godoc
+// @Summary      List risk policies
+// @Description  Lists the risk policies portfolios can be assigned: the defaults of the built-in risk profiles and the custom policies from the configuration.
+// @Tags         portfolios
+// @Produce      json
+// @Success      200  {array}   portfolio.RiskPolicy "Risk policies, sorted by name"
+// @Router       /risk-policies [get]
+func (ph *PortfolioHandler) ListRiskPolicies(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, ph.service.RiskPolicies())
+}
+
+// GetExposure godoc
+// @Summary      Get portfolio exposure
+// @Description  Breaks a portfolio down by position and sector weight at the latest prices and lists the limits of its risk policy it breaches, with their severity and the holdings causing them.
+// @Tags         portfolios
+// @Produce      json
+// @Param        id query string true "Portfolio ID"
+// @Success      200  {object}  portfolio.Exposure "Position and sector exposure"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Portfolio not found"
+// @Failure      422  {object}  ErrorResponse "No FX rate available for a currency held"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /portfolio/exposure [get]
+func (ph *PortfolioHandler) GetExposure(w http.ResponseWriter, r *http.Request) {
+	portfolioID := r.URL.Query().Get("id")
+	if portfolioID == "" {
+		respondWithError(w, http.StatusBadRequest, "id query parameter is required")
+		return
+	}
+
+	exposure, err := ph.service.GetExposure(portfolioID)
+	if err != nil {
+		respondWithCashError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, exposure)
+}
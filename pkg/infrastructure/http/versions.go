@@ -0,0 +1,109 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// Companies and portfolios carry a version that every save increments. Responses holding one
+// send the version as a strong ETag; requests changing a portfolio may send it back in
+// If-Match to only apply if nobody changed the portfolio since it was read.
+
+// setETag sets the ETag header of the response to an aggregate version.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// respondWithPortfolio sends a portfolio with its version as the ETag.
+func respondWithPortfolio(w http.ResponseWriter, code int, p *portfolio.Portfolio) {
+	setETag(w, p.Version)
+	respondWithJSON(w, code, p)
+}
+
+// respondWithCompany sends a company with its version as the ETag.
+func respondWithCompany(w http.ResponseWriter, code int, c *company.Company) {
+	setETag(w, c.Version)
+	respondWithJSON(w, code, c)
+}
+
+// respondWithVersionError answers, with the version of the aggregate as the ETag, if err is a
+// version error: 409 Conflict when the aggregate was changed by another request while this one
+// was changing it, 412 Precondition Failed when it was not at the If-Match version. It reports
+// whether it did.
+func respondWithVersionError(w http.ResponseWriter, err error) bool {
+	var portfolioConflict *portfolio.VersionConflictError
+	var companyConflict *company.VersionConflictError
+	var precondition *portfolio.PreconditionFailedError
+	switch {
+	case errors.As(err, &portfolioConflict):
+		setETag(w, portfolioConflict.CurrentVersion)
+	case errors.As(err, &companyConflict):
+		setETag(w, companyConflict.CurrentVersion)
+	case errors.As(err, &precondition):
+		setETag(w, precondition.CurrentVersion)
+		respondWithError(w, http.StatusPreconditionFailed, err.Error())
+		return true
+	default:
+		return false
+	}
+	respondWithError(w, http.StatusConflict, err.Error())
+	return true
+}
+
+// ifMatchVersions returns the versions the If-Match header of r allows a change to apply to:
+// nil, for any, when it is absent or "*". Weak tags and tags that are no version never match,
+// so the result is empty, and not nil, when the header lists nothing else.
+func ifMatchVersions(r *http.Request) ([]int, error) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return nil, nil
+	}
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, nil
+		}
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid If-Match entity tag %s", tag)
+		}
+		if version, err := strconv.Atoi(unquoted); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// expectedVersions returns the versions of a portfolio the If-Match header of a request changing
+// it allows, to pass to the service: it checks them against the portfolio it loads for the
+// change, whose save then fails if another change lands first. When the header allows no
+// version at all, it answers 412 Precondition Failed with the current version as the ETag, and
+// on an invalid header 400; in both cases it returns false.
+func (ph *PortfolioHandler) expectedVersions(w http.ResponseWriter, r *http.Request, portfolioID string) ([]int, bool) {
+	versions, err := ifMatchVersions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	if versions == nil || len(versions) > 0 {
+		return versions, true
+	}
+	p, err := ph.service.GetPortfolioDetails(portfolioID)
+	if err != nil {
+		respondWithCashError(w, err)
+		return nil, false
+	}
+	setETag(w, p.Version)
+	respondWithError(w, http.StatusPreconditionFailed, fmt.Sprintf("portfolio %s is at version %d", portfolioID, p.Version))
+	return nil, false
+}
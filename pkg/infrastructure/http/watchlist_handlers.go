@@ -0,0 +1,287 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/watchlist"
+)
+
+// WatchlistServiceProvider defines the interface for watchlist operations needed by handlers.
+type WatchlistServiceProvider interface {
+	CreateWatchlist(name, owner, portfolioID string) (*watchlist.Watchlist, error)
+	GetWatchlist(id string) (*watchlist.Watchlist, error)
+	ListWatchlists(owner, portfolioID string) ([]*watchlist.Watchlist, error)
+	Watch(id, ticker string, targetPrice portfolio.Money, targetScore float64, note string) (*watchlist.Watchlist, error)
+	Unwatch(id, ticker string) (*watchlist.Watchlist, error)
+	DeleteWatchlist(id string) error
+	CheckEntrySignals(at time.Time) ([]application.EntryAlert, error)
+}
+
+// WatchlistHandler holds dependencies for watchlist-related HTTP handlers.
+type WatchlistHandler struct {
+	service WatchlistServiceProvider
+}
+
+// NewWatchlistHandler creates a new WatchlistHandler.
+func NewWatchlistHandler(ws WatchlistServiceProvider) *WatchlistHandler {
+	return &WatchlistHandler{service: ws}
+}
+
+// CreateWatchlistRequest DTO for creating a watchlist
+type CreateWatchlistRequest struct {
+	Name        string `json:"name" example:"Deep value candidates"`
+	Owner       string `json:"owner,omitempty" example:"jane.doe"`                                   // User the watchlist belongs to
+	PortfolioID string `json:"portfolioId,omitempty" example:"3f2b8c1e-6a4d-4c1b-9a7e-2d5f8e9c0b1a"` // Portfolio entry recommendations are made for
+}
+
+// WatchRequest DTO for adding a company to a watchlist or changing its target
+type WatchRequest struct {
+	WatchlistID string          `json:"watchlistId" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Ticker      string          `json:"ticker" example:"AAPL"`
+	TargetPrice portfolio.Money `json:"targetPrice"`                        // Buy at or below, e.g. {"amount": 15000, "currency": "USD"}
+	TargetScore float64         `json:"targetScore,omitempty" example:"75"` // Buy once the score reaches this
+	Note        string          `json:"note,omitempty" example:"Wait for a pullback after earnings"`
+}
+
+// UnwatchRequest DTO for removing a company from a watchlist
+type UnwatchRequest struct {
+	WatchlistID string `json:"watchlistId" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Ticker      string `json:"ticker" example:"AAPL"`
+}
+
+// DeleteWatchlistRequest DTO for deleting a watchlist
+type DeleteWatchlistRequest struct {
+	WatchlistID string `json:"watchlistId" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+}
+
+// CreateWatchlist godoc
+// @Summary      Create a watchlist
+// @Description  Creates an empty watchlist belonging to a user or kept for a portfolio. Entry signals of a portfolio's watchlist become position entry recommendations for it.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        watchlist body CreateWatchlistRequest true "Watchlist"
+// @Success      201  {object}  watchlist.Watchlist "Created watchlist"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlist/create [post]
+func (wh *WatchlistHandler) CreateWatchlist(w http.ResponseWriter, r *http.Request) {
+	var req CreateWatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	list, err := wh.service.CreateWatchlist(req.Name, req.Owner, req.PortfolioID)
+	if err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, list)
+}
+
+// GetWatchlist godoc
+// @Summary      Get a watchlist
+// @Description  Retrieves a watchlist with the target of each watched company.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        id query string true "Watchlist ID"
+// @Success      200  {object}  watchlist.Watchlist "Watchlist"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Watchlist not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlist [get]
+func (wh *WatchlistHandler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		respondWithError(w, http.StatusBadRequest, "watchlist id query parameter is required")
+		return
+	}
+
+	list, err := wh.service.GetWatchlist(id)
+	if err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// ListWatchlists godoc
+// @Summary      List watchlists
+// @Description  Lists the watchlists of a user or of a portfolio, oldest first; every watchlist when neither is given.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        owner query string false "Owner"
+// @Param        portfolioId query string false "Portfolio ID"
+// @Success      200  {array}   watchlist.Watchlist "Watchlists"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlists [get]
+func (wh *WatchlistHandler) ListWatchlists(w http.ResponseWriter, r *http.Request) {
+	owner, portfolioID := r.URL.Query().Get("owner"), r.URL.Query().Get("portfolioId")
+	if owner != "" && portfolioID != "" {
+		respondWithError(w, http.StatusBadRequest, "owner and portfolioId cannot be combined")
+		return
+	}
+
+	lists, err := wh.service.ListWatchlists(owner, portfolioID)
+	if err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+	if lists == nil {
+		lists = []*watchlist.Watchlist{}
+	}
+
+	respondWithJSON(w, http.StatusOK, lists)
+}
+
+// Watch godoc
+// @Summary      Watch a company
+// @Description  Adds a company to a watchlist with a target buy price, a target score or both, or replaces the target of a company already watched. An entry signal fires when either target is met.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        item body WatchRequest true "Company and target"
+// @Success      200  {object}  watchlist.Watchlist "Updated watchlist"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Watchlist or company not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlist/watch [post]
+func (wh *WatchlistHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	var req WatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.WatchlistID == "" || req.Ticker == "" {
+		respondWithError(w, http.StatusBadRequest, "watchlistId and ticker are required")
+		return
+	}
+
+	list, err := wh.service.Watch(req.WatchlistID, req.Ticker, req.TargetPrice, req.TargetScore, req.Note)
+	if err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// Unwatch godoc
+// @Summary      Unwatch a company
+// @Description  Removes a company from a watchlist.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        item body UnwatchRequest true "Company to remove"
+// @Success      200  {object}  watchlist.Watchlist "Updated watchlist"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Watchlist not found or company not watched"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlist/unwatch [post]
+func (wh *WatchlistHandler) Unwatch(w http.ResponseWriter, r *http.Request) {
+	var req UnwatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.WatchlistID == "" || req.Ticker == "" {
+		respondWithError(w, http.StatusBadRequest, "watchlistId and ticker are required")
+		return
+	}
+
+	list, err := wh.service.Unwatch(req.WatchlistID, req.Ticker)
+	if err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// DeleteWatchlist godoc
+// @Summary      Delete a watchlist
+// @Description  Deletes a watchlist and everything on it.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Param        watchlist body DeleteWatchlistRequest true "Watchlist to delete"
+// @Success      204  "Watchlist deleted"
+// @Failure      400  {object}  ErrorResponse "Invalid request"
+// @Failure      404  {object}  ErrorResponse "Watchlist not found"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlist/delete [post]
+func (wh *WatchlistHandler) DeleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	var req DeleteWatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if req.WatchlistID == "" {
+		respondWithError(w, http.StatusBadRequest, "watchlistId is required")
+		return
+	}
+
+	if err := wh.service.DeleteWatchlist(req.WatchlistID); err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CheckEntrySignals godoc
+// @Summary      Check entry signals
+// @Description  Evaluates every watchlist against the latest prices and scores. Each company whose target buy price or target score was crossed raises an alert; for watchlists kept for a portfolio it proposes a position entry recommendation. Signals fire once per crossing. This also runs periodically.
+// @Tags         watchlists
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   application.EntryAlert "Alerts raised by this check"
+// @Failure      500  {object}  ErrorResponse "Internal server error"
+// @Router       /watchlists/check [post]
+func (wh *WatchlistHandler) CheckEntrySignals(w http.ResponseWriter, r *http.Request) {
+	alerts, err := wh.service.CheckEntrySignals(time.Time{})
+	if err != nil {
+		respondWithWatchlistError(w, err)
+		return
+	}
+	if alerts == nil {
+		alerts = []application.EntryAlert{}
+	}
+
+	respondWithJSON(w, http.StatusOK, alerts)
+}
+
+// respondWithWatchlistError maps errors from the watchlist service to HTTP statuses: 404 for
+// unknown watchlists, companies or tickers not watched, and 400 for invalid input.
+func respondWithWatchlistError(w http.ResponseWriter, err error) {
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, watchlist.ErrNotWatched), strings.Contains(errStr, "not found"):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case strings.Contains(errStr, "domain error") ||
+		strings.Contains(errStr, "cannot be empty") ||
+		strings.Contains(errStr, "not both"):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
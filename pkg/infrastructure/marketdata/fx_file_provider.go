@@ -0,0 +1,130 @@
+package marketdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// dateLayout is the date format used by the file-backed market data tables.
+const dateLayout = "2006-01-02"
+
+// datedRate is an exchange rate effective from a given day.
+type datedRate struct {
+	date time.Time
+	rate float64
+}
+
+// FileFXRateProvider is a file-backed implementation of portfolio.FXRateProvider.
+// It stands in for a market data feed by serving historical rates loaded from a CSV table
+// with the columns date,from,to,rate (e.g. "2024-01-02,EUR,USD,1.0945").
+// It is safe for concurrent use because the table is read-only after loading.
+type FileFXRateProvider struct {
+	rates map[string][]datedRate // Keyed by "FROM/TO", sorted by date
+}
+
+// NewFileFXRateProvider loads the rate table at path.
+func NewFileFXRateProvider(path string) (*FileFXRateProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fx rates file: %w", err)
+	}
+	defer f.Close()
+	return NewFXRateProviderFromReader(f)
+}
+
+// NewFXRateProviderFromReader loads a rate table from r.
+// Blank lines, lines starting with '#' and a leading "date" header row are ignored.
+func NewFXRateProviderFromReader(r io.Reader) (*FileFXRateProvider, error) {
+	records, err := readTable(r, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fx rates: %w", err)
+	}
+
+	p := &FileFXRateProvider{rates: make(map[string][]datedRate)}
+	for i, rec := range records {
+		date, err := time.Parse(dateLayout, rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("fx rates row %d: invalid date %q: %w", i+1, rec[0], err)
+		}
+		from, to := strings.ToUpper(rec[1]), strings.ToUpper(rec[2])
+		rate, err := strconv.ParseFloat(rec[3], 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("fx rates row %d: invalid rate %q", i+1, rec[3])
+		}
+		key := pairKey(from, to)
+		p.rates[key] = append(p.rates[key], datedRate{date: date, rate: rate})
+	}
+	for _, series := range p.rates {
+		sort.Slice(series, func(i, j int) bool { return series[i].date.Before(series[j].date) })
+	}
+	return p, nil
+}
+
+// Rate returns the most recent from→to rate effective on or before the given date.
+// If only the inverse pair is on file, its reciprocal is used.
+func (p *FileFXRateProvider) Rate(from, to string, on time.Time) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	if rate, ok := latestRate(p.rates[pairKey(from, to)], on); ok {
+		return rate, nil
+	}
+	if rate, ok := latestRate(p.rates[pairKey(to, from)], on); ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("%s/%s on %s: %w", from, to, on.Format(dateLayout), portfolio.ErrFXRateNotFound)
+}
+
+// latestRate finds the last rate in a date-sorted series that is effective on the given day.
+func latestRate(series []datedRate, on time.Time) (float64, bool) {
+	day := truncateToDay(on)
+	i := sort.Search(len(series), func(i int) bool { return series[i].date.After(day) })
+	if i == 0 {
+		return 0, false
+	}
+	return series[i-1].rate, true
+}
+
+func pairKey(from, to string) string {
+	return from + "/" + to
+}
+
+// truncateToDay drops the time of day so lookups match the daily granularity of the tables.
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// readTable reads a CSV table with the given number of columns, skipping blank lines,
+// comment lines starting with '#' and an optional header row whose first column is "date".
+func readTable(r io.Reader, columns int) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = columns
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 && strings.EqualFold(rec[0], "date") {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,89 @@
+package marketdata_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/marketdata"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestFileFXRateProvider(t *testing.T) {
+	table := `date,from,to,rate
+# Historical EUR/USD fixings
+2024-01-02,EUR,USD,1.10
+2024-01-05,EUR,USD,1.20
+`
+	rates, err := marketdata.NewFXRateProviderFromReader(strings.NewReader(table))
+	if err != nil {
+		t.Fatalf("NewFXRateProviderFromReader() error = %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		from, to string
+		on       time.Time
+		want     float64
+	}{
+		{"ExactDate", "EUR", "USD", day("2024-01-02"), 1.10},
+		{"CarriesForward", "EUR", "USD", day("2024-01-04"), 1.10},
+		{"LatestRate", "EUR", "USD", day("2024-02-01").Add(15 * time.Hour), 1.20},
+		{"InversePair", "USD", "EUR", day("2024-01-05"), 1 / 1.20},
+		{"SameCurrency", "USD", "USD", day("2000-01-01"), 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rates.Rate(tc.from, tc.to, tc.on)
+			if err != nil {
+				t.Fatalf("Rate() error = %v", err)
+			}
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("Rate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("BeforeFirstRate", func(t *testing.T) {
+		_, err := rates.Rate("EUR", "USD", day("2023-12-31"))
+		if !errors.Is(err, portfolio.ErrFXRateNotFound) {
+			t.Errorf("Rate() error = %v, want ErrFXRateNotFound", err)
+		}
+	})
+
+	t.Run("InvalidRow", func(t *testing.T) {
+		_, err := marketdata.NewFXRateProviderFromReader(strings.NewReader("2024-01-02,EUR,USD,abc\n"))
+		if err == nil {
+			t.Error("NewFXRateProviderFromReader() with invalid rate expected error, got nil")
+		}
+	})
+}
+
+func TestFilePriceProvider(t *testing.T) {
+	table := `2024-01-03,AAPL,18400,USD
+2024-01-02,AAPL,18564,USD
+`
+	prices, err := marketdata.NewPriceProviderFromReader(strings.NewReader(table))
+	if err != nil {
+		t.Fatalf("NewPriceProviderFromReader() error = %v", err)
+	}
+
+	got, err := prices.Price("AAPL", day("2024-01-10"))
+	if err != nil {
+		t.Fatalf("Price() error = %v", err)
+	}
+	if got.Amount != 18400 || got.Currency != "USD" {
+		t.Errorf("Price() = %v, want 18400 USD", got)
+	}
+
+	if _, err := prices.Price("MSFT", day("2024-01-10")); !errors.Is(err, portfolio.ErrPriceNotFound) {
+		t.Errorf("Price() for unknown ticker error = %v, want ErrPriceNotFound", err)
+	}
+}
@@ -0,0 +1,79 @@
+package marketdata
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// datedPrice is a closing price for a given day.
+type datedPrice struct {
+	date  time.Time
+	price portfolio.Money
+}
+
+// FilePriceProvider is a file-backed implementation of portfolio.PriceProvider.
+// It serves daily closing prices loaded from a CSV table with the columns
+// date,ticker,amount,currency where amount is in the smallest currency unit
+// (e.g. "2024-01-02,AAPL,18564,USD").
+// It is safe for concurrent use because the table is read-only after loading.
+type FilePriceProvider struct {
+	prices map[string][]datedPrice // Keyed by ticker, sorted by date
+}
+
+// NewFilePriceProvider loads the price table at path.
+func NewFilePriceProvider(path string) (*FilePriceProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prices file: %w", err)
+	}
+	defer f.Close()
+	return NewPriceProviderFromReader(f)
+}
+
+// NewPriceProviderFromReader loads a price table from r.
+func NewPriceProviderFromReader(r io.Reader) (*FilePriceProvider, error) {
+	records, err := readTable(r, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prices: %w", err)
+	}
+
+	p := &FilePriceProvider{prices: make(map[string][]datedPrice)}
+	for i, rec := range records {
+		date, err := time.Parse(dateLayout, rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("prices row %d: invalid date %q: %w", i+1, rec[0], err)
+		}
+		amount, err := strconv.ParseInt(rec[2], 10, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("prices row %d: invalid amount %q", i+1, rec[2])
+		}
+		price, err := portfolio.NewMoney(amount, strings.ToUpper(rec[3]))
+		if err != nil {
+			return nil, fmt.Errorf("prices row %d: %w", i+1, err)
+		}
+		ticker := rec[1]
+		p.prices[ticker] = append(p.prices[ticker], datedPrice{date: date, price: *price})
+	}
+	for _, series := range p.prices {
+		sort.Slice(series, func(i, j int) bool { return series[i].date.Before(series[j].date) })
+	}
+	return p, nil
+}
+
+// Price returns the most recent closing price for ticker on or before the given date.
+func (p *FilePriceProvider) Price(ticker string, on time.Time) (portfolio.Money, error) {
+	series := p.prices[ticker]
+	day := truncateToDay(on)
+	i := sort.Search(len(series), func(i int) bool { return series[i].date.After(day) })
+	if i == 0 {
+		return portfolio.Money{}, fmt.Errorf("%s on %s: %w", ticker, on.Format(dateLayout), portfolio.ErrPriceNotFound)
+	}
+	return series[i-1].price, nil
+}
@@ -0,0 +1,245 @@
+// Package audited wraps the company and portfolio repositories so that every change saved
+// through them is recorded in the audit log, with who made it, through which endpoint and how
+// the aggregate differs from before. The wrappers are made for one source, a request or a
+// scheduled job, and the services handling it write through them.
+//
+// Where a unit of work can hold audit entries, as bbolt's and PostgreSQL's can, each change
+// commits together with its entry. Elsewhere, in memory, the entry is appended once the change
+// is saved; should that fail, the failure is logged rather than reported, since reporting a
+// change that was made as failed would have it made again.
+package audited
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// recorder records changes made on behalf of a source in an audit log. Within a unit of work
+// it holds the entries back until the unit commits, so that a failed unit records nothing.
+type recorder struct {
+	log     audit.Log
+	source  audit.Source
+	now     func() time.Time
+	pending *[]*audit.Entry // Nil outside a unit of work
+}
+
+// newRecorder creates a recorder of the changes made on behalf of source.
+func newRecorder(log audit.Log, source audit.Source) recorder {
+	return recorder{
+		log:    log,
+		source: source,
+		now:    func() time.Time { return time.Now().UTC().Truncate(time.Microsecond) }, // The precision every log keeps
+	}
+}
+
+// record records that an aggregate, now at version, changed from before to after. Within a
+// unit of work it fails if the entry cannot be made, failing the unit; outside one the change
+// is saved already, and a failure to record it is only logged.
+func (r recorder) record(aggregateType audit.AggregateType, id string, version int, before, after any) error {
+	e, err := audit.NewEntry(r.source, aggregateType, id, version, before, after, r.now())
+	if r.pending != nil {
+		if err != nil {
+			return fmt.Errorf("failed to audit the change to %s %s: %w", aggregateType, id, err)
+		}
+		*r.pending = append(*r.pending, e)
+		return nil
+	}
+	if err == nil {
+		err = r.log.Append(e)
+	}
+	if err != nil {
+		logUnaudited(aggregateType, id, err)
+	}
+	return nil
+}
+
+// logUnaudited logs that a change saved to an aggregate could not be recorded in the audit log.
+func logUnaudited(aggregateType audit.AggregateType, id string, err error) {
+	log.Printf("%s %s was changed but the change could not be audited: %v\n", aggregateType, id, err)
+}
+
+// RepositoryOption configures optional behaviour of the audited repositories.
+type RepositoryOption func(*repositoryOptions)
+
+// repositoryOptions holds what RepositoryOptions configure.
+type repositoryOptions struct {
+	uow *AuditedUnitOfWork // Nil to write straight through to the repository
+}
+
+// ThroughUnitOfWork makes the repository save and delete each aggregate in a unit of uow, over
+// the same storage as the repository, so that the change commits together with its audit
+// entry when the units can hold audit entries.
+func ThroughUnitOfWork(uow *AuditedUnitOfWork) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.uow = uow
+	}
+}
+
+// newRepositoryOptions applies opts.
+func newRepositoryOptions(opts []RepositoryOption) repositoryOptions {
+	var o repositoryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// AuditedCompanyRepository is a CompanyRepository recording the changes saved through it.
+type AuditedCompanyRepository struct {
+	company.CompanyRepository
+	recorder recorder
+	options  repositoryOptions
+}
+
+// NewAuditedCompanyRepository creates a new instance of AuditedCompanyRepository that records
+// the changes saved through repo in log, as made by source.
+func NewAuditedCompanyRepository(repo company.CompanyRepository, log audit.Log, source audit.Source, opts ...RepositoryOption) *AuditedCompanyRepository {
+	return &AuditedCompanyRepository{CompanyRepository: repo, recorder: newRecorder(log, source), options: newRepositoryOptions(opts)}
+}
+
+// Save saves a company and records how it differs from the one stored before.
+func (r *AuditedCompanyRepository) Save(c *company.Company) error {
+	if c == nil {
+		return r.CompanyRepository.Save(c)
+	}
+	if u := r.options.uow; u != nil {
+		version := c.Version
+		err := u.Do(func(repos application.Repositories) error { return repos.Companies.Save(c) })
+		if err != nil {
+			c.Version = version // As a failed save leaves it
+		}
+		return err
+	}
+	before, err := r.CompanyRepository.FindByTicker(c.Ticker)
+	if err != nil && !errors.Is(err, company.ErrCompanyNotFound) {
+		return err
+	}
+	if err := r.CompanyRepository.Save(c); err != nil {
+		return err
+	}
+	return r.recorder.record(audit.Company, c.Ticker, c.Version, before, c)
+}
+
+// Delete deletes a company and records its removal.
+func (r *AuditedCompanyRepository) Delete(ticker string) error {
+	if u := r.options.uow; u != nil {
+		return u.Do(func(repos application.Repositories) error { return repos.Companies.Delete(ticker) })
+	}
+	before, err := r.CompanyRepository.FindByTicker(ticker)
+	if err != nil && !errors.Is(err, company.ErrCompanyNotFound) {
+		return err
+	}
+	if err := r.CompanyRepository.Delete(ticker); err != nil {
+		return err
+	}
+	return r.recorder.record(audit.Company, ticker, 0, before, nil)
+}
+
+// AuditedPortfolioRepository is a PortfolioRepository recording the changes saved through it.
+type AuditedPortfolioRepository struct {
+	portfolio.PortfolioRepository
+	recorder recorder
+	options  repositoryOptions
+}
+
+// NewAuditedPortfolioRepository creates a new instance of AuditedPortfolioRepository that
+// records the changes saved through repo in log, as made by source.
+func NewAuditedPortfolioRepository(repo portfolio.PortfolioRepository, log audit.Log, source audit.Source, opts ...RepositoryOption) *AuditedPortfolioRepository {
+	return &AuditedPortfolioRepository{PortfolioRepository: repo, recorder: newRecorder(log, source), options: newRepositoryOptions(opts)}
+}
+
+// Save saves a portfolio and records how it differs from the one stored before.
+func (r *AuditedPortfolioRepository) Save(p *portfolio.Portfolio) error {
+	if p == nil {
+		return r.PortfolioRepository.Save(p)
+	}
+	if u := r.options.uow; u != nil {
+		version := p.Version
+		err := u.Do(func(repos application.Repositories) error { return repos.Portfolios.Save(p) })
+		if err != nil {
+			p.Version = version // As a failed save leaves it
+		}
+		return err
+	}
+	before, err := r.PortfolioRepository.FindByID(p.ID)
+	if err != nil && !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		return err
+	}
+	if err := r.PortfolioRepository.Save(p); err != nil {
+		return err
+	}
+	return r.recorder.record(audit.Portfolio, p.ID, p.Version, before, p)
+}
+
+// Delete deletes a portfolio and records its removal.
+func (r *AuditedPortfolioRepository) Delete(id string) error {
+	if u := r.options.uow; u != nil {
+		return u.Do(func(repos application.Repositories) error { return repos.Portfolios.Delete(id) })
+	}
+	before, err := r.PortfolioRepository.FindByID(id)
+	if err != nil && !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		return err
+	}
+	if err := r.PortfolioRepository.Delete(id); err != nil {
+		return err
+	}
+	return r.recorder.record(audit.Portfolio, id, 0, before, nil)
+}
+
+// AuditedUnitOfWork is a UnitOfWork recording the changes its units commit.
+type AuditedUnitOfWork struct {
+	uow    application.UnitOfWork
+	log    audit.Log
+	source audit.Source
+}
+
+// NewAuditedUnitOfWork creates a new instance of AuditedUnitOfWork that records the changes
+// committed by the units of uow in log, as made by source.
+func NewAuditedUnitOfWork(uow application.UnitOfWork, log audit.Log, source audit.Source) *AuditedUnitOfWork {
+	return &AuditedUnitOfWork{uow: uow, log: log, source: source}
+}
+
+// Do runs work in a unit of work and records the changes it made, in the order it made them.
+// When the unit can hold audit entries, they are its last writes and commit with the changes:
+// taking the log last keeps it locked for as short as possible, and never before the
+// aggregates the unit writes. Otherwise they are appended once the unit has committed.
+func (u *AuditedUnitOfWork) Do(work func(repos application.Repositories) error) error {
+	var pending []*audit.Entry
+	err := u.uow.Do(func(repos application.Repositories) error {
+		pending = nil
+		rec := newRecorder(u.log, u.source)
+		rec.pending = &pending
+		audited := repos
+		audited.Companies = &AuditedCompanyRepository{CompanyRepository: repos.Companies, recorder: rec}
+		audited.Portfolios = &AuditedPortfolioRepository{PortfolioRepository: repos.Portfolios, recorder: rec}
+		if err := work(audited); err != nil {
+			return err
+		}
+		if repos.AuditLog == nil {
+			return nil
+		}
+		for _, e := range pending {
+			if err := repos.AuditLog.Append(e); err != nil {
+				return fmt.Errorf("failed to audit the change to %s %s: %w", e.AggregateType, e.AggregateID, err)
+			}
+		}
+		pending = nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range pending {
+		if err := u.log.Append(e); err != nil {
+			logUnaudited(e.AggregateType, e.AggregateID, err)
+		}
+	}
+	return nil
+}
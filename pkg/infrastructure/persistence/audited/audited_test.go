@@ -0,0 +1,201 @@
+package audited
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/repotest"
+)
+
+var alice = audit.Source{Actor: "alice", Endpoint: "POST /portfolio/cash/deposit"}
+
+func TestAuditedRepositories_Contract(t *testing.T) {
+	repotest.TestPortfolioRepository(t, func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository) {
+		log := memory.NewInMemoryAuditLog()
+		companies := memory.NewInMemoryCompanyRepository()
+		return NewAuditedCompanyRepository(companies, log, alice),
+			NewAuditedPortfolioRepository(memory.NewInMemoryPortfolioRepository(companies), log, alice)
+	})
+}
+
+func TestAuditedRepositories_ThroughUnitOfWorkContract(t *testing.T) {
+	repotest.TestPortfolioRepository(t, func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository) {
+		log := memory.NewInMemoryAuditLog()
+		companies := memory.NewInMemoryCompanyRepository()
+		portfolios := memory.NewInMemoryPortfolioRepository(companies)
+		uow := NewAuditedUnitOfWork(memory.NewInMemoryUnitOfWork(companies, portfolios, memory.NewInMemoryRecommendationRepository()), log, alice)
+		return NewAuditedCompanyRepository(companies, log, alice, ThroughUnitOfWork(uow)),
+			NewAuditedPortfolioRepository(portfolios, log, alice, ThroughUnitOfWork(uow))
+	})
+}
+
+// auditingUnitOfWork holds audit entries as PostgreSQL's unit of work does, appending them to
+// log as part of the unit.
+type auditingUnitOfWork struct {
+	application.UnitOfWork
+	log audit.Log
+}
+
+func (u auditingUnitOfWork) Do(work func(repos application.Repositories) error) error {
+	return u.UnitOfWork.Do(func(repos application.Repositories) error {
+		repos.AuditLog = u.log
+		return work(repos)
+	})
+}
+
+// failingLog refuses every entry.
+type failingLog struct {
+	audit.Log
+}
+
+func (failingLog) Append(e *audit.Entry) error {
+	return errors.New("audit log unavailable")
+}
+
+// describe returns the aggregate, version, actor and changed paths of each entry.
+func describe(entries []*audit.Entry) string {
+	var s []string
+	for _, e := range entries {
+		paths := make([]string, len(e.Changes))
+		for i, c := range e.Changes {
+			paths[i] = c.Path
+		}
+		s = append(s, fmt.Sprintf("%s %s v%d by %s: %v", e.AggregateType, e.AggregateID, e.Version, e.Actor, paths))
+	}
+	return fmt.Sprint(s)
+}
+
+func TestAuditedRepositories_Record(t *testing.T) {
+	log := memory.NewInMemoryAuditLog()
+	companies := NewAuditedCompanyRepository(memory.NewInMemoryCompanyRepository(), log, alice)
+	portfolios := NewAuditedPortfolioRepository(memory.NewInMemoryPortfolioRepository(companies), log, alice)
+
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	if err := companies.Save(c); err != nil {
+		t.Fatalf("Save(AAPL) error = %v", err)
+	}
+	c.CurrentScore = 80
+	if err := companies.Save(c); err != nil {
+		t.Fatalf("Save(AAPL) error = %v", err)
+	}
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	if err := portfolios.Save(p); err != nil {
+		t.Fatalf("Save(p1) error = %v", err)
+	}
+
+	// A save that is refused records nothing.
+	stale, _ := portfolio.NewPortfolio("p1", portfolio.Aggressive, portfolio.Money{Amount: 100000, Currency: "USD"})
+	if err := portfolios.Save(stale); !errors.Is(err, portfolio.ErrVersionConflict) {
+		t.Fatalf("Save(stale) error = %v, want a version conflict", err)
+	}
+	if err := portfolios.Delete("p1"); err != nil {
+		t.Fatalf("Delete(p1) error = %v", err)
+	}
+
+	entries, err := log.Find(audit.Filter{})
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("Find() = %s, %v, want 4 entries", describe(entries), err)
+	}
+	if got := describe(entries[1:2]); got != "[company AAPL v2 by alice: [CurrentScore Version]]" {
+		t.Errorf("update entry = %s, want the changed score", got)
+	}
+	if e := entries[3]; e.AggregateID != "p1" || e.Version != 0 || len(e.Changes) == 0 || e.Changes[0].After != nil {
+		t.Errorf("delete entry = %s, want p1 removed", describe(entries[3:]))
+	}
+	if entries[0].Endpoint != alice.Endpoint || audit.Verify(entries) != nil {
+		t.Errorf("Find() = %+v, want a verified chain of alice's changes", entries)
+	}
+}
+
+func TestAuditedUnitOfWork(t *testing.T) {
+	log := memory.NewInMemoryAuditLog()
+	companies := memory.NewInMemoryCompanyRepository()
+	portfolios := memory.NewInMemoryPortfolioRepository(companies)
+	uow := NewAuditedUnitOfWork(memory.NewInMemoryUnitOfWork(companies, portfolios, memory.NewInMemoryRecommendationRepository()), log, alice)
+
+	save := func(repos application.Repositories) error {
+		c, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+		if err := repos.Companies.Save(c); err != nil {
+			return err
+		}
+		p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+		return repos.Portfolios.Save(p)
+	}
+
+	errFailed := errors.New("failed")
+	err := uow.Do(func(repos application.Repositories) error {
+		if err := save(repos); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Do() error = %v, want the error of the work", err)
+	}
+	if entries, _ := log.Find(audit.Filter{}); len(entries) != 0 {
+		t.Errorf("Find() after a failed unit = %s, want nothing recorded", describe(entries))
+	}
+
+	if err := uow.Do(save); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	entries, err := log.Find(audit.Filter{})
+	if err != nil || len(entries) != 2 || entries[0].AggregateType != audit.Company || entries[1].AggregateID != "p1" || entries[1].Actor != "alice" {
+		t.Errorf("Find() after a committed unit = %s, %v, want AAPL then p1", describe(entries), err)
+	}
+}
+
+func TestAuditedRepositories_UnauditedChange(t *testing.T) {
+	companies := memory.NewInMemoryCompanyRepository()
+	repo := NewAuditedCompanyRepository(companies, failingLog{memory.NewInMemoryAuditLog()}, alice)
+
+	// The company is saved before the log refuses its entry: the save must not be reported as
+	// failed, or it would be made again.
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	if err := repo.Save(c); err != nil {
+		t.Fatalf("Save() error = %v, want nil for a saved change", err)
+	}
+	if stored, err := companies.FindByTicker("AAPL"); err != nil || stored.Version != 1 {
+		t.Errorf("FindByTicker() = %+v, %v, want AAPL saved", stored, err)
+	}
+}
+
+func TestAuditedUnitOfWork_EntriesInUnit(t *testing.T) {
+	newRepo := func(unitLog audit.Log) (*AuditedCompanyRepository, *memory.InMemoryCompanyRepository, audit.Log) {
+		log := memory.NewInMemoryAuditLog()
+		companies := memory.NewInMemoryCompanyRepository()
+		portfolios := memory.NewInMemoryPortfolioRepository(companies)
+		unit := auditingUnitOfWork{memory.NewInMemoryUnitOfWork(companies, portfolios, memory.NewInMemoryRecommendationRepository()), unitLog}
+		uow := NewAuditedUnitOfWork(unit, log, alice)
+		return NewAuditedCompanyRepository(companies, log, alice, ThroughUnitOfWork(uow)), companies, log
+	}
+
+	unitLog := memory.NewInMemoryAuditLog()
+	repo, _, log := newRepo(unitLog)
+	c, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	if err := repo.Save(c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if entries, _ := unitLog.Find(audit.Filter{}); len(entries) != 1 || entries[0].AggregateID != "AAPL" {
+		t.Errorf("entries in the unit = %s, want AAPL's", describe(entries))
+	}
+	if entries, _ := log.Find(audit.Filter{}); len(entries) != 0 {
+		t.Errorf("entries appended after the unit = %s, want none", describe(entries))
+	}
+
+	// An entry the unit cannot append fails it, and the change is not saved.
+	repo, companies, _ := newRepo(failingLog{memory.NewInMemoryAuditLog()})
+	c, _ = company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	if err := repo.Save(c); err == nil || c.Version != 0 {
+		t.Errorf("Save() = version %d, error %v, want an error and the version unchanged", c.Version, err)
+	}
+	if _, err := companies.FindByTicker("AAPL"); !errors.Is(err, company.ErrCompanyNotFound) {
+		t.Errorf("FindByTicker() error = %v, want AAPL not saved", err)
+	}
+}
@@ -0,0 +1,72 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// BoltAuditLog is a bbolt implementation of the audit Log. Entries are JSON documents keyed by
+// their big-endian sequence, so that cursors walk the log in order.
+type BoltAuditLog struct {
+	db database
+}
+
+// NewBoltAuditLog creates a new instance of BoltAuditLog on a database opened by Open.
+func NewBoltAuditLog(db *bbolt.DB) *BoltAuditLog {
+	return &BoltAuditLog{db: db}
+}
+
+// Append seals an entry after the last one of the log and stores it.
+func (l *BoltAuditLog) Append(e *audit.Entry) error {
+	if e == nil {
+		return errors.New("audit entry cannot be nil")
+	}
+	err := l.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(auditLogBucket)
+		sequence, previous := 1, ""
+		if k, v := b.Cursor().Last(); k != nil {
+			last, err := audit.DecodeEntry(v)
+			if err != nil {
+				return err
+			}
+			sequence, previous = decodeVersion(k)+1, last.Hash
+		}
+		if err := e.Seal(sequence, previous); err != nil {
+			return err
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return b.Put(encodeVersion(sequence), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry for %s %s: %w", e.AggregateType, e.AggregateID, err)
+	}
+	return nil
+}
+
+// Find retrieves the entries the filter selects, in log order.
+func (l *BoltAuditLog) Find(filter audit.Filter) ([]*audit.Entry, error) {
+	found := []*audit.Entry{}
+	err := l.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(auditLogBucket).ForEach(func(_, v []byte) error {
+			e, err := audit.DecodeEntry(v)
+			if err != nil {
+				return err
+			}
+			if filter.Matches(e) {
+				found = append(found, e)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	return found, nil
+}
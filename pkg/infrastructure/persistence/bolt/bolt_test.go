@@ -0,0 +1,211 @@
+package bolt_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/bolt"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/eventsourced"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/repotest"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// testDB opens a database in a fresh file, closing it when the test ends.
+func testDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+	return testDBAt(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+// testDBAt opens the database at path, closing it when the test ends.
+func testDBAt(t *testing.T, path string) *bbolt.DB {
+	t.Helper()
+	db, err := bolt.Open(path)
+	if err != nil {
+		t.Fatalf("Open(%s) error = %v", path, err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestBoltCompanyRepository_Contract(t *testing.T) {
+	repotest.TestCompanyRepository(t, func(t *testing.T) company.CompanyRepository {
+		return bolt.NewBoltCompanyRepository(testDB(t))
+	})
+}
+
+func TestBoltPortfolioRepository_Contract(t *testing.T) {
+	repotest.TestPortfolioRepository(t, func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository) {
+		db := testDB(t)
+		return bolt.NewBoltCompanyRepository(db), bolt.NewBoltPortfolioRepository(db)
+	})
+}
+
+func TestBoltPortfolioEventStore_Contract(t *testing.T) {
+	repotest.TestPortfolioEventStore(t, func(t *testing.T) portfolio.EventStore {
+		return bolt.NewBoltPortfolioEventStore(testDB(t))
+	})
+}
+
+func TestBoltAuditLog_Contract(t *testing.T) {
+	repotest.TestAuditLog(t, func(t *testing.T) audit.Log {
+		return bolt.NewBoltAuditLog(testDB(t))
+	})
+}
+
+func TestBoltValuationSnapshotRepository_Contract(t *testing.T) {
+	repotest.TestValuationSnapshotRepository(t, func(t *testing.T) portfolio.ValuationSnapshotRepository {
+		return bolt.NewBoltValuationSnapshotRepository(testDB(t))
+	})
+}
+
+func TestBoltRiskMetricsRepository_Contract(t *testing.T) {
+	repotest.TestRiskMetricsRepository(t, func(t *testing.T) portfolio.RiskMetricsRepository {
+		return bolt.NewBoltRiskMetricsRepository(testDB(t))
+	})
+}
+
+func TestBoltAuditLog_Tampering(t *testing.T) {
+	db := testDB(t)
+	log := bolt.NewBoltAuditLog(db)
+	for _, actor := range []string{"alice", "bob"} {
+		e, _ := audit.NewEntry(audit.Source{Actor: actor, Endpoint: "POST /company/create"}, audit.Company, "AAPL", 1, nil, map[string]int{"Score": 80}, time.Now().UTC())
+		if err := log.Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// Someone with access to the file rewrites who made the first change.
+	err := db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte("audit_log"))
+		k, v := b.Cursor().First()
+		return b.Put(k, bytes.Replace(v, []byte(`"alice"`), []byte(`"mallory"`), 1))
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	entries, err := log.Find(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	var tampered *audit.TamperedError
+	if err := audit.Verify(entries); !errors.As(err, &tampered) || tampered.Sequence != 1 {
+		t.Errorf("Verify() error = %v, want tampering detected at entry 1", err)
+	}
+}
+
+func TestEventSourcedPortfolioRepository_Contract(t *testing.T) {
+	repotest.TestPortfolioRepository(t, func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository) {
+		db := testDB(t)
+		companies := bolt.NewBoltCompanyRepository(db)
+		return companies, eventsourced.NewEventSourcedPortfolioRepository(bolt.NewBoltPortfolioEventStore(db), companies, 2)
+	})
+}
+
+func TestBoltUnitOfWork_Contract(t *testing.T) {
+	repotest.TestSerialUnitOfWork(t, func(t *testing.T) (application.UnitOfWork, company.CompanyRepository, portfolio.PortfolioRepository) {
+		db := testDB(t)
+		uow := bolt.NewBoltUnitOfWork(db, memory.NewInMemoryRecommendationRepository())
+		return uow, bolt.NewBoltCompanyRepository(db), bolt.NewBoltPortfolioRepository(db)
+	})
+}
+
+func TestBoltUnitOfWork_EventSourcedContract(t *testing.T) {
+	repotest.TestSerialUnitOfWork(t, func(t *testing.T) (application.UnitOfWork, company.CompanyRepository, portfolio.PortfolioRepository) {
+		db := testDB(t)
+		companies := bolt.NewBoltCompanyRepository(db)
+		uow := bolt.NewBoltUnitOfWork(db, memory.NewInMemoryRecommendationRepository(), bolt.WithEventSourcedPortfolios(2))
+		return uow, companies, eventsourced.NewEventSourcedPortfolioRepository(bolt.NewBoltPortfolioEventStore(db), companies, 2)
+	})
+}
+
+func TestBoltUnitOfWork_AuditLog(t *testing.T) {
+	db := testDB(t)
+	recommendations := memory.NewInMemoryRecommendationRepository()
+	uow := bolt.NewBoltUnitOfWork(db, recommendations)
+	log := bolt.NewBoltAuditLog(db)
+	source := audit.Source{Actor: "alice", Endpoint: "POST /company/create"}
+	trades := []portfolio.TradeRecommendation{{Action: portfolio.Enter, Ticker: "AAPL", Quantity: 10}}
+	saveAudited := func(repos application.Repositories) error {
+		c, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+		if err := repos.Companies.Save(c); err != nil {
+			return err
+		}
+		rec, _ := recommendation.NewRecommendation("r1", "p1", trades, time.Now(), time.Hour)
+		if err := repos.Recommendations.Save(rec); err != nil {
+			return err
+		}
+		e, _ := audit.NewEntry(source, audit.Company, "AAPL", c.Version, nil, c, time.Now().UTC())
+		return repos.AuditLog.Append(e)
+	}
+
+	errFailed := errors.New("failed")
+	err := uow.Do(func(repos application.Repositories) error {
+		if err := saveAudited(repos); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Do() error = %v, want the error of the work", err)
+	}
+	if entries, err := log.Find(audit.Filter{}); err != nil || len(entries) != 0 {
+		t.Errorf("Find() after a failed unit = %d entries, %v, want none", len(entries), err)
+	}
+	if _, err := bolt.NewBoltCompanyRepository(db).FindByTicker("AAPL"); !errors.Is(err, bolt.ErrCompanyNotFound) {
+		t.Errorf("FindByTicker(AAPL) after a failed unit error = %v, want ErrCompanyNotFound", err)
+	}
+	if _, err := recommendations.FindByID("r1"); !errors.Is(err, memory.ErrRecommendationNotFound) {
+		t.Errorf("FindByID(r1) after a failed unit error = %v, want ErrRecommendationNotFound", err)
+	}
+
+	if err := uow.Do(saveAudited); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if entries, err := log.Find(audit.Filter{}); err != nil || len(entries) != 1 || entries[0].AggregateID != "AAPL" {
+		t.Errorf("Find() after a committed unit = %d entries, %v, want AAPL's", len(entries), err)
+	}
+	if _, err := recommendations.FindByID("r1"); err != nil {
+		t.Errorf("FindByID(r1) after a committed unit error = %v", err)
+	}
+}
+
+func TestOpen_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := bolt.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	xom, _ := company.NewCompany("XOM", company.FinancialMetrics{PERatio: 10}, company.Energy)
+	if err := bolt.NewBoltCompanyRepository(db).Save(xom); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, portfolio.Money{Amount: 100000, Currency: "USD"})
+	pos, _ := portfolio.NewPosition("XOM", 10, portfolio.Money{Amount: 1000, Currency: "USD"})
+	if err := p.AddPosition(*pos, portfolio.Money{Amount: 10000, Currency: "USD"}); err != nil {
+		t.Fatalf("AddPosition() error = %v", err)
+	}
+	if err := bolt.NewBoltPortfolioRepository(db).Save(p); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	db.Close()
+
+	// What was saved survives the process that saved it.
+	db = testDBAt(t, path)
+	repo := bolt.NewBoltPortfolioRepository(db)
+	if energy, err := repo.SearchBySector(company.Energy); err != nil || len(energy) != 1 || energy[0].Holdings["XOM"].Shares != 10 {
+		t.Errorf("SearchBySector(Energy) after reopening = %v, %v, want p1", energy, err)
+	}
+	if _, err := repo.FindByID("p2"); !errors.Is(err, bolt.ErrPortfolioNotFound) {
+		t.Errorf("FindByID(missing) error = %v, want ErrPortfolioNotFound", err)
+	}
+}
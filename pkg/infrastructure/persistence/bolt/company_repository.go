@@ -0,0 +1,151 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// ErrCompanyNotFound is returned when a company is not found in the repository.
+var ErrCompanyNotFound = company.ErrCompanyNotFound
+
+// BoltCompanyRepository is a bbolt implementation of the CompanyRepository interface.
+type BoltCompanyRepository struct {
+	db database
+}
+
+// NewBoltCompanyRepository creates a new instance of BoltCompanyRepository on a database
+// opened by Open.
+func NewBoltCompanyRepository(db *bbolt.DB) *BoltCompanyRepository {
+	return &BoltCompanyRepository{db: db}
+}
+
+// Save creates or updates a company, unless it is stale.
+func (r *BoltCompanyRepository) Save(c *company.Company) error {
+	if c == nil {
+		return errors.New("company cannot be nil")
+	}
+	if c.Ticker == "" {
+		return errors.New("company ticker cannot be empty")
+	}
+
+	saved := c.Clone()
+	saved.Version++
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode company %s: %w", c.Ticker, err)
+	}
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(companiesBucket)
+		current := 0
+		if stored := b.Get([]byte(c.Ticker)); stored != nil {
+			s, err := decodeCompany(stored)
+			if err != nil {
+				return err
+			}
+			current = s.Version
+		}
+		if c.Version != current {
+			return &company.VersionConflictError{Ticker: c.Ticker, Version: c.Version, CurrentVersion: current}
+		}
+		return b.Put([]byte(c.Ticker), data)
+	})
+	var conflict *company.VersionConflictError
+	if errors.As(err, &conflict) {
+		return conflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save company %s: %w", c.Ticker, err)
+	}
+	c.Version = saved.Version
+	return nil
+}
+
+// FindByTicker retrieves a company by its stock ticker.
+func (r *BoltCompanyRepository) FindByTicker(ticker string) (*company.Company, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+
+	var c *company.Company
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(companiesBucket).Get([]byte(ticker))
+		if data == nil {
+			return ErrCompanyNotFound
+		}
+		var err error
+		c, err = decodeCompany(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SearchByScoreRange retrieves companies whose current value score falls within the given
+// range, bounds included, ordered by ticker.
+func (r *BoltCompanyRepository) SearchByScoreRange(minScore, maxScore float64) ([]*company.Company, error) {
+	if minScore > maxScore {
+		return nil, errors.New("minScore cannot be greater than maxScore")
+	}
+	return r.scan(func(c *company.Company) bool {
+		return c.CurrentScore >= minScore && c.CurrentScore <= maxScore
+	})
+}
+
+// FindAll retrieves all companies, ordered by ticker.
+func (r *BoltCompanyRepository) FindAll() ([]*company.Company, error) {
+	return r.scan(func(*company.Company) bool { return true })
+}
+
+// Delete removes a company by its ticker.
+func (r *BoltCompanyRepository) Delete(ticker string) error {
+	if ticker == "" {
+		return errors.New("ticker cannot be empty")
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(companiesBucket)
+		if b.Get([]byte(ticker)) == nil {
+			return fmt.Errorf("company with ticker '%s' not found for deletion: %w", ticker, ErrCompanyNotFound)
+		}
+		if err := b.Delete([]byte(ticker)); err != nil {
+			return fmt.Errorf("failed to delete company %s: %w", ticker, err)
+		}
+		return nil
+	})
+}
+
+// scan returns the companies accepted by match, in key order and so by ticker.
+func (r *BoltCompanyRepository) scan(match func(*company.Company) bool) ([]*company.Company, error) {
+	results := []*company.Company{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(companiesBucket).ForEach(func(_, data []byte) error {
+			c, err := decodeCompany(data)
+			if err != nil {
+				return err
+			}
+			if match(c) {
+				results = append(results, c)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// decodeCompany rebuilds a company from its stored document.
+func decodeCompany(data []byte) (*company.Company, error) {
+	var c company.Company
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid stored company: %w", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,70 @@
+// Package bolt implements the repositories on an embedded bbolt database: a single file that
+// needs no server, for deployments too small to run PostgreSQL. Aggregates are stored as JSON
+// documents in one bucket per aggregate, keyed by their identifier. Portfolio event streams and
+// their snapshots, for event-sourced persistence, are nested buckets keyed by version, and the
+// audit log a bucket keyed by sequence. End-of-day valuations and risk metrics are nested
+// buckets keyed by day.
+package bolt
+
+import (
+	"fmt"
+	"time"
+
+	bbolt "go.etcd.io/bbolt"
+)
+
+// Bucket names.
+var (
+	companiesBucket          = []byte("companies")
+	portfoliosBucket         = []byte("portfolios")
+	portfolioEventsBucket    = []byte("portfolio_events")
+	portfolioSnapshotsBucket = []byte("portfolio_snapshots")
+	auditLogBucket           = []byte("audit_log")
+	valuationSnapshotsBucket = []byte("valuation_snapshots")
+	riskMetricsBucket        = []byte("risk_metrics")
+)
+
+// Open opens the database file at path, creating it and its buckets when missing. Only one
+// process can hold the file open; Open gives up after a second if another one does.
+func Open(path string) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{companiesBucket, portfoliosBucket, portfolioEventsBucket, portfolioSnapshotsBucket, auditLogBucket, valuationSnapshotsBucket, riskMetricsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// database is what the repositories need of the database or of the transaction of a unit of
+// work. bbolt does not nest transactions: within a unit, a repository's transactions are the
+// unit's, and what a failed one wrote before failing is undone only if the unit fails too.
+type database interface {
+	View(fn func(*bbolt.Tx) error) error
+	Update(fn func(*bbolt.Tx) error) error
+}
+
+// txDatabase runs the transactions of the repositories of a unit of work in the unit's.
+type txDatabase struct {
+	tx *bbolt.Tx
+}
+
+// View runs fn in the unit's transaction.
+func (d txDatabase) View(fn func(*bbolt.Tx) error) error {
+	return fn(d.tx)
+}
+
+// Update runs fn in the unit's transaction.
+func (d txDatabase) Update(fn func(*bbolt.Tx) error) error {
+	return fn(d.tx)
+}
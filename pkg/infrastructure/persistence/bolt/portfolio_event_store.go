@@ -0,0 +1,189 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// BoltPortfolioEventStore is a bbolt implementation of the portfolio EventStore. Each stream,
+// and the snapshots of its portfolio, is a nested bucket keyed by the portfolio ID whose keys
+// are the big-endian versions, so that cursors walk it in order.
+type BoltPortfolioEventStore struct {
+	db database
+}
+
+// NewBoltPortfolioEventStore creates a new instance of BoltPortfolioEventStore on a database
+// opened by Open.
+func NewBoltPortfolioEventStore(db *bbolt.DB) *BoltPortfolioEventStore {
+	return &BoltPortfolioEventStore{db: db}
+}
+
+// Append adds an event to the end of its portfolio's stream, with the snapshot of the state it
+// produced if there is one, unless the stream has moved on.
+func (s *BoltPortfolioEventStore) Append(event portfolio.Event, snapshot *portfolio.Portfolio) error {
+	if event.PortfolioID == "" {
+		return errors.New("event portfolio ID cannot be empty")
+	}
+	if snapshot != nil && (snapshot.ID != event.PortfolioID || snapshot.Version != event.Version) {
+		return errors.New("snapshot must be of the portfolio and version of its event")
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	var state []byte
+	if snapshot != nil {
+		if state, err = json.Marshal(snapshot); err != nil {
+			return fmt.Errorf("failed to encode snapshot of portfolio %s: %w", event.PortfolioID, err)
+		}
+	}
+	err = s.db.Update(func(tx *bbolt.Tx) error {
+		stream, err := tx.Bucket(portfolioEventsBucket).CreateBucketIfNotExists([]byte(event.PortfolioID))
+		if err != nil {
+			return err
+		}
+		last := 0
+		if k, _ := stream.Cursor().Last(); k != nil {
+			last = decodeVersion(k)
+		}
+		if event.Version != last+1 {
+			return &portfolio.VersionConflictError{PortfolioID: event.PortfolioID, Version: event.Version - 1, CurrentVersion: last}
+		}
+		if err := stream.Put(encodeVersion(event.Version), data); err != nil {
+			return err
+		}
+		if snapshot == nil {
+			return nil
+		}
+		snapshots, err := tx.Bucket(portfolioSnapshotsBucket).CreateBucketIfNotExists([]byte(event.PortfolioID))
+		if err != nil {
+			return err
+		}
+		return snapshots.Put(encodeVersion(event.Version), state)
+	})
+	var conflict *portfolio.VersionConflictError
+	if errors.As(err, &conflict) {
+		return conflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	return nil
+}
+
+// Load returns the latest snapshot of a portfolio taken at or before at and the events after it.
+func (s *BoltPortfolioEventStore) Load(portfolioID string, at time.Time) (*portfolio.Portfolio, []portfolio.Event, error) {
+	var (
+		snapshot *portfolio.Portfolio
+		events   []portfolio.Event
+	)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		stream := tx.Bucket(portfolioEventsBucket).Bucket([]byte(portfolioID))
+		if stream == nil {
+			return nil
+		}
+		var err error
+		if events, err = decodeEvents(stream, 1, at); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		// The latest snapshot within the events loaded replaces those up to it.
+		snapshots := tx.Bucket(portfolioSnapshotsBucket).Bucket([]byte(portfolioID))
+		if snapshots == nil {
+			return nil
+		}
+		c := snapshots.Cursor()
+		k, v := c.Seek(encodeVersion(events[len(events)-1].Version + 1))
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		if k == nil {
+			return nil
+		}
+		if snapshot, err = decodePortfolio(v); err != nil {
+			return err
+		}
+		events = events[decodeVersion(k):]
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load portfolio %s: %w", portfolioID, err)
+	}
+	if events == nil {
+		events = []portfolio.Event{}
+	}
+	return snapshot, events, nil
+}
+
+// Events returns the whole stream of a portfolio, oldest first.
+func (s *BoltPortfolioEventStore) Events(portfolioID string) ([]portfolio.Event, error) {
+	events := []portfolio.Event{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		stream := tx.Bucket(portfolioEventsBucket).Bucket([]byte(portfolioID))
+		if stream == nil {
+			return nil
+		}
+		var err error
+		events, err = decodeEvents(stream, 1, time.Time{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events of portfolio %s: %w", portfolioID, err)
+	}
+	return events, nil
+}
+
+// StreamIDs returns the IDs of the portfolios that have a stream, in key order.
+func (s *BoltPortfolioEventStore) StreamIDs() ([]string, error) {
+	ids := []string{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(portfolioEventsBucket).ForEachBucket(func(k []byte) error {
+			ids = append(ids, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event streams: %w", err)
+	}
+	return ids, nil
+}
+
+// decodeEvents decodes the events of a stream from version from on, up to the first one
+// recorded after at; a zero at decodes them all.
+func decodeEvents(stream *bbolt.Bucket, from int, at time.Time) ([]portfolio.Event, error) {
+	events := []portfolio.Event{}
+	c := stream.Cursor()
+	for k, v := c.Seek(encodeVersion(from)); k != nil; k, v = c.Next() {
+		var e portfolio.Event
+		if err := json.Unmarshal(v, &e); err != nil {
+			return nil, fmt.Errorf("invalid stored event: %w", err)
+		}
+		if !at.IsZero() && e.RecordedAt.After(at) {
+			break
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// encodeVersion returns the key of a version in a stream or snapshot bucket.
+func encodeVersion(version int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(version))
+}
+
+// decodeVersion returns the version a stream or snapshot bucket key stands for.
+func decodeVersion(key []byte) int {
+	return int(binary.BigEndian.Uint64(key))
+}
@@ -0,0 +1,183 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// ErrPortfolioNotFound is returned when a portfolio is not found.
+var ErrPortfolioNotFound = portfolio.ErrPortfolioNotFound
+
+// BoltPortfolioRepository is a bbolt implementation of the PortfolioRepository interface.
+type BoltPortfolioRepository struct {
+	db database
+}
+
+// NewBoltPortfolioRepository creates a new instance of BoltPortfolioRepository on a database
+// opened by Open. SearchBySector reads the sectors of holdings from the companies stored in
+// the same database.
+func NewBoltPortfolioRepository(db *bbolt.DB) *BoltPortfolioRepository {
+	return &BoltPortfolioRepository{db: db}
+}
+
+// Save creates or updates a portfolio, unless it is stale.
+func (r *BoltPortfolioRepository) Save(p *portfolio.Portfolio) error {
+	if p == nil {
+		return errors.New("portfolio cannot be nil")
+	}
+	if p.ID == "" {
+		return errors.New("portfolio ID cannot be empty")
+	}
+
+	saved := p.Clone()
+	saved.Version++
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode portfolio %s: %w", p.ID, err)
+	}
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(portfoliosBucket)
+		current := 0
+		if stored := b.Get([]byte(p.ID)); stored != nil {
+			s, err := decodePortfolio(stored)
+			if err != nil {
+				return err
+			}
+			current = s.Version
+		}
+		if p.Version != current {
+			return &portfolio.VersionConflictError{PortfolioID: p.ID, Version: p.Version, CurrentVersion: current}
+		}
+		return b.Put([]byte(p.ID), data)
+	})
+	var conflict *portfolio.VersionConflictError
+	if errors.As(err, &conflict) {
+		return conflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+	}
+	p.Version = saved.Version
+	return nil
+}
+
+// FindByID retrieves a portfolio by its unique identifier.
+func (r *BoltPortfolioRepository) FindByID(id string) (*portfolio.Portfolio, error) {
+	if id == "" {
+		return nil, errors.New("portfolio ID cannot be empty")
+	}
+
+	var p *portfolio.Portfolio
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(portfoliosBucket).Get([]byte(id))
+		if data == nil {
+			return ErrPortfolioNotFound
+		}
+		var err error
+		p, err = decodePortfolio(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindAll retrieves all portfolios, ordered by ID.
+func (r *BoltPortfolioRepository) FindAll() ([]*portfolio.Portfolio, error) {
+	return r.scan(func(*bbolt.Tx, *portfolio.Portfolio) (bool, error) { return true, nil })
+}
+
+// SearchByRiskProfile retrieves portfolios matching a specific risk profile, ordered by ID.
+func (r *BoltPortfolioRepository) SearchByRiskProfile(riskProfile portfolio.RiskProfile) ([]*portfolio.Portfolio, error) {
+	return r.scan(func(_ *bbolt.Tx, p *portfolio.Portfolio) (bool, error) {
+		return p.RiskProfile == riskProfile, nil
+	})
+}
+
+// SearchBySector retrieves portfolios that hold positions in companies of the given sector,
+// ordered by ID. Holdings of companies that are not stored match no sector.
+func (r *BoltPortfolioRepository) SearchBySector(sector company.Sector) ([]*portfolio.Portfolio, error) {
+	return r.scan(func(tx *bbolt.Tx, p *portfolio.Portfolio) (bool, error) {
+		companies := tx.Bucket(companiesBucket)
+		for ticker := range p.Holdings {
+			data := companies.Get([]byte(ticker))
+			if data == nil {
+				continue
+			}
+			c, err := decodeCompany(data)
+			if err != nil {
+				return false, err
+			}
+			if c.Sector == sector {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// Delete removes a portfolio by its ID.
+func (r *BoltPortfolioRepository) Delete(id string) error {
+	if id == "" {
+		return errors.New("portfolio ID cannot be empty")
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(portfoliosBucket)
+		if b.Get([]byte(id)) == nil {
+			return fmt.Errorf("portfolio with ID '%s' not found for deletion: %w", id, ErrPortfolioNotFound)
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return fmt.Errorf("failed to delete portfolio %s: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// scan returns the portfolios accepted by match, in key order and so by ID. match runs in the
+// read transaction, so it sees the same state of the database as the scan.
+func (r *BoltPortfolioRepository) scan(match func(*bbolt.Tx, *portfolio.Portfolio) (bool, error)) ([]*portfolio.Portfolio, error) {
+	results := []*portfolio.Portfolio{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(portfoliosBucket).ForEach(func(_, data []byte) error {
+			p, err := decodePortfolio(data)
+			if err != nil {
+				return err
+			}
+			ok, err := match(tx, p)
+			if err != nil {
+				return err
+			}
+			if ok {
+				results = append(results, p)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// decodePortfolio rebuilds a portfolio from its stored document. Maps the aggregate expects to
+// be usable are initialized even when the document has none.
+func decodePortfolio(data []byte) (*portfolio.Portfolio, error) {
+	var p portfolio.Portfolio
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid stored portfolio: %w", err)
+	}
+	if p.Holdings == nil {
+		p.Holdings = make(map[string]portfolio.Position)
+	}
+	if p.ForeignCash == nil {
+		p.ForeignCash = make(map[string]portfolio.Money)
+	}
+	return &p, nil
+}
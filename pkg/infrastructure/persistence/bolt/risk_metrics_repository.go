@@ -0,0 +1,79 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// BoltRiskMetricsRepository is a bbolt implementation of the RiskMetricsRepository. The metrics
+// of each portfolio are a nested bucket keyed by the portfolio ID, in which the metrics of each
+// day are a JSON document keyed by the day (YYYY-MM-DD).
+type BoltRiskMetricsRepository struct {
+	db database
+}
+
+// NewBoltRiskMetricsRepository creates a new instance of BoltRiskMetricsRepository on a database
+// opened by Open.
+func NewBoltRiskMetricsRepository(db *bbolt.DB) *BoltRiskMetricsRepository {
+	return &BoltRiskMetricsRepository{db: db}
+}
+
+// Save stores the metrics for the day of their AsOf, replacing any stored for the same day.
+func (r *BoltRiskMetricsRepository) Save(metrics *portfolio.RiskMetrics) error {
+	if metrics == nil {
+		return errors.New("risk metrics cannot be nil")
+	}
+	if metrics.PortfolioID == "" {
+		return errors.New("risk metrics portfolio ID cannot be empty")
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode risk metrics of portfolio %s: %w", metrics.PortfolioID, err)
+	}
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		byDay, err := tx.Bucket(riskMetricsBucket).CreateBucketIfNotExists([]byte(metrics.PortfolioID))
+		if err != nil {
+			return err
+		}
+		return byDay.Put([]byte(metrics.AsOf.Format(time.DateOnly)), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save risk metrics of portfolio %s: %w", metrics.PortfolioID, err)
+	}
+	return nil
+}
+
+// FindByPortfolio retrieves a portfolio's metrics with AsOf in [from, to], oldest first.
+func (r *BoltRiskMetricsRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error) {
+	var results []*portfolio.RiskMetrics
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		byDay := tx.Bucket(riskMetricsBucket).Bucket([]byte(portfolioID))
+		if byDay == nil {
+			return nil
+		}
+		return byDay.ForEach(func(_, data []byte) error {
+			var m portfolio.RiskMetrics
+			if err := json.Unmarshal(data, &m); err != nil {
+				return fmt.Errorf("invalid stored risk metrics: %w", err)
+			}
+			if (!from.IsZero() && m.AsOf.Before(from)) || (!to.IsZero() && m.AsOf.After(to)) {
+				return nil
+			}
+			results = append(results, &m)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read risk metrics of portfolio %s: %w", portfolioID, err)
+	}
+	// Days of metrics measured in different time zones need not sort as their AsOf do.
+	sort.Slice(results, func(i, j int) bool { return results[i].AsOf.Before(results[j].AsOf) })
+	return results, nil
+}
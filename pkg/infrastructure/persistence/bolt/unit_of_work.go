@@ -0,0 +1,67 @@
+package bolt
+
+import (
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/eventsourced"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// BoltUnitOfWork is a bbolt implementation of the application's UnitOfWork. A unit is one
+// read-write transaction, in which every company and portfolio write is made, and every append
+// to the audit log. Writers wait for each other, so units run one at a time. Recommendations
+// are not stored in the database, so the unit holds the ones it saves back and writes them to
+// their repository only once the transaction has committed.
+type BoltUnitOfWork struct {
+	db               *bbolt.DB
+	recommendations  recommendation.RecommendationRepository
+	eventSourced     bool
+	snapshotInterval int
+}
+
+// UnitOfWorkOption configures optional behaviour of a BoltUnitOfWork.
+type UnitOfWorkOption func(*BoltUnitOfWork)
+
+// WithEventSourcedPortfolios makes the unit keep portfolios as event streams, as an
+// EventSourcedPortfolioRepository over a BoltPortfolioEventStore does, taking a snapshot of a
+// portfolio every snapshotInterval events.
+func WithEventSourcedPortfolios(snapshotInterval int) UnitOfWorkOption {
+	return func(u *BoltUnitOfWork) {
+		u.eventSourced = true
+		u.snapshotInterval = snapshotInterval
+	}
+}
+
+// NewBoltUnitOfWork creates a new instance of BoltUnitOfWork on a database opened by Open.
+func NewBoltUnitOfWork(db *bbolt.DB, recommendations recommendation.RecommendationRepository, opts ...UnitOfWorkOption) *BoltUnitOfWork {
+	u := &BoltUnitOfWork{db: db, recommendations: recommendations}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// Do runs work in a transaction and commits it if work succeeds.
+func (u *BoltUnitOfWork) Do(work func(repos application.Repositories) error) error {
+	recommendations := memory.NewHeldRecommendationRepository(u.recommendations)
+	err := u.db.Update(func(tx *bbolt.Tx) error {
+		db := txDatabase{tx: tx}
+		companies := &BoltCompanyRepository{db: db}
+		repos := application.Repositories{
+			Companies:       companies,
+			Portfolios:      &BoltPortfolioRepository{db: db},
+			Recommendations: recommendations,
+			AuditLog:        &BoltAuditLog{db: db},
+		}
+		if u.eventSourced {
+			repos.Portfolios = eventsourced.NewEventSourcedPortfolioRepository(&BoltPortfolioEventStore{db: db}, companies, u.snapshotInterval)
+		}
+		return work(repos)
+	})
+	if err != nil {
+		return err
+	}
+	recommendations.Apply()
+	return nil
+}
@@ -0,0 +1,79 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	bbolt "go.etcd.io/bbolt"
+)
+
+// BoltValuationSnapshotRepository is a bbolt implementation of the ValuationSnapshotRepository.
+// The snapshots of each portfolio are a nested bucket keyed by the portfolio ID, in which each
+// snapshot is a JSON document keyed by its day (YYYY-MM-DD).
+type BoltValuationSnapshotRepository struct {
+	db database
+}
+
+// NewBoltValuationSnapshotRepository creates a new instance of BoltValuationSnapshotRepository on
+// a database opened by Open.
+func NewBoltValuationSnapshotRepository(db *bbolt.DB) *BoltValuationSnapshotRepository {
+	return &BoltValuationSnapshotRepository{db: db}
+}
+
+// Save stores the snapshot for the day of its AsOf, replacing any stored for the same day.
+func (r *BoltValuationSnapshotRepository) Save(snapshot *portfolio.Valuation) error {
+	if snapshot == nil {
+		return errors.New("valuation snapshot cannot be nil")
+	}
+	if snapshot.PortfolioID == "" {
+		return errors.New("valuation snapshot portfolio ID cannot be empty")
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode valuation snapshot of portfolio %s: %w", snapshot.PortfolioID, err)
+	}
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		byDay, err := tx.Bucket(valuationSnapshotsBucket).CreateBucketIfNotExists([]byte(snapshot.PortfolioID))
+		if err != nil {
+			return err
+		}
+		return byDay.Put([]byte(snapshot.AsOf.Format(time.DateOnly)), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save valuation snapshot of portfolio %s: %w", snapshot.PortfolioID, err)
+	}
+	return nil
+}
+
+// FindByPortfolio retrieves a portfolio's snapshots with AsOf in [from, to], oldest first.
+func (r *BoltValuationSnapshotRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error) {
+	var results []*portfolio.Valuation
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		byDay := tx.Bucket(valuationSnapshotsBucket).Bucket([]byte(portfolioID))
+		if byDay == nil {
+			return nil
+		}
+		return byDay.ForEach(func(_, data []byte) error {
+			var v portfolio.Valuation
+			if err := json.Unmarshal(data, &v); err != nil {
+				return fmt.Errorf("invalid stored valuation snapshot: %w", err)
+			}
+			if (!from.IsZero() && v.AsOf.Before(from)) || (!to.IsZero() && v.AsOf.After(to)) {
+				return nil
+			}
+			results = append(results, &v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read valuation snapshots of portfolio %s: %w", portfolioID, err)
+	}
+	// Days of snapshots taken in different time zones need not sort as their AsOf do.
+	sort.Slice(results, func(i, j int) bool { return results[i].AsOf.Before(results[j].AsOf) })
+	return results, nil
+}
@@ -0,0 +1,244 @@
+// Package eventsourced keeps portfolios as the streams of the changes made to them rather than
+// as their latest state. Every save appends an event to the portfolio's stream in an event
+// store, which any storage backend can provide, and a portfolio is rebuilt by replaying its
+// stream from the latest snapshot. Nothing is ever overwritten: the stream is an audit trail of
+// the portfolio, which can be rebuilt as of any past time.
+package eventsourced
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// DefaultSnapshotInterval is the number of events between two snapshots of a portfolio.
+const DefaultSnapshotInterval = 50
+
+// ErrPortfolioNotFound is returned when a portfolio is not found.
+var ErrPortfolioNotFound = portfolio.ErrPortfolioNotFound
+
+// EventSourcedPortfolioRepository is an implementation of the PortfolioRepository that stores
+// portfolios as event streams. It also implements PortfolioHistory.
+type EventSourcedPortfolioRepository struct {
+	store            portfolio.EventStore
+	companyRepo      company.CompanyRepository // For sector lookups
+	snapshotInterval int
+	now              func() time.Time
+}
+
+// NewEventSourcedPortfolioRepository creates a new instance of EventSourcedPortfolioRepository
+// that keeps its streams in store and takes a snapshot of a portfolio every snapshotInterval
+// events; a non-positive interval means DefaultSnapshotInterval. It requires a
+// CompanyRepository to look up company sectors for SearchBySector.
+func NewEventSourcedPortfolioRepository(store portfolio.EventStore, companyRepo company.CompanyRepository, snapshotInterval int) *EventSourcedPortfolioRepository {
+	if snapshotInterval <= 0 {
+		snapshotInterval = DefaultSnapshotInterval
+	}
+	return &EventSourcedPortfolioRepository{
+		store:            store,
+		companyRepo:      companyRepo,
+		snapshotInterval: snapshotInterval,
+		now:              func() time.Time { return time.Now().UTC().Truncate(time.Microsecond) }, // The precision every store keeps
+	}
+}
+
+// Save appends the changes made to a portfolio since it was loaded to its stream, unless it is
+// stale. A deleted portfolio created again continues its old stream, so its versions carry on
+// from the deletion.
+func (r *EventSourcedPortfolioRepository) Save(p *portfolio.Portfolio) error {
+	if p == nil {
+		return errors.New("portfolio cannot be nil")
+	}
+	if p.ID == "" {
+		return errors.New("portfolio ID cannot be empty")
+	}
+
+	current, streamVersion, err := r.load(p.ID, time.Time{})
+	if err != nil {
+		return err
+	}
+	currentVersion := 0
+	if current != nil {
+		currentVersion = current.Version
+	}
+	if p.Version != currentVersion {
+		return &portfolio.VersionConflictError{PortfolioID: p.ID, Version: p.Version, CurrentVersion: currentVersion}
+	}
+
+	event, err := portfolio.NewEvent(current, p, streamVersion+1, r.now())
+	if err != nil {
+		return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+	}
+	var snapshot *portfolio.Portfolio
+	if event.Version%r.snapshotInterval == 0 {
+		snapshot = p.Clone()
+		snapshot.Version = event.Version
+	}
+	if err := r.store.Append(event, snapshot); err != nil {
+		if errors.Is(err, portfolio.ErrVersionConflict) {
+			return r.conflict(p)
+		}
+		return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+	}
+	p.Version = event.Version
+	return nil
+}
+
+// FindByID rebuilds a portfolio from its stream.
+func (r *EventSourcedPortfolioRepository) FindByID(id string) (*portfolio.Portfolio, error) {
+	return r.FindByIDAsOf(id, time.Time{})
+}
+
+// FindByIDAsOf rebuilds a portfolio from the events of its stream recorded up to the given
+// time. A zero time rebuilds it as it is now.
+func (r *EventSourcedPortfolioRepository) FindByIDAsOf(id string, at time.Time) (*portfolio.Portfolio, error) {
+	if id == "" {
+		return nil, errors.New("portfolio ID cannot be empty")
+	}
+
+	p, _, err := r.load(id, at)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPortfolioNotFound
+	}
+	return p, nil
+}
+
+// Events returns the stream of a portfolio, oldest first, its deletion included.
+func (r *EventSourcedPortfolioRepository) Events(id string) ([]portfolio.Event, error) {
+	if id == "" {
+		return nil, errors.New("portfolio ID cannot be empty")
+	}
+
+	events, err := r.store.Events(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events of portfolio %s: %w", id, err)
+	}
+	if len(events) == 0 {
+		return nil, ErrPortfolioNotFound
+	}
+	return events, nil
+}
+
+// FindAll rebuilds every portfolio that is not deleted, ordered by ID.
+func (r *EventSourcedPortfolioRepository) FindAll() ([]*portfolio.Portfolio, error) {
+	return r.filter(func(*portfolio.Portfolio) (bool, error) { return true, nil })
+}
+
+// SearchByRiskProfile retrieves portfolios matching a specific risk profile, ordered by ID.
+func (r *EventSourcedPortfolioRepository) SearchByRiskProfile(riskProfile portfolio.RiskProfile) ([]*portfolio.Portfolio, error) {
+	return r.filter(func(p *portfolio.Portfolio) (bool, error) { return p.RiskProfile == riskProfile, nil })
+}
+
+// SearchBySector retrieves portfolios that hold positions in companies of the given sector,
+// ordered by ID. Holdings of companies that are not known are ignored.
+func (r *EventSourcedPortfolioRepository) SearchBySector(sector company.Sector) ([]*portfolio.Portfolio, error) {
+	if r.companyRepo == nil {
+		return nil, errors.New("company repository is not available for sector search")
+	}
+	return r.filter(func(p *portfolio.Portfolio) (bool, error) {
+		for ticker := range p.Holdings {
+			c, err := r.companyRepo.FindByTicker(ticker)
+			if errors.Is(err, company.ErrCompanyNotFound) {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("failed to look up the sector of %s: %w", ticker, err)
+			}
+			if c.Sector == sector {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// Delete records the deletion of a portfolio at the end of its stream, which is kept.
+func (r *EventSourcedPortfolioRepository) Delete(id string) error {
+	if id == "" {
+		return errors.New("portfolio ID cannot be empty")
+	}
+
+	current, streamVersion, err := r.load(id, time.Time{})
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("portfolio with ID '%s' not found for deletion: %w", id, ErrPortfolioNotFound)
+	}
+	if err := r.store.Append(portfolio.NewDeletedEvent(id, streamVersion+1, r.now()), nil); err != nil {
+		if errors.Is(err, portfolio.ErrVersionConflict) {
+			return r.conflict(current)
+		}
+		return fmt.Errorf("failed to delete portfolio %s: %w", id, err)
+	}
+	return nil
+}
+
+// load rebuilds a portfolio as of the given time, nil if it did not exist then, and returns the
+// version of the last event replayed.
+func (r *EventSourcedPortfolioRepository) load(id string, at time.Time) (*portfolio.Portfolio, int, error) {
+	snapshot, events, err := r.store.Load(id, at)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load portfolio %s: %w", id, err)
+	}
+	p, err := portfolio.Replay(snapshot, events)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to rebuild portfolio %s: %w", id, err)
+	}
+	version := 0
+	switch {
+	case len(events) > 0:
+		version = events[len(events)-1].Version
+	case snapshot != nil:
+		version = snapshot.Version
+	}
+	return p, version, nil
+}
+
+// conflict returns the error reporting that p is stale because its stream moved on while it
+// was being saved.
+func (r *EventSourcedPortfolioRepository) conflict(p *portfolio.Portfolio) error {
+	current, _, err := r.load(p.ID, time.Time{})
+	if err != nil {
+		return err
+	}
+	currentVersion := 0
+	if current != nil {
+		currentVersion = current.Version
+	}
+	return &portfolio.VersionConflictError{PortfolioID: p.ID, Version: p.Version, CurrentVersion: currentVersion}
+}
+
+// filter rebuilds the portfolios that are not deleted and returns those matching keep, ordered
+// by ID.
+func (r *EventSourcedPortfolioRepository) filter(keep func(*portfolio.Portfolio) (bool, error)) ([]*portfolio.Portfolio, error) {
+	ids, err := r.store.StreamIDs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portfolios: %w", err)
+	}
+
+	results := []*portfolio.Portfolio{}
+	for _, id := range ids {
+		p, _, err := r.load(id, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			continue
+		}
+		ok, err := keep(p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			results = append(results, p)
+		}
+	}
+	return results, nil
+}
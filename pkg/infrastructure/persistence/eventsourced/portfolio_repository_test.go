@@ -0,0 +1,140 @@
+package eventsourced
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/repotest"
+)
+
+func usd(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+
+// newTestRepository returns a repository over an empty in-memory store whose clock starts on
+// 3 June 2024 and moves one hour forward every time it is read.
+func newTestRepository(snapshotInterval int) (*EventSourcedPortfolioRepository, *memory.InMemoryPortfolioEventStore) {
+	store := memory.NewInMemoryPortfolioEventStore()
+	repo := NewEventSourcedPortfolioRepository(store, memory.NewInMemoryCompanyRepository(), snapshotInterval)
+	clock := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time {
+		clock = clock.Add(time.Hour)
+		return clock
+	}
+	return repo, store
+}
+
+func mustSave(t *testing.T, repo *EventSourcedPortfolioRepository, p *portfolio.Portfolio) {
+	t.Helper()
+	if err := repo.Save(p); err != nil {
+		t.Fatalf("Save(%s) error = %v", p.ID, err)
+	}
+}
+
+func TestEventSourcedPortfolioRepository_Contract(t *testing.T) {
+	for _, interval := range []int{1, 2, DefaultSnapshotInterval} {
+		t.Run(fmt.Sprintf("SnapshotEvery%d", interval), func(t *testing.T) {
+			repotest.TestPortfolioRepository(t, func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository) {
+				companies := memory.NewInMemoryCompanyRepository()
+				return companies, NewEventSourcedPortfolioRepository(memory.NewInMemoryPortfolioEventStore(), companies, interval)
+			})
+		})
+	}
+}
+
+func TestEventSourcedPortfolioRepository_Snapshots(t *testing.T) {
+	repo, store := newTestRepository(3)
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, usd(100000))
+	mustSave(t, repo, p)
+	for i := 0; i < 6; i++ {
+		if err := p.Deposit(usd(1000), time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), "Savings"); err != nil {
+			t.Fatalf("Deposit() error = %v", err)
+		}
+		mustSave(t, repo, p)
+	}
+
+	snapshot, events, err := store.Load("p1", time.Time{})
+	if err != nil || snapshot == nil || snapshot.Version != 6 || len(events) != 1 || events[0].Version != 7 {
+		t.Fatalf("Load() = %v, %d events, %v, want the snapshot at version 6 and event 7", snapshot, len(events), err)
+	}
+	found, err := repo.FindByID("p1")
+	if err != nil || found.Version != 7 || found.CashBalance != usd(106000) || len(found.Ledger) != 7 {
+		t.Errorf("FindByID() = %+v, %v, want p1 at version 7 with 1060.00", found, err)
+	}
+}
+
+func TestEventSourcedPortfolioRepository_History(t *testing.T) {
+	repo, _ := newTestRepository(2)
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, usd(100000))
+	mustSave(t, repo, p) // Recorded at 10:00
+	pos, _ := portfolio.NewPosition("AAPL", 10, usd(1000))
+	if err := p.AddPosition(*pos, usd(10000)); err != nil {
+		t.Fatalf("AddPosition() error = %v", err)
+	}
+	mustSave(t, repo, p) // 11:00
+	if err := p.RemovePosition("AAPL", 4, usd(5000)); err != nil {
+		t.Fatalf("RemovePosition() error = %v", err)
+	}
+	mustSave(t, repo, p)                      // 12:00
+	if err := repo.Delete("p1"); err != nil { // 13:00
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	at := func(hour int) time.Time { return time.Date(2024, 6, 3, hour, 30, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		at         time.Time
+		wantShares int // -1 when the portfolio did not exist
+		wantCash   int64
+	}{
+		{"BeforeCreation", at(9), -1, 0},
+		{"Created", at(10), 0, 100000},
+		{"Bought", at(11), 10, 90000},
+		{"PartlySold", at(12), 6, 95000},
+		{"Deleted", at(13), -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repo.FindByIDAsOf("p1", tt.at)
+			if tt.wantShares < 0 {
+				if found != nil || !errors.Is(err, ErrPortfolioNotFound) {
+					t.Errorf("FindByIDAsOf() = %+v, %v, want ErrPortfolioNotFound", found, err)
+				}
+				return
+			}
+			if err != nil || found.Holdings["AAPL"].Shares != tt.wantShares || found.CashBalance != usd(tt.wantCash) {
+				t.Errorf("FindByIDAsOf() = %+v, %v, want %d AAPL and %d cash", found, err, tt.wantShares, tt.wantCash)
+			}
+		})
+	}
+
+	events, err := repo.Events("p1")
+	want := []portfolio.EventType{portfolio.PortfolioCreated, portfolio.PositionOpened, portfolio.PositionAdjusted, portfolio.PortfolioDeleted}
+	if err != nil || len(events) != len(want) {
+		t.Fatalf("Events() = %d events, %v, want %d", len(events), err, len(want))
+	}
+	for i, e := range events {
+		if e.Type != want[i] || !e.RecordedAt.Equal(time.Date(2024, 6, 3, 10+i, 0, 0, 0, time.UTC)) {
+			t.Errorf("event %d = %v recorded at %v, want %v at %d:00", i, e.Type, e.RecordedAt, want[i], 10+i)
+		}
+	}
+	if _, err := repo.Events("p2"); !errors.Is(err, ErrPortfolioNotFound) {
+		t.Errorf("Events(missing) error = %v, want ErrPortfolioNotFound", err)
+	}
+
+	// Created again, the portfolio continues its stream.
+	again, _ := portfolio.NewPortfolio("p1", portfolio.Aggressive, usd(500))
+	mustSave(t, repo, again)
+	if again.Version != 5 {
+		t.Errorf("Version of the portfolio created again = %d, want 5", again.Version)
+	}
+	if found, err := repo.FindByID("p1"); err != nil || found.RiskProfile != portfolio.Aggressive || len(found.Holdings) != 0 || len(found.Ledger) != 1 {
+		t.Errorf("FindByID() = %+v, %v, want the new portfolio", found, err)
+	}
+	if found, err := repo.FindByIDAsOf("p1", at(11)); err != nil || found.Holdings["AAPL"].Shares != 10 {
+		t.Errorf("FindByIDAsOf(before the deletion) = %+v, %v, want the old portfolio", found, err)
+	}
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+// InMemoryAuditLog is an in-memory implementation of the audit Log. It keeps the entries in
+// their JSON form, as a database would, so that nothing handed out can change them.
+type InMemoryAuditLog struct {
+	mu      sync.RWMutex
+	entries [][]byte // In log order
+	last    string   // Hash of the last entry
+}
+
+// NewInMemoryAuditLog creates a new instance of InMemoryAuditLog.
+func NewInMemoryAuditLog() *InMemoryAuditLog {
+	return &InMemoryAuditLog{}
+}
+
+// Append seals an entry after the last one of the log and stores it.
+func (l *InMemoryAuditLog) Append(e *audit.Entry) error {
+	if e == nil {
+		return errors.New("audit entry cannot be nil")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := e.Seal(len(l.entries)+1, l.last); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry %d: %w", e.Sequence, err)
+	}
+	l.entries = append(l.entries, data)
+	l.last = e.Hash
+	return nil
+}
+
+// Find retrieves the entries the filter selects, in log order.
+func (l *InMemoryAuditLog) Find(filter audit.Filter) ([]*audit.Entry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	found := []*audit.Entry{}
+	for _, data := range l.entries {
+		e, err := audit.DecodeEntry(data)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Matches(e) {
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
@@ -3,16 +3,18 @@ package memory
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/jizumer/expedition-value/pkg/domain/company"
 )
 
 // ErrCompanyNotFound is returned when a company is not found in the repository.
-var ErrCompanyNotFound = errors.New("company not found")
+var ErrCompanyNotFound = company.ErrCompanyNotFound
 
 // InMemoryCompanyRepository is an in-memory implementation of the CompanyRepository interface.
-// It uses a map to store companies and a RWMutex for concurrent access.
+// It uses a map to store companies and a RWMutex for concurrent access. Like a database, it
+// keeps copies of the companies saved and hands out copies of them.
 type InMemoryCompanyRepository struct {
 	mu        sync.RWMutex
 	companies map[string]*company.Company // Keyed by Ticker
@@ -25,7 +27,7 @@ func NewInMemoryCompanyRepository() *InMemoryCompanyRepository {
 	}
 }
 
-// Save creates or updates a company in the in-memory store.
+// Save creates or updates a company in the in-memory store, unless it is stale.
 func (r *InMemoryCompanyRepository) Save(c *company.Company) error {
 	if c == nil {
 		return errors.New("company cannot be nil")
@@ -37,7 +39,15 @@ func (r *InMemoryCompanyRepository) Save(c *company.Company) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.companies[c.Ticker] = c
+	current := 0
+	if stored, exists := r.companies[c.Ticker]; exists {
+		current = stored.Version
+	}
+	if c.Version != current {
+		return &company.VersionConflictError{Ticker: c.Ticker, Version: c.Version, CurrentVersion: current}
+	}
+	c.Version++
+	r.companies[c.Ticker] = c.Clone()
 	return nil
 }
 
@@ -54,10 +64,11 @@ func (r *InMemoryCompanyRepository) FindByTicker(ticker string) (*company.Compan
 	if !exists {
 		return nil, ErrCompanyNotFound
 	}
-	return company, nil
+	return company.Clone(), nil
 }
 
-// SearchByScoreRange retrieves companies whose current value score falls within the given range.
+// SearchByScoreRange retrieves companies whose current value score falls within the given
+// range, bounds included, ordered by ticker.
 func (r *InMemoryCompanyRepository) SearchByScoreRange(minScore, maxScore float64) ([]*company.Company, error) {
 	if minScore > maxScore {
 		return nil, errors.New("minScore cannot be greater than maxScore")
@@ -66,12 +77,26 @@ func (r *InMemoryCompanyRepository) SearchByScoreRange(minScore, maxScore float6
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var results []*company.Company
+	results := []*company.Company{}
 	for _, c := range r.companies {
 		if c.CurrentScore >= minScore && c.CurrentScore <= maxScore {
-			results = append(results, c)
+			results = append(results, c.Clone())
 		}
 	}
+	sortByTicker(results)
+	return results, nil
+}
+
+// FindAll retrieves all companies in the repository, ordered by ticker.
+func (r *InMemoryCompanyRepository) FindAll() ([]*company.Company, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*company.Company, 0, len(r.companies))
+	for _, c := range r.companies {
+		results = append(results, c.Clone())
+	}
+	sortByTicker(results)
 	return results, nil
 }
 
@@ -97,3 +122,8 @@ func (r *InMemoryCompanyRepository) Delete(ticker string) error {
 	delete(r.companies, ticker)
 	return nil
 }
+
+// sortByTicker orders companies by ticker, the order of the database backends.
+func sortByTicker(companies []*company.Company) {
+	sort.Slice(companies, func(i, j int) bool { return companies[i].Ticker < companies[j].Ticker })
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+// HeldRecommendationRepository is the recommendation repository of a unit of work over a
+// storage that does not keep recommendations. It holds the recommendations saved in the unit
+// back, reading them in place of the stored ones, until Apply writes them to the underlying
+// repository once the unit's transaction has committed. It is not safe for concurrent use.
+type HeldRecommendationRepository struct {
+	repo recommendation.RecommendationRepository
+	held map[string]*recommendation.Recommendation // Keyed by Recommendation ID
+	ids  []string                                  // Saved IDs, in the order first saved
+}
+
+// NewHeldRecommendationRepository creates a new instance of HeldRecommendationRepository over repo.
+func NewHeldRecommendationRepository(repo recommendation.RecommendationRepository) *HeldRecommendationRepository {
+	return &HeldRecommendationRepository{repo: repo, held: make(map[string]*recommendation.Recommendation)}
+}
+
+// Save holds a copy of the recommendation back until the unit commits.
+func (h *HeldRecommendationRepository) Save(rec *recommendation.Recommendation) error {
+	if rec == nil {
+		return errors.New("recommendation cannot be nil")
+	}
+	if rec.ID == "" {
+		return errors.New("recommendation ID cannot be empty")
+	}
+	if _, ok := h.held[rec.ID]; !ok {
+		h.ids = append(h.ids, rec.ID)
+	}
+	h.held[rec.ID] = rec.Clone()
+	return nil
+}
+
+// FindByID returns the recommendation saved in the unit, or else the stored one.
+func (h *HeldRecommendationRepository) FindByID(id string) (*recommendation.Recommendation, error) {
+	if rec, ok := h.held[id]; ok {
+		return rec.Clone(), nil
+	}
+	return h.repo.FindByID(id)
+}
+
+// FindByPortfolio retrieves the recommendations made for a portfolio, oldest first, those
+// saved in the unit included.
+func (h *HeldRecommendationRepository) FindByPortfolio(portfolioID string) ([]*recommendation.Recommendation, error) {
+	stored, err := h.repo.FindByPortfolio(portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	return h.merge(stored, func(rec *recommendation.Recommendation) bool { return rec.PortfolioID == portfolioID }), nil
+}
+
+// FindByStatus retrieves the recommendations in the given status, oldest first, those saved in
+// the unit included.
+func (h *HeldRecommendationRepository) FindByStatus(status recommendation.Status) ([]*recommendation.Recommendation, error) {
+	stored, err := h.repo.FindByStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	return h.merge(stored, func(rec *recommendation.Recommendation) bool { return rec.Status == status }), nil
+}
+
+// merge replaces the stored recommendations saved in the unit by the held ones matching keep,
+// sorted by creation time.
+func (h *HeldRecommendationRepository) merge(stored []*recommendation.Recommendation, keep func(*recommendation.Recommendation) bool) []*recommendation.Recommendation {
+	results := make([]*recommendation.Recommendation, 0, len(stored)+len(h.held))
+	for _, rec := range stored {
+		if _, ok := h.held[rec.ID]; !ok {
+			results = append(results, rec)
+		}
+	}
+	for _, id := range h.ids {
+		if rec := h.held[id]; keep(rec) {
+			results = append(results, rec.Clone())
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results
+}
+
+// Apply saves the held recommendations to the underlying repository, in the order they were
+// first saved. It runs once the unit has committed, so it logs the recommendations it fails to
+// save rather than fail a unit whose changes are already stored.
+func (h *HeldRecommendationRepository) Apply() {
+	for _, id := range h.ids {
+		if err := h.repo.Save(h.held[id]); err != nil {
+			log.Printf("recommendation %s was changed in a committed unit of work but could not be saved: %v\n", id, err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package memory_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+	"github.com/jizumer/expedition-value/pkg/domain/watchlist"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/repotest"
+)
+
+func TestInMemoryCompanyRepository_Contract(t *testing.T) {
+	repotest.TestCompanyRepository(t, func(t *testing.T) company.CompanyRepository {
+		return memory.NewInMemoryCompanyRepository()
+	})
+}
+
+func TestInMemoryPortfolioRepository_Contract(t *testing.T) {
+	repotest.TestPortfolioRepository(t, func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository) {
+		companies := memory.NewInMemoryCompanyRepository()
+		return companies, memory.NewInMemoryPortfolioRepository(companies)
+	})
+}
+
+func TestInMemoryPortfolioEventStore_Contract(t *testing.T) {
+	repotest.TestPortfolioEventStore(t, func(t *testing.T) portfolio.EventStore {
+		return memory.NewInMemoryPortfolioEventStore()
+	})
+}
+
+// The isolation tests below change the aggregates they save and find while other goroutines
+// read and save the same ones. Run under the race detector (go test -race), they fail if the
+// repository shares any state with its callers.
+
+// concurrently runs work on the given number of goroutines and waits for them.
+func concurrently(workers int, work func(worker int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			work(worker)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestInMemoryCompanyRepository_Isolation(t *testing.T) {
+	repo := memory.NewInMemoryCompanyRepository()
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	aapl.CurrentScore = 70
+	if _, err := aapl.DeclareDividend(time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), 24, "USD"); err != nil {
+		t.Fatalf("DeclareDividend() error = %v", err)
+	}
+	if err := repo.Save(aapl); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	concurrently(8, func(worker int) {
+		for i := 0; i < 50; i++ {
+			// Readers change what they found without saving it.
+			if found, err := repo.FindByTicker("AAPL"); err == nil {
+				found.Dividends[0].AmountPerShare = int64(worker)
+				found.CurrentScore = 0
+			}
+			if all, err := repo.FindAll(); err == nil && len(all) == 1 {
+				all[0].Dividends = append(all[0].Dividends[:0], company.Dividend{})
+			}
+			// Writers keep changing what they saved.
+			if c, err := repo.FindByTicker("AAPL"); err == nil {
+				c.FinancialMetrics.PERatio = float64(10 + worker)
+				if repo.Save(c) == nil {
+					c.Dividends[0].AmountPerShare = -1
+				}
+			}
+			// The one saved aggregate is still read concurrently with the writers above.
+			_ = aapl.Dividends[0].AmountPerShare
+		}
+	})
+
+	found, err := repo.FindByTicker("AAPL")
+	if err != nil || found.CurrentScore != 70 || len(found.Dividends) != 1 || found.Dividends[0].AmountPerShare != 24 {
+		t.Errorf("FindByTicker() = %+v, %v, want only saved changes", found, err)
+	}
+}
+
+func TestInMemoryPortfolioRepository_Isolation(t *testing.T) {
+	companies := memory.NewInMemoryCompanyRepository()
+	repo := memory.NewInMemoryPortfolioRepository(companies)
+	aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{PERatio: 15}, company.Technology)
+	if err := companies.Save(aapl); err != nil {
+		t.Fatalf("Save(AAPL) error = %v", err)
+	}
+	usd := func(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+	p, _ := portfolio.NewPortfolio("p1", portfolio.Moderate, usd(1000000))
+	pos, _ := portfolio.NewPosition("AAPL", 10, usd(10000))
+	if err := p.AddPosition(*pos, usd(100000)); err != nil {
+		t.Fatalf("AddPosition() error = %v", err)
+	}
+	if err := repo.Save(p); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	saved := 0
+	var mu sync.Mutex
+	concurrently(8, func(worker int) {
+		for i := 0; i < 50; i++ {
+			// Readers change what they found without saving it.
+			if found, err := repo.FindByID("p1"); err == nil {
+				delete(found.Holdings, "AAPL")
+				found.ForeignCash["EUR"] = portfolio.Money{Amount: 1, Currency: "EUR"}
+				found.Ledger[0].Amount = usd(1)
+			}
+			if bySector, err := repo.SearchBySector(company.Technology); err == nil && len(bySector) == 1 {
+				bySector[0].Holdings["MSFT"] = portfolio.Position{CompanyTicker: "MSFT", Shares: 1}
+			}
+			// Writers change the holdings map through the aggregate, save, and keep changing it.
+			if w, err := repo.FindByID("p1"); err == nil {
+				if err := w.Deposit(usd(100), time.Now(), "worker"); err != nil {
+					t.Errorf("Deposit() error = %v", err)
+					return
+				}
+				if repo.Save(w) == nil {
+					mu.Lock()
+					saved++
+					mu.Unlock()
+					w.Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 99}
+				}
+			}
+			_ = p.Holdings["AAPL"]
+		}
+	})
+
+	found, err := repo.FindByID("p1")
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if len(found.Holdings) != 1 || found.Holdings["AAPL"].Shares != 10 || len(found.ForeignCash) != 0 ||
+		found.CashBalance != usd(900000+100*int64(saved)) || found.Version != saved+1 {
+		t.Errorf("FindByID() = %+v, want the %d saved deposits only", found, saved)
+	}
+}
+
+func TestInMemoryRecommendationRepository_Isolation(t *testing.T) {
+	repo := memory.NewInMemoryRecommendationRepository()
+	trades := []portfolio.TradeRecommendation{{Action: portfolio.Enter, Ticker: "AAPL", Quantity: 10}}
+	rec, _ := recommendation.NewRecommendation("r1", "p1", trades, time.Now(), time.Hour)
+	if err := repo.Save(rec); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	concurrently(8, func(worker int) {
+		for i := 0; i < 50; i++ {
+			if found, err := repo.FindByID("r1"); err == nil {
+				found.Trades[0].Quantity = worker
+			}
+			if byPortfolio, err := repo.FindByPortfolio("p1"); err == nil && len(byPortfolio) == 1 {
+				byPortfolio[0].Trades[0].Ticker = "MSFT"
+				if repo.Save(byPortfolio[0]) == nil {
+					byPortfolio[0].Trades[0].Ticker = "XOM"
+				}
+			}
+			_ = rec.Trades[0].Quantity
+		}
+	})
+
+	found, err := repo.FindByID("r1")
+	if err != nil || found.Trades[0].Ticker != "MSFT" || found.Trades[0].Quantity != 10 {
+		t.Errorf("FindByID() = %+v, %v, want only saved changes", found, err)
+	}
+}
+
+func TestInMemoryWatchlistRepository_Isolation(t *testing.T) {
+	repo := memory.NewInMemoryWatchlistRepository()
+	w, _ := watchlist.NewWatchlist("w1", "Value ideas", "alice", "", time.Now())
+	if err := w.Watch("AAPL", portfolio.Money{Amount: 10000, Currency: "USD"}, 0, "", time.Now()); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if err := repo.Save(w); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	concurrently(8, func(worker int) {
+		for i := 0; i < 50; i++ {
+			if found, err := repo.FindByID("w1"); err == nil {
+				delete(found.Items, "AAPL")
+			}
+			if byOwner, err := repo.FindByOwner("alice"); err == nil && len(byOwner) == 1 {
+				if err := byOwner[0].Watch("MSFT", portfolio.Money{}, 60, "", time.Now()); err != nil {
+					t.Errorf("Watch() error = %v", err)
+					return
+				}
+				if repo.Save(byOwner[0]) == nil {
+					byOwner[0].Items["XOM"] = watchlist.WatchItem{Ticker: "XOM"}
+				}
+			}
+			_ = w.Items["AAPL"]
+		}
+	})
+
+	found, err := repo.FindByID("w1")
+	if err != nil || len(found.Items) != 2 || found.Items["AAPL"].Ticker != "AAPL" || found.Items["MSFT"].Ticker != "MSFT" {
+		t.Errorf("FindByID() = %+v, %v, want AAPL and MSFT only", found, err)
+	}
+}
+
+func TestInMemoryUnitOfWork_Contract(t *testing.T) {
+	repotest.TestUnitOfWork(t, func(t *testing.T) (application.UnitOfWork, company.CompanyRepository, portfolio.PortfolioRepository) {
+		companies := memory.NewInMemoryCompanyRepository()
+		portfolios := memory.NewInMemoryPortfolioRepository(companies)
+		return memory.NewInMemoryUnitOfWork(companies, portfolios, memory.NewInMemoryRecommendationRepository()), companies, portfolios
+	})
+}
+
+func TestInMemoryUnitOfWork_Recommendations(t *testing.T) {
+	recommendations := memory.NewInMemoryRecommendationRepository()
+	companies := memory.NewInMemoryCompanyRepository()
+	uow := memory.NewInMemoryUnitOfWork(companies, memory.NewInMemoryPortfolioRepository(companies), recommendations)
+	trades := []portfolio.TradeRecommendation{{Action: portfolio.Enter, Ticker: "AAPL", Quantity: 10}}
+
+	errFailed := errors.New("failed")
+	err := uow.Do(func(repos application.Repositories) error {
+		rec, _ := recommendation.NewRecommendation("r1", "p1", trades, time.Now(), time.Hour)
+		if err := repos.Recommendations.Save(rec); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Do() error = %v, want the error of the work", err)
+	}
+	if _, err := recommendations.FindByID("r1"); !errors.Is(err, memory.ErrRecommendationNotFound) {
+		t.Errorf("FindByID(r1) after a failed unit error = %v, want ErrRecommendationNotFound", err)
+	}
+
+	err = uow.Do(func(repos application.Repositories) error {
+		rec, _ := recommendation.NewRecommendation("r1", "p1", trades, time.Now(), time.Hour)
+		return repos.Recommendations.Save(rec)
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if _, err := recommendations.FindByID("r1"); err != nil {
+		t.Errorf("FindByID(r1) after a committed unit error = %v", err)
+	}
+}
+
+func TestHeldRecommendationRepository(t *testing.T) {
+	recommendations := memory.NewInMemoryRecommendationRepository()
+	trades := []portfolio.TradeRecommendation{{Action: portfolio.Enter, Ticker: "AAPL", Quantity: 10}}
+	now := time.Now()
+	stored, _ := recommendation.NewRecommendation("r1", "p1", trades, now, time.Hour)
+	_ = recommendations.Save(stored)
+
+	held := memory.NewHeldRecommendationRepository(recommendations)
+	approved := stored.Clone()
+	_ = approved.Approve("alice", now)
+	proposed, _ := recommendation.NewRecommendation("r2", "p1", trades, now.Add(time.Second), time.Hour)
+	for _, rec := range []*recommendation.Recommendation{approved, proposed} {
+		if err := held.Save(rec); err != nil {
+			t.Fatalf("Save(%s) error = %v", rec.ID, err)
+		}
+	}
+
+	if found, err := held.FindByPortfolio("p1"); err != nil || len(found) != 2 || found[0].Status != recommendation.Approved || found[1].ID != "r2" {
+		t.Errorf("FindByPortfolio() = %+v, %v, want r1 as approved, then r2", found, err)
+	}
+	if found, err := held.FindByStatus(recommendation.Proposed); err != nil || len(found) != 1 || found[0].ID != "r2" {
+		t.Errorf("FindByStatus(Proposed) = %+v, %v, want r2 only", found, err)
+	}
+	if found, _ := recommendations.FindByID("r1"); found.Status != recommendation.Proposed {
+		t.Errorf("stored r1 status = %v before Apply, want Proposed", found.Status)
+	}
+
+	held.Apply()
+	if found, err := recommendations.FindByID("r1"); err != nil || found.Status != recommendation.Approved {
+		t.Errorf("FindByID(r1) after Apply = %+v, %v, want it approved", found, err)
+	}
+	if _, err := recommendations.FindByID("r2"); err != nil {
+		t.Errorf("FindByID(r2) after Apply error = %v", err)
+	}
+}
+
+func TestInMemoryAuditLog_Contract(t *testing.T) {
+	repotest.TestAuditLog(t, func(t *testing.T) audit.Log {
+		return memory.NewInMemoryAuditLog()
+	})
+}
+
+func TestInMemoryValuationSnapshotRepository_Contract(t *testing.T) {
+	repotest.TestValuationSnapshotRepository(t, func(t *testing.T) portfolio.ValuationSnapshotRepository {
+		return memory.NewInMemoryValuationSnapshotRepository()
+	})
+}
+
+func TestInMemoryRiskMetricsRepository_Contract(t *testing.T) {
+	repotest.TestRiskMetricsRepository(t, func(t *testing.T) portfolio.RiskMetricsRepository {
+		return memory.NewInMemoryRiskMetricsRepository()
+	})
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// InMemoryPortfolioEventStore is an in-memory implementation of the portfolio EventStore. Like a
+// database, it keeps copies of the events and snapshots appended and hands out copies of them.
+type InMemoryPortfolioEventStore struct {
+	mu        sync.RWMutex
+	streams   map[string][]portfolio.Event      // Keyed by portfolio ID, oldest first
+	snapshots map[string][]*portfolio.Portfolio // Keyed by portfolio ID, oldest first
+}
+
+// NewInMemoryPortfolioEventStore creates a new instance of InMemoryPortfolioEventStore.
+func NewInMemoryPortfolioEventStore() *InMemoryPortfolioEventStore {
+	return &InMemoryPortfolioEventStore{
+		streams:   make(map[string][]portfolio.Event),
+		snapshots: make(map[string][]*portfolio.Portfolio),
+	}
+}
+
+// Append adds an event to the end of its portfolio's stream, with the snapshot of the state it
+// produced if there is one, unless the stream has moved on.
+func (s *InMemoryPortfolioEventStore) Append(event portfolio.Event, snapshot *portfolio.Portfolio) error {
+	if event.PortfolioID == "" {
+		return errors.New("event portfolio ID cannot be empty")
+	}
+	if snapshot != nil && (snapshot.ID != event.PortfolioID || snapshot.Version != event.Version) {
+		return errors.New("snapshot must be of the portfolio and version of its event")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream := s.streams[event.PortfolioID]
+	if event.Version != len(stream)+1 {
+		return &portfolio.VersionConflictError{PortfolioID: event.PortfolioID, Version: event.Version - 1, CurrentVersion: len(stream)}
+	}
+	s.streams[event.PortfolioID] = append(stream, event.Clone())
+	if snapshot != nil {
+		s.snapshots[event.PortfolioID] = append(s.snapshots[event.PortfolioID], snapshot.Clone())
+	}
+	return nil
+}
+
+// Load returns the latest snapshot of a portfolio taken at or before at and the events after it.
+func (s *InMemoryPortfolioEventStore) Load(portfolioID string, at time.Time) (*portfolio.Portfolio, []portfolio.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stream := s.streams[portfolioID]
+	end := len(stream)
+	if !at.IsZero() {
+		end = sort.Search(len(stream), func(i int) bool { return stream[i].RecordedAt.After(at) })
+	}
+
+	var snapshot *portfolio.Portfolio
+	for _, taken := range s.snapshots[portfolioID] {
+		if taken.Version > end {
+			break
+		}
+		snapshot = taken
+	}
+	start := 0
+	if snapshot != nil {
+		start = snapshot.Version
+		snapshot = snapshot.Clone()
+	}
+
+	events := make([]portfolio.Event, 0, end-start)
+	for _, e := range stream[start:end] {
+		events = append(events, e.Clone())
+	}
+	return snapshot, events, nil
+}
+
+// Events returns the whole stream of a portfolio, oldest first.
+func (s *InMemoryPortfolioEventStore) Events(portfolioID string) ([]portfolio.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]portfolio.Event, 0, len(s.streams[portfolioID]))
+	for _, e := range s.streams[portfolioID] {
+		events = append(events, e.Clone())
+	}
+	return events, nil
+}
+
+// StreamIDs returns the IDs of the portfolios that have a stream, ordered.
+func (s *InMemoryPortfolioEventStore) StreamIDs() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.streams))
+	for id := range s.streams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
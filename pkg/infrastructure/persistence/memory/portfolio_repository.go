@@ -3,6 +3,7 @@ package memory
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/jizumer/expedition-value/pkg/domain/company"
@@ -10,9 +11,10 @@ import (
 )
 
 // ErrPortfolioNotFound is returned when a portfolio is not found.
-var ErrPortfolioNotFound = errors.New("portfolio not found")
+var ErrPortfolioNotFound = portfolio.ErrPortfolioNotFound
 
-// InMemoryPortfolioRepository is an in-memory implementation of the PortfolioRepository.
+// InMemoryPortfolioRepository is an in-memory implementation of the PortfolioRepository. Like a
+// database, it keeps copies of the portfolios saved and hands out copies of them.
 type InMemoryPortfolioRepository struct {
 	mu           sync.RWMutex
 	portfolios   map[string]*portfolio.Portfolio // Keyed by Portfolio ID
@@ -28,7 +30,7 @@ func NewInMemoryPortfolioRepository(compRepo company.CompanyRepository) *InMemor
 	}
 }
 
-// Save creates or updates a portfolio in the in-memory store.
+// Save creates or updates a portfolio in the in-memory store, unless it is stale.
 func (r *InMemoryPortfolioRepository) Save(p *portfolio.Portfolio) error {
 	if p == nil {
 		return errors.New("portfolio cannot be nil")
@@ -40,7 +42,15 @@ func (r *InMemoryPortfolioRepository) Save(p *portfolio.Portfolio) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.portfolios[p.ID] = p
+	current := 0
+	if stored, exists := r.portfolios[p.ID]; exists {
+		current = stored.Version
+	}
+	if p.Version != current {
+		return &portfolio.VersionConflictError{PortfolioID: p.ID, Version: p.Version, CurrentVersion: current}
+	}
+	p.Version++
+	r.portfolios[p.ID] = p.Clone()
 	return nil
 }
 
@@ -57,38 +67,41 @@ func (r *InMemoryPortfolioRepository) FindByID(id string) (*portfolio.Portfolio,
 	if !exists {
 		return nil, ErrPortfolioNotFound
 	}
-	return portfolio, nil
+	return portfolio.Clone(), nil
 }
 
-// FindAll retrieves all portfolios.
+// FindAll retrieves all portfolios, ordered by ID.
 func (r *InMemoryPortfolioRepository) FindAll() ([]*portfolio.Portfolio, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	results := make([]*portfolio.Portfolio, 0, len(r.portfolios))
 	for _, p := range r.portfolios {
-		results = append(results, p)
+		results = append(results, p.Clone())
 	}
+	sortByID(results)
 	return results, nil
 }
 
-// SearchByRiskProfile retrieves portfolios matching a specific risk profile.
+// SearchByRiskProfile retrieves portfolios matching a specific risk profile, ordered by ID.
 // (This was defined in the domain interface, adding implementation here)
 func (r *InMemoryPortfolioRepository) SearchByRiskProfile(riskProfile portfolio.RiskProfile) ([]*portfolio.Portfolio, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var results []*portfolio.Portfolio
+	results := []*portfolio.Portfolio{}
 	for _, p := range r.portfolios {
 		if p.RiskProfile == riskProfile {
-			results = append(results, p)
+			results = append(results, p.Clone())
 		}
 	}
+	sortByID(results)
 	return results, nil
 }
 
 
-// SearchBySector retrieves portfolios that hold positions in companies of the given sector.
+// SearchBySector retrieves portfolios that hold positions in companies of the given sector,
+// ordered by ID.
 // This implementation requires looking up company details using the CompanyRepository.
 func (r *InMemoryPortfolioRepository) SearchBySector(sector company.Sector) ([]*portfolio.Portfolio, error) {
 	if r.companyRepo == nil {
@@ -98,7 +111,7 @@ func (r *InMemoryPortfolioRepository) SearchBySector(sector company.Sector) ([]*
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var results []*portfolio.Portfolio
+	results := []*portfolio.Portfolio{}
 	seenPortfolios := make(map[string]bool) // To avoid adding the same portfolio multiple times
 
 	for _, p := range r.portfolios {
@@ -114,12 +127,13 @@ func (r *InMemoryPortfolioRepository) SearchBySector(sector company.Sector) ([]*
 				continue
 			}
 			if comp.Sector == sector {
-				results = append(results, p)
+				results = append(results, p.Clone())
 				seenPortfolios[p.ID] = true
 				break // Found a matching company in this portfolio, move to the next portfolio
 			}
 		}
 	}
+	sortByID(results)
 	return results, nil
 }
 
@@ -143,3 +157,8 @@ func (r *InMemoryPortfolioRepository) Delete(id string) error {
 	delete(r.portfolios, id)
 	return nil
 }
+
+// sortByID orders portfolios by ID, the order of the database backends.
+func sortByID(portfolios []*portfolio.Portfolio) {
+	sort.Slice(portfolios, func(i, j int) bool { return portfolios[i].ID < portfolios[j].ID })
+}
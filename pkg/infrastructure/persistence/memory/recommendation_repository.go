@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+)
+
+// ErrRecommendationNotFound is returned when a recommendation is not found.
+var ErrRecommendationNotFound = errors.New("recommendation not found")
+
+// InMemoryRecommendationRepository is an in-memory implementation of the RecommendationRepository.
+// Like a database, it keeps copies of the recommendations saved and hands out copies of them.
+type InMemoryRecommendationRepository struct {
+	mu              sync.RWMutex
+	recommendations map[string]*recommendation.Recommendation // Keyed by Recommendation ID
+}
+
+// NewInMemoryRecommendationRepository creates a new instance of InMemoryRecommendationRepository.
+func NewInMemoryRecommendationRepository() *InMemoryRecommendationRepository {
+	return &InMemoryRecommendationRepository{
+		recommendations: make(map[string]*recommendation.Recommendation),
+	}
+}
+
+// Save creates or updates a recommendation in the in-memory store.
+func (r *InMemoryRecommendationRepository) Save(rec *recommendation.Recommendation) error {
+	if rec == nil {
+		return errors.New("recommendation cannot be nil")
+	}
+	if rec.ID == "" {
+		return errors.New("recommendation ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recommendations[rec.ID] = rec.Clone()
+	return nil
+}
+
+// FindByID retrieves a recommendation by its unique identifier.
+func (r *InMemoryRecommendationRepository) FindByID(id string) (*recommendation.Recommendation, error) {
+	if id == "" {
+		return nil, errors.New("recommendation ID cannot be empty")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, exists := r.recommendations[id]
+	if !exists {
+		return nil, ErrRecommendationNotFound
+	}
+	return rec.Clone(), nil
+}
+
+// FindByPortfolio retrieves the recommendations made for a portfolio, oldest first.
+func (r *InMemoryRecommendationRepository) FindByPortfolio(portfolioID string) ([]*recommendation.Recommendation, error) {
+	return r.filter(func(rec *recommendation.Recommendation) bool { return rec.PortfolioID == portfolioID }), nil
+}
+
+// FindByStatus retrieves the recommendations in the given status, oldest first.
+func (r *InMemoryRecommendationRepository) FindByStatus(status recommendation.Status) ([]*recommendation.Recommendation, error) {
+	return r.filter(func(rec *recommendation.Recommendation) bool { return rec.Status == status }), nil
+}
+
+// filter returns the stored recommendations matching keep, sorted by creation time.
+func (r *InMemoryRecommendationRepository) filter(keep func(*recommendation.Recommendation) bool) []*recommendation.Recommendation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*recommendation.Recommendation
+	for _, rec := range r.recommendations {
+		if keep(rec) {
+			results = append(results, rec.Clone())
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// InMemoryRiskMetricsRepository is an in-memory implementation of the RiskMetricsRepository.
+type InMemoryRiskMetricsRepository struct {
+	mu      sync.RWMutex
+	metrics map[string]map[string]*portfolio.RiskMetrics // Keyed by portfolio ID, then by day (YYYY-MM-DD)
+}
+
+// NewInMemoryRiskMetricsRepository creates a new instance of InMemoryRiskMetricsRepository.
+func NewInMemoryRiskMetricsRepository() *InMemoryRiskMetricsRepository {
+	return &InMemoryRiskMetricsRepository{
+		metrics: make(map[string]map[string]*portfolio.RiskMetrics),
+	}
+}
+
+// Save stores the metrics for the day of their AsOf, replacing any stored for the same day.
+func (r *InMemoryRiskMetricsRepository) Save(metrics *portfolio.RiskMetrics) error {
+	if metrics == nil {
+		return errors.New("risk metrics cannot be nil")
+	}
+	if metrics.PortfolioID == "" {
+		return errors.New("risk metrics portfolio ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byDay, ok := r.metrics[metrics.PortfolioID]
+	if !ok {
+		byDay = make(map[string]*portfolio.RiskMetrics)
+		r.metrics[metrics.PortfolioID] = byDay
+	}
+	stored := *metrics
+	stored.VaR = append([]portfolio.ValueAtRisk(nil), metrics.VaR...)
+	byDay[metrics.AsOf.Format("2006-01-02")] = &stored
+	return nil
+}
+
+// FindByPortfolio retrieves a portfolio's metrics with AsOf in [from, to], oldest first.
+func (r *InMemoryRiskMetricsRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*portfolio.RiskMetrics
+	for _, m := range r.metrics[portfolioID] {
+		if (!from.IsZero() && m.AsOf.Before(from)) || (!to.IsZero() && m.AsOf.After(to)) {
+			continue
+		}
+		found := *m
+		found.VaR = append([]portfolio.ValueAtRisk(nil), m.VaR...)
+		results = append(results, &found)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].AsOf.Before(results[j].AsOf) })
+	return results, nil
+}
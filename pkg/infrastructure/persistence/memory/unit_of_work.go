@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"maps"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// InMemoryUnitOfWork is an in-memory implementation of the application's UnitOfWork over
+// company, portfolio and recommendation repositories. A unit works on its own copies of the
+// repositories' contents and commits by putting the aggregates it changed back under the
+// repositories' locks, unless another writer changed one of those since the unit copied them.
+// Recommendations carry no version, so the unit's writes to them always win.
+type InMemoryUnitOfWork struct {
+	companies       *InMemoryCompanyRepository
+	portfolios      *InMemoryPortfolioRepository
+	recommendations *InMemoryRecommendationRepository
+}
+
+// NewInMemoryUnitOfWork creates a new instance of InMemoryUnitOfWork over the given repositories.
+func NewInMemoryUnitOfWork(companies *InMemoryCompanyRepository, portfolios *InMemoryPortfolioRepository, recommendations *InMemoryRecommendationRepository) *InMemoryUnitOfWork {
+	return &InMemoryUnitOfWork{companies: companies, portfolios: portfolios, recommendations: recommendations}
+}
+
+// Do runs work against copies of the repositories and commits what it changed if it succeeds.
+func (u *InMemoryUnitOfWork) Do(work func(repos application.Repositories) error) error {
+	// The stored aggregates are never changed in place, only replaced, so the copies can share
+	// them and a changed aggregate is one whose pointer differs.
+	u.companies.mu.RLock()
+	companiesBase := maps.Clone(u.companies.companies)
+	u.companies.mu.RUnlock()
+	u.portfolios.mu.RLock()
+	portfoliosBase := maps.Clone(u.portfolios.portfolios)
+	u.portfolios.mu.RUnlock()
+	u.recommendations.mu.RLock()
+	recommendationsBase := maps.Clone(u.recommendations.recommendations)
+	u.recommendations.mu.RUnlock()
+
+	companies := &InMemoryCompanyRepository{companies: maps.Clone(companiesBase)}
+	portfolios := &InMemoryPortfolioRepository{portfolios: maps.Clone(portfoliosBase), companyRepo: companies}
+	recommendations := &InMemoryRecommendationRepository{recommendations: maps.Clone(recommendationsBase)}
+	if err := work(application.Repositories{Companies: companies, Portfolios: portfolios, Recommendations: recommendations}); err != nil {
+		return err
+	}
+
+	// Locked in the order SearchBySector takes them.
+	u.portfolios.mu.Lock()
+	defer u.portfolios.mu.Unlock()
+	u.companies.mu.Lock()
+	defer u.companies.mu.Unlock()
+	u.recommendations.mu.Lock()
+	defer u.recommendations.mu.Unlock()
+
+	changedPortfolios := changed(portfoliosBase, portfolios.portfolios)
+	for _, id := range changedPortfolios {
+		if current := u.portfolios.portfolios[id]; current != portfoliosBase[id] {
+			return &portfolio.VersionConflictError{PortfolioID: id, Version: portfolioVersion(portfoliosBase[id]), CurrentVersion: portfolioVersion(current)}
+		}
+	}
+	changedCompanies := changed(companiesBase, companies.companies)
+	for _, ticker := range changedCompanies {
+		if current := u.companies.companies[ticker]; current != companiesBase[ticker] {
+			return &company.VersionConflictError{Ticker: ticker, Version: companyVersion(companiesBase[ticker]), CurrentVersion: companyVersion(current)}
+		}
+	}
+
+	apply(u.portfolios.portfolios, portfolios.portfolios, changedPortfolios)
+	apply(u.companies.companies, companies.companies, changedCompanies)
+	apply(u.recommendations.recommendations, recommendations.recommendations, changed(recommendationsBase, recommendations.recommendations))
+	return nil
+}
+
+// changed returns the keys of the aggregates that were saved or deleted in a unit's copy of
+// a repository's contents since it was copied from base.
+func changed[T any](base, unit map[string]*T) []string {
+	var keys []string
+	for key, v := range unit {
+		if base[key] != v {
+			keys = append(keys, key)
+		}
+	}
+	for key := range base {
+		if _, kept := unit[key]; !kept {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// apply puts the aggregates of a unit's copy stored under keys into the repository's contents,
+// removing those the unit deleted.
+func apply[T any](contents, unit map[string]*T, keys []string) {
+	for _, key := range keys {
+		if v, kept := unit[key]; kept {
+			contents[key] = v
+		} else {
+			delete(contents, key)
+		}
+	}
+}
+
+// portfolioVersion returns the version of a stored portfolio, 0 when there is none.
+func portfolioVersion(p *portfolio.Portfolio) int {
+	if p == nil {
+		return 0
+	}
+	return p.Version
+}
+
+// companyVersion returns the version of a stored company, 0 when there is none.
+func companyVersion(c *company.Company) int {
+	if c == nil {
+		return 0
+	}
+	return c.Version
+}
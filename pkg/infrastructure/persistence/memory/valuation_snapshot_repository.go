@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// InMemoryValuationSnapshotRepository is an in-memory implementation of the ValuationSnapshotRepository.
+type InMemoryValuationSnapshotRepository struct {
+	mu        sync.RWMutex
+	snapshots map[string]map[string]*portfolio.Valuation // Keyed by portfolio ID, then by day (YYYY-MM-DD)
+}
+
+// NewInMemoryValuationSnapshotRepository creates a new instance of InMemoryValuationSnapshotRepository.
+func NewInMemoryValuationSnapshotRepository() *InMemoryValuationSnapshotRepository {
+	return &InMemoryValuationSnapshotRepository{
+		snapshots: make(map[string]map[string]*portfolio.Valuation),
+	}
+}
+
+// Save stores the snapshot for the day of its AsOf, replacing any stored for the same day.
+func (r *InMemoryValuationSnapshotRepository) Save(snapshot *portfolio.Valuation) error {
+	if snapshot == nil {
+		return errors.New("valuation snapshot cannot be nil")
+	}
+	if snapshot.PortfolioID == "" {
+		return errors.New("valuation snapshot portfolio ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byDay, ok := r.snapshots[snapshot.PortfolioID]
+	if !ok {
+		byDay = make(map[string]*portfolio.Valuation)
+		r.snapshots[snapshot.PortfolioID] = byDay
+	}
+	byDay[snapshot.AsOf.Format("2006-01-02")] = copyValuation(snapshot)
+	return nil
+}
+
+// FindByPortfolio retrieves a portfolio's snapshots with AsOf in [from, to], oldest first.
+func (r *InMemoryValuationSnapshotRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*portfolio.Valuation
+	for _, v := range r.snapshots[portfolioID] {
+		if (!from.IsZero() && v.AsOf.Before(from)) || (!to.IsZero() && v.AsOf.After(to)) {
+			continue
+		}
+		results = append(results, copyValuation(v))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].AsOf.Before(results[j].AsOf) })
+	return results, nil
+}
+
+// copyValuation returns a copy of v that shares no slices with it.
+func copyValuation(v *portfolio.Valuation) *portfolio.Valuation {
+	c := *v
+	c.Holdings = append([]portfolio.HoldingValuation(nil), v.Holdings...)
+	c.Cash = append([]portfolio.Money(nil), v.Cash...)
+	return &c
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/jizumer/expedition-value/pkg/domain/watchlist"
+)
+
+// ErrWatchlistNotFound is returned when a watchlist is not found.
+var ErrWatchlistNotFound = errors.New("watchlist not found")
+
+// InMemoryWatchlistRepository is an in-memory implementation of the WatchlistRepository. Like a
+// database, it keeps copies of the watchlists saved and hands out copies of them.
+type InMemoryWatchlistRepository struct {
+	mu         sync.RWMutex
+	watchlists map[string]*watchlist.Watchlist // Keyed by Watchlist ID
+}
+
+// NewInMemoryWatchlistRepository creates a new instance of InMemoryWatchlistRepository.
+func NewInMemoryWatchlistRepository() *InMemoryWatchlistRepository {
+	return &InMemoryWatchlistRepository{
+		watchlists: make(map[string]*watchlist.Watchlist),
+	}
+}
+
+// Save creates or updates a watchlist in the in-memory store.
+func (r *InMemoryWatchlistRepository) Save(w *watchlist.Watchlist) error {
+	if w == nil {
+		return errors.New("watchlist cannot be nil")
+	}
+	if w.ID == "" {
+		return errors.New("watchlist ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.watchlists[w.ID] = w.Clone()
+	return nil
+}
+
+// FindByID retrieves a watchlist by its unique identifier.
+func (r *InMemoryWatchlistRepository) FindByID(id string) (*watchlist.Watchlist, error) {
+	if id == "" {
+		return nil, errors.New("watchlist ID cannot be empty")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, exists := r.watchlists[id]
+	if !exists {
+		return nil, ErrWatchlistNotFound
+	}
+	return w.Clone(), nil
+}
+
+// FindAll retrieves all watchlists, oldest first.
+func (r *InMemoryWatchlistRepository) FindAll() ([]*watchlist.Watchlist, error) {
+	return r.filter(func(*watchlist.Watchlist) bool { return true }), nil
+}
+
+// FindByOwner retrieves the watchlists belonging to a user, oldest first.
+func (r *InMemoryWatchlistRepository) FindByOwner(owner string) ([]*watchlist.Watchlist, error) {
+	return r.filter(func(w *watchlist.Watchlist) bool { return w.Owner == owner }), nil
+}
+
+// FindByPortfolio retrieves the watchlists kept for a portfolio, oldest first.
+func (r *InMemoryWatchlistRepository) FindByPortfolio(portfolioID string) ([]*watchlist.Watchlist, error) {
+	return r.filter(func(w *watchlist.Watchlist) bool { return w.PortfolioID == portfolioID }), nil
+}
+
+// Delete removes a watchlist from the in-memory store by its ID.
+func (r *InMemoryWatchlistRepository) Delete(id string) error {
+	if id == "" {
+		return errors.New("watchlist ID cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.watchlists[id]; !exists {
+		return ErrWatchlistNotFound
+	}
+	delete(r.watchlists, id)
+	return nil
+}
+
+// filter returns the stored watchlists matching keep, sorted by creation time.
+func (r *InMemoryWatchlistRepository) filter(keep func(*watchlist.Watchlist) bool) []*watchlist.Watchlist {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*watchlist.Watchlist
+	for _, w := range r.watchlists {
+		if keep(w) {
+			results = append(results, w.Clone())
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+// PostgresAuditLog is a PostgreSQL implementation of the audit Log. Each entry is stored as the
+// JSON document it was hashed from, next to the columns it is filtered by; the table refuses
+// updates and deletes. Every method has a variant taking a context; the others bound each query
+// by the log's query timeout.
+type PostgresAuditLog struct {
+	db      dbtx
+	timeout time.Duration
+}
+
+// NewPostgresAuditLog creates a new instance of PostgresAuditLog. A non-positive timeout means
+// DefaultQueryTimeout.
+func NewPostgresAuditLog(pool *pgxpool.Pool, timeout time.Duration) *PostgresAuditLog {
+	return &PostgresAuditLog{db: pool, timeout: timeout}
+}
+
+// Append seals an entry after the last one of the log and stores it.
+func (l *PostgresAuditLog) Append(e *audit.Entry) error {
+	ctx, cancel := withTimeout(l.timeout)
+	defer cancel()
+	return l.AppendContext(ctx, e)
+}
+
+// AppendContext seals an entry after the last one of the log and stores it, holding the table
+// against other appends until it is stored so that the chain does not fork. In a unit of work,
+// the entry is stored when the unit commits, and the table is held until then.
+func (l *PostgresAuditLog) AppendContext(ctx context.Context, e *audit.Entry) error {
+	if e == nil {
+		return errors.New("audit entry cannot be nil")
+	}
+	tx, err := l.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry for %s %s: %w", e.AggregateType, e.AggregateID, err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if _, err := tx.Exec(ctx, "LOCK TABLE audit_log IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock the audit log: %w", err)
+	}
+	sequence, previous := 1, ""
+	err = tx.QueryRow(ctx, "SELECT sequence, hash FROM audit_log ORDER BY sequence DESC LIMIT 1").Scan(&sequence, &previous)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to read the end of the audit log: %w", err)
+	default:
+		sequence++
+	}
+	if err := e.Seal(sequence, previous); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry %d: %w", e.Sequence, err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO audit_log (sequence, recorded_at, actor, aggregate_type, aggregate_id, hash, entry)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		e.Sequence, e.RecordedAt, e.Actor, string(e.AggregateType), e.AggregateID, e.Hash, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry %d: %w", e.Sequence, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to append audit entry %d: %w", e.Sequence, err)
+	}
+	return nil
+}
+
+// Find retrieves the entries the filter selects, in log order.
+func (l *PostgresAuditLog) Find(filter audit.Filter) ([]*audit.Entry, error) {
+	ctx, cancel := withTimeout(l.timeout)
+	defer cancel()
+	return l.FindContext(ctx, filter)
+}
+
+// FindContext retrieves the entries the filter selects, in log order.
+func (l *PostgresAuditLog) FindContext(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	// Zero bounds are passed as NULL and match every entry.
+	var from, to *time.Time
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+	rows, err := l.db.Query(ctx, `
+		SELECT entry::text FROM audit_log
+		WHERE ($1 = '' OR aggregate_type = $1)
+			AND ($2 = '' OR aggregate_id = $2)
+			AND ($3 = '' OR actor = $3)
+			AND ($4::timestamptz IS NULL OR recorded_at >= $4)
+			AND ($5::timestamptz IS NULL OR recorded_at <= $5)
+		ORDER BY sequence`,
+		string(filter.AggregateType), filter.AggregateID, filter.Actor, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the audit log: %w", err)
+	}
+	defer rows.Close()
+
+	found := []*audit.Entry{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read audit entry: %w", err)
+		}
+		e, err := audit.DecodeEntry([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query the audit log: %w", err)
+	}
+	return found, nil
+}
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+)
+
+// ErrCompanyNotFound is returned when a company is not found in the repository.
+var ErrCompanyNotFound = company.ErrCompanyNotFound
+
+// companyColumns are the columns scanned by scanCompany, in order.
+const companyColumns = "ticker, sector, current_score, financial_metrics, dividends, corporate_actions, metrics_revisions, updated_at, version"
+
+// PostgresCompanyRepository is a PostgreSQL implementation of the CompanyRepository interface.
+// Every method has a variant taking a context; the others bound each query by the repository's
+// query timeout.
+type PostgresCompanyRepository struct {
+	db      dbtx
+	timeout time.Duration
+}
+
+// NewPostgresCompanyRepository creates a new instance of PostgresCompanyRepository. A
+// non-positive timeout means DefaultQueryTimeout.
+func NewPostgresCompanyRepository(pool *pgxpool.Pool, timeout time.Duration) *PostgresCompanyRepository {
+	return &PostgresCompanyRepository{db: pool, timeout: timeout}
+}
+
+// Save creates or updates a company, unless it is stale.
+func (r *PostgresCompanyRepository) Save(c *company.Company) error {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SaveContext(ctx, c)
+}
+
+// SaveContext creates or updates a company, unless it is stale.
+func (r *PostgresCompanyRepository) SaveContext(ctx context.Context, c *company.Company) error {
+	if c == nil {
+		return errors.New("company cannot be nil")
+	}
+	if c.Ticker == "" {
+		return errors.New("company ticker cannot be empty")
+	}
+
+	metrics, err := json.Marshal(c.FinancialMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode financial metrics of %s: %w", c.Ticker, err)
+	}
+	dividends, err := json.Marshal(orEmpty(c.Dividends))
+	if err != nil {
+		return fmt.Errorf("failed to encode dividends of %s: %w", c.Ticker, err)
+	}
+	actions, err := json.Marshal(orEmpty(c.CorporateActions))
+	if err != nil {
+		return fmt.Errorf("failed to encode corporate actions of %s: %w", c.Ticker, err)
+	}
+	revisions, err := json.Marshal(orEmpty(c.MetricsRevisions))
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics revisions of %s: %w", c.Ticker, err)
+	}
+
+	// A new company must not exist yet; an existing one must still be at the version loaded.
+	var tag pgconn.CommandTag
+	if c.Version == 0 {
+		tag, err = r.db.Exec(ctx, `
+			INSERT INTO companies (`+companyColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1)
+			ON CONFLICT (ticker) DO NOTHING`,
+			c.Ticker, c.Sector.String(), c.CurrentScore, metrics, dividends, actions, revisions, c.UpdatedAt)
+	} else {
+		tag, err = r.db.Exec(ctx, `
+			UPDATE companies SET
+				sector = $2,
+				current_score = $3,
+				financial_metrics = $4,
+				dividends = $5,
+				corporate_actions = $6,
+				metrics_revisions = $7,
+				updated_at = $8,
+				version = version + 1
+			WHERE ticker = $1 AND version = $9`,
+			c.Ticker, c.Sector.String(), c.CurrentScore, metrics, dividends, actions, revisions, c.UpdatedAt, c.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save company %s: %w", c.Ticker, err)
+	}
+	if tag.RowsAffected() == 0 {
+		var current int
+		err := r.db.QueryRow(ctx, "SELECT version FROM companies WHERE ticker = $1", c.Ticker).Scan(&current)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to save company %s: %w", c.Ticker, err)
+		}
+		return &company.VersionConflictError{Ticker: c.Ticker, Version: c.Version, CurrentVersion: current}
+	}
+	c.Version++
+	return nil
+}
+
+// FindByTicker retrieves a company by its stock ticker.
+func (r *PostgresCompanyRepository) FindByTicker(ticker string) (*company.Company, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.FindByTickerContext(ctx, ticker)
+}
+
+// FindByTickerContext retrieves a company by its stock ticker.
+func (r *PostgresCompanyRepository) FindByTickerContext(ctx context.Context, ticker string) (*company.Company, error) {
+	if ticker == "" {
+		return nil, errors.New("ticker cannot be empty")
+	}
+
+	row := r.db.QueryRow(ctx, "SELECT "+companyColumns+" FROM companies WHERE ticker = $1", ticker)
+	c, err := scanCompany(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCompanyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find company %s: %w", ticker, err)
+	}
+	return c, nil
+}
+
+// SearchByScoreRange retrieves companies whose current value score falls within the given
+// range, bounds included, ordered by ticker.
+func (r *PostgresCompanyRepository) SearchByScoreRange(minScore, maxScore float64) ([]*company.Company, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SearchByScoreRangeContext(ctx, minScore, maxScore)
+}
+
+// SearchByScoreRangeContext retrieves companies whose current value score falls within the
+// given range, bounds included, ordered by ticker.
+func (r *PostgresCompanyRepository) SearchByScoreRangeContext(ctx context.Context, minScore, maxScore float64) ([]*company.Company, error) {
+	if minScore > maxScore {
+		return nil, errors.New("minScore cannot be greater than maxScore")
+	}
+	return r.query(ctx, "SELECT "+companyColumns+" FROM companies WHERE current_score BETWEEN $1 AND $2 ORDER BY ticker", minScore, maxScore)
+}
+
+// FindAll retrieves all companies, ordered by ticker.
+func (r *PostgresCompanyRepository) FindAll() ([]*company.Company, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.FindAllContext(ctx)
+}
+
+// FindAllContext retrieves all companies, ordered by ticker.
+func (r *PostgresCompanyRepository) FindAllContext(ctx context.Context) ([]*company.Company, error) {
+	return r.query(ctx, "SELECT "+companyColumns+" FROM companies ORDER BY ticker")
+}
+
+// Delete removes a company by its ticker.
+func (r *PostgresCompanyRepository) Delete(ticker string) error {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.DeleteContext(ctx, ticker)
+}
+
+// DeleteContext removes a company by its ticker.
+func (r *PostgresCompanyRepository) DeleteContext(ctx context.Context, ticker string) error {
+	if ticker == "" {
+		return errors.New("ticker cannot be empty")
+	}
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM companies WHERE ticker = $1", ticker)
+	if err != nil {
+		return fmt.Errorf("failed to delete company %s: %w", ticker, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("company with ticker '%s' not found for deletion: %w", ticker, ErrCompanyNotFound)
+	}
+	return nil
+}
+
+// query runs a query returning companyColumns and scans every row.
+func (r *PostgresCompanyRepository) query(ctx context.Context, sql string, args ...any) ([]*company.Company, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*company.Company{}
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read company: %w", err)
+		}
+		results = append(results, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	return results, nil
+}
+
+// scanCompany reads a company from a row of companyColumns.
+func scanCompany(row pgx.Row) (*company.Company, error) {
+	var (
+		c                                      company.Company
+		sector                                 string
+		metrics, dividends, actions, revisions []byte
+	)
+	if err := row.Scan(&c.Ticker, &sector, &c.CurrentScore, &metrics, &dividends, &actions, &revisions, &c.UpdatedAt, &c.Version); err != nil {
+		return nil, err
+	}
+	c.Sector = company.ParseSector(sector)
+	if err := json.Unmarshal(metrics, &c.FinancialMetrics); err != nil {
+		return nil, fmt.Errorf("invalid financial metrics of %s: %w", c.Ticker, err)
+	}
+	if err := json.Unmarshal(dividends, &c.Dividends); err != nil {
+		return nil, fmt.Errorf("invalid dividends of %s: %w", c.Ticker, err)
+	}
+	if err := json.Unmarshal(actions, &c.CorporateActions); err != nil {
+		return nil, fmt.Errorf("invalid corporate actions of %s: %w", c.Ticker, err)
+	}
+	if err := json.Unmarshal(revisions, &c.MetricsRevisions); err != nil {
+		return nil, fmt.Errorf("invalid metrics revisions of %s: %w", c.Ticker, err)
+	}
+	if len(c.Dividends) == 0 {
+		c.Dividends = nil
+	}
+	if len(c.CorporateActions) == 0 {
+		c.CorporateActions = nil
+	}
+	if len(c.MetricsRevisions) == 0 {
+		c.MetricsRevisions = nil
+	}
+	return &c, nil
+}
+
+// orEmpty returns s, or an empty slice when s is nil, so that it is stored as a JSON array.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
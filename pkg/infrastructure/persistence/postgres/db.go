@@ -0,0 +1,66 @@
+// Package postgres implements the repositories on PostgreSQL. Connections come from a pgx pool
+// shared by all repositories; the schema is created and upgraded by Migrate from the migrations
+// embedded in the binary.
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultQueryTimeout bounds each query made through the context-free repository methods.
+const DefaultQueryTimeout = 5 * time.Second
+
+// Open connects a pool of at most maxConns connections (the pgx default when not positive) to
+// the database at url, a PostgreSQL URL or keyword/value connection string, and checks that
+// the database can be reached.
+func Open(ctx context.Context, url string, maxConns int) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	if maxConns > 0 {
+		cfg.MaxConns = int32(maxConns)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to reach the database: %w", err)
+	}
+	return pool, nil
+}
+
+// dbtx is what the repositories need of the pool or of the transaction of a unit of work. A
+// transaction begun on a transaction is a savepoint, so a repository's own transactions nest in
+// a unit's.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// withTimeout returns a context for one query of a context-free repository method.
+func withTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// nullTime returns t as a query argument, NULL when t is zero, for ranges open on that side.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
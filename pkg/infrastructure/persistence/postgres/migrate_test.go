@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadMigrations(embedded) error = %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].version != 1 || migrations[0].name != "create_companies" {
+		t.Errorf("loadMigrations(embedded) = %+v, want create_companies first", migrations)
+	}
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{"Gap", fstest.MapFS{"migrations/0001_a.sql": {}, "migrations/0003_c.sql": {}}, "out of sequence"},
+		{"Duplicate", fstest.MapFS{"migrations/0001_a.sql": {}, "migrations/01_b.sql": {}}, "out of sequence"},
+		{"Unnumbered", fstest.MapFS{"migrations/create_a.sql": {}}, "not named"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadMigrations(tt.files); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadMigrations() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+-- Companies and their fundamentals. Dividends and corporate actions are owned by the company
+-- aggregate and stored with it.
+CREATE TABLE companies (
+    ticker            TEXT PRIMARY KEY,
+    sector            TEXT NOT NULL,
+    current_score     DOUBLE PRECISION NOT NULL,
+    financial_metrics JSONB NOT NULL,
+    dividends         JSONB NOT NULL,
+    corporate_actions JSONB NOT NULL,
+    updated_at        TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX companies_current_score_idx ON companies (current_score);
+CREATE INDEX companies_sector_idx ON companies (sector);
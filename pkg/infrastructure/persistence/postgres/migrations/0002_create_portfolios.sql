@@ -0,0 +1,20 @@
+-- Portfolios are stored as a document of the whole aggregate, with the columns queries filter on
+-- alongside. portfolio_holdings indexes the tickers each portfolio holds for sector searches; it
+-- is rewritten with the portfolio on every save.
+CREATE TABLE portfolios (
+    id            TEXT PRIMARY KEY,
+    risk_profile  TEXT NOT NULL,
+    base_currency TEXT NOT NULL,
+    state         JSONB NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX portfolios_risk_profile_idx ON portfolios (risk_profile);
+
+CREATE TABLE portfolio_holdings (
+    portfolio_id TEXT NOT NULL REFERENCES portfolios (id) ON DELETE CASCADE,
+    ticker       TEXT NOT NULL,
+    PRIMARY KEY (portfolio_id, ticker)
+);
+
+CREATE INDEX portfolio_holdings_ticker_idx ON portfolio_holdings (ticker);
@@ -0,0 +1,4 @@
+-- Aggregate versions for optimistic concurrency: a save only succeeds against the version it was
+-- loaded from. Rows saved before versions existed count as saved once.
+ALTER TABLE companies ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE portfolios ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+-- Event-sourced portfolios: each portfolio is the stream of the events saved for it, in version
+-- order, with a snapshot of its state every so many events so that it is not rebuilt from the
+-- start. Both tables are only ever appended to; a deleted portfolio keeps its stream.
+CREATE TABLE portfolio_events (
+    portfolio_id TEXT NOT NULL,
+    version      BIGINT NOT NULL,
+    type         TEXT NOT NULL,
+    recorded_at  TIMESTAMPTZ NOT NULL,
+    data         JSONB NOT NULL,
+    PRIMARY KEY (portfolio_id, version)
+);
+
+CREATE TABLE portfolio_snapshots (
+    portfolio_id TEXT NOT NULL,
+    version      BIGINT NOT NULL,
+    state        JSONB NOT NULL,
+    PRIMARY KEY (portfolio_id, version)
+);
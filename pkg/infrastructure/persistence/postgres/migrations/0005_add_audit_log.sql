@@ -0,0 +1,26 @@
+-- Audit log of company and portfolio changes. Each entry is kept as the exact JSON it was hashed
+-- from (JSON, not JSONB, which would rewrite it), chained to the entry before by its hash. The
+-- table only takes inserts: updating or deleting an entry is refused.
+CREATE TABLE audit_log (
+    sequence       BIGINT PRIMARY KEY,
+    recorded_at    TIMESTAMPTZ NOT NULL,
+    actor          TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id   TEXT NOT NULL,
+    hash           TEXT NOT NULL,
+    entry          JSON NOT NULL
+);
+
+CREATE INDEX audit_log_aggregate_idx ON audit_log (aggregate_type, aggregate_id);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX audit_log_recorded_at_idx ON audit_log (recorded_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'the audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
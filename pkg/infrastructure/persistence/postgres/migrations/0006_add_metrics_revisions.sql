@@ -0,0 +1,4 @@
+-- Every revision of a company's financial metrics, with the period it describes and when it
+-- became known, so that companies can be read as known on a past date. Companies saved before
+-- revisions existed have none.
+ALTER TABLE companies ADD COLUMN metrics_revisions JSONB NOT NULL DEFAULT '[]';
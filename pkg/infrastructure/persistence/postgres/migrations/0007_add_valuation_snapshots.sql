@@ -0,0 +1,9 @@
+-- End-of-day valuations of portfolios, one per portfolio and day, from which performance is
+-- measured. A valuation taken again on the same day replaces the first.
+CREATE TABLE valuation_snapshots (
+    portfolio_id TEXT NOT NULL,
+    day          DATE NOT NULL,
+    as_of        TIMESTAMPTZ NOT NULL,
+    valuation    JSONB NOT NULL,
+    PRIMARY KEY (portfolio_id, day)
+);
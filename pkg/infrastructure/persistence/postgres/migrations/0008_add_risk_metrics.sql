@@ -0,0 +1,9 @@
+-- Daily risk metrics of portfolios, one per portfolio and day, charted as trends. Metrics
+-- measured again on the same day replace the first.
+CREATE TABLE risk_metrics (
+    portfolio_id TEXT NOT NULL,
+    day          DATE NOT NULL,
+    as_of        TIMESTAMPTZ NOT NULL,
+    metrics      JSONB NOT NULL,
+    PRIMARY KEY (portfolio_id, day)
+);
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// uniqueViolation is the SQLSTATE of an insert clashing with a primary key.
+const uniqueViolation = "23505"
+
+// PostgresPortfolioEventStore is a PostgreSQL implementation of the portfolio EventStore. Events
+// and snapshots are stored as JSON documents keyed by portfolio and version. Every method has a
+// variant taking a context; the others bound each query by the store's query timeout.
+type PostgresPortfolioEventStore struct {
+	db      dbtx
+	timeout time.Duration
+}
+
+// NewPostgresPortfolioEventStore creates a new instance of PostgresPortfolioEventStore. A
+// non-positive timeout means DefaultQueryTimeout.
+func NewPostgresPortfolioEventStore(pool *pgxpool.Pool, timeout time.Duration) *PostgresPortfolioEventStore {
+	return &PostgresPortfolioEventStore{db: pool, timeout: timeout}
+}
+
+// Append adds an event to the end of its portfolio's stream, with the snapshot of the state it
+// produced if there is one, unless the stream has moved on.
+func (s *PostgresPortfolioEventStore) Append(event portfolio.Event, snapshot *portfolio.Portfolio) error {
+	ctx, cancel := withTimeout(s.timeout)
+	defer cancel()
+	return s.AppendContext(ctx, event, snapshot)
+}
+
+// AppendContext adds an event, and its snapshot if there is one, in one transaction, unless the
+// stream has moved on.
+func (s *PostgresPortfolioEventStore) AppendContext(ctx context.Context, event portfolio.Event, snapshot *portfolio.Portfolio) error {
+	if event.PortfolioID == "" {
+		return errors.New("event portfolio ID cannot be empty")
+	}
+	if snapshot != nil && (snapshot.ID != event.PortfolioID || snapshot.Version != event.Version) {
+		return errors.New("snapshot must be of the portfolio and version of its event")
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to append event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// The event must follow the last one; two appends of the same version clash on the key.
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO portfolio_events (portfolio_id, version, type, recorded_at, data)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (SELECT COALESCE(MAX(version), 0) FROM portfolio_events WHERE portfolio_id = $1) = $2 - 1`,
+		event.PortfolioID, event.Version, event.Type.String(), event.RecordedAt, data)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation || err == nil && tag.RowsAffected() == 0 {
+		// Within a unit of work the failed insert aborts the transaction until it is rolled back.
+		tx.Rollback(ctx)
+		return s.conflict(ctx, event)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	if snapshot != nil {
+		state, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to encode snapshot of portfolio %s: %w", event.PortfolioID, err)
+		}
+		_, err = tx.Exec(ctx, "INSERT INTO portfolio_snapshots (portfolio_id, version, state) VALUES ($1, $2, $3)",
+			event.PortfolioID, event.Version, state)
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot of portfolio %s: %w", event.PortfolioID, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to append event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	return nil
+}
+
+// Load returns the latest snapshot of a portfolio taken at or before at and the events after it.
+func (s *PostgresPortfolioEventStore) Load(portfolioID string, at time.Time) (*portfolio.Portfolio, []portfolio.Event, error) {
+	ctx, cancel := withTimeout(s.timeout)
+	defer cancel()
+	return s.LoadContext(ctx, portfolioID, at)
+}
+
+// LoadContext returns the latest snapshot of a portfolio taken at or before at and the events
+// after it, read from one snapshot of the database. Within a unit of work they are read by the
+// unit's transaction instead.
+func (s *PostgresPortfolioEventStore) LoadContext(ctx context.Context, portfolioID string, at time.Time) (*portfolio.Portfolio, []portfolio.Event, error) {
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if pool, ok := s.db.(*pgxpool.Pool); ok {
+		tx, err = pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	} else {
+		tx, err = s.db.Begin(ctx)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load portfolio %s: %w", portfolioID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The stream is cut before the first event recorded after at, if any.
+	var before *time.Time
+	if !at.IsZero() {
+		before = &at
+	}
+	var end int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(
+			(SELECT MIN(version) - 1 FROM portfolio_events WHERE portfolio_id = $1 AND recorded_at > $2),
+			(SELECT MAX(version) FROM portfolio_events WHERE portfolio_id = $1),
+			0)`, portfolioID, before).Scan(&end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load portfolio %s: %w", portfolioID, err)
+	}
+
+	var (
+		snapshot *portfolio.Portfolio
+		start    int
+		state    []byte
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT version, state FROM portfolio_snapshots
+		WHERE portfolio_id = $1 AND version <= $2
+		ORDER BY version DESC LIMIT 1`, portfolioID, end).Scan(&start, &state)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return nil, nil, fmt.Errorf("failed to load snapshot of portfolio %s: %w", portfolioID, err)
+	default:
+		if snapshot, err = decodePortfolio(state, start); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	events, err := queryEvents(ctx, tx, `
+		SELECT data FROM portfolio_events
+		WHERE portfolio_id = $1 AND version > $2 AND version <= $3
+		ORDER BY version`, portfolioID, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot, events, nil
+}
+
+// Events returns the whole stream of a portfolio, oldest first.
+func (s *PostgresPortfolioEventStore) Events(portfolioID string) ([]portfolio.Event, error) {
+	ctx, cancel := withTimeout(s.timeout)
+	defer cancel()
+	return s.EventsContext(ctx, portfolioID)
+}
+
+// EventsContext returns the whole stream of a portfolio, oldest first.
+func (s *PostgresPortfolioEventStore) EventsContext(ctx context.Context, portfolioID string) ([]portfolio.Event, error) {
+	return queryEvents(ctx, s.db, "SELECT data FROM portfolio_events WHERE portfolio_id = $1 ORDER BY version", portfolioID)
+}
+
+// StreamIDs returns the IDs of the portfolios that have a stream, ordered.
+func (s *PostgresPortfolioEventStore) StreamIDs() ([]string, error) {
+	ctx, cancel := withTimeout(s.timeout)
+	defer cancel()
+	return s.StreamIDsContext(ctx)
+}
+
+// StreamIDsContext returns the IDs of the portfolios that have a stream, ordered.
+func (s *PostgresPortfolioEventStore) StreamIDsContext(ctx context.Context) ([]string, error) {
+	rows, err := s.db.Query(ctx, "SELECT DISTINCT portfolio_id FROM portfolio_events ORDER BY portfolio_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event streams: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event streams: %w", err)
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, nil
+}
+
+// conflict returns the error reporting that event does not follow the last one of its stream.
+func (s *PostgresPortfolioEventStore) conflict(ctx context.Context, event portfolio.Event) error {
+	var current int
+	err := s.db.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM portfolio_events WHERE portfolio_id = $1", event.PortfolioID).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to append event %d of portfolio %s: %w", event.Version, event.PortfolioID, err)
+	}
+	return &portfolio.VersionConflictError{PortfolioID: event.PortfolioID, Version: event.Version - 1, CurrentVersion: current}
+}
+
+// queryEvents runs a query returning event documents and decodes every row.
+func queryEvents(ctx context.Context, q dbtx, sql string, args ...any) ([]portfolio.Event, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []portfolio.Event{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read event: %w", err)
+		}
+		var e portfolio.Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("invalid stored event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,251 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// ErrPortfolioNotFound is returned when a portfolio is not found.
+var ErrPortfolioNotFound = portfolio.ErrPortfolioNotFound
+
+// PostgresPortfolioRepository is a PostgreSQL implementation of the PortfolioRepository
+// interface. The aggregate is stored as a JSON document next to the columns searches filter on.
+// Every method has a variant taking a context; the others bound each query by the repository's
+// query timeout.
+type PostgresPortfolioRepository struct {
+	db      dbtx
+	timeout time.Duration
+}
+
+// NewPostgresPortfolioRepository creates a new instance of PostgresPortfolioRepository. A
+// non-positive timeout means DefaultQueryTimeout.
+func NewPostgresPortfolioRepository(pool *pgxpool.Pool, timeout time.Duration) *PostgresPortfolioRepository {
+	return &PostgresPortfolioRepository{db: pool, timeout: timeout}
+}
+
+// Save creates or updates a portfolio, unless it is stale.
+func (r *PostgresPortfolioRepository) Save(p *portfolio.Portfolio) error {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SaveContext(ctx, p)
+}
+
+// SaveContext creates or updates a portfolio, together with the index of its holdings, in
+// one transaction, unless it is stale.
+func (r *PostgresPortfolioRepository) SaveContext(ctx context.Context, p *portfolio.Portfolio) error {
+	if p == nil {
+		return errors.New("portfolio cannot be nil")
+	}
+	if p.ID == "" {
+		return errors.New("portfolio ID cannot be empty")
+	}
+
+	saved := p.Clone()
+	saved.Version++
+	state, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode portfolio %s: %w", p.ID, err)
+	}
+	tickers := make([]string, 0, len(p.Holdings))
+	for ticker := range p.Holdings {
+		tickers = append(tickers, ticker)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// A new portfolio must not exist yet; an existing one must still be at the version loaded.
+	var tag pgconn.CommandTag
+	if p.Version == 0 {
+		tag, err = tx.Exec(ctx, `
+			INSERT INTO portfolios (id, risk_profile, base_currency, state, updated_at, version)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (id) DO NOTHING`,
+			p.ID, p.RiskProfile.String(), p.BaseCurrency, state, p.UpdatedAt, saved.Version)
+	} else {
+		tag, err = tx.Exec(ctx, `
+			UPDATE portfolios SET
+				risk_profile = $2,
+				base_currency = $3,
+				state = $4,
+				updated_at = $5,
+				version = $6
+			WHERE id = $1 AND version = $7`,
+			p.ID, p.RiskProfile.String(), p.BaseCurrency, state, p.UpdatedAt, saved.Version, p.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		var current int
+		err := tx.QueryRow(ctx, "SELECT version FROM portfolios WHERE id = $1", p.ID).Scan(&current)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+		}
+		return &portfolio.VersionConflictError{PortfolioID: p.ID, Version: p.Version, CurrentVersion: current}
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM portfolio_holdings WHERE portfolio_id = $1", p.ID); err != nil {
+		return fmt.Errorf("failed to save holdings of portfolio %s: %w", p.ID, err)
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO portfolio_holdings (portfolio_id, ticker) SELECT $1, unnest($2::text[])", p.ID, tickers); err != nil {
+		return fmt.Errorf("failed to save holdings of portfolio %s: %w", p.ID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save portfolio %s: %w", p.ID, err)
+	}
+	p.Version = saved.Version
+	return nil
+}
+
+// FindByID retrieves a portfolio by its unique identifier.
+func (r *PostgresPortfolioRepository) FindByID(id string) (*portfolio.Portfolio, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.FindByIDContext(ctx, id)
+}
+
+// FindByIDContext retrieves a portfolio by its unique identifier.
+func (r *PostgresPortfolioRepository) FindByIDContext(ctx context.Context, id string) (*portfolio.Portfolio, error) {
+	if id == "" {
+		return nil, errors.New("portfolio ID cannot be empty")
+	}
+
+	var (
+		state   []byte
+		version int
+	)
+	err := r.db.QueryRow(ctx, "SELECT state, version FROM portfolios WHERE id = $1", id).Scan(&state, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPortfolioNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find portfolio %s: %w", id, err)
+	}
+	return decodePortfolio(state, version)
+}
+
+// FindAll retrieves all portfolios, ordered by ID.
+func (r *PostgresPortfolioRepository) FindAll() ([]*portfolio.Portfolio, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.FindAllContext(ctx)
+}
+
+// FindAllContext retrieves all portfolios, ordered by ID.
+func (r *PostgresPortfolioRepository) FindAllContext(ctx context.Context) ([]*portfolio.Portfolio, error) {
+	return r.query(ctx, "SELECT state, version FROM portfolios ORDER BY id")
+}
+
+// SearchByRiskProfile retrieves portfolios matching a specific risk profile, ordered by ID.
+func (r *PostgresPortfolioRepository) SearchByRiskProfile(riskProfile portfolio.RiskProfile) ([]*portfolio.Portfolio, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SearchByRiskProfileContext(ctx, riskProfile)
+}
+
+// SearchByRiskProfileContext retrieves portfolios matching a specific risk profile, ordered by ID.
+func (r *PostgresPortfolioRepository) SearchByRiskProfileContext(ctx context.Context, riskProfile portfolio.RiskProfile) ([]*portfolio.Portfolio, error) {
+	return r.query(ctx, "SELECT state, version FROM portfolios WHERE risk_profile = $1 ORDER BY id", riskProfile.String())
+}
+
+// SearchBySector retrieves portfolios that hold positions in companies of the given sector,
+// ordered by ID.
+func (r *PostgresPortfolioRepository) SearchBySector(sector company.Sector) ([]*portfolio.Portfolio, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SearchBySectorContext(ctx, sector)
+}
+
+// SearchBySectorContext retrieves portfolios that hold positions in companies of the given
+// sector, ordered by ID.
+func (r *PostgresPortfolioRepository) SearchBySectorContext(ctx context.Context, sector company.Sector) ([]*portfolio.Portfolio, error) {
+	return r.query(ctx, `
+		SELECT p.state, p.version FROM portfolios p
+		WHERE EXISTS (
+			SELECT 1 FROM portfolio_holdings h JOIN companies c ON c.ticker = h.ticker
+			WHERE h.portfolio_id = p.id AND c.sector = $1
+		)
+		ORDER BY p.id`, sector.String())
+}
+
+// Delete removes a portfolio by its ID.
+func (r *PostgresPortfolioRepository) Delete(id string) error {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.DeleteContext(ctx, id)
+}
+
+// DeleteContext removes a portfolio by its ID; its holdings index goes with it.
+func (r *PostgresPortfolioRepository) DeleteContext(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("portfolio ID cannot be empty")
+	}
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM portfolios WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete portfolio %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("portfolio with ID '%s' not found for deletion: %w", id, ErrPortfolioNotFound)
+	}
+	return nil
+}
+
+// query runs a query returning portfolio states and versions and decodes every row.
+func (r *PostgresPortfolioRepository) query(ctx context.Context, sql string, args ...any) ([]*portfolio.Portfolio, error) {
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query portfolios: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*portfolio.Portfolio{}
+	for rows.Next() {
+		var (
+			state   []byte
+			version int
+		)
+		if err := rows.Scan(&state, &version); err != nil {
+			return nil, fmt.Errorf("failed to read portfolio: %w", err)
+		}
+		p, err := decodePortfolio(state, version)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query portfolios: %w", err)
+	}
+	return results, nil
+}
+
+// decodePortfolio rebuilds a portfolio from its stored state and version; the version column
+// wins over the state's, which rows saved before versions existed lack. Maps the aggregate
+// expects to be usable are initialized even when the state has none.
+func decodePortfolio(state []byte, version int) (*portfolio.Portfolio, error) {
+	var p portfolio.Portfolio
+	if err := json.Unmarshal(state, &p); err != nil {
+		return nil, fmt.Errorf("invalid stored portfolio: %w", err)
+	}
+	p.Version = version
+	if p.Holdings == nil {
+		p.Holdings = make(map[string]portfolio.Position)
+	}
+	if p.ForeignCash == nil {
+		p.ForeignCash = make(map[string]portfolio.Money)
+	}
+	return &p, nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// PostgresRiskMetricsRepository is a PostgreSQL implementation of the RiskMetricsRepository.
+// The metrics of each day are stored as a JSON document keyed by portfolio and day. Every method
+// has a variant taking a context; the others bound each query by the repository's query timeout.
+type PostgresRiskMetricsRepository struct {
+	db      dbtx
+	timeout time.Duration
+}
+
+// NewPostgresRiskMetricsRepository creates a new instance of PostgresRiskMetricsRepository. A
+// non-positive timeout means DefaultQueryTimeout.
+func NewPostgresRiskMetricsRepository(pool *pgxpool.Pool, timeout time.Duration) *PostgresRiskMetricsRepository {
+	return &PostgresRiskMetricsRepository{db: pool, timeout: timeout}
+}
+
+// Save stores the metrics for the day of their AsOf, replacing any stored for the same day.
+func (r *PostgresRiskMetricsRepository) Save(metrics *portfolio.RiskMetrics) error {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SaveContext(ctx, metrics)
+}
+
+// SaveContext stores the metrics for the day of their AsOf, replacing any stored for the same day.
+func (r *PostgresRiskMetricsRepository) SaveContext(ctx context.Context, metrics *portfolio.RiskMetrics) error {
+	if metrics == nil {
+		return errors.New("risk metrics cannot be nil")
+	}
+	if metrics.PortfolioID == "" {
+		return errors.New("risk metrics portfolio ID cannot be empty")
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode risk metrics of portfolio %s: %w", metrics.PortfolioID, err)
+	}
+	// pgx takes the day of AsOf in AsOf's time zone, not the database's.
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO risk_metrics (portfolio_id, day, as_of, metrics)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (portfolio_id, day) DO UPDATE SET as_of = EXCLUDED.as_of, metrics = EXCLUDED.metrics`,
+		metrics.PortfolioID, pgtype.Date{Time: metrics.AsOf, Valid: true}, metrics.AsOf, data)
+	if err != nil {
+		return fmt.Errorf("failed to save risk metrics of portfolio %s: %w", metrics.PortfolioID, err)
+	}
+	return nil
+}
+
+// FindByPortfolio retrieves a portfolio's metrics with AsOf in [from, to], oldest first.
+func (r *PostgresRiskMetricsRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.FindByPortfolioContext(ctx, portfolioID, from, to)
+}
+
+// FindByPortfolioContext retrieves a portfolio's metrics with AsOf in [from, to], oldest first.
+func (r *PostgresRiskMetricsRepository) FindByPortfolioContext(ctx context.Context, portfolioID string, from, to time.Time) ([]*portfolio.RiskMetrics, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT metrics FROM risk_metrics
+		WHERE portfolio_id = $1
+			AND ($2::timestamptz IS NULL OR as_of >= $2)
+			AND ($3::timestamptz IS NULL OR as_of <= $3)
+		ORDER BY as_of`,
+		portfolioID, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query risk metrics of portfolio %s: %w", portfolioID, err)
+	}
+	defer rows.Close()
+
+	var results []*portfolio.RiskMetrics
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read risk metrics: %w", err)
+		}
+		var m portfolio.RiskMetrics
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid stored risk metrics: %w", err)
+		}
+		results = append(results, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query risk metrics of portfolio %s: %w", portfolioID, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/recommendation"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/eventsourced"
+	"github.com/jizumer/expedition-value/pkg/infrastructure/persistence/memory"
+)
+
+// PostgresUnitOfWork is a PostgreSQL implementation of the application's UnitOfWork. A unit is
+// one database transaction, in which every company and portfolio write is made, and every
+// append to the audit log; the repositories' own transactions become savepoints within it.
+// Recommendations are not stored in PostgreSQL, so the unit holds the ones it saves back and
+// writes them to their repository only once the transaction has committed.
+type PostgresUnitOfWork struct {
+	pool             *pgxpool.Pool
+	timeout          time.Duration
+	recommendations  recommendation.RecommendationRepository
+	eventSourced     bool
+	snapshotInterval int
+}
+
+// UnitOfWorkOption configures optional behaviour of a PostgresUnitOfWork.
+type UnitOfWorkOption func(*PostgresUnitOfWork)
+
+// WithEventSourcedPortfolios makes the unit keep portfolios as event streams, as an
+// EventSourcedPortfolioRepository over a PostgresPortfolioEventStore does, taking a snapshot of
+// a portfolio every snapshotInterval events.
+func WithEventSourcedPortfolios(snapshotInterval int) UnitOfWorkOption {
+	return func(u *PostgresUnitOfWork) {
+		u.eventSourced = true
+		u.snapshotInterval = snapshotInterval
+	}
+}
+
+// NewPostgresUnitOfWork creates a new instance of PostgresUnitOfWork whose repositories bound
+// each query by timeout, a non-positive one meaning DefaultQueryTimeout.
+func NewPostgresUnitOfWork(pool *pgxpool.Pool, timeout time.Duration, recommendations recommendation.RecommendationRepository, opts ...UnitOfWorkOption) *PostgresUnitOfWork {
+	u := &PostgresUnitOfWork{pool: pool, timeout: timeout, recommendations: recommendations}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// Do runs work in a transaction and commits it if work succeeds. Beginning and committing the
+// transaction are bounded by the query timeout; the queries of work by their repositories'.
+func (u *PostgresUnitOfWork) Do(work func(repos application.Repositories) error) error {
+	ctx, cancel := withTimeout(u.timeout)
+	defer cancel()
+	return u.DoContext(ctx, work)
+}
+
+// DoContext runs work in a transaction begun and committed with ctx, and commits it if work
+// succeeds.
+func (u *PostgresUnitOfWork) DoContext(ctx context.Context, work func(repos application.Repositories) error) error {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin unit of work: %w", err)
+	}
+	defer tx.Rollback(context.Background()) // No-op once committed
+
+	companies := &PostgresCompanyRepository{db: tx, timeout: u.timeout}
+	recommendations := memory.NewHeldRecommendationRepository(u.recommendations)
+	repos := application.Repositories{
+		Companies:       companies,
+		Portfolios:      &PostgresPortfolioRepository{db: tx, timeout: u.timeout},
+		Recommendations: recommendations,
+		AuditLog:        &PostgresAuditLog{db: tx, timeout: u.timeout},
+	}
+	if u.eventSourced {
+		store := &PostgresPortfolioEventStore{db: tx, timeout: u.timeout}
+		repos.Portfolios = eventsourced.NewEventSourcedPortfolioRepository(store, companies, u.snapshotInterval)
+	}
+	if err := work(repos); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit unit of work: %w", err)
+	}
+	recommendations.Apply()
+	return nil
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// PostgresValuationSnapshotRepository is a PostgreSQL implementation of the
+// ValuationSnapshotRepository. Each snapshot is stored as a JSON document keyed by portfolio and
+// day. Every method has a variant taking a context; the others bound each query by the
+// repository's query timeout.
+type PostgresValuationSnapshotRepository struct {
+	db      dbtx
+	timeout time.Duration
+}
+
+// NewPostgresValuationSnapshotRepository creates a new instance of
+// PostgresValuationSnapshotRepository. A non-positive timeout means DefaultQueryTimeout.
+func NewPostgresValuationSnapshotRepository(pool *pgxpool.Pool, timeout time.Duration) *PostgresValuationSnapshotRepository {
+	return &PostgresValuationSnapshotRepository{db: pool, timeout: timeout}
+}
+
+// Save stores the snapshot for the day of its AsOf, replacing any stored for the same day.
+func (r *PostgresValuationSnapshotRepository) Save(snapshot *portfolio.Valuation) error {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.SaveContext(ctx, snapshot)
+}
+
+// SaveContext stores the snapshot for the day of its AsOf, replacing any stored for the same day.
+func (r *PostgresValuationSnapshotRepository) SaveContext(ctx context.Context, snapshot *portfolio.Valuation) error {
+	if snapshot == nil {
+		return errors.New("valuation snapshot cannot be nil")
+	}
+	if snapshot.PortfolioID == "" {
+		return errors.New("valuation snapshot portfolio ID cannot be empty")
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode valuation snapshot of portfolio %s: %w", snapshot.PortfolioID, err)
+	}
+	// pgx takes the day of AsOf in AsOf's time zone, not the database's.
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO valuation_snapshots (portfolio_id, day, as_of, valuation)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (portfolio_id, day) DO UPDATE SET as_of = EXCLUDED.as_of, valuation = EXCLUDED.valuation`,
+		snapshot.PortfolioID, pgtype.Date{Time: snapshot.AsOf, Valid: true}, snapshot.AsOf, data)
+	if err != nil {
+		return fmt.Errorf("failed to save valuation snapshot of portfolio %s: %w", snapshot.PortfolioID, err)
+	}
+	return nil
+}
+
+// FindByPortfolio retrieves a portfolio's snapshots with AsOf in [from, to], oldest first.
+func (r *PostgresValuationSnapshotRepository) FindByPortfolio(portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error) {
+	ctx, cancel := withTimeout(r.timeout)
+	defer cancel()
+	return r.FindByPortfolioContext(ctx, portfolioID, from, to)
+}
+
+// FindByPortfolioContext retrieves a portfolio's snapshots with AsOf in [from, to], oldest first.
+func (r *PostgresValuationSnapshotRepository) FindByPortfolioContext(ctx context.Context, portfolioID string, from, to time.Time) ([]*portfolio.Valuation, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT valuation FROM valuation_snapshots
+		WHERE portfolio_id = $1
+			AND ($2::timestamptz IS NULL OR as_of >= $2)
+			AND ($3::timestamptz IS NULL OR as_of <= $3)
+		ORDER BY as_of`,
+		portfolioID, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query valuation snapshots of portfolio %s: %w", portfolioID, err)
+	}
+	defer rows.Close()
+
+	var results []*portfolio.Valuation
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read valuation snapshot: %w", err)
+		}
+		var v portfolio.Valuation
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("invalid stored valuation snapshot: %w", err)
+		}
+		results = append(results, &v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query valuation snapshots of portfolio %s: %w", portfolioID, err)
+	}
+	return results, nil
+}
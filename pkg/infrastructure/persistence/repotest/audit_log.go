@@ -0,0 +1,140 @@
+package repotest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/audit"
+)
+
+// AuditLogFactory returns an empty audit log for one test.
+type AuditLogFactory func(t *testing.T) audit.Log
+
+// TestAuditLog runs the audit Log contract against logs from newLog.
+func TestAuditLog(t *testing.T, newLog AuditLogFactory) {
+	t.Run("AppendAndFind", func(t *testing.T) {
+		log := newLog(t)
+		appended := []*audit.Entry{
+			auditEntry(t, "alice", audit.Portfolio, "p1", day(2024, 6, 3)),
+			auditEntry(t, "bob", audit.Company, "AAPL", day(2024, 6, 4)),
+			auditEntry(t, "alice", audit.Portfolio, "p2", day(2024, 6, 5)),
+		}
+		for _, e := range appended {
+			mustAppendEntry(t, log, e)
+		}
+		if appended[2].Sequence != 3 || appended[2].PreviousHash != appended[1].Hash || appended[0].PreviousHash != "" {
+			t.Errorf("Append() sealed %+v, want sequences from 1 each following the last", appended)
+		}
+
+		all, err := log.Find(audit.Filter{})
+		if err != nil || sequences(all) != "[1 2 3]" {
+			t.Fatalf("Find() = %s, %v, want [1 2 3]", sequences(all), err)
+		}
+		if err := audit.Verify(all); err != nil {
+			t.Errorf("Verify(Find()) error = %v, want the chain intact after storage", err)
+		}
+		if e := all[1]; e.Actor != "bob" || e.Endpoint != "POST /company/create" || e.AggregateType != audit.Company ||
+			e.AggregateID != "AAPL" || !e.RecordedAt.Equal(day(2024, 6, 4)) || len(e.Changes) != 1 || e.Changes[0].Path != "Score" {
+			t.Errorf("Find()[1] = %+v, want the entry as appended", e)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		log := newLog(t)
+		mustAppendEntry(t, log, auditEntry(t, "alice", audit.Portfolio, "p1", day(2024, 6, 3)))
+		mustAppendEntry(t, log, auditEntry(t, "bob", audit.Company, "AAPL", day(2024, 6, 4)))
+		mustAppendEntry(t, log, auditEntry(t, "alice", audit.Portfolio, "p2", day(2024, 6, 5)))
+		mustAppendEntry(t, log, auditEntry(t, "bob", audit.Portfolio, "p1", day(2024, 6, 6)))
+
+		tests := []struct {
+			name   string
+			filter audit.Filter
+			want   string
+		}{
+			{"aggregate type", audit.Filter{AggregateType: audit.Portfolio}, "[1 3 4]"},
+			{"aggregate", audit.Filter{AggregateType: audit.Portfolio, AggregateID: "p1"}, "[1 4]"},
+			{"actor", audit.Filter{Actor: "bob"}, "[2 4]"},
+			{"time range", audit.Filter{From: day(2024, 6, 4), To: day(2024, 6, 5)}, "[2 3]"},
+			{"all of them", audit.Filter{AggregateType: audit.Portfolio, Actor: "alice", From: day(2024, 6, 4)}, "[3]"},
+			{"nothing", audit.Filter{Actor: "carol"}, "[]"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := log.Find(tt.filter)
+				if err != nil || found == nil || sequences(found) != tt.want {
+					t.Errorf("Find(%+v) = %s, %v, want %s", tt.filter, sequences(found), err, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		log := newLog(t)
+		e := auditEntry(t, "alice", audit.Portfolio, "p1", day(2024, 6, 3))
+		mustAppendEntry(t, log, e)
+		e.Actor = "mallory"
+
+		found, _ := log.Find(audit.Filter{})
+		found[0].Changes[0].After = "tampered"
+		found, err := log.Find(audit.Filter{})
+		if err != nil || len(found) != 1 || found[0].Actor != "alice" || audit.Verify(found) != nil {
+			t.Errorf("Find() = %+v, %v, want the entry as appended", found, err)
+		}
+	})
+
+	t.Run("ConcurrentAppends", func(t *testing.T) {
+		log := newLog(t)
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				e := auditEntry(t, fmt.Sprintf("writer%d", i), audit.Portfolio, "p1", day(2024, 6, 3))
+				if err := log.Append(e); err != nil {
+					t.Errorf("Append() error = %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		all, err := log.Find(audit.Filter{})
+		if err != nil || len(all) != concurrentWriters {
+			t.Fatalf("Find() = %s, %v, want %d entries", sequences(all), err, concurrentWriters)
+		}
+		if err := audit.Verify(all); err != nil {
+			t.Errorf("Verify(Find()) error = %v, want one unbroken chain", err)
+		}
+	})
+}
+
+// auditEntry returns an unsealed entry recording a change of score by actor.
+func auditEntry(t *testing.T, actor string, aggregateType audit.AggregateType, id string, at time.Time) *audit.Entry {
+	t.Helper()
+	endpoint := "POST /company/create"
+	if aggregateType == audit.Portfolio {
+		endpoint = "POST /portfolio/cash/deposit"
+	}
+	type scored struct{ Score float64 }
+	e, err := audit.NewEntry(audit.Source{Actor: actor, Endpoint: endpoint}, aggregateType, id, 2, scored{Score: 0.5}, scored{Score: 80}, at)
+	if err != nil {
+		t.Fatalf("NewEntry() error = %v", err)
+	}
+	return e
+}
+
+func mustAppendEntry(t *testing.T, log audit.Log, e *audit.Entry) {
+	t.Helper()
+	if err := log.Append(e); err != nil {
+		t.Fatalf("Append(%s %s) error = %v", e.AggregateType, e.AggregateID, err)
+	}
+}
+
+func sequences(entries []*audit.Entry) string {
+	s := make([]int, len(entries))
+	for i, e := range entries {
+		s[i] = e.Sequence
+	}
+	return fmt.Sprint(s)
+}
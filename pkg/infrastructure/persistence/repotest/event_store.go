@@ -0,0 +1,235 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// EventStoreFactory returns an empty portfolio event store for one test.
+type EventStoreFactory func(t *testing.T) portfolio.EventStore
+
+// TestPortfolioEventStore runs the EventStore contract against stores from newStore.
+func TestPortfolioEventStore(t *testing.T, newStore EventStoreFactory) {
+	t.Run("AppendAndLoad", func(t *testing.T) {
+		store := newStore(t)
+		events, states := portfolioEvents(t, "p1", 2)
+		for _, e := range events {
+			mustAppend(t, store, e, nil)
+		}
+
+		snapshot, loaded, err := store.Load("p1", time.Time{})
+		if err != nil || snapshot != nil || len(loaded) != 2 {
+			t.Fatalf("Load() = %v, %d events, %v, want the 2 events without a snapshot", snapshot, len(loaded), err)
+		}
+		if loaded[0].Type != portfolio.PortfolioCreated || loaded[1].Type != portfolio.CashDeposited ||
+			!loaded[1].RecordedAt.Equal(events[1].RecordedAt) || len(loaded[1].Entries) != 1 || loaded[1].Cash["USD"] != states[1].CashBalance {
+			t.Errorf("Load() events = %+v, want the events as appended", loaded)
+		}
+		p, err := portfolio.Replay(snapshot, loaded)
+		if err != nil || p == nil || p.Version != 2 || p.CashBalance != states[1].CashBalance || p.Holdings["AAPL"].Shares != 10 || len(p.Ledger) != len(states[1].Ledger) {
+			t.Errorf("Replay(Load()) = %+v, %v, want p1 at version 2", p, err)
+		}
+
+		all, err := store.Events("p1")
+		if err != nil || len(all) != 2 || all[0].Version != 1 || all[1].Version != 2 {
+			t.Errorf("Events() = %+v, %v, want versions 1 and 2", all, err)
+		}
+	})
+
+	t.Run("LoadMissing", func(t *testing.T) {
+		store := newStore(t)
+		if snapshot, events, err := store.Load("p1", time.Time{}); err != nil || snapshot != nil || len(events) != 0 {
+			t.Errorf("Load(missing) = %v, %v, %v, want nothing", snapshot, events, err)
+		}
+		if events, err := store.Events("p1"); err != nil || events == nil || len(events) != 0 {
+			t.Errorf("Events(missing) = %#v, %v, want an empty slice", events, err)
+		}
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		store := newStore(t)
+		events, _ := portfolioEvents(t, "p1", 3)
+		mustAppend(t, store, events[0], nil)
+		mustAppend(t, store, events[1], nil)
+
+		var conflict *portfolio.VersionConflictError
+		if err := store.Append(events[1], nil); !errors.As(err, &conflict) || conflict.CurrentVersion != 2 {
+			t.Errorf("Append(version 2 again) error = %v, want a VersionConflictError at version 2", err)
+		}
+		skipping := events[2]
+		skipping.Version = 4
+		if err := store.Append(skipping, nil); !errors.Is(err, portfolio.ErrVersionConflict) {
+			t.Errorf("Append(version 4) error = %v, want ErrVersionConflict", err)
+		}
+		other := events[1]
+		other.PortfolioID = "p2"
+		if err := store.Append(other, nil); !errors.As(err, &conflict) || conflict.CurrentVersion != 0 {
+			t.Errorf("Append(version 2 of a new stream) error = %v, want a VersionConflictError at version 0", err)
+		}
+		if all, err := store.Events("p1"); err != nil || len(all) != 2 {
+			t.Errorf("Events() = %d events, %v, want the 2 appended", len(all), err)
+		}
+	})
+
+	t.Run("Snapshots", func(t *testing.T) {
+		store := newStore(t)
+		events, states := portfolioEvents(t, "p1", 5)
+		for i, e := range events {
+			var snapshot *portfolio.Portfolio
+			if e.Version%2 == 0 {
+				snapshot = states[i]
+			}
+			mustAppend(t, store, e, snapshot)
+		}
+
+		tests := []struct {
+			name         string
+			at           time.Time
+			wantSnapshot int // Version of the snapshot, 0 for none
+			wantEvents   string
+		}{
+			{"Latest", time.Time{}, 4, "[5]"},
+			{"AtAnEvent", events[2].RecordedAt, 2, "[3]"},
+			{"BetweenEvents", events[3].RecordedAt.Add(time.Minute), 4, "[]"},
+			{"BeforeTheFirstSnapshot", events[0].RecordedAt, 0, "[1]"},
+			{"BeforeTheStream", events[0].RecordedAt.Add(-time.Minute), 0, "[]"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				snapshot, loaded, err := store.Load("p1", tt.at)
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				gotSnapshot := 0
+				if snapshot != nil {
+					gotSnapshot = snapshot.Version
+				}
+				if gotSnapshot != tt.wantSnapshot || versions(loaded) != tt.wantEvents {
+					t.Errorf("Load() = snapshot %d and events %s, want snapshot %d and events %s", gotSnapshot, versions(loaded), tt.wantSnapshot, tt.wantEvents)
+				}
+				if snapshot != nil && (snapshot.CashBalance != states[snapshot.Version-1].CashBalance || len(snapshot.Ledger) != len(states[snapshot.Version-1].Ledger)) {
+					t.Errorf("Load() snapshot = %+v, want the state at version %d", snapshot, snapshot.Version)
+				}
+			})
+		}
+	})
+
+	t.Run("StreamIDs", func(t *testing.T) {
+		store := newStore(t)
+		if ids, err := store.StreamIDs(); err != nil || ids == nil || len(ids) != 0 {
+			t.Errorf("StreamIDs(empty) = %#v, %v, want an empty slice", ids, err)
+		}
+		for _, id := range []string{"p3", "p1", "p2"} {
+			events, _ := portfolioEvents(t, id, 1)
+			mustAppend(t, store, events[0], nil)
+		}
+		if ids, err := store.StreamIDs(); err != nil || fmt.Sprint(ids) != "[p1 p2 p3]" {
+			t.Errorf("StreamIDs() = %v, %v, want [p1 p2 p3]", ids, err)
+		}
+	})
+
+	t.Run("CopyIsolation", func(t *testing.T) {
+		store := newStore(t)
+		events, states := portfolioEvents(t, "p1", 2)
+		mustAppend(t, store, events[0], nil)
+		mustAppend(t, store, events[1], states[1])
+
+		// Neither what was appended nor what was loaded shares state with the store.
+		events[1].Entries[0].Amount = usd(1)
+		states[1].Holdings["MSFT"] = portfolio.Position{CompanyTicker: "MSFT", Shares: 1}
+		snapshot, _, _ := store.Load("p1", time.Time{})
+		snapshot.Ledger[0].Amount = usd(1)
+		loaded, _ := store.Events("p1")
+		loaded[1].Cash["USD"] = usd(1)
+
+		snapshot, _, err := store.Load("p1", time.Time{})
+		if err != nil || snapshot == nil || len(snapshot.Holdings) != 1 || snapshot.Ledger[0].Amount != usd(1000000) {
+			t.Errorf("Load() after changing copies = %+v, %v, want the snapshot as appended", snapshot, err)
+		}
+		if again, err := store.Events("p1"); err != nil || again[1].Entries[0].Amount != usd(5000) || again[1].Cash["USD"] == usd(1) {
+			t.Errorf("Events() after changing copies = %+v, %v, want the events as appended", again, err)
+		}
+	})
+
+	t.Run("ConcurrentAppends", func(t *testing.T) {
+		store := newStore(t)
+		events, _ := portfolioEvents(t, "shared", 2)
+		mustAppend(t, store, events[0], nil)
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				switch err := store.Append(events[1], nil); {
+				case err == nil:
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				case !errors.Is(err, portfolio.ErrVersionConflict):
+					t.Errorf("Append() error = %v, want nil or ErrVersionConflict", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if succeeded != 1 {
+			t.Errorf("%d concurrent appends of version 2 succeeded, want 1", succeeded)
+		}
+		if all, err := store.Events("shared"); err != nil || versions(all) != "[1 2]" {
+			t.Errorf("Events() = %s, %v, want [1 2]", versions(all), err)
+		}
+	})
+}
+
+// portfolioEvents returns the first n events of a portfolio built by newPortfolio, then changed
+// by a deposit of 50.00 per event, one hour apart, and its state after each of them.
+func portfolioEvents(t *testing.T, id string, n int) ([]portfolio.Event, []*portfolio.Portfolio) {
+	t.Helper()
+	p := newPortfolio(t, id, portfolio.Moderate)
+	var (
+		events []portfolio.Event
+		states []*portfolio.Portfolio
+		before *portfolio.Portfolio
+	)
+	for version := 1; version <= n; version++ {
+		if version > 1 {
+			if err := p.Deposit(usd(5000), day(2024, 6, version), "Savings"); err != nil {
+				t.Fatalf("Deposit() error = %v", err)
+			}
+		}
+		e, err := portfolio.NewEvent(before, p, version, day(2024, 6, 3).Add(time.Duration(version)*time.Hour))
+		if err != nil {
+			t.Fatalf("NewEvent() error = %v", err)
+		}
+		p.Version = version
+		events = append(events, e)
+		states = append(states, p.Clone())
+		before = p.Clone()
+	}
+	return events, states
+}
+
+func mustAppend(t *testing.T, store portfolio.EventStore, e portfolio.Event, snapshot *portfolio.Portfolio) {
+	t.Helper()
+	if err := store.Append(e, snapshot); err != nil {
+		t.Fatalf("Append(%s version %d) error = %v", e.PortfolioID, e.Version, err)
+	}
+}
+
+// versions lists the versions of events, in order, for comparisons and messages.
+func versions(events []portfolio.Event) string {
+	list := make([]int, len(events))
+	for i, e := range events {
+		list[i] = e.Version
+	}
+	return fmt.Sprint(list)
+}
@@ -0,0 +1,620 @@
+// Package repotest is the contract test suite of the repository interfaces. Every storage
+// backend runs it against its own implementations, so that the application services can rely
+// on the same behaviour whichever backend is configured.
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// CompanyRepositoryFactory returns an empty company repository for one test.
+type CompanyRepositoryFactory func(t *testing.T) company.CompanyRepository
+
+// PortfolioRepositoryFactory returns empty company and portfolio repositories for one test;
+// the portfolio repository resolves sectors through the company one.
+type PortfolioRepositoryFactory func(t *testing.T) (company.CompanyRepository, portfolio.PortfolioRepository)
+
+// SectorSearcher is implemented by portfolio repositories that can search by the sector of
+// the companies held. TestPortfolioRepository checks the search of those that do.
+type SectorSearcher interface {
+	SearchBySector(sector company.Sector) ([]*portfolio.Portfolio, error)
+}
+
+// concurrentWriters is the number of goroutines saving at once in the concurrency tests.
+const concurrentWriters = 8
+
+// TestCompanyRepository runs the CompanyRepository contract against repositories from newRepo.
+func TestCompanyRepository(t *testing.T, newRepo CompanyRepositoryFactory) {
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		aapl := newCompany(t, "AAPL", company.Technology, 70)
+		if _, err := aapl.DeclareDividend(day(2024, 2, 9), day(2024, 2, 15), 24, "USD"); err != nil {
+			t.Fatalf("DeclareDividend() error = %v", err)
+		}
+		mustSaveCompany(t, repo, aapl)
+
+		found, err := repo.FindByTicker("AAPL")
+		if err != nil {
+			t.Fatalf("FindByTicker() error = %v", err)
+		}
+		if found.Ticker != "AAPL" || found.Sector != company.Technology || found.CurrentScore != 70 ||
+			found.FinancialMetrics.PERatio != 15 || len(found.Dividends) != 1 || found.Dividends[0].AmountPerShare != 24 {
+			t.Errorf("FindByTicker() = %+v, want AAPL as saved", found)
+		}
+	})
+
+	t.Run("SaveReplaces", func(t *testing.T) {
+		repo := newRepo(t)
+		aapl := newCompany(t, "AAPL", company.Technology, 70)
+		mustSaveCompany(t, repo, aapl)
+		aapl.CurrentScore = 85
+		mustSaveCompany(t, repo, aapl)
+
+		all, err := repo.FindAll()
+		if err != nil || len(all) != 1 || all[0].CurrentScore != 85 {
+			t.Errorf("FindAll() = %v, %v, want AAPL once with the new score", all, err)
+		}
+	})
+
+	t.Run("SaveInvalid", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Save(nil); err == nil {
+			t.Errorf("Save(nil) error = nil, want an error")
+		}
+		if err := repo.Save(&company.Company{}); err == nil {
+			t.Errorf("Save(no ticker) error = nil, want an error")
+		}
+	})
+
+	t.Run("FindMissing", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveCompany(t, repo, newCompany(t, "AAPL", company.Technology, 70))
+
+		c, err := repo.FindByTicker("MSFT")
+		if c != nil || !errors.Is(err, company.ErrCompanyNotFound) {
+			t.Errorf("FindByTicker(missing) = %v, %v, want nil and ErrCompanyNotFound", c, err)
+		}
+		if _, err := repo.FindByTicker(""); err == nil || errors.Is(err, company.ErrCompanyNotFound) {
+			t.Errorf("FindByTicker(\"\") error = %v, want a validation error", err)
+		}
+	})
+
+	t.Run("FindAllOrdered", func(t *testing.T) {
+		repo := newRepo(t)
+		if all, err := repo.FindAll(); err != nil || all == nil || len(all) != 0 {
+			t.Errorf("FindAll(empty) = %#v, %v, want an empty slice", all, err)
+		}
+		for _, ticker := range []string{"XOM", "AAPL", "MSFT"} {
+			mustSaveCompany(t, repo, newCompany(t, ticker, company.Technology, 50))
+		}
+		if all, err := repo.FindAll(); err != nil || tickers(all) != "[AAPL MSFT XOM]" {
+			t.Errorf("FindAll() = %s, %v, want [AAPL MSFT XOM]", tickers(all), err)
+		}
+	})
+
+	t.Run("SearchByScoreRange", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveCompany(t, repo, newCompany(t, "AAPL", company.Technology, 70))
+		mustSaveCompany(t, repo, newCompany(t, "MSFT", company.Technology, 80))
+		mustSaveCompany(t, repo, newCompany(t, "XOM", company.Energy, 40))
+
+		tests := []struct {
+			name     string
+			min, max float64
+			want     string
+		}{
+			{"BoundsIncluded", 40, 70, "[AAPL XOM]"},
+			{"SinglePoint", 80, 80, "[MSFT]"},
+			{"JustOutside", 70.001, 79.999, "[]"},
+			{"All", 0, 100, "[AAPL MSFT XOM]"},
+		}
+		for _, tt := range tests {
+			found, err := repo.SearchByScoreRange(tt.min, tt.max)
+			if err != nil || found == nil || tickers(found) != tt.want {
+				t.Errorf("%s: SearchByScoreRange(%v, %v) = %s, %v, want %s", tt.name, tt.min, tt.max, tickers(found), err, tt.want)
+			}
+		}
+		if _, err := repo.SearchByScoreRange(100, 60); err == nil {
+			t.Errorf("SearchByScoreRange(100, 60) error = nil, want an error")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveCompany(t, repo, newCompany(t, "AAPL", company.Technology, 70))
+		mustSaveCompany(t, repo, newCompany(t, "XOM", company.Energy, 40))
+		if err := repo.Delete("AAPL"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if c, err := repo.FindByTicker("AAPL"); c != nil || !errors.Is(err, company.ErrCompanyNotFound) {
+			t.Errorf("FindByTicker(deleted) = %v, %v, want nil and ErrCompanyNotFound", c, err)
+		}
+		if all, err := repo.FindAll(); err != nil || tickers(all) != "[XOM]" {
+			t.Errorf("FindAll() = %s, %v, want [XOM]", tickers(all), err)
+		}
+	})
+
+	t.Run("DeleteMissing", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Delete("AAPL"); !errors.Is(err, company.ErrCompanyNotFound) {
+			t.Errorf("Delete(missing) error = %v, want ErrCompanyNotFound", err)
+		}
+	})
+
+	t.Run("Versions", func(t *testing.T) {
+		repo := newRepo(t)
+		aapl := newCompany(t, "AAPL", company.Technology, 70)
+		mustSaveCompany(t, repo, aapl)
+		mustSaveCompany(t, repo, aapl)
+		if aapl.Version != 2 {
+			t.Errorf("Version after two saves = %d, want 2", aapl.Version)
+		}
+		if found, err := repo.FindByTicker("AAPL"); err != nil || found.Version != 2 {
+			t.Errorf("FindByTicker() = %+v, %v, want version 2", found, err)
+		}
+	})
+
+	t.Run("StaleSave", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveCompany(t, repo, newCompany(t, "AAPL", company.Technology, 70))
+		first, _ := repo.FindByTicker("AAPL")
+		second, _ := repo.FindByTicker("AAPL")
+		first.CurrentScore = 80
+		mustSaveCompany(t, repo, first)
+
+		second.CurrentScore = 90
+		err := repo.Save(second)
+		var conflict *company.VersionConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, company.ErrVersionConflict) || conflict.CurrentVersion != 2 || conflict.Version != 1 {
+			t.Fatalf("Save(stale) error = %v, want a VersionConflictError at version 2", err)
+		}
+		if second.Version != 1 {
+			t.Errorf("Version after a rejected save = %d, want 1", second.Version)
+		}
+		if found, err := repo.FindByTicker("AAPL"); err != nil || found.CurrentScore != 80 {
+			t.Errorf("FindByTicker() = %+v, %v, want the first write", found, err)
+		}
+
+		// Creating a company that exists, or saving one that was deleted, is stale too.
+		if err := repo.Save(newCompany(t, "AAPL", company.Technology, 10)); !errors.Is(err, company.ErrVersionConflict) {
+			t.Errorf("Save(duplicate) error = %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete("AAPL"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if err := repo.Save(first); !errors.As(err, &conflict) || conflict.CurrentVersion != 0 {
+			t.Errorf("Save(deleted) error = %v, want a VersionConflictError at version 0", err)
+		}
+	})
+
+	t.Run("ConcurrentSaves", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveCompany(t, repo, newCompany(t, "SHARED", company.Energy, 50))
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Every writer saves its own company and updates the shared one from version 1.
+				if err := repo.Save(newCompany(t, fmt.Sprintf("T%02d", i), company.Technology, float64(i))); err != nil {
+					t.Errorf("Save(T%02d) error = %v", i, err)
+				}
+				shared := newCompany(t, "SHARED", company.Energy, float64(i))
+				shared.Version = 1
+				switch err := repo.Save(shared); {
+				case err == nil:
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				case !errors.Is(err, company.ErrVersionConflict):
+					t.Errorf("Save(SHARED) error = %v, want nil or ErrVersionConflict", err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if succeeded != 1 {
+			t.Errorf("%d concurrent updates of version 1 succeeded, want 1", succeeded)
+		}
+		all, err := repo.FindAll()
+		if err != nil || len(all) != concurrentWriters+1 {
+			t.Fatalf("FindAll() = %s, %v, want %d companies", tickers(all), err, concurrentWriters+1)
+		}
+		shared, err := repo.FindByTicker("SHARED")
+		if err != nil || shared.Version != 2 || shared.CurrentScore < 0 || shared.CurrentScore >= concurrentWriters {
+			t.Errorf("FindByTicker(SHARED) = %+v, %v, want one of the writes at version 2", shared, err)
+		}
+	})
+
+	t.Run("MetricsRevisions", func(t *testing.T) {
+		repo := newRepo(t)
+		aapl, _ := company.NewCompany("AAPL", company.FinancialMetrics{}, company.Technology)
+		aapl.CurrentScore = 70
+		for _, r := range []struct {
+			pe                 float64
+			periodEnd, knownAt time.Time
+		}{
+			{12, day(2024, 3, 31), day(2024, 4, 25)},
+			{20, day(2024, 6, 30), day(2024, 7, 25)},
+			{18, day(2024, 3, 31), day(2024, 6, 10)}, // A restatement of Q1, recorded late
+		} {
+			if err := aapl.ReviseFinancialMetrics(company.FinancialMetrics{PERatio: r.pe}, r.periodEnd, r.knownAt); err != nil {
+				t.Fatalf("ReviseFinancialMetrics() error = %v", err)
+			}
+		}
+		mustSaveCompany(t, repo, aapl)
+		mustSaveCompany(t, repo, newCompany(t, "MSFT", company.Technology, 80)) // Known only since its creation
+
+		found, err := repo.FindByTicker("AAPL")
+		if err != nil || len(found.MetricsRevisions) != 3 || found.FinancialMetrics.PERatio != 20 ||
+			found.MetricsRevisions[1].Metrics.PERatio != 18 || !found.MetricsRevisions[1].KnownAt.Equal(day(2024, 6, 10)) {
+			t.Fatalf("FindByTicker() = %+v, %v, want AAPL with its revisions as saved", found, err)
+		}
+
+		// As known in May, AAPL had only its first Q1 figures and MSFT was unknown.
+		past := company.KnownAt(repo, day(2024, 5, 1))
+		if c, err := past.FindByTicker("AAPL"); err != nil || c.FinancialMetrics.PERatio != 12 || c.CurrentScore != 70 {
+			t.Errorf("KnownAt(May).FindByTicker(AAPL) = %+v, %v, want Q1's first P/E of 12", c, err)
+		}
+		if c, err := company.KnownAt(repo, day(2024, 6, 15)).FindByTicker("AAPL"); err != nil || c.FinancialMetrics.PERatio != 18 {
+			t.Errorf("KnownAt(June).FindByTicker(AAPL) = %+v, %v, want the restated P/E of 18", c, err)
+		}
+		if _, err := past.FindByTicker("MSFT"); !errors.Is(err, company.ErrCompanyNotFound) {
+			t.Errorf("KnownAt(May).FindByTicker(MSFT) error = %v, want ErrCompanyNotFound", err)
+		}
+		if all, err := past.FindAll(); err != nil || len(all) != 1 || all[0].Ticker != "AAPL" {
+			t.Errorf("KnownAt(May).FindAll() = %v, %v, want AAPL alone", all, err)
+		}
+		if err := past.Save(found); err == nil {
+			t.Error("KnownAt(May).Save() expected error, got nil")
+		}
+	})
+
+	t.Run("CopyIsolation", func(t *testing.T) {
+		repo := newRepo(t)
+		aapl := newCompany(t, "AAPL", company.Technology, 70)
+		if _, err := aapl.DeclareDividend(day(2024, 2, 9), day(2024, 2, 15), 24, "USD"); err != nil {
+			t.Fatalf("DeclareDividend() error = %v", err)
+		}
+		mustSaveCompany(t, repo, aapl)
+
+		// Neither the saved company nor the ones found share state with the repository.
+		aapl.CurrentScore = 10
+		aapl.Dividends[0].AmountPerShare = 1
+		found, _ := repo.FindByTicker("AAPL")
+		found.CurrentScore = 20
+		found.Dividends[0].AmountPerShare = 2
+		listed, _ := repo.FindAll()
+		listed[0].FinancialMetrics.PERatio = 99
+
+		again, err := repo.FindByTicker("AAPL")
+		if err != nil || again.CurrentScore != 70 || again.Dividends[0].AmountPerShare != 24 || again.FinancialMetrics.PERatio != 15 {
+			t.Errorf("FindByTicker() after changing copies = %+v, %v, want AAPL as saved", again, err)
+		}
+	})
+}
+
+// TestPortfolioRepository runs the PortfolioRepository contract against repositories from
+// newRepos.
+func TestPortfolioRepository(t *testing.T, newRepos PortfolioRepositoryFactory) {
+	t.Run("SaveAndFind", func(t *testing.T) {
+		_, repo := newRepos(t)
+		p := newPortfolio(t, "p1", portfolio.Moderate)
+		p.ForeignCash["EUR"] = portfolio.Money{Amount: 2500, Currency: "EUR"}
+		mustSavePortfolio(t, repo, p)
+
+		found, err := repo.FindByID("p1")
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		if found.ID != "p1" || found.RiskProfile != portfolio.Moderate || found.CashBalance != p.CashBalance ||
+			found.Holdings["AAPL"].Shares != 10 || found.ForeignCash["EUR"].Amount != 2500 || len(found.Ledger) != len(p.Ledger) {
+			t.Errorf("FindByID() = %+v, want p1 as saved", found)
+		}
+	})
+
+	t.Run("SaveReplaces", func(t *testing.T) {
+		_, repo := newRepos(t)
+		p := newPortfolio(t, "p1", portfolio.Moderate)
+		mustSavePortfolio(t, repo, p)
+		if err := p.RemovePosition("AAPL", 10, usd(110000)); err != nil {
+			t.Fatalf("RemovePosition() error = %v", err)
+		}
+		mustSavePortfolio(t, repo, p)
+
+		found, err := repo.FindByID("p1")
+		if err != nil || len(found.Holdings) != 0 || found.CashBalance != usd(1010000) {
+			t.Errorf("FindByID() = %+v, %v, want p1 without holdings", found, err)
+		}
+	})
+
+	t.Run("SaveInvalid", func(t *testing.T) {
+		_, repo := newRepos(t)
+		if err := repo.Save(nil); err == nil {
+			t.Errorf("Save(nil) error = nil, want an error")
+		}
+		if err := repo.Save(&portfolio.Portfolio{}); err == nil {
+			t.Errorf("Save(no ID) error = nil, want an error")
+		}
+	})
+
+	t.Run("FindMissing", func(t *testing.T) {
+		_, repo := newRepos(t)
+		mustSavePortfolio(t, repo, newPortfolio(t, "p1", portfolio.Moderate))
+
+		p, err := repo.FindByID("p2")
+		if p != nil || !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+			t.Errorf("FindByID(missing) = %v, %v, want nil and ErrPortfolioNotFound", p, err)
+		}
+		if _, err := repo.FindByID(""); err == nil || errors.Is(err, portfolio.ErrPortfolioNotFound) {
+			t.Errorf("FindByID(\"\") error = %v, want a validation error", err)
+		}
+	})
+
+	t.Run("FindAllOrdered", func(t *testing.T) {
+		_, repo := newRepos(t)
+		if all, err := repo.FindAll(); err != nil || all == nil || len(all) != 0 {
+			t.Errorf("FindAll(empty) = %#v, %v, want an empty slice", all, err)
+		}
+		for _, id := range []string{"p3", "p1", "p2"} {
+			mustSavePortfolio(t, repo, newPortfolio(t, id, portfolio.Moderate))
+		}
+		if all, err := repo.FindAll(); err != nil || ids(all) != "[p1 p2 p3]" {
+			t.Errorf("FindAll() = %s, %v, want [p1 p2 p3]", ids(all), err)
+		}
+	})
+
+	t.Run("SearchByRiskProfile", func(t *testing.T) {
+		_, repo := newRepos(t)
+		mustSavePortfolio(t, repo, newPortfolio(t, "p3", portfolio.Aggressive))
+		mustSavePortfolio(t, repo, newPortfolio(t, "p1", portfolio.Aggressive))
+		mustSavePortfolio(t, repo, newPortfolio(t, "p2", portfolio.Moderate))
+
+		tests := []struct {
+			profile portfolio.RiskProfile
+			want    string
+		}{
+			{portfolio.Aggressive, "[p1 p3]"},
+			{portfolio.Moderate, "[p2]"},
+			{portfolio.Conservative, "[]"},
+		}
+		for _, tt := range tests {
+			found, err := repo.SearchByRiskProfile(tt.profile)
+			if err != nil || found == nil || ids(found) != tt.want {
+				t.Errorf("SearchByRiskProfile(%v) = %s, %v, want %s", tt.profile, ids(found), err, tt.want)
+			}
+		}
+	})
+
+	t.Run("SearchBySector", func(t *testing.T) {
+		companies, repo := newRepos(t)
+		searcher, ok := repo.(SectorSearcher)
+		if !ok {
+			t.Skip("repository cannot search by sector")
+		}
+		mustSaveCompany(t, companies, newCompany(t, "AAPL", company.Technology, 70))
+		mustSavePortfolio(t, repo, newPortfolio(t, "p2", portfolio.Moderate))
+		mustSavePortfolio(t, repo, newPortfolio(t, "p1", portfolio.Moderate))
+		mustSavePortfolio(t, repo, &portfolio.Portfolio{ID: "p3", BaseCurrency: "USD", Holdings: map[string]portfolio.Position{}})
+
+		if tech, err := searcher.SearchBySector(company.Technology); err != nil || ids(tech) != "[p1 p2]" {
+			t.Errorf("SearchBySector(Technology) = %s, %v, want [p1 p2]", ids(tech), err)
+		}
+		if energy, err := searcher.SearchBySector(company.Energy); err != nil || energy == nil || len(energy) != 0 {
+			t.Errorf("SearchBySector(Energy) = %#v, %v, want an empty slice", energy, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, repo := newRepos(t)
+		mustSavePortfolio(t, repo, newPortfolio(t, "p1", portfolio.Moderate))
+		mustSavePortfolio(t, repo, newPortfolio(t, "p2", portfolio.Moderate))
+		if err := repo.Delete("p1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if p, err := repo.FindByID("p1"); p != nil || !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+			t.Errorf("FindByID(deleted) = %v, %v, want nil and ErrPortfolioNotFound", p, err)
+		}
+		if all, err := repo.FindAll(); err != nil || ids(all) != "[p2]" {
+			t.Errorf("FindAll() = %s, %v, want [p2]", ids(all), err)
+		}
+	})
+
+	t.Run("DeleteMissing", func(t *testing.T) {
+		_, repo := newRepos(t)
+		if err := repo.Delete("p1"); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+			t.Errorf("Delete(missing) error = %v, want ErrPortfolioNotFound", err)
+		}
+	})
+
+	t.Run("Versions", func(t *testing.T) {
+		_, repo := newRepos(t)
+		p := newPortfolio(t, "p1", portfolio.Moderate)
+		mustSavePortfolio(t, repo, p)
+		mustSavePortfolio(t, repo, p)
+		if p.Version != 2 {
+			t.Errorf("Version after two saves = %d, want 2", p.Version)
+		}
+		if found, err := repo.FindByID("p1"); err != nil || found.Version != 2 {
+			t.Errorf("FindByID() = %+v, %v, want version 2", found, err)
+		}
+	})
+
+	t.Run("StaleSave", func(t *testing.T) {
+		_, repo := newRepos(t)
+		mustSavePortfolio(t, repo, newPortfolio(t, "p1", portfolio.Moderate))
+		first, _ := repo.FindByID("p1")
+		second, _ := repo.FindByID("p1")
+		if err := first.Deposit(usd(5000), day(2024, 3, 1), "Bonus"); err != nil {
+			t.Fatalf("Deposit() error = %v", err)
+		}
+		mustSavePortfolio(t, repo, first)
+
+		if err := second.RemovePosition("AAPL", 10, usd(110000)); err != nil {
+			t.Fatalf("RemovePosition() error = %v", err)
+		}
+		err := repo.Save(second)
+		var conflict *portfolio.VersionConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, portfolio.ErrVersionConflict) || conflict.CurrentVersion != 2 || conflict.Version != 1 {
+			t.Fatalf("Save(stale) error = %v, want a VersionConflictError at version 2", err)
+		}
+		if second.Version != 1 {
+			t.Errorf("Version after a rejected save = %d, want 1", second.Version)
+		}
+		if found, err := repo.FindByID("p1"); err != nil || found.CashBalance != usd(905000) || found.Holdings["AAPL"].Shares != 10 {
+			t.Errorf("FindByID() = %+v, %v, want the first write", found, err)
+		}
+
+		// Creating a portfolio that exists, or saving one that was deleted, is stale too.
+		if err := repo.Save(newPortfolio(t, "p1", portfolio.Aggressive)); !errors.Is(err, portfolio.ErrVersionConflict) {
+			t.Errorf("Save(duplicate) error = %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete("p1"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if err := repo.Save(first); !errors.As(err, &conflict) || conflict.CurrentVersion != 0 {
+			t.Errorf("Save(deleted) error = %v, want a VersionConflictError at version 0", err)
+		}
+	})
+
+	t.Run("ConcurrentSaves", func(t *testing.T) {
+		_, repo := newRepos(t)
+		mustSavePortfolio(t, repo, newPortfolio(t, "shared", portfolio.Moderate))
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Every writer saves its own portfolio and updates the shared one from version 1.
+				if err := repo.Save(newPortfolio(t, fmt.Sprintf("p%02d", i), portfolio.Moderate)); err != nil {
+					t.Errorf("Save(p%02d) error = %v", i, err)
+				}
+				shared := newPortfolio(t, "shared", portfolio.Aggressive)
+				shared.Version = 1
+				switch err := repo.Save(shared); {
+				case err == nil:
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				case !errors.Is(err, portfolio.ErrVersionConflict):
+					t.Errorf("Save(shared) error = %v, want nil or ErrVersionConflict", err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if succeeded != 1 {
+			t.Errorf("%d concurrent updates of version 1 succeeded, want 1", succeeded)
+		}
+		all, err := repo.FindAll()
+		if err != nil || len(all) != concurrentWriters+1 {
+			t.Fatalf("FindAll() = %s, %v, want %d portfolios", ids(all), err, concurrentWriters+1)
+		}
+		shared, err := repo.FindByID("shared")
+		if err != nil || shared.Version != 2 || shared.RiskProfile != portfolio.Aggressive {
+			t.Errorf("FindByID(shared) = %+v, %v, want the update at version 2", shared, err)
+		}
+	})
+
+	t.Run("CopyIsolation", func(t *testing.T) {
+		_, repo := newRepos(t)
+		p := newPortfolio(t, "p1", portfolio.Moderate)
+		mustSavePortfolio(t, repo, p)
+
+		// Neither the saved portfolio nor the ones found share state with the repository.
+		p.CashBalance = usd(1)
+		p.Holdings["MSFT"] = portfolio.Position{CompanyTicker: "MSFT", Shares: 1}
+		p.Ledger[0].Amount = usd(1)
+		found, _ := repo.FindByID("p1")
+		delete(found.Holdings, "AAPL")
+		found.ForeignCash["EUR"] = portfolio.Money{Amount: 1, Currency: "EUR"}
+		listed, _ := repo.FindAll()
+		listed[0].Holdings["AAPL"] = portfolio.Position{CompanyTicker: "AAPL", Shares: 99}
+
+		again, err := repo.FindByID("p1")
+		if err != nil || again.CashBalance != usd(900000) || len(again.Holdings) != 1 || again.Holdings["AAPL"].Shares != 10 ||
+			len(again.ForeignCash) != 0 || again.Ledger[0].Amount != usd(1000000) {
+			t.Errorf("FindByID() after changing copies = %+v, %v, want p1 as saved", again, err)
+		}
+	})
+}
+
+// newCompany returns a company with a P/E of 15.
+func newCompany(t *testing.T, ticker string, sector company.Sector, score float64) *company.Company {
+	t.Helper()
+	c, err := company.NewCompany(ticker, company.FinancialMetrics{PERatio: 15, PBRatio: 2, DebtToEquity: 0.5}, sector)
+	if err != nil {
+		t.Errorf("NewCompany(%s) error = %v", ticker, err)
+		return nil
+	}
+	c.CurrentScore = score
+	return c
+}
+
+// newPortfolio returns a USD portfolio holding 10 AAPL bought for 1000.00 out of 10000.00.
+func newPortfolio(t *testing.T, id string, profile portfolio.RiskProfile) *portfolio.Portfolio {
+	t.Helper()
+	p, err := portfolio.NewPortfolio(id, profile, usd(1000000))
+	if err != nil {
+		t.Errorf("NewPortfolio(%s) error = %v", id, err)
+		return nil
+	}
+	pos, _ := portfolio.NewPosition("AAPL", 10, usd(10000))
+	if err := p.AddPosition(*pos, usd(100000)); err != nil {
+		t.Errorf("AddPosition() error = %v", err)
+	}
+	return p
+}
+
+func usd(amount int64) portfolio.Money { return portfolio.Money{Amount: amount, Currency: "USD"} }
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func mustSaveCompany(t *testing.T, repo company.CompanyRepository, c *company.Company) {
+	t.Helper()
+	if err := repo.Save(c); err != nil {
+		t.Fatalf("Save(%s) error = %v", c.Ticker, err)
+	}
+}
+
+func mustSavePortfolio(t *testing.T, repo portfolio.PortfolioRepository, p *portfolio.Portfolio) {
+	t.Helper()
+	if err := repo.Save(p); err != nil {
+		t.Fatalf("Save(%s) error = %v", p.ID, err)
+	}
+}
+
+// tickers lists the tickers of companies, in order, for comparisons and messages.
+func tickers(companies []*company.Company) string {
+	list := make([]string, len(companies))
+	for i, c := range companies {
+		list[i] = c.Ticker
+	}
+	return fmt.Sprint(list)
+}
+
+// ids lists the IDs of portfolios, in order, for comparisons and messages.
+func ids(portfolios []*portfolio.Portfolio) string {
+	list := make([]string, len(portfolios))
+	for i, p := range portfolios {
+		list[i] = p.ID
+	}
+	return fmt.Sprint(list)
+}
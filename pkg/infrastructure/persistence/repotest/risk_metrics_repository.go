@@ -0,0 +1,132 @@
+package repotest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// RiskMetricsRepositoryFactory returns an empty risk metrics repository for one test.
+type RiskMetricsRepositoryFactory func(t *testing.T) portfolio.RiskMetricsRepository
+
+// TestRiskMetricsRepository runs the RiskMetricsRepository contract against repositories from
+// newRepo.
+func TestRiskMetricsRepository(t *testing.T, newRepo RiskMetricsRepositoryFactory) {
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		saved := riskMetrics("p1", at(2024, 6, 4, 17), 0.2)
+		mustSaveRiskMetrics(t, repo, saved)
+		mustSaveRiskMetrics(t, repo, riskMetrics("p1", at(2024, 6, 3, 17), 0.1))
+		mustSaveRiskMetrics(t, repo, riskMetrics("p2", at(2024, 6, 3, 17), 0.3))
+
+		found, err := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		if err != nil || metricsAsOfs(found) != "[2024-06-03 2024-06-04]" {
+			t.Fatalf("FindByPortfolio(p1) = %s, %v, want [2024-06-03 2024-06-04]", metricsAsOfs(found), err)
+		}
+		if m := found[1]; m.PortfolioID != "p1" || !m.AsOf.Equal(saved.AsOf) || m.Benchmark != "SPY" || m.Observations != 60 ||
+			m.Value != usd(1200) || m.Volatility != 0.2 || m.Beta != 1.1 || m.MaxDrawdown != 0.15 ||
+			len(m.VaR) != 2 || m.VaR[0] != saved.VaR[0] || m.VaR[1] != saved.VaR[1] {
+			t.Errorf("FindByPortfolio(p1)[1] = %+v, want the metrics as saved", m)
+		}
+		if found, err := repo.FindByPortfolio("p3", time.Time{}, time.Time{}); err != nil || len(found) != 0 {
+			t.Errorf("FindByPortfolio(p3) = %s, %v, want none", metricsAsOfs(found), err)
+		}
+	})
+
+	t.Run("SameDayReplaced", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveRiskMetrics(t, repo, riskMetrics("p1", at(2024, 6, 3, 12), 0.1))
+		mustSaveRiskMetrics(t, repo, riskMetrics("p1", at(2024, 6, 3, 17), 0.2))
+
+		found, err := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		if err != nil || len(found) != 1 || !found[0].AsOf.Equal(at(2024, 6, 3, 17)) || found[0].Volatility != 0.2 {
+			t.Errorf("FindByPortfolio(p1) = %+v, %v, want the later metrics of the day alone", found, err)
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		repo := newRepo(t)
+		for d := 3; d <= 6; d++ {
+			mustSaveRiskMetrics(t, repo, riskMetrics("p1", at(2024, 6, d, 17), float64(d)/10))
+		}
+
+		tests := []struct {
+			name     string
+			from, to time.Time
+			want     string
+		}{
+			{"closed", at(2024, 6, 4, 0), at(2024, 6, 5, 17), "[2024-06-04 2024-06-05]"},
+			{"open start", time.Time{}, at(2024, 6, 4, 17), "[2024-06-03 2024-06-04]"},
+			{"open end", at(2024, 6, 5, 17), time.Time{}, "[2024-06-05 2024-06-06]"},
+			{"nothing", at(2024, 6, 7, 0), time.Time{}, "[]"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := repo.FindByPortfolio("p1", tt.from, tt.to)
+				if err != nil || metricsAsOfs(found) != tt.want {
+					t.Errorf("FindByPortfolio(p1, %v, %v) = %s, %v, want %s", tt.from, tt.to, metricsAsOfs(found), err, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		repo := newRepo(t)
+		m := riskMetrics("p1", at(2024, 6, 3, 17), 0.2)
+		mustSaveRiskMetrics(t, repo, m)
+		m.VaR[0].Ratio = 0.9
+
+		found, _ := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		found[0].VaR[1].Ratio = 0.9
+		found, err := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		if err != nil || len(found) != 1 || found[0].VaR[0].Ratio != 0.05 || found[0].VaR[1].Ratio != 0.04 {
+			t.Errorf("FindByPortfolio(p1) = %+v, %v, want the metrics as saved", found, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Save(nil); err == nil {
+			t.Error("Save(nil) succeeded, want an error")
+		}
+		if err := repo.Save(riskMetrics("", at(2024, 6, 3, 17), 0.2)); err == nil {
+			t.Error("Save() of metrics without a portfolio succeeded, want an error")
+		}
+	})
+}
+
+// riskMetrics returns the metrics of a portfolio worth 1200 USD with the given volatility, and
+// its value at risk by both methods.
+func riskMetrics(portfolioID string, asOf time.Time, volatility float64) *portfolio.RiskMetrics {
+	return &portfolio.RiskMetrics{
+		PortfolioID:  portfolioID,
+		AsOf:         asOf,
+		Benchmark:    "SPY",
+		Observations: 60,
+		Value:        usd(1200),
+		Volatility:   volatility,
+		Beta:         1.1,
+		MaxDrawdown:  0.15,
+		VaR: []portfolio.ValueAtRisk{
+			{Method: portfolio.Historical, Confidence: 0.99, HorizonDays: 1, Ratio: 0.05, Loss: usd(60)},
+			{Method: portfolio.Parametric, Confidence: 0.99, HorizonDays: 1, Ratio: 0.04, Loss: usd(48)},
+		},
+	}
+}
+
+func mustSaveRiskMetrics(t *testing.T, repo portfolio.RiskMetricsRepository, m *portfolio.RiskMetrics) {
+	t.Helper()
+	if err := repo.Save(m); err != nil {
+		t.Fatalf("Save(%s on %s) error = %v", m.PortfolioID, m.AsOf.Format(time.DateOnly), err)
+	}
+}
+
+func metricsAsOfs(metrics []*portfolio.RiskMetrics) string {
+	s := make([]string, len(metrics))
+	for i, m := range metrics {
+		s[i] = m.AsOf.UTC().Format(time.DateOnly)
+	}
+	return fmt.Sprint(s)
+}
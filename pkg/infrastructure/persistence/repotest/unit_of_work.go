@@ -0,0 +1,221 @@
+package repotest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jizumer/expedition-value/pkg/application"
+	"github.com/jizumer/expedition-value/pkg/domain/company"
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// UnitOfWorkFactory returns a unit of work over empty company and portfolio repositories for
+// one test, and the repositories themselves to watch what it commits.
+type UnitOfWorkFactory func(t *testing.T) (application.UnitOfWork, company.CompanyRepository, portfolio.PortfolioRepository)
+
+// TestUnitOfWork runs the UnitOfWork contract against units of work from newUnit.
+func TestUnitOfWork(t *testing.T, newUnit UnitOfWorkFactory) {
+	testUnitOfWork(t, newUnit, false)
+}
+
+// TestSerialUnitOfWork runs the UnitOfWork contract against units of work from newUnit that
+// hold the only write lock of their database while they work, as bbolt's do. Another writer
+// would wait for such a unit to end, so it is not made to change an aggregate in the middle of
+// one.
+func TestSerialUnitOfWork(t *testing.T, newUnit UnitOfWorkFactory) {
+	testUnitOfWork(t, newUnit, true)
+}
+
+func testUnitOfWork(t *testing.T, newUnit UnitOfWorkFactory, serial bool) {
+	t.Run("Commit", func(t *testing.T) {
+		uow, companies, portfolios := newUnit(t)
+		mustSavePortfolio(t, portfolios, newPortfolio(t, "old", portfolio.Moderate))
+
+		err := uow.Do(func(repos application.Repositories) error {
+			mustSaveCompany(t, repos.Companies, newCompany(t, "AAPL", company.Technology, 80))
+			mustSavePortfolio(t, repos.Portfolios, newPortfolio(t, "p1", portfolio.Moderate))
+			if err := repos.Portfolios.Delete("old"); err != nil {
+				t.Fatalf("Delete(old) error = %v", err)
+			}
+
+			// The unit reads its own writes; nobody else sees them before it commits.
+			if found, err := repos.Portfolios.FindAll(); err != nil || ids(found) != "[p1]" {
+				t.Errorf("FindAll() in the unit = %s, %v, want [p1]", ids(found), err)
+			}
+			if searcher, ok := repos.Portfolios.(SectorSearcher); ok {
+				if found, err := searcher.SearchBySector(company.Technology); err != nil || ids(found) != "[p1]" {
+					t.Errorf("SearchBySector(Technology) in the unit = %s, %v, want [p1]", ids(found), err)
+				}
+			}
+			if _, err := companies.FindByTicker("AAPL"); !errors.Is(err, company.ErrCompanyNotFound) {
+				t.Errorf("FindByTicker(AAPL) outside the unit error = %v, want ErrCompanyNotFound", err)
+			}
+			if found, err := portfolios.FindAll(); err != nil || ids(found) != "[old]" {
+				t.Errorf("FindAll() outside the unit = %s, %v, want [old]", ids(found), err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+
+		if c, err := companies.FindByTicker("AAPL"); err != nil || c.Version != 1 {
+			t.Errorf("FindByTicker(AAPL) = %+v, %v, want version 1", c, err)
+		}
+		if found, err := portfolios.FindAll(); err != nil || ids(found) != "[p1]" || found[0].Version != 1 {
+			t.Errorf("FindAll() = %s, %v, want p1 at version 1", ids(found), err)
+		}
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		uow, companies, portfolios := newUnit(t)
+		mustSavePortfolio(t, portfolios, newPortfolio(t, "old", portfolio.Moderate))
+
+		errFailed := errors.New("failed")
+		err := uow.Do(func(repos application.Repositories) error {
+			mustSaveCompany(t, repos.Companies, newCompany(t, "AAPL", company.Technology, 80))
+			mustSavePortfolio(t, repos.Portfolios, newPortfolio(t, "p1", portfolio.Moderate))
+			if err := repos.Portfolios.Delete("old"); err != nil {
+				t.Fatalf("Delete(old) error = %v", err)
+			}
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("Do() error = %v, want the error of the work", err)
+		}
+
+		if _, err := companies.FindByTicker("AAPL"); !errors.Is(err, company.ErrCompanyNotFound) {
+			t.Errorf("FindByTicker(AAPL) error = %v, want ErrCompanyNotFound", err)
+		}
+		if found, err := portfolios.FindAll(); err != nil || ids(found) != "[old]" || found[0].Version != 1 {
+			t.Errorf("FindAll() = %s, %v, want old alone at version 1", ids(found), err)
+		}
+	})
+
+	t.Run("StaleConflict", func(t *testing.T) {
+		uow, companies, portfolios := newUnit(t)
+		mustSaveCompany(t, companies, newCompany(t, "AAPL", company.Technology, 80))
+		mustSavePortfolio(t, portfolios, newPortfolio(t, "p1", portfolio.Moderate))
+		stale, _ := portfolios.FindByID("p1")
+		other, _ := portfolios.FindByID("p1")
+		other.RiskProfile = portfolio.Aggressive
+		mustSavePortfolio(t, portfolios, other)
+
+		err := uow.Do(func(repos application.Repositories) error {
+			c, err := repos.Companies.FindByTicker("AAPL")
+			if err != nil {
+				t.Fatalf("FindByTicker(AAPL) error = %v", err)
+			}
+			c.CurrentScore = 90
+			mustSaveCompany(t, repos.Companies, c)
+			stale.RiskProfile = portfolio.Conservative
+			return repos.Portfolios.Save(stale)
+		})
+		var conflict *portfolio.VersionConflictError
+		if !errors.As(err, &conflict) || conflict.CurrentVersion != 2 {
+			t.Fatalf("Do() error = %v, want a VersionConflictError at version 2", err)
+		}
+
+		if c, err := companies.FindByTicker("AAPL"); err != nil || c.Version != 1 || c.CurrentScore != 80 {
+			t.Errorf("FindByTicker(AAPL) = %+v, %v, want it unchanged at version 1", c, err)
+		}
+		if p, err := portfolios.FindByID("p1"); err != nil || p.Version != 2 || p.RiskProfile != portfolio.Aggressive {
+			t.Errorf("FindByID(p1) = %+v, %v, want the other writer's change", p, err)
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		if serial {
+			t.Skip("no other writer can change an aggregate while a serial unit works")
+		}
+		uow, companies, portfolios := newUnit(t)
+		mustSaveCompany(t, companies, newCompany(t, "AAPL", company.Technology, 80))
+		mustSavePortfolio(t, portfolios, newPortfolio(t, "p1", portfolio.Moderate))
+
+		err := uow.Do(func(repos application.Repositories) error {
+			c, err := repos.Companies.FindByTicker("AAPL")
+			if err != nil {
+				t.Fatalf("FindByTicker(AAPL) error = %v", err)
+			}
+			c.CurrentScore = 90
+			mustSaveCompany(t, repos.Companies, c)
+			p, err := repos.Portfolios.FindByID("p1")
+			if err != nil {
+				t.Fatalf("FindByID(p1) error = %v", err)
+			}
+
+			// Another writer changes the portfolio while the unit is working.
+			other, _ := portfolios.FindByID("p1")
+			other.RiskProfile = portfolio.Aggressive
+			mustSavePortfolio(t, portfolios, other)
+
+			p.RiskProfile = portfolio.Conservative
+			return repos.Portfolios.Save(p)
+		})
+		var conflict *portfolio.VersionConflictError
+		if !errors.As(err, &conflict) || conflict.CurrentVersion != 2 {
+			t.Fatalf("Do() error = %v, want a VersionConflictError at version 2", err)
+		}
+
+		if c, err := companies.FindByTicker("AAPL"); err != nil || c.Version != 1 || c.CurrentScore != 80 {
+			t.Errorf("FindByTicker(AAPL) = %+v, %v, want it unchanged at version 1", c, err)
+		}
+		if p, err := portfolios.FindByID("p1"); err != nil || p.Version != 2 || p.RiskProfile != portfolio.Aggressive {
+			t.Errorf("FindByID(p1) = %+v, %v, want the other writer's change", p, err)
+		}
+	})
+
+	t.Run("ConcurrentUnits", func(t *testing.T) {
+		uow, companies, portfolios := newUnit(t)
+		mustSaveCompany(t, companies, newCompany(t, "AAPL", company.Technology, 80))
+		p1 := newPortfolio(t, "p1", portfolio.Moderate)
+		mustSavePortfolio(t, portfolios, p1)
+		entries := len(p1.Ledger)
+
+		// Every unit changes both aggregates, so they must end up changed the same number of times.
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentWriters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := uow.Do(func(repos application.Repositories) error {
+					c, err := repos.Companies.FindByTicker("AAPL")
+					if err != nil {
+						return err
+					}
+					c.CurrentScore++
+					if err := repos.Companies.Save(c); err != nil {
+						return err
+					}
+					p, err := repos.Portfolios.FindByID("p1")
+					if err != nil {
+						return err
+					}
+					if err := p.Deposit(usd(100), day(2024, 6, 3), "Savings"); err != nil {
+						return err
+					}
+					return repos.Portfolios.Save(p)
+				})
+				if err != nil && !errors.Is(err, company.ErrVersionConflict) && !errors.Is(err, portfolio.ErrVersionConflict) {
+					t.Errorf("Do() error = %v, want nil or a version conflict", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		c, err := companies.FindByTicker("AAPL")
+		if err != nil {
+			t.Fatalf("FindByTicker(AAPL) error = %v", err)
+		}
+		p, err := portfolios.FindByID("p1")
+		if err != nil {
+			t.Fatalf("FindByID(p1) error = %v", err)
+		}
+		committed := c.Version - 1
+		if committed < 1 || p.Version-1 != committed || int(c.CurrentScore)-80 != committed || len(p.Ledger)-entries != committed {
+			t.Errorf("company at version %d scored %v, portfolio at version %d with %d ledger entries; want both changed by each committed unit",
+				c.Version, c.CurrentScore, p.Version, len(p.Ledger))
+		}
+	})
+}
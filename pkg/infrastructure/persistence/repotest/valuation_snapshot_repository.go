@@ -0,0 +1,137 @@
+package repotest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/domain/portfolio"
+)
+
+// ValuationSnapshotRepositoryFactory returns an empty valuation snapshot repository for one test.
+type ValuationSnapshotRepositoryFactory func(t *testing.T) portfolio.ValuationSnapshotRepository
+
+// TestValuationSnapshotRepository runs the ValuationSnapshotRepository contract against
+// repositories from newRepo.
+func TestValuationSnapshotRepository(t *testing.T, newRepo ValuationSnapshotRepositoryFactory) {
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repo := newRepo(t)
+		saved := valuation("p1", at(2024, 6, 4, 17), 1200)
+		mustSaveSnapshot(t, repo, saved)
+		mustSaveSnapshot(t, repo, valuation("p1", at(2024, 6, 3, 17), 1100))
+		mustSaveSnapshot(t, repo, valuation("p2", at(2024, 6, 3, 17), 500))
+
+		found, err := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		if err != nil || asOfs(found) != "[2024-06-03 2024-06-04]" {
+			t.Fatalf("FindByPortfolio(p1) = %s, %v, want [2024-06-03 2024-06-04]", asOfs(found), err)
+		}
+		if v := found[1]; v.PortfolioID != "p1" || v.BaseCurrency != "USD" || !v.AsOf.Equal(saved.AsOf) ||
+			len(v.Holdings) != 1 || v.Holdings[0] != saved.Holdings[0] || len(v.Cash) != 1 || v.Cash[0] != usd(200) ||
+			v.MarketValue != usd(1000) || v.CashValue != usd(200) || v.TotalValue != usd(1200) {
+			t.Errorf("FindByPortfolio(p1)[1] = %+v, want the snapshot as saved", v)
+		}
+		if found, err := repo.FindByPortfolio("p3", time.Time{}, time.Time{}); err != nil || len(found) != 0 {
+			t.Errorf("FindByPortfolio(p3) = %s, %v, want none", asOfs(found), err)
+		}
+	})
+
+	t.Run("SameDayReplaced", func(t *testing.T) {
+		repo := newRepo(t)
+		mustSaveSnapshot(t, repo, valuation("p1", at(2024, 6, 3, 12), 1100))
+		mustSaveSnapshot(t, repo, valuation("p1", at(2024, 6, 3, 17), 1150))
+
+		found, err := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		if err != nil || len(found) != 1 || !found[0].AsOf.Equal(at(2024, 6, 3, 17)) || found[0].TotalValue != usd(1150) {
+			t.Errorf("FindByPortfolio(p1) = %+v, %v, want the later snapshot of the day alone", found, err)
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		repo := newRepo(t)
+		for d := 3; d <= 6; d++ {
+			mustSaveSnapshot(t, repo, valuation("p1", at(2024, 6, d, 17), int64(1000+d)))
+		}
+
+		tests := []struct {
+			name     string
+			from, to time.Time
+			want     string
+		}{
+			{"closed", at(2024, 6, 4, 0), at(2024, 6, 5, 17), "[2024-06-04 2024-06-05]"},
+			{"open start", time.Time{}, at(2024, 6, 4, 17), "[2024-06-03 2024-06-04]"},
+			{"open end", at(2024, 6, 5, 17), time.Time{}, "[2024-06-05 2024-06-06]"},
+			{"nothing", at(2024, 6, 7, 0), time.Time{}, "[]"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := repo.FindByPortfolio("p1", tt.from, tt.to)
+				if err != nil || asOfs(found) != tt.want {
+					t.Errorf("FindByPortfolio(p1, %v, %v) = %s, %v, want %s", tt.from, tt.to, asOfs(found), err, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		repo := newRepo(t)
+		v := valuation("p1", at(2024, 6, 3, 17), 1200)
+		mustSaveSnapshot(t, repo, v)
+		v.Holdings[0].Shares = 99
+
+		found, _ := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		found[0].Cash[0] = usd(1)
+		found, err := repo.FindByPortfolio("p1", time.Time{}, time.Time{})
+		if err != nil || len(found) != 1 || found[0].Holdings[0].Shares != 10 || found[0].Cash[0] != usd(200) {
+			t.Errorf("FindByPortfolio(p1) = %+v, %v, want the snapshot as saved", found, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Save(nil); err == nil {
+			t.Error("Save(nil) succeeded, want an error")
+		}
+		if err := repo.Save(valuation("", at(2024, 6, 3, 17), 1200)); err == nil {
+			t.Error("Save() of a snapshot without a portfolio succeeded, want an error")
+		}
+	})
+}
+
+// valuation returns a snapshot of a portfolio worth total USD, 200 of them in cash and the rest
+// in 10 shares of AAPL.
+func valuation(portfolioID string, asOf time.Time, total int64) *portfolio.Valuation {
+	market := usd(total - 200)
+	price := usd((total - 200) / 10)
+	return &portfolio.Valuation{
+		PortfolioID:  portfolioID,
+		BaseCurrency: "USD",
+		AsOf:         asOf,
+		Holdings: []portfolio.HoldingValuation{
+			{CompanyTicker: "AAPL", Shares: 10, Price: price, MarketValue: market, FXRate: 1, BaseMarketValue: market},
+		},
+		Cash:        []portfolio.Money{usd(200)},
+		MarketValue: market,
+		CashValue:   usd(200),
+		TotalValue:  usd(total),
+	}
+}
+
+// at returns the given hour of a day, in UTC.
+func at(year int, month time.Month, d, hour int) time.Time {
+	return time.Date(year, month, d, hour, 0, 0, 0, time.UTC)
+}
+
+func mustSaveSnapshot(t *testing.T, repo portfolio.ValuationSnapshotRepository, v *portfolio.Valuation) {
+	t.Helper()
+	if err := repo.Save(v); err != nil {
+		t.Fatalf("Save(%s on %s) error = %v", v.PortfolioID, v.AsOf.Format(time.DateOnly), err)
+	}
+}
+
+func asOfs(snapshots []*portfolio.Valuation) string {
+	s := make([]string, len(snapshots))
+	for i, v := range snapshots {
+		s[i] = v.AsOf.UTC().Format(time.DateOnly)
+	}
+	return fmt.Sprint(s)
+}
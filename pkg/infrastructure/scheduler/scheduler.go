@@ -0,0 +1,97 @@
+// Package scheduler runs the server's periodic jobs, such as the end-of-day valuation snapshots,
+// interest accrual, dividend processing and recommendation expiry.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Schedule decides when a job runs next.
+type Schedule interface {
+	// Next returns the first run time strictly after the given time.
+	Next(after time.Time) time.Time
+}
+
+// every runs a job at a fixed interval.
+type every time.Duration
+
+// Every returns a schedule that runs a job every interval, starting one interval from now.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+// Next returns the time one interval after the given time.
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// dailyAt runs a job once a day at a fixed time of day (UTC).
+type dailyAt time.Duration
+
+// DailyAt returns a schedule that runs a job once a day, offset after midnight UTC
+// (e.g., 22*time.Hour for 22:00 UTC).
+func DailyAt(offset time.Duration) Schedule {
+	return dailyAt(offset)
+}
+
+// Next returns the first time of day after the given time.
+func (d dailyAt) Next(after time.Time) time.Time {
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, time.UTC).Add(time.Duration(d))
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Job is a named task run on a schedule. Run receives the time the run was due.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(now time.Time) error
+}
+
+// Scheduler runs jobs on their schedules until stopped. Each job runs in its own goroutine, so
+// a slow job does not delay the others; a job's runs never overlap.
+type Scheduler struct {
+	jobs []Job
+}
+
+// New creates a Scheduler for the given jobs.
+func New(jobs ...Job) *Scheduler {
+	return &Scheduler{jobs: jobs}
+}
+
+// Run runs the jobs until ctx is cancelled, then waits for the runs in progress to finish.
+// Job errors are logged and do not stop the job's schedule.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// loop waits for each run time of job and runs it.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		next := job.Schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := job.Run(next); err != nil {
+			log.Printf("Scheduled job %q failed: %v\n", job.Name, err)
+		}
+	}
+}
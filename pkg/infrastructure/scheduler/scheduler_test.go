@@ -0,0 +1,66 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jizumer/expedition-value/pkg/infrastructure/scheduler"
+)
+
+func TestDailyAt_Next(t *testing.T) {
+	schedule := scheduler.DailyAt(22 * time.Hour)
+	tests := []struct {
+		name  string
+		after time.Time
+		want  time.Time
+	}{
+		{"SameDay", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC), time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC)},
+		{"AtRunTime", time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC), time.Date(2024, 6, 4, 22, 0, 0, 0, time.UTC)},
+		{"NextDay", time.Date(2024, 6, 3, 23, 30, 0, 0, time.UTC), time.Date(2024, 6, 4, 22, 0, 0, 0, time.UTC)},
+		{"OtherZone", time.Date(2024, 6, 3, 23, 30, 0, 0, time.FixedZone("CEST", 2*3600)), time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	var runs, failures int32
+	s := scheduler.New(
+		scheduler.Job{Name: "counter", Schedule: scheduler.Every(5 * time.Millisecond), Run: func(now time.Time) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}},
+		scheduler.Job{Name: "failing", Schedule: scheduler.Every(5 * time.Millisecond), Run: func(now time.Time) error {
+			atomic.AddInt32(&failures, 1)
+			return errors.New("boom")
+		}},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after the context was cancelled")
+	}
+	if atomic.LoadInt32(&runs) < 2 {
+		t.Errorf("counter ran %d times, want at least 2", runs)
+	}
+	if atomic.LoadInt32(&failures) < 2 {
+		t.Errorf("failing job ran %d times, want it to keep running after errors", failures)
+	}
+}